@@ -0,0 +1,37 @@
+package bootstrap
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gophab/gophrame/core/command"
+	CoreConfig "github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+// 执行子命令，只启动子命令需要的阶段
+func runCommand(cmd *command.Command, args []string) int {
+	if cmd.Bootstrap >= command.BOOTSTRAP_CONFIG {
+		if err := CoreConfig.InitConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, "Load configuration error:", err.Error())
+			return 1
+		}
+
+		for _, f := range cmd.Requires {
+			f()
+		}
+	}
+
+	if cmd.Bootstrap >= command.BOOTSTRAP_INIT {
+		if err := starter.Init(); err != nil {
+			fmt.Fprintln(os.Stderr, "Initializing error:", err.Error())
+			return 1
+		}
+	}
+
+	if err := cmd.Run(args); err != nil {
+		fmt.Fprintln(os.Stderr, cmd.Name+":", err.Error())
+		return 1
+	}
+	return 0
+}
@@ -1,6 +1,8 @@
 package bootstrap
 
 import (
+	"os"
+
 	// system initialization
 	_ "github.com/gophab/gophrame/core/destroy" // 监听程序退出信号，用于资源的释放
 	_ "github.com/gophab/gophrame/core/engine"
@@ -11,12 +13,14 @@ import (
 	// core
 	"github.com/gophab/gophrame/core/command"
 
+	_ "github.com/gophab/gophrame/core/audit"
 	_ "github.com/gophab/gophrame/core/casbin"
 	_ "github.com/gophab/gophrame/core/email"
 	_ "github.com/gophab/gophrame/core/email/code"
 	_ "github.com/gophab/gophrame/core/i18n"
 	_ "github.com/gophab/gophrame/core/microservice"
 	_ "github.com/gophab/gophrame/core/mongo"
+	_ "github.com/gophab/gophrame/core/outbox"
 
 	_ "github.com/gophab/gophrame/core/rabbitmq"
 	_ "github.com/gophab/gophrame/core/redis"
@@ -27,8 +31,10 @@ import (
 	_ "github.com/gophab/gophrame/core/websocket"
 
 	// starter
+	_ "github.com/gophab/gophrame/core/actuator"
 	_ "github.com/gophab/gophrame/core/database/starter"
 	_ "github.com/gophab/gophrame/core/identify/starter"
+	_ "github.com/gophab/gophrame/core/logger/starter"
 	_ "github.com/gophab/gophrame/core/oss/starter"
 	_ "github.com/gophab/gophrame/core/payment/starter"
 	_ "github.com/gophab/gophrame/core/social/starter"
@@ -58,20 +64,29 @@ func Init() {
 	// 1. Register() - RegisterConfig() - RegisterInitializor() - RegisterStarter() - RegisterTerminater - RegisterPlugin - RegisterPlugin
 	logger.Info("Initializing Framework Bootstrap...")
 
-	// 1. 解析命令行参数
+	// 1. 解析命令行参数，非 serve 子命令执行后退出
 	command.Init()
+	if cmd, args := command.Current(); cmd != nil && cmd.Bootstrap != command.BOOTSTRAP_SERVE {
+		os.Exit(runCommand(cmd, args))
+	}
 
-	// 2. 读取配置
-	config.Init()
+	// 2. 读取配置，失败时中止启动，不以默认配置运行
+	if err := config.Init(); err != nil {
+		logger.Fatal("Initializing Framework Config error: ", err.Error())
+	}
 
-	// 3. 启动器
-	starter.Init()
+	// 3. 启动器，失败时中止启动
+	if err := starter.Init(); err != nil {
+		logger.Fatal("Initializing Framework error: ", err.Error())
+	}
 
 	// 4. 启动router
 	router.Init()
 
 	// 5. 启动器
-	starter.Start()
+	if err := starter.Start(); err != nil {
+		logger.Fatal("Starting Framework error: ", err.Error())
+	}
 
 	logger.Info("Initialized Framework Bootstrap")
 }
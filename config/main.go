@@ -7,7 +7,8 @@ import (
 
 var ConfigYml config.IYmlConfig = config.ConfigYml
 
-func Init() {
+// 配置无法解析，或引用的密钥、环境变量、文件无法解析时返回错误
+func Init() error {
 	logger.Info("Initializing Framework Config...")
-	config.InitConfig()
+	return config.InitConfig()
 }
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gophab/gophrame/core/command"
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/global"
+)
+
+func TestInitFailsOnUnresolvedReference(t *testing.T) {
+	defer func(path, profile string) { global.BasePath, command.Profile = path, profile }(global.BasePath, command.Profile)
+	global.BasePath, command.Profile = t.TempDir(), "test"
+
+	if err := os.MkdirAll(filepath.Join(global.BasePath, "conf"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "database:\n  password: ${env:GOPHRAME_TEST_NOT_SET}\n"
+	if err := os.WriteFile(filepath.Join(global.BasePath, "conf", "application-test.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Init(); !errors.Is(err, config.ErrEnvNotSet) {
+		t.Fatalf("expected ErrEnvNotSet, got %v", err)
+	}
+}
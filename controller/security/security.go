@@ -16,10 +16,10 @@ import (
 
 type SecurityController struct {
 	controller.ResourceController
-	MobileValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator"`
-	EmailValidator    *EmailCode.EmailCodeValidator `inject:"emailCodeValidator"`
+	MobileValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator,optional"`
+	EmailValidator    *EmailCode.EmailCodeValidator `inject:"emailCodeValidator,optional"`
 	UserService       service.UserService           `inject:"commonUserService"`
-	InviteCodeService service.InviteCodeService     `inject:"commonInviteCodeService"`
+	InviteCodeService service.InviteCodeService     `inject:"commonInviteCodeService,optional"`
 }
 
 func (c *SecurityController) InitRouter(g *gin.RouterGroup) *gin.RouterGroup {
@@ -113,7 +113,7 @@ func (u *SecurityController) Register(c *gin.Context) {
 		}
 	}
 
-	if form.InviteCode != "" {
+	if form.InviteCode != "" && u.InviteCodeService != nil {
 		// 验证邀请码
 		if iv, err := u.InviteCodeService.FindByInviteCode(form.InviteCode); err != nil {
 			response.SystemErrorMessage(c, 400, err.Error())
@@ -0,0 +1,32 @@
+package actuator
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/permission"
+	"github.com/gophab/gophrame/core/security"
+	"github.com/gophab/gophrame/core/starter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 运维管理端点，仅管理员可访问: /actuator/**
+var Resources = &controller.Controllers{
+	Base: "/actuator",
+	Handlers: []gin.HandlerFunc{
+		security.HandleTokenVerify(), // oauth2 验证
+		permission.NeedAdmin(),
+	},
+	Controllers: []controller.Controller{},
+}
+
+func AddController(c ...controller.Controller) {
+	Resources.AddController(c...)
+}
+
+func init() {
+	starter.RegisterInitializor(Init)
+}
+
+func Init() {
+	controller.AddController(Resources)
+}
@@ -0,0 +1,32 @@
+package actuator
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventbusController struct {
+	controller.ResourceController
+}
+
+var eventbusController = &EventbusController{}
+
+func init() {
+	inject.InjectValue("eventbusController", eventbusController)
+	AddController(eventbusController)
+}
+
+func (c *EventbusController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/eventbus", Handler: c.GetMetrics},
+	})
+}
+
+// 各主题的分发统计
+func (c *EventbusController) GetMetrics(ctx *gin.Context) {
+	response.Success(ctx, eventbus.Metrics())
+}
@@ -0,0 +1,105 @@
+package actuator
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/feign"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 健康状态，按严重程度递增
+const (
+	HEALTH_UP       = "UP"
+	HEALTH_DEGRADED = "DEGRADED"
+	HEALTH_DOWN     = "DOWN"
+)
+
+var healthLevels = map[string]int{HEALTH_UP: 0, HEALTH_DEGRADED: 1, HEALTH_DOWN: 2}
+
+type Health struct {
+	Status  string `json:"status"`
+	Details any    `json:"details,omitempty"`
+}
+
+var (
+	healthMutex      sync.RWMutex
+	healthIndicators = make(map[string]func() Health)
+)
+
+// 注册健康检查项
+func RegisterHealthIndicator(name string, indicator func() Health) {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	healthIndicators[name] = indicator
+}
+
+type HealthController struct {
+	controller.ResourceController
+}
+
+var healthController = &HealthController{}
+
+func init() {
+	inject.InjectValue("healthController", healthController)
+	AddController(healthController)
+
+	RegisterHealthIndicator("feign", feignHealth)
+}
+
+func (c *HealthController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/health", Handler: c.GetHealth},
+	})
+}
+
+// 整体状态取各检查项中最严重的状态，DOWN 时返回 503
+func (c *HealthController) GetHealth(ctx *gin.Context) {
+	healthMutex.RLock()
+	names := make([]string, 0, len(healthIndicators))
+	for name := range healthIndicators {
+		names = append(names, name)
+	}
+	healthMutex.RUnlock()
+	sort.Strings(names)
+
+	status := HEALTH_UP
+	components := make(map[string]Health, len(names))
+	for _, name := range names {
+		healthMutex.RLock()
+		indicator := healthIndicators[name]
+		healthMutex.RUnlock()
+
+		health := indicator()
+		components[name] = health
+		if healthLevels[health.Status] > healthLevels[status] {
+			status = health.Status
+		}
+	}
+
+	code := http.StatusOK
+	if status == HEALTH_DOWN {
+		code = http.StatusServiceUnavailable
+	}
+	response.Response(ctx, code, 0, gin.H{
+		"status":     status,
+		"components": components,
+	})
+}
+
+// 存在打开的熔断器时降级
+func feignHealth() Health {
+	services := feign.Status()
+	status := HEALTH_UP
+	for _, service := range services {
+		if service.CircuitBreaker != nil && service.CircuitBreaker.State != feign.BREAKER_CLOSED {
+			status = HEALTH_DEGRADED
+		}
+	}
+	return Health{Status: status, Details: services}
+}
@@ -0,0 +1,87 @@
+package actuator
+
+import (
+	"net/http"
+
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/cron"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/webservice/request"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobsController struct {
+	controller.ResourceController
+}
+
+var jobsController = &JobsController{}
+
+func init() {
+	inject.InjectValue("jobsController", jobsController)
+	AddController(jobsController)
+}
+
+func (c *JobsController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/jobs", Handler: c.GetJobs},
+		{HttpMethod: "GET", ResourcePath: "/jobs/:name/history", Handler: c.GetHistory},
+		{HttpMethod: "POST", ResourcePath: "/jobs/:name/pause", Handler: c.Pause},
+		{HttpMethod: "POST", ResourcePath: "/jobs/:name/resume", Handler: c.Resume},
+		{HttpMethod: "POST", ResourcePath: "/jobs/:name/trigger", Handler: c.Trigger},
+	})
+}
+
+// 任务列表：调度表达式、暂停状态、下次执行时间、最近一次执行结果
+func (c *JobsController) GetJobs(ctx *gin.Context) {
+	response.Success(ctx, cron.Jobs())
+}
+
+// 执行历史: ?limit=20
+func (c *JobsController) GetHistory(ctx *gin.Context) {
+	limit := request.Param(ctx, "limit").DefaultInt(20)
+
+	result, err := cron.History(ctx.Param("name"), limit)
+	if err != nil {
+		jobError(ctx, err)
+		return
+	}
+	response.Success(ctx, result)
+}
+
+func (c *JobsController) Pause(ctx *gin.Context) {
+	if err := cron.Pause(ctx.Param("name")); err != nil {
+		jobError(ctx, err)
+		return
+	}
+	response.Success(ctx, nil)
+}
+
+func (c *JobsController) Resume(ctx *gin.Context) {
+	if err := cron.Resume(ctx.Param("name")); err != nil {
+		jobError(ctx, err)
+		return
+	}
+	response.Success(ctx, nil)
+}
+
+// 立即触发一次（异步执行）
+func (c *JobsController) Trigger(ctx *gin.Context) {
+	if err := cron.Trigger(ctx.Param("name")); err != nil {
+		jobError(ctx, err)
+		return
+	}
+	response.Success(ctx, nil)
+}
+
+func jobError(ctx *gin.Context, err error) {
+	switch err {
+	case cron.ErrJobNotFound:
+		response.NotFound(ctx, err.Error())
+	case cron.ErrJobRunning:
+		response.ErrorMessage(ctx, http.StatusConflict, http.StatusConflict, err.Error())
+	default:
+		response.SystemError(ctx, err)
+	}
+}
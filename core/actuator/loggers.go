@@ -0,0 +1,66 @@
+package actuator
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoggersController struct {
+	controller.ResourceController
+}
+
+var loggersController = &LoggersController{}
+
+func init() {
+	inject.InjectValue("loggersController", loggersController)
+	AddController(loggersController)
+}
+
+func (c *LoggersController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/loggers", Handler: c.GetLevels},
+		{HttpMethod: "PUT", ResourcePath: "/loggers", Handler: c.SetLevel},
+		{HttpMethod: "DELETE", ResourcePath: "/loggers", Handler: c.ResetLevel},
+	})
+}
+
+// 当前日志级别，默认级别的键为 ""
+func (c *LoggersController) GetLevels(ctx *gin.Context) {
+	response.Success(ctx, logger.Levels())
+}
+
+// 运行时调整日志级别: {"package": "github.com/gophab/gophrame/core/config", "level": "debug"}
+func (c *LoggersController) SetLevel(ctx *gin.Context) {
+	var request struct {
+		Package string `json:"package"`
+		Level   string `json:"level" binding:"required"`
+	}
+	if err := ctx.ShouldBind(&request); err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	if err := logger.SetLevel(request.Package, request.Level); err != nil {
+		response.FailMessage(ctx, errors.INVALID_PARAMS, err.Error())
+		return
+	}
+
+	logger.Info("Change log level: ", request.Package, request.Level)
+	response.Success(ctx, logger.Levels())
+}
+
+func (c *LoggersController) ResetLevel(ctx *gin.Context) {
+	pkg := ctx.Query("package")
+	if pkg == "" {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	logger.ResetLevel(pkg)
+	response.Success(ctx, logger.Levels())
+}
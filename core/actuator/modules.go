@@ -0,0 +1,32 @@
+package actuator
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/module"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModulesController struct {
+	controller.ResourceController
+}
+
+var modulesController = &ModulesController{}
+
+func init() {
+	inject.InjectValue("modulesController", modulesController)
+	AddController(modulesController)
+}
+
+func (c *ModulesController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/modules", Handler: c.GetModules},
+	})
+}
+
+// 模块列表：按启动顺序，包含依赖、状态与失败原因
+func (c *ModulesController) GetModules(ctx *gin.Context) {
+	response.Success(ctx, module.Modules())
+}
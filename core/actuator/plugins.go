@@ -0,0 +1,60 @@
+package actuator
+
+import (
+	"errors"
+
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/plugin"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PluginsController struct {
+	controller.ResourceController
+}
+
+var pluginsController = &PluginsController{}
+
+func init() {
+	inject.InjectValue("pluginsController", pluginsController)
+	AddController(pluginsController)
+}
+
+func (c *PluginsController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/plugins", Handler: c.GetPlugins},
+		{HttpMethod: "POST", ResourcePath: "/plugins/:uuid/enable", Handler: c.Enable},
+		{HttpMethod: "POST", ResourcePath: "/plugins/:uuid/disable", Handler: c.Disable},
+	})
+}
+
+// 插件列表：版本、运行方式、挂载路径、启用状态与失败原因
+func (c *PluginsController) GetPlugins(ctx *gin.Context) {
+	response.Success(ctx, plugin.Plugins())
+}
+
+func (c *PluginsController) Enable(ctx *gin.Context) {
+	if err := plugin.Enable(ctx.Param("uuid")); err != nil {
+		pluginError(ctx, err)
+		return
+	}
+	response.Success(ctx, nil)
+}
+
+func (c *PluginsController) Disable(ctx *gin.Context) {
+	if err := plugin.Disable(ctx.Param("uuid")); err != nil {
+		pluginError(ctx, err)
+		return
+	}
+	response.Success(ctx, nil)
+}
+
+func pluginError(ctx *gin.Context, err error) {
+	if errors.Is(err, plugin.ErrPluginNotFound) {
+		response.NotFound(ctx, err.Error())
+	} else {
+		response.SystemError(ctx, err)
+	}
+}
@@ -0,0 +1,477 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/audit/config"
+	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/logger"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
+	"github.com/gophab/gophrame/core/starter"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	ACTION_CREATE = "CREATE"
+	ACTION_UPDATE = "UPDATE"
+	ACTION_DELETE = "DELETE"
+)
+
+const REDACTED = "******"
+
+var ErrImmutable = errors.New("audit log is append-only")
+
+// 实现该接口的模型在增删改时自动记录字段变更，返回值作为审计对象名称
+type Auditable interface {
+	AuditTarget() string
+}
+
+// 字段变更，脱敏字段只记录是否变化
+type Change struct {
+	Field    string `json:"field"`
+	Before   any    `json:"before,omitempty"`
+	After    any    `json:"after,omitempty"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+var (
+	stopSeal context.CancelFunc
+	sealDone chan struct{}
+)
+
+func init() {
+	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
+}
+
+func Start() {
+	logger.Debug("Enable audit trail: ", config.Setting.Enabled)
+	if !config.Setting.Enabled || global.DB == nil {
+		return
+	}
+
+	registerHooks(global.DB)
+	startSeal(max(config.Setting.SealInterval, 100*time.Millisecond))
+}
+
+func startSeal(interval time.Duration) {
+	var ctx context.Context
+	ctx, stopSeal = context.WithCancel(context.Background())
+	sealDone = make(chan struct{})
+	go func() {
+		defer close(sealDone)
+		auditRepository.sealLoop(ctx, interval)
+	}()
+}
+
+// 停止定期入链，等待剩余的待入链日志处理完成，超时后放弃等待
+func Terminate() {
+	if stopSeal == nil {
+		return
+	}
+	stopSeal()
+	stopSeal = nil
+
+	timeout := config.Setting.TerminateTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	select {
+	case <-sealDone:
+	case <-time.After(timeout):
+		logger.Warn("Seal audit log timeout after ", timeout.String())
+	}
+}
+
+func registerHooks(db *gorm.DB) {
+	db.Callback().Create().After("gorm:create").Register("AuditCreateHook", AuditCreateHook)
+	db.Callback().Update().Before("gorm:update").Register("AuditBeforeUpdateHook", AuditBeforeHook)
+	db.Callback().Update().After("gorm:update").Register("AuditUpdateHook", AuditUpdateHook)
+	db.Callback().Delete().Before("gorm:delete").Register("AuditBeforeDeleteHook", AuditBeforeHook)
+	db.Callback().Delete().After("gorm:delete").Register("AuditDeleteHook", AuditDeleteHook)
+}
+
+func auditTarget(db *gorm.DB) (string, bool) {
+	if db.Statement.Schema == nil {
+		return "", false
+	}
+	if auditable, b := reflect.New(db.Statement.Schema.ModelType).Interface().(Auditable); b {
+		return auditable.AuditTarget(), true
+	}
+	return "", false
+}
+
+// 1. 更新和删除前读取原值
+func AuditBeforeHook(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+
+	if db.Statement.Schema.ModelType == reflect.TypeOf(AuditLog{}) {
+		db.AddError(ErrImmutable)
+		return
+	}
+
+	if _, b := auditTarget(db); b {
+		db.InstanceSet("audit:before", loadBefore(db))
+	}
+}
+
+// 2. 新增
+func AuditCreateHook(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	target, b := auditTarget(db)
+	if !b {
+		return
+	}
+
+	var logs []*AuditLog
+	eachItem(db.Statement.ReflectValue, func(item reflect.Value) {
+		logs = append(logs, newLog(db, ACTION_CREATE, target, item, diff(db, reflect.Value{}, item)))
+	})
+	record(db, logs)
+}
+
+// 3. 修改：重新读取新值并与原值比较，只记录变化的字段
+func AuditUpdateHook(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	target, b := auditTarget(db)
+	if !b {
+		return
+	}
+
+	before := instanceBefore(db)
+	if !before.IsValid() || before.Len() == 0 {
+		return
+	}
+	after := loadAfter(db, before, false)
+
+	var logs []*AuditLog
+	for i := 0; i < before.Len(); i++ {
+		item := before.Index(i)
+		if changes := diff(db, item, after[primaryKey(db, item)]); len(changes) > 0 {
+			logs = append(logs, newLog(db, ACTION_UPDATE, target, item, changes))
+		}
+	}
+	record(db, logs)
+}
+
+// 4. 删除：软删除时记录变化的字段，否则记录全部原值
+func AuditDeleteHook(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	target, b := auditTarget(db)
+	if !b {
+		return
+	}
+
+	before := instanceBefore(db)
+	if !before.IsValid() || before.Len() == 0 {
+		return
+	}
+	after := loadAfter(db, before, true)
+
+	var logs []*AuditLog
+	for i := 0; i < before.Len(); i++ {
+		item := before.Index(i)
+		logs = append(logs, newLog(db, ACTION_DELETE, target, item, diff(db, item, after[primaryKey(db, item)])))
+	}
+	record(db, logs)
+}
+
+func eachItem(value reflect.Value, fn func(item reflect.Value)) {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if item := reflect.Indirect(value.Index(i)); item.Kind() == reflect.Struct {
+				fn(item)
+			}
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+func instanceBefore(db *gorm.DB) reflect.Value {
+	if v, b := db.InstanceGet("audit:before"); b {
+		if value, ok := v.(reflect.Value); ok && value.IsValid() {
+			return value
+		}
+	}
+	return reflect.Value{}
+}
+
+// 按语句的 WHERE 条件和模型主键读取受影响的行，没有条件时不读取
+func loadBefore(db *gorm.DB) reflect.Value {
+	stmt := db.Statement
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(stmt.Table)
+
+	var conditions = false
+	if c, b := stmt.Clauses["WHERE"]; b {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			tx = tx.Clauses(clause.Where{Exprs: append([]clause.Expression(nil), where.Exprs...)})
+			conditions = true
+		}
+	}
+
+	var items []reflect.Value
+	eachItem(stmt.ReflectValue, func(item reflect.Value) {
+		items = append(items, item)
+	})
+	if condition := primaryKeyCondition(db, items); condition != nil {
+		tx = tx.Where(condition)
+		conditions = true
+	}
+
+	if !conditions {
+		return reflect.Value{}
+	}
+
+	if config.Setting.MaxRows > 0 {
+		tx = tx.Limit(config.Setting.MaxRows + 1)
+	}
+
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	if err := tx.Find(rows.Interface()).Error; err != nil {
+		logger.Warn("Load audit values error: ", stmt.Table, err.Error())
+		return reflect.Value{}
+	}
+
+	result := rows.Elem()
+	if limit := config.Setting.MaxRows; limit > 0 && result.Len() > limit {
+		logger.Warn("Too many rows to audit, truncated: ", stmt.Table, limit)
+		result = result.Slice(0, limit)
+	}
+	return result
+}
+
+// 按主键重新读取，软删除的行需 Unscoped
+func loadAfter(db *gorm.DB, before reflect.Value, unscoped bool) map[string]reflect.Value {
+	var result = make(map[string]reflect.Value)
+
+	var items []reflect.Value
+	for i := 0; i < before.Len(); i++ {
+		items = append(items, before.Index(i))
+	}
+	condition := primaryKeyCondition(db, items)
+	if condition == nil {
+		return result
+	}
+
+	stmt := db.Statement
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(stmt.Table)
+	if unscoped {
+		tx = tx.Unscoped()
+	}
+
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	if err := tx.Where(condition).Find(rows.Interface()).Error; err != nil {
+		logger.Warn("Load audit values error: ", stmt.Table, err.Error())
+		return result
+	}
+
+	for i := 0; i < rows.Elem().Len(); i++ {
+		item := rows.Elem().Index(i)
+		result[primaryKey(db, item)] = item
+	}
+	return result
+}
+
+// 按主键定位行：单主键使用 IN，联合主键逐行组合条件
+func primaryKeyCondition(db *gorm.DB, items []reflect.Value) clause.Expression {
+	fields := db.Statement.Schema.PrimaryFields
+	if len(fields) == 0 || len(items) == 0 {
+		return nil
+	}
+
+	var exprs []clause.Expression
+	var ids []any
+	for _, item := range items {
+		var eqs []clause.Expression
+		for _, field := range fields {
+			v, zero := field.ValueOf(db.Statement.Context, item)
+			if zero {
+				eqs = nil
+				break
+			}
+			eqs = append(eqs, clause.Eq{Column: clause.Column{Name: field.DBName}, Value: v})
+			ids = append(ids, v)
+		}
+		if len(eqs) > 0 {
+			exprs = append(exprs, clause.And(eqs...))
+		}
+	}
+
+	switch {
+	case len(exprs) == 0:
+		return nil
+	case len(fields) == 1:
+		return clause.IN{Column: clause.Column{Name: fields[0].DBName}, Values: ids}
+	default:
+		return clause.Or(exprs...)
+	}
+}
+
+func primaryKey(db *gorm.DB, item reflect.Value) string {
+	var keys []string
+	for _, field := range db.Statement.Schema.PrimaryFields {
+		if v, zero := field.ValueOf(db.Statement.Context, item); !zero {
+			keys = append(keys, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// 比较字段，before 或 after 无效时分别视为新增和删除
+func diff(db *gorm.DB, before, after reflect.Value) []*Change {
+	var changes = make([]*Change, 0)
+	for _, field := range db.Statement.Schema.Fields {
+		if field.DBName == "" || field.AutoUpdateTime > 0 || field.Tag.Get("audit") == "-" {
+			continue
+		}
+
+		var change = &Change{Field: field.DBName}
+		if before.IsValid() {
+			change.Before = fieldValue(db, before, field)
+		}
+		if after.IsValid() {
+			change.After = fieldValue(db, after, field)
+		}
+		if change.Before == nil && change.After == nil {
+			continue
+		}
+		if before.IsValid() && after.IsValid() && equal(change.Before, change.After) {
+			continue
+		}
+
+		if redacted(field) {
+			change.Redacted = true
+			if change.Before != nil {
+				change.Before = REDACTED
+			}
+			if change.After != nil {
+				change.After = REDACTED
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func fieldValue(db *gorm.DB, item reflect.Value, field *schema.Field) any {
+	v, zero := field.ValueOf(db.Statement.Context, item)
+	if zero {
+		return nil
+	}
+
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+func equal(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	x, err1 := json.Marshal(a)
+	y, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(x) == string(y)
+}
+
+func redacted(field *schema.Field) bool {
+	if field.Tag.Get("audit") == "redact" {
+		return true
+	}
+	if _, b := field.Tag.Lookup("sensitive"); b {
+		return true
+	}
+
+	name := strings.ToLower(field.DBName)
+	for _, keyword := range config.Setting.Redact {
+		if keyword != "" && strings.Contains(name, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+func newLog(db *gorm.DB, action string, target string, item reflect.Value, changes []*Change) *AuditLog {
+	var log = &AuditLog{
+		Action:   action,
+		Target:   target,
+		TargetId: primaryKey(db, item),
+		Changes:  string(must(json.Marshal(changes))),
+	}
+
+	if c := SecurityUtil.GetCurrentContext(); c != nil {
+		log.OperatorId = SecurityUtil.GetCurrentUserId(c)
+		log.Ip = c.ClientIP()
+		log.RequestId = c.GetString("_REQUEST_ID_")
+		if v, b := c.Value("_CURRENT_TENANT_ID_").(string); b {
+			log.TenantId = v
+		}
+	}
+
+	// 优先使用数据所属租户
+	if field := db.Statement.Schema.LookUpField("tenant_id"); field != nil {
+		if v := fieldValue(db, item, field); v != nil {
+			log.TenantId = fmt.Sprint(v)
+		}
+	}
+	return log
+}
+
+func must(data []byte, err error) []byte {
+	if err != nil {
+		return []byte("[]")
+	}
+	return data
+}
+
+func record(db *gorm.DB, logs []*AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+
+	// 与业务语句在同一事务中写入，回滚时不留下日志；非严格模式下写入失败回滚到保存点，不影响业务事务
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	_, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter)
+	savepoint := inTransaction && !config.Setting.Strict && tx.SavePoint("audit").Error == nil
+
+	if err := auditRepository.Stage(tx, logs...); err != nil {
+		logger.Error("Append audit log error: ", db.Statement.Table, err.Error())
+		if config.Setting.Strict {
+			db.AddError(err)
+		} else if savepoint {
+			tx.RollbackTo("audit")
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/audit/config"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 审计日志：Id 连续递增，Hash 覆盖本行内容和上一行的 Hash，任一行被修改或删除都会使链断开
+type AuditLog struct {
+	Id          int64  `gorm:"column:id;primaryKey;autoIncrement:false" json:"id"`
+	Action      string `gorm:"column:action" json:"action"`
+	Target      string `gorm:"column:target" json:"target"`
+	TargetId    string `gorm:"column:target_id" json:"targetId"`
+	Changes     string `gorm:"column:changes" json:"changes"` // []Change JSON
+	OperatorId  string `gorm:"column:operator_id" json:"operatorId,omitempty"`
+	TenantId    string `gorm:"column:tenant_id" json:"tenantId,omitempty"`
+	Ip          string `gorm:"column:ip" json:"ip,omitempty"`
+	RequestId   string `gorm:"column:request_id" json:"requestId,omitempty"`
+	CreatedTime int64  `gorm:"column:created_time" json:"createdTime"` // 毫秒时间戳，不受数据库时区影响
+	PrevHash    string `gorm:"column:prev_hash" json:"prevHash"`
+	Hash        string `gorm:"column:hash" json:"hash"`
+}
+
+func (*AuditLog) TableName() string {
+	return "sys_audit_log"
+}
+
+// 链头：最后一行的 Id 和 Hash，入链时行锁保证集群内串行；同时用于发现尾部被截断
+type AuditHead struct {
+	Id     int64  `gorm:"column:id;primaryKey;autoIncrement:false"`
+	LastId int64  `gorm:"column:last_id"`
+	Hash   string `gorm:"column:hash"`
+}
+
+func (*AuditHead) TableName() string {
+	return "sys_audit_head"
+}
+
+func (l *AuditLog) Digest() string {
+	var h hash.Hash
+	if config.Setting.Secret != "" {
+		h = hmac.New(sha256.New, []byte(config.Setting.Secret))
+	} else {
+		h = sha256.New()
+	}
+
+	// 长度前缀，避免字段拼接产生歧义
+	for _, v := range []string{
+		strconv.FormatInt(l.Id, 10),
+		l.PrevHash,
+		l.Action,
+		l.Target,
+		l.TargetId,
+		l.Changes,
+		l.OperatorId,
+		l.TenantId,
+		l.Ip,
+		l.RequestId,
+		strconv.FormatInt(l.CreatedTime, 10),
+	} {
+		h.Write([]byte(strconv.Itoa(len(v)) + ":" + v))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 待入链的审计日志：与业务数据在同一事务中写入，业务回滚时一并回滚；
+// 提交后由 Seal 按 Seq 顺序追加到哈希链，业务事务不占用链头
+type AuditPending struct {
+	Seq         int64  `gorm:"column:seq;primaryKey;autoIncrement"`
+	Action      string `gorm:"column:action"`
+	Target      string `gorm:"column:target"`
+	TargetId    string `gorm:"column:target_id"`
+	Changes     string `gorm:"column:changes"`
+	OperatorId  string `gorm:"column:operator_id"`
+	TenantId    string `gorm:"column:tenant_id"`
+	Ip          string `gorm:"column:ip"`
+	RequestId   string `gorm:"column:request_id"`
+	CreatedTime int64  `gorm:"column:created_time"`
+}
+
+func (*AuditPending) TableName() string {
+	return "sys_audit_pending"
+}
+
+type AuditRepository struct {
+	*gorm.DB `inject:"database"`
+	mutex    sync.Mutex
+}
+
+var auditRepository = &AuditRepository{}
+
+func init() {
+	inject.InjectValue("auditRepository", auditRepository)
+}
+
+// 追加审计日志：写入待入链表后立即入链
+func Append(logs ...*AuditLog) error {
+	if err := auditRepository.Stage(auditRepository.DB, logs...); err != nil {
+		return err
+	}
+	_, err := auditRepository.Seal()
+	return err
+}
+
+// 在 db 所在的连接（事务）中写入待入链的日志，不加锁
+func (r *AuditRepository) Stage(db *gorm.DB, logs ...*AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	pending := make([]*AuditPending, 0, len(logs))
+	for _, log := range logs {
+		if log.CreatedTime == 0 {
+			log.CreatedTime = now
+		}
+		pending = append(pending, &AuditPending{
+			Action:      log.Action,
+			Target:      log.Target,
+			TargetId:    log.TargetId,
+			Changes:     log.Changes,
+			OperatorId:  log.OperatorId,
+			TenantId:    log.TenantId,
+			Ip:          log.Ip,
+			RequestId:   log.RequestId,
+			CreatedTime: log.CreatedTime,
+		})
+	}
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&pending).Error
+}
+
+// 将已提交的待入链日志追加到哈希链，返回本批入链的条数；
+// 独立的短事务中锁定链头，集群内串行，不持有其他连接
+func (r *AuditRepository) Seal() (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var count int
+	err := r.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+		var head AuditHead
+		if res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", 1).Limit(1).Find(&head); res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			head = AuditHead{Id: 1}
+			if err := tx.Create(&head).Error; err != nil {
+				return err
+			}
+		}
+
+		var pending []*AuditPending
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("seq ASC").Limit(batchSize).Find(&pending).Error; err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		logs := make([]*AuditLog, 0, len(pending))
+		seqs := make([]int64, 0, len(pending))
+		for _, p := range pending {
+			log := &AuditLog{
+				Id:          head.LastId + 1,
+				PrevHash:    head.Hash,
+				Action:      p.Action,
+				Target:      p.Target,
+				TargetId:    p.TargetId,
+				Changes:     p.Changes,
+				OperatorId:  p.OperatorId,
+				TenantId:    p.TenantId,
+				Ip:          p.Ip,
+				RequestId:   p.RequestId,
+				CreatedTime: p.CreatedTime,
+			}
+			log.Hash = log.Digest()
+			head.LastId, head.Hash = log.Id, log.Hash
+			logs = append(logs, log)
+			seqs = append(seqs, p.Seq)
+		}
+
+		if err := tx.Create(&logs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("seq in ?", seqs).Delete(&AuditPending{}).Error; err != nil {
+			return err
+		}
+		count = len(logs)
+		return tx.Model(&AuditHead{}).Where("id = ?", 1).UpdateColumns(map[string]any{
+			"last_id": head.LastId,
+			"hash":    head.Hash,
+		}).Error
+	})
+	return count, err
+}
+
+// 定期入链，每次处理到没有待入链的日志为止
+func (r *AuditRepository) sealLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.sealAll()
+			return
+		case <-ticker.C:
+			r.sealAll()
+		}
+	}
+}
+
+func (r *AuditRepository) sealAll() {
+	for {
+		count, err := r.Seal()
+		if err != nil {
+			logger.Error("Seal audit log error: ", err.Error())
+			return
+		}
+		if count < batchSize {
+			return
+		}
+	}
+}
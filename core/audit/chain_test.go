@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type auditItem struct {
+	Id       string `gorm:"column:id;primaryKey"`
+	Name     string `gorm:"column:name"`
+	Password string `gorm:"column:password"`
+}
+
+func (*auditItem) TableName() string {
+	return "test_audit_item"
+}
+
+func (*auditItem) AuditTarget() string {
+	return "item"
+}
+
+func openChain(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "audit.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&auditItem{}, &AuditLog{}, &AuditHead{}, &AuditPending{}); err != nil {
+		t.Fatal(err)
+	}
+	registerHooks(db)
+
+	previous := auditRepository.DB
+	auditRepository.DB = db
+	t.Cleanup(func() { auditRepository.DB = previous })
+	return db
+}
+
+func TestRollbackLeavesNoLog(t *testing.T) {
+	db := openChain(t)
+
+	if err := db.Create(&auditItem{Id: "1", Name: "first", Password: "p1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	rollback := errors.New("rollback")
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&auditItem{Id: "2", Name: "second"}).Error; err != nil {
+			return err
+		}
+		return rollback
+	}); err != rollback {
+		t.Fatalf("transaction = %v", err)
+	}
+	if err := db.Model(&auditItem{Id: "1"}).Update("name", "renamed").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := auditRepository.Seal()
+	if err != nil || count != 2 {
+		t.Fatalf("seal = %d, %v", count, err)
+	}
+
+	var logs []*AuditLog
+	db.Order("id").Find(&logs)
+	if len(logs) != 2 || logs[0].Action != ACTION_CREATE || logs[1].Action != ACTION_UPDATE || logs[1].TargetId != "1" {
+		t.Fatalf("unexpected logs: %+v", logs)
+	}
+
+	var pending int64
+	db.Model(&AuditPending{}).Count(&pending)
+	if pending != 0 {
+		t.Fatalf("pending logs left: %d", pending)
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	db := openChain(t)
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := db.Create(&auditItem{Id: name, Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+		// 分批入链，链在批次之间保持连续
+		if _, err := auditRepository.Seal(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := Verify(0, 0)
+	if err != nil || !result.Valid || result.Checked != 3 || result.LastId != 3 {
+		t.Fatalf("verify = %+v, %v", result, err)
+	}
+
+	// 篡改中间一行：本行哈希不符
+	if err := db.Exec("UPDATE sys_audit_log SET target_id = ? WHERE id = ?", "x", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	if result, _ = Verify(0, 0); result.Valid || result.BrokenId != 2 || result.Reason != "hash mismatch" {
+		t.Fatalf("tampered row not detected: %+v", result)
+	}
+	if result, _ = Verify(3, 0); !result.Valid {
+		t.Fatalf("range after tampered row should be valid: %+v", result)
+	}
+
+	// 删除链尾：只能通过链头发现
+	db.Exec("UPDATE sys_audit_log SET target_id = ? WHERE id = ?", "b", 2)
+	if err := db.Exec("DELETE FROM sys_audit_log WHERE id = ?", 3).Error; err != nil {
+		t.Fatal(err)
+	}
+	if result, _ = Verify(0, 0); result.Valid || result.Reason != "head mismatch" {
+		t.Fatalf("truncated tail not detected: %+v", result)
+	}
+}
+
+func TestAuditLogImmutable(t *testing.T) {
+	db := openChain(t)
+
+	if err := db.Create(&auditItem{Id: "1", Name: "first"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auditRepository.Seal(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&AuditLog{}).Where("id = ?", 1).Update("target_id", "x").Error; !errors.Is(err, ErrImmutable) {
+		t.Fatalf("expected ErrImmutable, got %v", err)
+	}
+}
+
+func TestTerminateSealsPending(t *testing.T) {
+	db := openChain(t)
+
+	startSeal(time.Hour)
+	if err := db.Create(&auditItem{Id: "1", Name: "first"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Terminate 返回时剩余的日志已入链
+	Terminate()
+
+	var pending, logs int64
+	db.Model(&AuditPending{}).Count(&pending)
+	db.Model(&AuditLog{}).Count(&logs)
+	if pending != 0 || logs != 1 {
+		t.Fatalf("pending=%d logs=%d after terminate", pending, logs)
+	}
+}
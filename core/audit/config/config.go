@@ -0,0 +1,33 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type AuditSetting struct {
+	Enabled bool     `json:"enabled" yaml:"enabled"`
+	Secret  string   `json:"secret" yaml:"secret"`   // 设置后使用 HMAC-SHA256 计算哈希链，没有密钥无法伪造
+	Redact  []string `json:"redact" yaml:"redact"`   // 字段名包含这些关键字时脱敏（另有 audit:"redact" 和 sensitive 标签）
+	MaxRows int      `json:"maxRows" yaml:"maxRows"` // 单条语句最多记录的行数，超出部分不记录
+	Strict  bool     `json:"strict" yaml:"strict"`   // 审计日志写入失败时语句返回错误
+
+	SealInterval     time.Duration `json:"sealInterval" yaml:"sealInterval"`         // 已提交的日志追加到哈希链的间隔
+	TerminateTimeout time.Duration `json:"terminateTimeout" yaml:"terminateTimeout"` // 退出时等待剩余日志入链的最长时间
+}
+
+var Setting *AuditSetting = &AuditSetting{
+	Enabled: false,
+	Redact:  []string{"password", "secret", "token", "salt", "credential"},
+	MaxRows: 1000,
+
+	SealInterval:     time.Second,
+	TerminateTimeout: 10 * time.Second,
+}
+
+func init() {
+	logger.Debug("Register Audit Config")
+	config.RegisterConfig("audit", Setting, "Audit Trail Settings")
+}
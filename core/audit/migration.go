@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"embed"
+
+	"github.com/gophab/gophrame/core/database"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+func init() {
+	database.RegisterMigrations("audit", migrations)
+}
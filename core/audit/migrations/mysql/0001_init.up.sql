@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS sys_audit_log (
+    id BIGINT NOT NULL,
+    action VARCHAR(16),
+    target VARCHAR(64),
+    target_id VARCHAR(255),
+    changes LONGTEXT,
+    operator_id VARCHAR(64),
+    tenant_id VARCHAR(64),
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_time BIGINT,
+    prev_hash VARCHAR(64),
+    hash VARCHAR(64),
+    PRIMARY KEY (id),
+    KEY idx_sys_audit_log_target (target, target_id),
+    KEY idx_sys_audit_log_operator_id (operator_id),
+    KEY idx_sys_audit_log_tenant_id_created_time (tenant_id, created_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_audit_head (
+    id BIGINT NOT NULL,
+    last_id BIGINT,
+    hash VARCHAR(64),
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT IGNORE INTO sys_audit_head (id, last_id, hash) VALUES (1, 0, '');
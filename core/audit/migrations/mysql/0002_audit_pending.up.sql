@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sys_audit_pending (
+    seq BIGINT NOT NULL AUTO_INCREMENT,
+    action VARCHAR(16),
+    target VARCHAR(64),
+    target_id VARCHAR(255),
+    changes LONGTEXT,
+    operator_id VARCHAR(64),
+    tenant_id VARCHAR(64),
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_time BIGINT,
+    PRIMARY KEY (seq)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sys_audit_head;
+
+DROP TABLE IF EXISTS sys_audit_log;
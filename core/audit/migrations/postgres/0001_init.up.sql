@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS sys_audit_log (
+    id BIGINT NOT NULL,
+    action VARCHAR(16),
+    target VARCHAR(64),
+    target_id VARCHAR(255),
+    changes TEXT,
+    operator_id VARCHAR(64),
+    tenant_id VARCHAR(64),
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_time BIGINT,
+    prev_hash VARCHAR(64),
+    hash VARCHAR(64),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_audit_log_target ON sys_audit_log (target, target_id);
+CREATE INDEX IF NOT EXISTS idx_sys_audit_log_operator_id ON sys_audit_log (operator_id);
+CREATE INDEX IF NOT EXISTS idx_sys_audit_log_tenant_id_created_time ON sys_audit_log (tenant_id, created_time);
+
+CREATE TABLE IF NOT EXISTS sys_audit_head (
+    id BIGINT NOT NULL,
+    last_id BIGINT,
+    hash VARCHAR(64),
+    PRIMARY KEY (id)
+);
+
+INSERT INTO sys_audit_head (id, last_id, hash) VALUES (1, 0, '') ON CONFLICT (id) DO NOTHING;
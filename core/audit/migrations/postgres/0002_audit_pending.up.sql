@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sys_audit_pending (
+    seq BIGSERIAL NOT NULL,
+    action VARCHAR(16),
+    target VARCHAR(64),
+    target_id VARCHAR(255),
+    changes TEXT,
+    operator_id VARCHAR(64),
+    tenant_id VARCHAR(64),
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_time BIGINT,
+    PRIMARY KEY (seq)
+);
@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gophab/gophrame/core/query"
+
+	"gorm.io/gorm"
+)
+
+const batchSize = 500
+
+// 查询条件，零值表示不限
+type Criteria struct {
+	Target     string
+	TargetId   string
+	OperatorId string
+	TenantId   string
+	Action     string
+	From       time.Time
+	To         time.Time
+}
+
+// 校验结果：Valid 为 false 时 BrokenId 为第一处断开的位置
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	LastId   int64  `json:"lastId"`
+	BrokenId int64  `json:"brokenId,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func (r *AuditRepository) where(criteria *Criteria) *gorm.DB {
+	tx := r.Model(&AuditLog{})
+	if criteria == nil {
+		return tx
+	}
+
+	if criteria.Target != "" {
+		tx = tx.Where("target = ?", criteria.Target)
+	}
+	if criteria.TargetId != "" {
+		tx = tx.Where("target_id = ?", criteria.TargetId)
+	}
+	if criteria.OperatorId != "" {
+		tx = tx.Where("operator_id = ?", criteria.OperatorId)
+	}
+	if criteria.TenantId != "" {
+		tx = tx.Where("tenant_id = ?", criteria.TenantId)
+	}
+	if criteria.Action != "" {
+		tx = tx.Where("action = ?", criteria.Action)
+	}
+	if !criteria.From.IsZero() {
+		tx = tx.Where("created_time >= ?", criteria.From.UnixMilli())
+	}
+	if !criteria.To.IsZero() {
+		tx = tx.Where("created_time < ?", criteria.To.UnixMilli())
+	}
+	return tx
+}
+
+func Find(criteria *Criteria, pageable query.Pageable) (int64, []*AuditLog, error) {
+	return auditRepository.Find(criteria, pageable)
+}
+
+func (r *AuditRepository) Find(criteria *Criteria, pageable query.Pageable) (int64, []*AuditLog, error) {
+	var count int64 = 0
+	if !pageable.NoCount() {
+		if res := r.where(criteria).Count(&count); res.Error != nil {
+			return 0, nil, res.Error
+		}
+	}
+
+	var results = make([]*AuditLog, 0)
+	if res := query.Page(r.where(criteria).Order("id DESC"), pageable).Find(&results); res.Error != nil {
+		return 0, nil, res.Error
+	}
+	return count, results, nil
+}
+
+// 按 Id 顺序分批读取
+func (r *AuditRepository) each(tx func() *gorm.DB, from int64, fn func(log *AuditLog) error) error {
+	for {
+		var batch []*AuditLog
+		if err := tx().Where("id >= ?", from).Order("id ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, log := range batch {
+			if err := fn(log); err != nil {
+				return err
+			}
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		from = batch[len(batch)-1].Id + 1
+	}
+}
+
+// 校验 [from, to] 范围内的哈希链，to 为 0 时校验到链尾并与链头比较
+func Verify(from, to int64) (*VerifyResult, error) {
+	return auditRepository.Verify(from, to)
+}
+
+func (r *AuditRepository) Verify(from, to int64) (*VerifyResult, error) {
+	from = max(from, 1)
+
+	var result = &VerifyResult{Valid: true, LastId: from - 1}
+	var prevHash string
+	if from > 1 {
+		var prev AuditLog
+		if res := r.Where("id = ?", from-1).Limit(1).Find(&prev); res.Error != nil {
+			return nil, res.Error
+		} else if res.RowsAffected == 0 {
+			result.Valid, result.BrokenId, result.Reason = false, from-1, "missing"
+			return result, nil
+		}
+		prevHash = prev.Hash
+	}
+
+	broken := func(id int64, reason string) error {
+		result.Valid, result.BrokenId, result.Reason = false, id, reason
+		return io.EOF
+	}
+
+	err := r.each(func() *gorm.DB {
+		tx := r.Model(&AuditLog{})
+		if to > 0 {
+			tx = tx.Where("id <= ?", to)
+		}
+		return tx
+	}, from, func(log *AuditLog) error {
+		switch {
+		case log.Id != result.LastId+1:
+			return broken(result.LastId+1, "missing")
+		case log.PrevHash != prevHash:
+			return broken(log.Id, "prev hash mismatch")
+		case log.Digest() != log.Hash:
+			return broken(log.Id, "hash mismatch")
+		}
+		result.Checked++
+		result.LastId, prevHash = log.Id, log.Hash
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !result.Valid {
+		return result, nil
+	}
+
+	if to > 0 {
+		if result.LastId < to {
+			result.Valid, result.BrokenId, result.Reason = false, result.LastId+1, "missing"
+		}
+		return result, nil
+	}
+
+	// 链尾被删除时只能通过链头发现
+	var head AuditHead
+	if res := r.Where("id = ?", 1).Limit(1).Find(&head); res.Error != nil {
+		return nil, res.Error
+	}
+	if head.LastId != result.LastId || head.Hash != prevHash {
+		result.Valid, result.BrokenId, result.Reason = false, result.LastId+1, "head mismatch"
+	}
+	return result, nil
+}
+
+// 导出：format 为 csv 或 json（每行一个 JSON 对象），包含哈希以便离线校验
+func Export(writer io.Writer, format string, criteria *Criteria) error {
+	return auditRepository.Export(writer, format, criteria)
+}
+
+func (r *AuditRepository) Export(writer io.Writer, format string, criteria *Criteria) error {
+	tx := func() *gorm.DB { return r.where(criteria) }
+
+	if format == "json" {
+		encoder := json.NewEncoder(writer)
+		return r.each(tx, 1, func(log *AuditLog) error {
+			return encoder.Encode(log)
+		})
+	}
+
+	w := csv.NewWriter(writer)
+	_ = w.Write([]string{"id", "time", "created_time", "action", "target", "target_id", "operator_id", "tenant_id", "ip", "request_id", "changes", "prev_hash", "hash"})
+	err := r.each(tx, 1, func(log *AuditLog) error {
+		return w.Write([]string{
+			strconv.FormatInt(log.Id, 10),
+			time.UnixMilli(log.CreatedTime).Format(time.RFC3339Nano),
+			strconv.FormatInt(log.CreatedTime, 10),
+			log.Action,
+			log.Target,
+			log.TargetId,
+			log.OperatorId,
+			log.TenantId,
+			log.Ip,
+			log.RequestId,
+			log.Changes,
+			log.PrevHash,
+			log.Hash,
+		})
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	return w.Error()
+}
@@ -19,7 +19,7 @@ type Captcha struct {
 }
 
 type CaptchaService struct {
-	Store   code.CodeStore `inject:"captchaCodeStore"`
+	Store   code.CodeStore `inject:"captchaCodeStore,optional"`
 	captcha *base64Captcha.Captcha
 }
 
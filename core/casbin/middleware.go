@@ -17,11 +17,11 @@ import (
 // Casbin检查用户对应的角色权限是否允许访问接口
 
 var __ = struct {
-	Enforcer *casbin.SyncedEnforcer `inject:"enforcer"`
+	Enforcer *casbin.SyncedEnforcer `inject:"enforcer,optional"`
 }{}
 
 func init() {
-	inject.InjectValue("casbin", __)
+	inject.InjectValue("casbin", &__)
 }
 
 // CasbinHandler 拦截器
@@ -1,6 +1,13 @@
 package command
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
 	"github.com/spf13/pflag"
 
 	"github.com/gophab/gophrame/core/global"
@@ -10,11 +17,61 @@ var Root string = "root"
 var Mode string = "production"
 var Profile string = ""
 
+// 子命令需要的启动阶段
+const (
+	BOOTSTRAP_NONE   = iota // 不加载配置
+	BOOTSTRAP_CONFIG        // 加载配置，再执行 Requires 中的初始化器
+	BOOTSTRAP_INIT          // 加载配置并执行全部初始化器，不启动路由与服务
+	BOOTSTRAP_SERVE         // 完整启动
+)
+
+// 子命令：<app> [flags] <command> [subcommand] [args...]
+type Command struct {
+	Name        string // 以空格分隔子命令，如 "migrate up"
+	Usage       string // 参数说明，如 "<name>"
+	Description string
+	Bootstrap   int
+	Requires    []func() // BOOTSTRAP_CONFIG 时，配置加载后执行的初始化器
+	Run         func(args []string) error
+}
+
+var (
+	commands    = make(map[string]*Command)
+	current     *Command
+	currentArgs []string
+)
+
+func RegisterCommand(cmd *Command) {
+	commands[cmd.Name] = cmd
+}
+
+func GetCommand(name string) *Command {
+	return commands[name]
+}
+
 // 0. 初始化
 func init() {
 	pflag.StringVar(&Mode, "mode", "production", "Run application in debug|production mode")
 	pflag.StringVar(&Profile, "profile", "", "Run application with profile")
 	pflag.StringVar(&Root, "root", "", "Working Root")
+
+	// 子命令之后的参数交给子命令解析
+	pflag.CommandLine.SetInterspersed(false)
+
+	RegisterCommand(&Command{
+		Name:        "serve",
+		Description: "Start the application server (default)",
+		Bootstrap:   BOOTSTRAP_SERVE,
+	})
+
+	RegisterCommand(&Command{
+		Name:        "help",
+		Description: "Show available commands",
+		Run: func(args []string) error {
+			PrintUsage(os.Stdout)
+			return nil
+		},
+	})
 }
 
 // 1. Command 解析
@@ -34,4 +91,60 @@ func Init() {
 	if Root != "" {
 		global.BasePath = Root
 	}
+
+	// 3. 解析子命令，未指定时为 serve
+	current, currentArgs = Resolve(pflag.Args())
+	if current == nil && pflag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Unknown command:", strings.Join(pflag.Args(), " "))
+		PrintUsage(os.Stderr)
+		os.Exit(2)
+	}
+}
+
+// 当前子命令及其参数，未指定子命令时返回 serve
+func Current() (*Command, []string) {
+	if current == nil {
+		return commands["serve"], nil
+	}
+	return current, currentArgs
+}
+
+// 按最长前缀匹配子命令
+func Resolve(args []string) (*Command, []string) {
+	for n := len(args); n > 0; n-- {
+		if cmd, b := commands[strings.Join(args[:n], " ")]; b {
+			return cmd, args[n:]
+		}
+	}
+	return nil, args
+}
+
+func PrintUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "Usage: %s [flags] <command> [args...]\n\nCommands:\n", os.Args[0])
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.Name, cmd.Usage, cmd.Description)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nFlags:")
+	pflag.CommandLine.SetOutput(w)
+	pflag.PrintDefaults()
+}
+
+// 子命令参数解析
+func NewFlagSet(cmd *Command) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(cmd.Name, pflag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s %s\n", os.Args[0], cmd.Name, cmd.Usage)
+		flags.PrintDefaults()
+	}
+	return flags
 }
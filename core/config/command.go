@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gophab/gophrame/core/command"
+)
+
+// 配置节点校验：实现该接口的配置在 config validate 时被校验
+type Validator interface {
+	Validate() error
+}
+
+func init() {
+	command.RegisterCommand(&command.Command{
+		Name:        "config encrypt",
+		Usage:       "[value]",
+		Description: "Encrypt a configuration value with the master key (reads stdin when omitted)",
+		Run:         encryptCommand,
+	})
+
+	command.RegisterCommand(&command.Command{
+		Name:        "config print",
+		Usage:       "[section...]",
+		Description: "Print the effective configuration with secrets masked",
+		Bootstrap:   command.BOOTSTRAP_CONFIG,
+		Run:         printCommand,
+	})
+
+	command.RegisterCommand(&command.Command{
+		Name:        "config validate",
+		Description: "Load and validate the configuration",
+		Run:         validateCommand,
+	})
+}
+
+func encryptCommand(args []string) error {
+	var plain string
+	if len(args) > 0 && args[0] != "-" {
+		plain = args[0]
+	} else {
+		// 从标准输入读取，避免明文出现在 shell 历史中
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		plain = strings.TrimRight(line, "\r\n")
+	}
+
+	value, err := Encrypt(plain)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func sectionNames(args []string) []string {
+	if len(args) > 0 {
+		return args
+	}
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printCommand(args []string) error {
+	for _, name := range sectionNames(args) {
+		setting, b := configs[name]
+		if !b {
+			return fmt.Errorf("unknown configuration section: %s", name)
+		}
+
+		text, err := json.MarshalIndent(setting.Setting, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("# %s: %s\n%s: %s\n\n", name, setting.Description, name, Mask(string(text)))
+	}
+	return nil
+}
+
+func validateCommand(args []string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range sectionNames(nil) {
+		if validator, ok := configs[name].Setting.(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	fmt.Println("Configuration OK")
+	return nil
+}
@@ -90,9 +90,15 @@ func loadConfig() error {
 	// First load into map[]
 	var err error
 	if err = InitYamlConfig(&config); err == nil {
+		// Resolve ENC(...) / ${env:} / ${file:}
+		if _, err = ResolveSecrets(config); err != nil {
+			logger.Error("Resolve configuration secrets error: ", err.Error())
+			return err
+		}
+
 		// Logger
 		if text, _ := json.MarshalToString(config); text != "" {
-			logger.Debug("Load application configuration: ", text)
+			logger.Debug("Load application configuration: ", Mask(text))
 		}
 
 		// Second to json
@@ -111,7 +117,7 @@ func loadConfig() error {
 				break
 			} else {
 				if text, _ := json.MarshalToString(value.Setting); text != "" {
-					logger.Debug("Load configuration: ", key, text)
+					logger.Debug("Load configuration: ", key, Mask(text))
 				}
 			}
 		}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 配置文件中的加密值与引用：
+//
+//	password: ENC(base64(nonce+ciphertext))   使用主密钥 AES-256-GCM 解密
+//	password: ${env:DB_PASSWORD}              读取环境变量
+//	password: ${file:/run/secrets/db_pass}    读取文件内容（去除末尾换行）
+//
+// 主密钥从环境变量 GOPHRAME_CONFIG_KEY 读取，或从 GOPHRAME_CONFIG_KEY_FILE 指定的文件读取。
+// 解密/解析后的值会登记为敏感值，所有配置日志输出均会经过 Mask() 处理。
+const (
+	MasterKeyEnv     = "GOPHRAME_CONFIG_KEY"
+	MasterKeyFileEnv = "GOPHRAME_CONFIG_KEY_FILE"
+
+	encPrefix = "ENC("
+	encSuffix = ")"
+	maskText  = "******"
+
+	// 长度过短的值不做掩码，避免日志中大量无意义的替换
+	minMaskLength = 4
+)
+
+var (
+	ErrMasterKeyNotFound = errors.New("config master key not found, set " + MasterKeyEnv + " or " + MasterKeyFileEnv)
+	ErrInvalidEncrypted  = errors.New("invalid encrypted config value")
+	ErrEnvNotSet         = errors.New("environment variable not set")
+
+	referencePattern = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+)
+
+type secretRegistry struct {
+	sync.RWMutex
+	values map[string]struct{}
+}
+
+var secrets = &secretRegistry{values: make(map[string]struct{})}
+
+func (r *secretRegistry) add(value string) {
+	if len(value) < minMaskLength {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	for _, v := range escapedForms(value) {
+		r.values[v] = struct{}{}
+	}
+}
+
+// 配置以 JSON 输出，含 " \ < > & 等字符的值在输出中是转义后的形式，需一并登记
+func escapedForms(value string) []string {
+	var result = []string{value}
+	if data, err := json.Marshal(value); err == nil {
+		result = append(result, string(data[1:len(data)-1]))
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err == nil {
+		data := bytes.TrimSpace(buffer.Bytes())
+		result = append(result, string(data[1:len(data)-1]))
+	}
+	return result
+}
+
+func (r *secretRegistry) list() []string {
+	r.RLock()
+	defer r.RUnlock()
+
+	result := make([]string, 0, len(r.values))
+	for v := range r.values {
+		result = append(result, v)
+	}
+	// 先替换较长的值，避免较短值是较长值子串时替换不完整
+	sort.Slice(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+// Mask 将文本中出现的所有已解密敏感值替换为掩码，用于日志及配置输出
+func Mask(text string) string {
+	for _, v := range secrets.list() {
+		text = strings.ReplaceAll(text, v, maskText)
+	}
+	return text
+}
+
+func masterKey() ([]byte, error) {
+	key := os.Getenv(MasterKeyEnv)
+	if key == "" {
+		if file := os.Getenv(MasterKeyFileEnv); file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			key = strings.TrimSpace(string(data))
+		}
+	}
+
+	if key == "" {
+		return nil, ErrMasterKeyNotFound
+	}
+
+	// 任意长度的主密钥统一派生为 32 字节
+	sum := sha256.Sum256([]byte(key))
+	return sum[:], nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt 使用主密钥加密明文，返回可直接写入配置文件的 ENC(...) 形式
+func Encrypt(plain string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed) + encSuffix, nil
+}
+
+// Decrypt 解密 ENC(...) 形式的配置值
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), encPrefix), encSuffix))
+	if err != nil {
+		return "", ErrInvalidEncrypted
+	}
+
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", ErrInvalidEncrypted
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidEncrypted
+	}
+	return string(plain), nil
+}
+
+func IsEncrypted(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, encPrefix) && strings.HasSuffix(value, encSuffix)
+}
+
+// ResolveValue 解析单个配置字符串：ENC(...) 解密，${env:X}/${file:path} 替换
+func ResolveValue(value string) (string, error) {
+	if IsEncrypted(value) {
+		plain, err := Decrypt(value)
+		if err != nil {
+			return "", err
+		}
+		secrets.add(plain)
+		return plain, nil
+	}
+
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var resolveErr error
+	result := referencePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		match := referencePattern.FindStringSubmatch(ref)
+		switch match[1] {
+		case "env":
+			v, b := os.LookupEnv(match[2])
+			if !b {
+				resolveErr = fmt.Errorf("config reference %s: %w", match[2], ErrEnvNotSet)
+				return ""
+			}
+			secrets.add(v)
+			return v
+		case "file":
+			data, err := os.ReadFile(match[2])
+			if err != nil {
+				resolveErr = fmt.Errorf("read config reference %s: %w", match[2], err)
+				return ""
+			}
+			v := strings.TrimRight(string(data), "\r\n")
+			secrets.add(v)
+			return v
+		}
+		return ref
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
+
+// ResolveSecrets 递归解析配置树中的所有加密值与引用
+func ResolveSecrets(node any) (any, error) {
+	switch v := node.(type) {
+	case string:
+		return ResolveValue(v)
+	case map[string]any:
+		for key, value := range v {
+			resolved, err := ResolveSecrets(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []any:
+		for i, value := range v {
+			resolved, err := ResolveSecrets(value)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		return node, nil
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Setenv(MasterKeyEnv, "test-master-key")
+
+	encrypted, err := Encrypt("s3cr3t-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatalf("expected ENC(...) form, got %s", encrypted)
+	}
+
+	plain, err := ResolveValue(encrypted)
+	if err != nil || plain != "s3cr3t-password" {
+		t.Fatalf("resolve = %q, %v", plain, err)
+	}
+
+	t.Setenv(MasterKeyEnv, "another-key")
+	if _, err := Decrypt(encrypted); err != ErrInvalidEncrypted {
+		t.Fatalf("decrypt with wrong key: %v", err)
+	}
+}
+
+func TestResolveEnvReference(t *testing.T) {
+	t.Setenv("GOPHRAME_TEST_DB_PASSWORD", "from-env")
+	if v, err := ResolveValue("prefix-${env:GOPHRAME_TEST_DB_PASSWORD}"); err != nil || v != "prefix-from-env" {
+		t.Fatalf("resolve = %q, %v", v, err)
+	}
+
+	// 设置为空值是合法的
+	t.Setenv("GOPHRAME_TEST_EMPTY", "")
+	if v, err := ResolveValue("${env:GOPHRAME_TEST_EMPTY}"); err != nil || v != "" {
+		t.Fatalf("resolve empty = %q, %v", v, err)
+	}
+
+	if _, err := ResolveValue("${env:GOPHRAME_TEST_NOT_SET}"); !errors.Is(err, ErrEnvNotSet) {
+		t.Fatalf("expected ErrEnvNotSet, got %v", err)
+	}
+}
+
+func TestResolveFileReference(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := ResolveValue("${file:" + file + "}"); err != nil || v != "from-file" {
+		t.Fatalf("resolve = %q, %v", v, err)
+	}
+	if _, err := ResolveValue("${file:" + file + ".missing}"); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestResolveSecretsTree(t *testing.T) {
+	t.Setenv("GOPHRAME_TEST_TOKEN", "tree-token")
+	tree := map[string]any{
+		"db":    map[string]any{"password": "${env:GOPHRAME_TEST_TOKEN}"},
+		"hosts": []any{"a", "${env:GOPHRAME_TEST_TOKEN}"},
+		"port":  3306,
+	}
+	if _, err := ResolveSecrets(tree); err != nil {
+		t.Fatal(err)
+	}
+	if tree["db"].(map[string]any)["password"] != "tree-token" || tree["hosts"].([]any)[1] != "tree-token" {
+		t.Fatalf("unexpected tree: %v", tree)
+	}
+
+	if _, err := ResolveSecrets(map[string]any{"x": "${env:GOPHRAME_TEST_NOT_SET}"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMaskEscapedOutput(t *testing.T) {
+	secret := `p"a\ss<w>&rd`
+	t.Setenv("GOPHRAME_TEST_SPECIAL", secret)
+	if _, err := ResolveValue("${env:GOPHRAME_TEST_SPECIAL}"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := json.Marshal(map[string]string{"password": secret})
+	if masked := Mask(string(data)); masked != `{"password":"`+maskText+`"}` {
+		t.Fatalf("secret not masked in json output: %s", masked)
+	}
+	if masked := Mask("password=" + secret); masked != "password="+maskText {
+		t.Fatalf("secret not masked in plain output: %s", masked)
+	}
+}
@@ -173,7 +173,12 @@ func (y *ymlConfig) Get(keyName string) any {
 	if y.keyIsCache(keyName) {
 		return y.getValueFromCache(keyName)
 	} else {
-		value := y.viper.Get(keyName)
+		value, err := ResolveSecrets(y.viper.Get(keyName))
+		if err != nil {
+			// 解析失败不缓存，避免未解析的引用被当作配置值使用
+			logger.Error("Resolve configuration secret error: ", keyName, err.Error())
+			return nil
+		}
 		y.cache(keyName, value)
 		return value
 	}
@@ -184,7 +189,11 @@ func (y *ymlConfig) GetString(keyName string) string {
 	if y.keyIsCache(keyName) {
 		return y.getValueFromCache(keyName).(string)
 	} else {
-		value := y.viper.GetString(keyName)
+		value, err := ResolveValue(y.viper.GetString(keyName))
+		if err != nil {
+			logger.Error("Resolve configuration secret error: ", keyName, err.Error())
+			return ""
+		}
 		y.cache(keyName, value)
 		return value
 	}
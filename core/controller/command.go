@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gophab/gophrame/core/command"
+	"github.com/gophab/gophrame/core/router"
+)
+
+func init() {
+	command.RegisterCommand(&command.Command{
+		Name:        "routes list",
+		Description: "List the HTTP routes registered by controllers",
+		Bootstrap:   command.BOOTSTRAP_INIT,
+		Run:         routesCommand,
+	})
+}
+
+func routesCommand(args []string) error {
+	router.Init()
+	Start()
+
+	routes := router.Root().Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER")
+	for _, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+	}
+	return w.Flush()
+}
@@ -3,6 +3,9 @@ package controller
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/gophab/gophrame/core/context"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/util"
 	"github.com/gophab/gophrame/core/webservice/request"
 )
 
@@ -15,6 +18,22 @@ func SetGlobalContext() gin.HandlerFunc {
 	}
 }
 
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestId := ctx.GetHeader("X-Request-Id")
+		if requestId == "" {
+			requestId = util.UUID()
+		}
+
+		ctx.Set("_REQUEST_ID_", requestId)
+		ctx.Header("X-Request-Id", requestId)
+		context.SetContextValue("_REQUEST_ID_", requestId)
+		defer context.RemoveContextValue("_REQUEST_ID_")
+
+		ctx.Next()
+	}
+}
+
 func EnableLocale() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		locale := ctx.GetHeader("x-set-locale")
@@ -30,3 +49,17 @@ func EnableLocale() gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// 请求作用域：SCOPE_REQUEST 的实例在请求结束时释放
+func RequestScope() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		inject.BeginScope()
+		defer func() {
+			if err := inject.EndScope(); err != nil {
+				logger.Warn("Close request scope error: ", err.Error())
+			}
+		}()
+
+		ctx.Next()
+	}
+}
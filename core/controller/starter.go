@@ -12,6 +12,6 @@ func init() {
 
 func Start() {
 	logger.Debug("Starting Core Controller ...")
-	router.Root().Use(SetGlobalContext(), EnableLocale())
+	router.Root().Use(SetGlobalContext(), RequestId(), EnableLocale(), RequestScope())
 	InitRouter(router.Root())
 }
@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gophab/gophrame/core/command"
+)
+
+func init() {
+	command.RegisterCommand(&command.Command{
+		Name:        "jobs list",
+		Description: "List registered jobs",
+		Bootstrap:   command.BOOTSTRAP_INIT,
+		Run:         listCommand,
+	})
+
+	command.RegisterCommand(&command.Command{
+		Name:        "jobs run",
+		Usage:       "<name>",
+		Description: "Run a job once in the foreground, honouring the cluster lease",
+		Bootstrap:   command.BOOTSTRAP_INIT,
+		Run:         runCommand,
+	})
+}
+
+func listCommand(args []string) error {
+	globalScheduler.Setup()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSPEC\tPAUSED\tLAST RUN\tSTATUS\tDESCRIPTION")
+	for _, job := range Jobs() {
+		last, status := "", ""
+		if job.LastRun != nil {
+			last, status = job.LastRun.StartTime.Format(time.DateTime), job.LastRun.Status
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\n", job.Name, job.Spec, job.Paused, last, status, job.Description)
+	}
+	return w.Flush()
+}
+
+func runCommand(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: jobs run <name>")
+	}
+
+	globalScheduler.Setup()
+
+	run, err := Run(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %s (attempts %d, %dms)\n", run.JobName, run.Status, run.Attempts, run.Duration)
+	if run.Status != RUN_SUCCESS {
+		return errors.New(run.Error)
+	}
+	return nil
+}
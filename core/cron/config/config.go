@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type CronSetting struct {
+	Lock             string        `json:"lock" yaml:"lock"`                         // redis|database|local，为空时自动选择
+	History          string        `json:"history" yaml:"history"`                   // database|memory，为空时自动选择
+	LeaseTime        time.Duration `json:"leaseTime" yaml:"leaseTime"`               // 任务未设置超时时的租约时长
+	LockAtLeast      time.Duration `json:"lockAtLeast" yaml:"lockAtLeast"`           // 任务完成后租约的最短保持时间，避免节点间时钟偏差导致重复执行
+	HistoryRetention time.Duration `json:"historyRetention" yaml:"historyRetention"` // 执行历史保留时长
+	HistorySize      int           `json:"historySize" yaml:"historySize"`           // 内存历史每个任务保留条数
+}
+
+var Setting *CronSetting = &CronSetting{
+	LeaseTime:        time.Minute * 10,
+	LockAtLeast:      time.Second * 10,
+	HistoryRetention: time.Hour * 24 * 30,
+	HistorySize:      100,
+}
+
+func init() {
+	logger.Debug("Register Cron Config")
+	config.RegisterConfig("cron", Setting, "Cron Job Settings")
+}
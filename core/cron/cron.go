@@ -1,28 +1,399 @@
 package cron
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/cron/config"
+	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/logger"
+	RedisConfig "github.com/gophab/gophrame/core/redis/config"
 	"github.com/gophab/gophrame/core/starter"
+
 	"github.com/robfig/cron/v3"
 )
 
-var globalCron = cron.New(cron.WithChain(
-	cron.SkipIfStillRunning(cron.DefaultLogger),
-))
+// 任务调度器：命名任务 + 集群租约 + 执行历史
+type Scheduler struct {
+	sync.RWMutex
+	cron    *cron.Cron
+	jobs    map[string]*scheduledJob
+	lock    LockProvider
+	history HistoryStore
+	node    string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+}
+
+var (
+	globalScheduler = NewScheduler()
+	anonymous       int32
+)
+
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		cron:   cron.New(),
+		jobs:   make(map[string]*scheduledJob),
+		node:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
 
 func init() {
 	starter.RegisterStarter(Start)
 	starter.RegisterTerminater(Stop)
+
+	_ = Register(&Job{
+		Name:        "cron.history-purge",
+		Description: "Purge expired job history",
+		Spec:        "@daily",
+		Func: func(ctx context.Context) error {
+			return globalScheduler.historyStore().Purge(time.Now().Add(-config.Setting.HistoryRetention))
+		},
+	})
+}
+
+func Default() *Scheduler {
+	return globalScheduler
 }
 
 func Start() {
-	globalCron.Start()
+	globalScheduler.Start()
 }
 
 func Stop() {
-	globalCron.Stop()
+	globalScheduler.Stop()
 }
 
+// 兼容原有方式：注册仅在本节点执行的匿名任务
 func AddFunc(spec string, cmd func()) error {
-	_, err := globalCron.AddFunc(spec, cmd)
-	return err
+	return Register(&Job{
+		Name:  fmt.Sprintf("anonymous-%d", atomic.AddInt32(&anonymous, 1)),
+		Spec:  spec,
+		Local: true,
+		Func: func(ctx context.Context) error {
+			cmd()
+			return nil
+		},
+	})
+}
+
+func Register(job *Job) error {
+	return globalScheduler.Register(job)
+}
+
+// 注册失败（任务名重复、表达式无效）时 panic，用于包初始化时注册的固定任务
+func MustRegister(job *Job) {
+	if err := Register(job); err != nil {
+		panic(fmt.Sprintf("cron: register job %s: %s", job.Name, err.Error()))
+	}
+}
+
+// 手动触发（异步）
+func Trigger(name string) error {
+	return globalScheduler.Trigger(name)
+}
+
+// 手动执行（同步），返回执行记录
+func Run(name string) (*JobRun, error) {
+	return globalScheduler.Run(name)
+}
+
+func Pause(name string) error {
+	return globalScheduler.Pause(name)
+}
+
+func Resume(name string) error {
+	return globalScheduler.Resume(name)
+}
+
+func Jobs() []*JobInfo {
+	return globalScheduler.Jobs()
+}
+
+func History(name string, limit int) ([]*JobRun, error) {
+	return globalScheduler.History(name, limit)
+}
+
+func (s *Scheduler) Register(job *Job) error {
+	if job.Name == "" || job.Func == nil {
+		return fmt.Errorf("invalid job: %s", job.Name)
+	}
+
+	schedule, err := cron.ParseStandard(job.Spec)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, b := s.jobs[job.Name]; b {
+		return fmt.Errorf("duplicated job: %s", job.Name)
+	}
+
+	sj := &scheduledJob{Job: job, schedule: schedule}
+	sj.entryId = s.cron.Schedule(schedule, cron.FuncJob(func() {
+		if _, err := s.execute(sj, TRIGGER_SCHEDULE); err != nil && err != ErrJobLocked && err != ErrJobPaused {
+			logger.Warn("Execute job error: ", sj.Name, err.Error())
+		}
+	}))
+	s.jobs[job.Name] = sj
+
+	if s.started {
+		s.checkMisfire(sj)
+	}
+	return nil
+}
+
+// 初始化租约与历史存储，不启动调度
+func (s *Scheduler) Setup() {
+	s.Lock()
+	defer s.Unlock()
+	s.setup()
+}
+
+func (s *Scheduler) setup() {
+	if s.lock == nil {
+		s.lock = createLockProvider(config.Setting.Lock)
+	}
+	if s.history == nil {
+		s.history = createHistoryStore(config.Setting.History)
+	}
+}
+
+func (s *Scheduler) Start() {
+	s.Lock()
+	if s.started {
+		s.Unlock()
+		return
+	}
+	s.setup()
+	s.started = true
+	s.Unlock()
+
+	logger.Info("Starting cron scheduler: ", s.node)
+	s.cron.Start()
+
+	s.RLock()
+	defer s.RUnlock()
+	for _, job := range s.jobs {
+		s.checkMisfire(job)
+	}
+}
+
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+}
+
+func (s *Scheduler) locker() LockProvider {
+	s.RLock()
+	defer s.RUnlock()
+	if s.lock == nil {
+		return localLockProvider
+	}
+	return s.lock
+}
+
+func (s *Scheduler) historyStore() HistoryStore {
+	s.RLock()
+	defer s.RUnlock()
+	if s.history == nil {
+		return memoryHistory
+	}
+	return s.history
+}
+
+// 启动时检查错过的执行
+func (s *Scheduler) checkMisfire(job *scheduledJob) {
+	if job.Misfire != MISFIRE_FIRE_ONCE {
+		return
+	}
+
+	last, err := s.historyStore().Last(job.Name)
+	if err != nil || last == nil {
+		return
+	}
+
+	if job.schedule.Next(last.StartTime).Before(time.Now()) {
+		logger.Info("Job misfired, fire once: ", job.Name)
+		go func() {
+			_, _ = s.execute(job, TRIGGER_MISFIRE)
+		}()
+	}
+}
+
+func (s *Scheduler) execute(job *scheduledJob, trigger string) (*JobRun, error) {
+	if trigger == TRIGGER_SCHEDULE {
+		if paused, _ := s.locker().IsPaused(job.Name); paused {
+			return nil, ErrJobPaused
+		}
+	}
+
+	if !job.running.CompareAndSwap(false, true) {
+		return nil, ErrJobRunning
+	}
+	defer job.running.Store(false)
+
+	ctx := s.ctx
+	if !job.Local {
+		lease := job.leaseTime(config.Setting.LeaseTime)
+		locked, err := s.locker().TryLock(job.Name, s.node, lease)
+		if err != nil {
+			return nil, err
+		}
+		if !locked {
+			return nil, ErrJobLocked
+		}
+
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(s.ctx)
+		stop := s.renew(job.Name, lease, cancel)
+		defer func() {
+			stop()
+			cancel(nil)
+		}()
+	}
+
+	result := job.run(ctx, s.node, trigger)
+
+	if !job.Local {
+		// 保持租约至少 LockAtLeast，避免其他节点在同一调度周期内重复执行
+		if err := s.locker().Unlock(job.Name, s.node, result.StartTime.Add(config.Setting.LockAtLeast)); err != nil {
+			logger.Warn("Release job lock error: ", job.Name, err.Error())
+		}
+	}
+
+	if result.Status != RUN_SUCCESS {
+		logger.Error("Job failed: ", job.Name, result.Status, result.Error)
+	}
+
+	if err := s.historyStore().Save(result); err != nil {
+		logger.Warn("Save job history error: ", job.Name, err.Error())
+	}
+	return result, nil
+}
+
+// 执行期间每隔租约时长的三分之一续期，租约被其他节点获取时取消执行；返回的函数停止续期
+func (s *Scheduler) renew(name string, lease time.Duration, cancel context.CancelCauseFunc) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(max(lease/3, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if renewed, err := s.locker().Renew(name, s.node, lease); err != nil {
+					logger.Warn("Renew job lock error: ", name, err.Error())
+				} else if !renewed {
+					logger.Error("Job lock lost, cancel: ", name)
+					cancel(ErrJobLocked)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *Scheduler) job(name string) (*scheduledJob, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if job, b := s.jobs[name]; b {
+		return job, nil
+	}
+	return nil, ErrJobNotFound
+}
+
+func (s *Scheduler) Trigger(name string) error {
+	job, err := s.job(name)
+	if err != nil {
+		return err
+	}
+
+	if job.running.Load() {
+		return ErrJobRunning
+	}
+
+	go func() {
+		if _, err := s.execute(job, TRIGGER_MANUAL); err != nil {
+			logger.Warn("Trigger job error: ", name, err.Error())
+		}
+	}()
+	return nil
+}
+
+func (s *Scheduler) Run(name string) (*JobRun, error) {
+	job, err := s.job(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.execute(job, TRIGGER_MANUAL)
+}
+
+func (s *Scheduler) Pause(name string) error {
+	if _, err := s.job(name); err != nil {
+		return err
+	}
+	return s.locker().SetPaused(name, true)
+}
+
+func (s *Scheduler) Resume(name string) error {
+	if _, err := s.job(name); err != nil {
+		return err
+	}
+	return s.locker().SetPaused(name, false)
+}
+
+func (s *Scheduler) Jobs() []*JobInfo {
+	s.RLock()
+	jobs := make([]*scheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.RUnlock()
+
+	result := make([]*JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, job.info(s))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (s *Scheduler) History(name string, limit int) ([]*JobRun, error) {
+	if _, err := s.job(name); err != nil {
+		return nil, err
+	}
+	return s.historyStore().Find(name, limit)
+}
+
+func useRedis() bool {
+	return RedisConfig.Setting.Enabled
+}
+
+func useDatabase() bool {
+	return global.DB != nil
 }
@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/cron/config"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// 任务执行历史
+type HistoryStore interface {
+	Save(run *JobRun) error
+	Last(name string) (*JobRun, error)
+	Find(name string, limit int) ([]*JobRun, error)
+	Purge(before time.Time) error
+}
+
+func createHistoryStore(mode string) HistoryStore {
+	if mode == "" {
+		if useDatabase() {
+			mode = "database"
+		} else {
+			mode = "memory"
+		}
+	}
+
+	logger.Info("Using cron history store: ", mode)
+	if mode == "database" {
+		return databaseHistory
+	}
+	return memoryHistory
+}
+
+// 内存历史，每个任务保留最近 HistorySize 条
+type MemoryHistoryStore struct {
+	sync.RWMutex
+	runs map[string][]*JobRun
+}
+
+var memoryHistory = &MemoryHistoryStore{runs: make(map[string][]*JobRun)}
+
+func (s *MemoryHistoryStore) Save(run *JobRun) error {
+	s.Lock()
+	defer s.Unlock()
+
+	runs := append(s.runs[run.JobName], run)
+	if size := config.Setting.HistorySize; size > 0 && len(runs) > size {
+		runs = runs[len(runs)-size:]
+	}
+	s.runs[run.JobName] = runs
+	return nil
+}
+
+func (s *MemoryHistoryStore) Last(name string) (*JobRun, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if runs := s.runs[name]; len(runs) > 0 {
+		return runs[len(runs)-1], nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryHistoryStore) Find(name string, limit int) ([]*JobRun, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	runs := s.runs[name]
+	result := make([]*JobRun, 0, len(runs))
+	for i := len(runs) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		result = append(result, runs[i])
+	}
+	return result, nil
+}
+
+func (s *MemoryHistoryStore) Purge(before time.Time) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for name, runs := range s.runs {
+		var j = 0
+		for _, run := range runs {
+			if !run.StartTime.Before(before) {
+				runs[j] = run
+				j++
+			}
+		}
+		s.runs[name] = runs[:j]
+	}
+	return nil
+}
+
+// 数据库历史，集群内共享
+type DatabaseHistoryStore struct {
+	*gorm.DB `inject:"database"`
+}
+
+var databaseHistory = &DatabaseHistoryStore{}
+
+func init() {
+	inject.InjectValue("jobHistoryStore", databaseHistory)
+}
+
+func (s *DatabaseHistoryStore) Save(run *JobRun) error {
+	return s.Create(run).Error
+}
+
+func (s *DatabaseHistoryStore) Last(name string) (*JobRun, error) {
+	var result JobRun
+	if res := s.Where("job_name = ?", name).Order("start_time DESC").Limit(1).Find(&result); res.Error != nil || res.RowsAffected == 0 {
+		return nil, res.Error
+	}
+	return &result, nil
+}
+
+func (s *DatabaseHistoryStore) Find(name string, limit int) ([]*JobRun, error) {
+	var result []*JobRun
+	tx := s.Where("job_name = ?", name).Order("start_time DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	err := tx.Find(&result).Error
+	return result, err
+}
+
+func (s *DatabaseHistoryStore) Purge(before time.Time) error {
+	return s.Where("start_time < ?", before).Delete(&JobRun{}).Error
+}
@@ -0,0 +1,217 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/snowflake"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	MISFIRE_SKIP      = "SKIP"      // 错过的执行直接跳过（默认）
+	MISFIRE_FIRE_ONCE = "FIRE_ONCE" // 启动时发现错过执行，立即补执行一次
+
+	TRIGGER_SCHEDULE = "SCHEDULE"
+	TRIGGER_MANUAL   = "MANUAL"
+	TRIGGER_MISFIRE  = "MISFIRE"
+
+	RUN_SUCCESS = "SUCCESS"
+	RUN_FAILED  = "FAILED"
+	RUN_TIMEOUT = "TIMEOUT"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is running")
+	ErrJobLocked   = errors.New("job is running on another node")
+	ErrJobPaused   = errors.New("job is paused")
+	ErrJobTimeout  = errors.New("job timeout")
+)
+
+// 任务定义
+type Job struct {
+	Name        string
+	Description string
+	Spec        string                          // cron 表达式，支持 @every 1m / @daily 等
+	Func        func(ctx context.Context) error // ctx 在超时或停止时取消
+	Timeout     time.Duration                   // 单次执行超时，0 表示不限
+	Retries     int                             // 失败重试次数
+	Backoff     time.Duration                   // 首次重试间隔，之后指数增长
+	Misfire     string                          // MISFIRE_SKIP|MISFIRE_FIRE_ONCE
+	Local       bool                            // 每个节点都执行，不获取集群租约
+}
+
+// 执行记录
+type JobRun struct {
+	Id        int64     `gorm:"column:id;primaryKey" json:"id"`
+	JobName   string    `gorm:"column:job_name" json:"jobName"`
+	Node      string    `gorm:"column:node" json:"node"`
+	Trigger   string    `gorm:"column:trigger_type" json:"trigger"`
+	Status    string    `gorm:"column:status" json:"status"`
+	Attempts  int       `gorm:"column:attempts" json:"attempts"`
+	StartTime time.Time `gorm:"column:start_time" json:"startTime"`
+	EndTime   time.Time `gorm:"column:end_time" json:"endTime"`
+	Duration  int64     `gorm:"column:duration" json:"duration"` // 毫秒
+	Error     string    `gorm:"column:error" json:"error,omitempty"`
+}
+
+func (r *JobRun) TableName() string {
+	return "sys_job_history"
+}
+
+func (r *JobRun) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.Id == 0 {
+		r.Id = snowflake.SnowflakeIdGenerator().GetId()
+	}
+	return
+}
+
+// 任务状态
+type JobInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Spec        string     `json:"spec"`
+	Local       bool       `json:"local"`
+	Paused      bool       `json:"paused"`
+	Running     bool       `json:"running"`
+	NextTime    *time.Time `json:"nextTime,omitempty"`
+	LastRun     *JobRun    `json:"lastRun,omitempty"`
+}
+
+type scheduledJob struct {
+	*Job
+	entryId  cron.EntryID
+	schedule cron.Schedule
+	running  atomic.Bool
+	mutex    sync.RWMutex
+	lastRun  *JobRun
+}
+
+func (j *scheduledJob) leaseTime(defaultLease time.Duration) time.Duration {
+	if j.Timeout <= 0 {
+		return defaultLease
+	}
+
+	lease := j.Timeout
+	backoff := j.Backoff
+	for i := 0; i < j.Retries; i++ {
+		lease += j.Timeout + backoff
+		backoff *= 2
+	}
+	return lease + time.Minute
+}
+
+// 执行一次（含重试），返回执行记录
+func (j *scheduledJob) run(ctx context.Context, node string, trigger string) *JobRun {
+	result := &JobRun{
+		JobName:   j.Name,
+		Node:      node,
+		Trigger:   trigger,
+		StartTime: time.Now(),
+	}
+
+	var err error
+	backoff := j.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 0; attempt <= j.Retries; attempt++ {
+		if attempt > 0 {
+			logger.Warn("Retry job: ", j.Name, attempt, err.Error())
+			select {
+			case <-ctx.Done():
+				attempt = j.Retries + 1
+				continue
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		result.Attempts++
+		if err = j.attempt(ctx); err == nil {
+			break
+		}
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).Milliseconds()
+	switch {
+	case err == nil:
+		result.Status = RUN_SUCCESS
+	case errors.Is(err, ErrJobTimeout):
+		result.Status = RUN_TIMEOUT
+		result.Error = err.Error()
+	default:
+		result.Status = RUN_FAILED
+		result.Error = err.Error()
+	}
+
+	j.mutex.Lock()
+	j.lastRun = result
+	j.mutex.Unlock()
+
+	return result
+}
+
+func (j *scheduledJob) attempt(ctx context.Context) (err error) {
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("job panic: %v\n%s", r, debug.Stack())
+			}
+		}()
+		done <- j.Func(ctx)
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	// 等待任务返回后再重试或释放租约，避免同一任务重叠执行
+	logger.Warn("Job cancelled, waiting for it to return: ", j.Name)
+	<-done
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrJobTimeout
+	}
+	return context.Cause(ctx)
+}
+
+func (j *scheduledJob) info(scheduler *Scheduler) *JobInfo {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	result := &JobInfo{
+		Name:        j.Name,
+		Description: j.Description,
+		Spec:        j.Spec,
+		Local:       j.Local,
+		Running:     j.running.Load(),
+		LastRun:     j.lastRun,
+	}
+
+	result.Paused, _ = scheduler.locker().IsPaused(j.Name)
+	if entry := scheduler.cron.Entry(j.entryId); entry.Valid() && !entry.Next.IsZero() {
+		next := entry.Next
+		result.NextTime = &next
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/core/cron/config"
+)
+
+func TestTimeoutRetryDoesNotOverlap(t *testing.T) {
+	var running, maxRunning, finished atomic.Int32
+	job := &scheduledJob{Job: &Job{
+		Name:    "test.overlap",
+		Timeout: 20 * time.Millisecond,
+		Retries: 2,
+		Backoff: time.Millisecond,
+		Func: func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			// 忽略 ctx，模拟不响应取消的任务
+			time.Sleep(60 * time.Millisecond)
+			running.Add(-1)
+			finished.Add(1)
+			return nil
+		},
+	}}
+
+	result := job.run(context.Background(), "node", TRIGGER_MANUAL)
+	if result.Status != RUN_TIMEOUT || result.Attempts != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if maxRunning.Load() != 1 {
+		t.Fatalf("attempts overlapped: %d", maxRunning.Load())
+	}
+	if finished.Load() != 3 {
+		t.Fatalf("run returned before attempts finished: %d", finished.Load())
+	}
+}
+
+// 续期失败的租约
+type lostLockProvider struct {
+	LocalLockProvider
+	mutex    sync.Mutex
+	renewed  int
+	unlocked bool
+}
+
+func (p *lostLockProvider) Renew(name string, owner string, ttl time.Duration) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.renewed++
+	return false, nil
+}
+
+func (p *lostLockProvider) Unlock(name string, owner string, until time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.unlocked = true
+	return nil
+}
+
+func TestLostLeaseCancelsJob(t *testing.T) {
+	defer func(lease time.Duration) { config.Setting.LeaseTime = lease }(config.Setting.LeaseTime)
+	config.Setting.LeaseTime = 3 * time.Second
+
+	lock := &lostLockProvider{LocalLockProvider: LocalLockProvider{locks: map[string]time.Time{}, paused: map[string]bool{}}}
+	s := NewScheduler()
+	s.lock = lock
+	s.history = &MemoryHistoryStore{runs: make(map[string][]*JobRun)}
+
+	job := &scheduledJob{Job: &Job{
+		Name: "test.lease",
+		Func: func(ctx context.Context) error {
+			<-ctx.Done()
+			return context.Cause(ctx)
+		},
+	}}
+
+	result, err := s.execute(job, TRIGGER_MANUAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != RUN_FAILED || result.Error != ErrJobLocked.Error() {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if lock.renewed != 1 || !lock.unlocked {
+		t.Fatalf("renewed=%d unlocked=%v", lock.renewed, lock.unlocked)
+	}
+}
+
+func TestMustRegisterPanicsOnInvalidJob(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "test.invalid") {
+			t.Fatalf("expected panic, got %v", r)
+		}
+	}()
+	MustRegister(&Job{Name: "test.invalid", Spec: "not a spec", Func: func(ctx context.Context) error { return nil }})
+}
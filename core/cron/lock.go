@@ -0,0 +1,258 @@
+package cron
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 集群任务租约：同一任务同一时刻只在一个节点执行
+type LockProvider interface {
+	// 获取租约，返回 false 表示租约被其他节点持有
+	TryLock(name string, owner string, ttl time.Duration) (bool, error)
+	// 续期，返回 false 表示租约已不属于 owner
+	Renew(name string, owner string, ttl time.Duration) (bool, error)
+	// 释放租约，until 之前其他节点仍不能获取
+	Unlock(name string, owner string, until time.Time) error
+	IsPaused(name string) (bool, error)
+	SetPaused(name string, paused bool) error
+}
+
+func createLockProvider(mode string) LockProvider {
+	if mode == "" {
+		switch {
+		case useRedis():
+			mode = "redis"
+		case useDatabase():
+			mode = "database"
+		default:
+			mode = "local"
+		}
+	}
+
+	logger.Info("Using cron lock provider: ", mode)
+	switch mode {
+	case "redis":
+		return &RedisLockProvider{Prefix: "cron:"}
+	case "database":
+		return databaseLockProvider
+	default:
+		return localLockProvider
+	}
+}
+
+// 单节点租约
+type LocalLockProvider struct {
+	mutex  sync.Mutex
+	locks  map[string]time.Time
+	paused map[string]bool
+}
+
+var localLockProvider = &LocalLockProvider{
+	locks:  make(map[string]time.Time),
+	paused: make(map[string]bool),
+}
+
+func (p *LocalLockProvider) TryLock(name string, owner string, ttl time.Duration) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if until, b := p.locks[name]; b && until.After(time.Now()) {
+		return false, nil
+	}
+	p.locks[name] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (p *LocalLockProvider) Renew(name string, owner string, ttl time.Duration) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.locks[name] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (p *LocalLockProvider) Unlock(name string, owner string, until time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.locks[name] = until
+	return nil
+}
+
+func (p *LocalLockProvider) IsPaused(name string) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.paused[name], nil
+}
+
+func (p *LocalLockProvider) SetPaused(name string, paused bool) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if paused {
+		p.paused[name] = true
+	} else {
+		delete(p.paused, name)
+	}
+	return nil
+}
+
+// 基于 Redis SET NX PX 的租约
+type RedisLockProvider struct {
+	Prefix string
+}
+
+// 仅持有者可以释放；until 未到时缩短为剩余时长，否则直接删除
+const redisUnlockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	if tonumber(ARGV[2]) > 0 then
+		return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+func (p *RedisLockProvider) TryLock(name string, owner string, ttl time.Duration) (bool, error) {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return false, ErrJobLocked
+	}
+	defer client.ReleaseOneRedisClient()
+
+	reply, err := client.Execute("SET", p.Prefix+"lock:"+name, owner, "NX", "PX", ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+const redisRenewScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`
+
+func (p *RedisLockProvider) Renew(name string, owner string, ttl time.Duration) (bool, error) {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return false, ErrJobLocked
+	}
+	defer client.ReleaseOneRedisClient()
+
+	return client.Bool(client.Execute("EVAL", redisRenewScript, 1, p.Prefix+"lock:"+name, owner, ttl.Milliseconds()))
+}
+
+func (p *RedisLockProvider) Unlock(name string, owner string, until time.Time) error {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return ErrJobLocked
+	}
+	defer client.ReleaseOneRedisClient()
+
+	_, err := client.Execute("EVAL", redisUnlockScript, 1, p.Prefix+"lock:"+name, owner, time.Until(until).Milliseconds())
+	return err
+}
+
+func (p *RedisLockProvider) IsPaused(name string) (bool, error) {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return false, nil
+	}
+	defer client.ReleaseOneRedisClient()
+
+	return client.Bool(client.Execute("EXISTS", p.Prefix+"paused:"+name))
+}
+
+func (p *RedisLockProvider) SetPaused(name string, paused bool) error {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return ErrJobLocked
+	}
+	defer client.ReleaseOneRedisClient()
+
+	var err error
+	if paused {
+		_, err = client.Execute("SET", p.Prefix+"paused:"+name, time.Now().Unix())
+	} else {
+		_, err = client.Execute("DEL", p.Prefix+"paused:"+name)
+	}
+	return err
+}
+
+// 任务租约记录
+type JobLock struct {
+	Name        string    `gorm:"column:name;primaryKey" json:"name"`
+	Owner       string    `gorm:"column:owner" json:"owner"`
+	LockedUntil time.Time `gorm:"column:locked_until" json:"lockedUntil"`
+	Paused      bool      `gorm:"column:paused" json:"paused"`
+}
+
+func (l *JobLock) TableName() string {
+	return "sys_job_lock"
+}
+
+// 基于数据库条件更新的租约
+type DatabaseLockProvider struct {
+	*gorm.DB `inject:"database"`
+}
+
+var databaseLockProvider = &DatabaseLockProvider{}
+
+func init() {
+	inject.InjectValue("jobLockProvider", databaseLockProvider)
+}
+
+func (p *DatabaseLockProvider) ensure(name string) error {
+	return p.Clauses(clause.OnConflict{DoNothing: true}).Create(&JobLock{Name: name, LockedUntil: time.Unix(0, 0)}).Error
+}
+
+func (p *DatabaseLockProvider) TryLock(name string, owner string, ttl time.Duration) (bool, error) {
+	if err := p.ensure(name); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	res := p.Model(&JobLock{}).
+		Where("name = ? AND locked_until < ?", name, now).
+		Updates(map[string]any{
+			"owner":        owner,
+			"locked_until": now.Add(ttl),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (p *DatabaseLockProvider) Renew(name string, owner string, ttl time.Duration) (bool, error) {
+	res := p.Model(&JobLock{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("locked_until", time.Now().Add(ttl))
+	return res.RowsAffected == 1, res.Error
+}
+
+func (p *DatabaseLockProvider) Unlock(name string, owner string, until time.Time) error {
+	return p.Model(&JobLock{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("locked_until", until).Error
+}
+
+func (p *DatabaseLockProvider) IsPaused(name string) (bool, error) {
+	var result JobLock
+	if res := p.Where("name = ?", name).Limit(1).Find(&result); res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	return result.Paused, nil
+}
+
+func (p *DatabaseLockProvider) SetPaused(name string, paused bool) error {
+	if err := p.ensure(name); err != nil {
+		return err
+	}
+	return p.Model(&JobLock{}).Where("name = ?", name).Update("paused", paused).Error
+}
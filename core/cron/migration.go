@@ -0,0 +1,14 @@
+package cron
+
+import (
+	"embed"
+
+	"github.com/gophab/gophrame/core/database"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+func init() {
+	database.RegisterMigrations("cron", migrations)
+}
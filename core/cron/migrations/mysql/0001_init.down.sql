@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sys_job_lock;
+DROP TABLE IF EXISTS sys_job_history;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS sys_job_history (
+    id BIGINT NOT NULL,
+    job_name VARCHAR(255),
+    node VARCHAR(255),
+    trigger_type VARCHAR(255),
+    status VARCHAR(255),
+    attempts INT,
+    start_time DATETIME(3),
+    end_time DATETIME(3),
+    duration BIGINT,
+    error TEXT,
+    PRIMARY KEY (id),
+    KEY idx_sys_job_history_job_name_start_time (job_name, start_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_job_lock (
+    name VARCHAR(64) NOT NULL,
+    owner VARCHAR(255),
+    locked_until DATETIME(3),
+    paused TINYINT(1),
+    PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
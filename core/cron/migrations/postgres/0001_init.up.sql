@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS sys_job_history (
+    id BIGINT NOT NULL,
+    job_name VARCHAR(255),
+    node VARCHAR(255),
+    trigger_type VARCHAR(255),
+    status VARCHAR(255),
+    attempts INT,
+    start_time TIMESTAMP(3),
+    end_time TIMESTAMP(3),
+    duration BIGINT,
+    error TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_job_history_job_name_start_time ON sys_job_history (job_name, start_time);
+
+CREATE TABLE IF NOT EXISTS sys_job_lock (
+    name VARCHAR(64) NOT NULL,
+    owner VARCHAR(255),
+    locked_until TIMESTAMP(3),
+    paused BOOLEAN,
+    PRIMARY KEY (name)
+);
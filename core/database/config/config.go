@@ -16,12 +16,19 @@ type DriverSetting struct {
 	ConnectionMaxLifeTime time.Duration `json:"connectionMaxLifeTime" yaml:"connectionMaxLifeTime"`
 }
 
+type MigrationSetting struct {
+	Auto        bool          `json:"auto" yaml:"auto"`               // 启动时自动执行迁移
+	DryRun      bool          `json:"dryRun" yaml:"dryRun"`           // 只输出待执行的脚本
+	LockTimeout time.Duration `json:"lockTimeout" yaml:"lockTimeout"` // 等待迁移锁的时长
+}
+
 type DatabaseSetting struct {
 	// Common Settings
 	Driver      string `json:"driver"`
 	TablePrefix string `json:"tablePrefix" yaml:"tablePrefix"`
 	DriverSetting
-	Read *DriverSetting `json:"read,omitempty" yaml:"read"`
+	Read      *DriverSetting    `json:"read,omitempty" yaml:"read"`
+	Migration *MigrationSetting `json:"migration" yaml:"migration"`
 }
 
 var Setting *DatabaseSetting = &DatabaseSetting{
@@ -39,6 +46,10 @@ var Setting *DatabaseSetting = &DatabaseSetting{
 		ConnectionMaxLifeTime: time.Second * 180,
 		MaxOpenConnections:    128,
 	},
+	Migration: &MigrationSetting{
+		Auto:        true,
+		LockTimeout: time.Minute,
+	},
 }
 
 func init() {
@@ -0,0 +1,510 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/database/config"
+	"github.com/gophab/gophrame/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// 迁移脚本：<dialect>/<version>_<name>.up.sql 与 <dialect>/<version>_<name>.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+const migrationLockName = "gophrame_schema_migration"
+
+var (
+	ErrMigrationLocked   = errors.New("schema migration is locked by another instance")
+	ErrMigrationChecksum = errors.New("applied migration has been modified")
+)
+
+// 单个迁移版本
+type Migration struct {
+	Source   string `json:"source"`
+	Version  int64  `json:"version"`
+	Name     string `json:"name"`
+	Up       string `json:"-"`
+	Down     string `json:"-"`
+	Checksum string `json:"checksum"`
+}
+
+// 已执行的迁移版本
+type SchemaVersion struct {
+	Source        string    `gorm:"column:source;primaryKey;size:64" json:"source"`
+	Version       int64     `gorm:"column:version;primaryKey;autoIncrement:false" json:"version"`
+	Name          string    `gorm:"column:name;size:255" json:"name"`
+	Checksum      string    `gorm:"column:checksum;size:64" json:"checksum"`
+	AppliedTime   time.Time `gorm:"column:applied_time" json:"appliedTime"`
+	ExecutionTime int64     `gorm:"column:execution_time" json:"executionTime"` // 毫秒
+}
+
+func (v *SchemaVersion) TableName() string {
+	return "schema_version"
+}
+
+// 迁移状态
+type MigrationStatus struct {
+	Migration
+	Applied     bool       `json:"applied"`
+	AppliedTime *time.Time `json:"appliedTime,omitempty"`
+	Modified    bool       `json:"modified,omitempty"` // 已执行的脚本被修改
+}
+
+// 迁移来源：一个 core 包或模块的全部迁移脚本
+type MigrationSource struct {
+	Name   string
+	FS     fs.FS
+	Module bool // 模块的迁移在 Module.Init 中执行，否则在数据库初始化后执行
+}
+
+var (
+	sourcesMutex sync.RWMutex
+	sources      []*MigrationSource
+)
+
+// 注册 core 包的迁移脚本，数据库初始化后自动执行
+func RegisterMigrations(name string, fsys fs.FS) {
+	registerMigrationSource(&MigrationSource{Name: name, FS: fsys})
+}
+
+// 注册模块的迁移脚本，由 Module.Init 执行
+func RegisterModuleMigrations(name string, fsys fs.FS) {
+	registerMigrationSource(&MigrationSource{Name: name, FS: fsys, Module: true})
+}
+
+func registerMigrationSource(source *MigrationSource) {
+	// 支持直接传入 //go:embed migrations 得到的 embed.FS
+	if info, err := fs.Stat(source.FS, "migrations"); err == nil && info.IsDir() {
+		source.FS, _ = fs.Sub(source.FS, "migrations")
+	}
+
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+
+	for i, s := range sources {
+		if s.Name == source.Name {
+			sources[i] = source
+			return
+		}
+	}
+	sources = append(sources, source)
+}
+
+// 已注册的迁移来源，core 包在前、模块在后
+func MigrationSources() []*MigrationSource {
+	sourcesMutex.RLock()
+	defer sourcesMutex.RUnlock()
+
+	result := append([]*MigrationSource{}, sources...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return !result[i].Module && result[j].Module
+	})
+	return result
+}
+
+func GetMigrationSource(name string) *MigrationSource {
+	sourcesMutex.RLock()
+	defer sourcesMutex.RUnlock()
+
+	for _, s := range sources {
+		if strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+// 启动时自动迁移（database.migration.auto）
+func AutoMigrate(sources ...*MigrationSource) error {
+	if !config.Setting.Enabled || config.Setting.Migration == nil || !config.Setting.Migration.Auto || len(sources) == 0 {
+		return nil
+	}
+
+	db := DB()
+	if db == nil {
+		return errors.New("database not initialized")
+	}
+
+	_, err := NewMigrator(db).Up(sources...)
+	return err
+}
+
+// 自动迁移 core 包注册的脚本
+func AutoMigrateCore() error {
+	var core []*MigrationSource
+	for _, s := range MigrationSources() {
+		if !s.Module {
+			core = append(core, s)
+		}
+	}
+	return AutoMigrate(core...)
+}
+
+type Migrator struct {
+	DB          *gorm.DB
+	DryRun      bool          // 只输出待执行的脚本，不执行
+	LockTimeout time.Duration // 等待其他实例释放迁移锁的时长
+}
+
+func NewMigrator(db *gorm.DB) *Migrator {
+	result := &Migrator{DB: db}
+	if setting := config.Setting.Migration; setting != nil {
+		result.DryRun = setting.DryRun
+		result.LockTimeout = setting.LockTimeout
+	}
+	return result
+}
+
+func (m *Migrator) dialect() string {
+	return m.DB.Dialector.Name()
+}
+
+// 读取来源中当前数据库方言的迁移脚本，按版本排序
+func (m *Migrator) Load(source *MigrationSource) ([]*Migration, error) {
+	dir := m.dialect()
+	entries, err := fs.ReadDir(source.FS, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			logger.Warn("No migrations for dialect: ", source.Name, dir)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	versions := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, err := fs.ReadFile(source.FS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, b := versions[version]
+		if !b {
+			migration = &Migration{Source: source.Name, Version: version, Name: matches[2]}
+			versions[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("duplicated migration version: %s %d", source.Name, version)
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]*Migration, 0, len(versions))
+	for _, migration := range versions {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("missing up script: %s %d_%s", source.Name, migration.Version, migration.Name)
+		}
+		result = append(result, migration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+func (m *Migrator) applied(db *gorm.DB, source string) (map[int64]*SchemaVersion, error) {
+	result := make(map[int64]*SchemaVersion)
+	if !db.Migrator().HasTable(&SchemaVersion{}) {
+		return result, nil
+	}
+
+	var versions []*SchemaVersion
+	if err := db.Where("source = ?", source).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		result[v.Version] = v
+	}
+	return result, nil
+}
+
+func (m *Migrator) Status(sources ...*MigrationSource) ([]*MigrationStatus, error) {
+	var result []*MigrationStatus
+	for _, source := range sources {
+		migrations, err := m.Load(source)
+		if err != nil {
+			return nil, err
+		}
+
+		applied, err := m.applied(m.DB, source.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, migration := range migrations {
+			status := &MigrationStatus{Migration: *migration}
+			if v, b := applied[migration.Version]; b {
+				status.Applied = true
+				status.AppliedTime = &v.AppliedTime
+				status.Modified = v.Checksum != migration.Checksum
+			}
+			result = append(result, status)
+		}
+	}
+	return result, nil
+}
+
+// 执行全部待执行的迁移，返回已执行（DryRun 时为待执行）的版本
+func (m *Migrator) Up(sources ...*MigrationSource) ([]*Migration, error) {
+	var result []*Migration
+	err := m.withLock(func(db *gorm.DB) error {
+		for _, source := range sources {
+			migrations, err := m.Load(source)
+			if err != nil {
+				return err
+			}
+
+			applied, err := m.applied(db, source.Name)
+			if err != nil {
+				return err
+			}
+
+			for _, migration := range migrations {
+				if v, b := applied[migration.Version]; b {
+					if v.Checksum != migration.Checksum {
+						return fmt.Errorf("%w: %s %d_%s", ErrMigrationChecksum, source.Name, migration.Version, migration.Name)
+					}
+					continue
+				}
+
+				if err := m.apply(db, migration, migration.Up, func(tx *gorm.DB, elapsed time.Duration) error {
+					return tx.Create(&SchemaVersion{
+						Source:        migration.Source,
+						Version:       migration.Version,
+						Name:          migration.Name,
+						Checksum:      migration.Checksum,
+						AppliedTime:   time.Now(),
+						ExecutionTime: elapsed.Milliseconds(),
+					}).Error
+				}); err != nil {
+					return err
+				}
+				result = append(result, migration)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// 回滚来源最近执行的 steps 个版本
+func (m *Migrator) Down(source *MigrationSource, steps int) ([]*Migration, error) {
+	var result []*Migration
+	err := m.withLock(func(db *gorm.DB) error {
+		migrations, err := m.Load(source)
+		if err != nil {
+			return err
+		}
+
+		applied, err := m.applied(db, source.Name)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(result) < steps; i-- {
+			migration := migrations[i]
+			if _, b := applied[migration.Version]; !b {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("missing down script: %s %d_%s", source.Name, migration.Version, migration.Name)
+			}
+
+			if err := m.apply(db, migration, migration.Down, func(tx *gorm.DB, _ time.Duration) error {
+				return tx.Where("source = ? AND version = ?", migration.Source, migration.Version).Delete(&SchemaVersion{}).Error
+			}); err != nil {
+				return err
+			}
+			result = append(result, migration)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (m *Migrator) apply(db *gorm.DB, migration *Migration, script string, record func(tx *gorm.DB, elapsed time.Duration) error) error {
+	statements := SplitStatements(script)
+	if m.DryRun {
+		logger.Info("[DRY-RUN] Migration: ", migration.Source, migration.Version, migration.Name)
+		for _, statement := range statements {
+			logger.Info("[DRY-RUN] ", statement)
+		}
+		return nil
+	}
+
+	logger.Info("Applying migration: ", migration.Source, migration.Version, migration.Name)
+	start := time.Now()
+
+	// PostgreSQL 的 DDL 支持事务；MySQL 的 DDL 会隐式提交，失败时需要人工处理
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("migration %s %d_%s failed: %w", migration.Source, migration.Version, migration.Name, err)
+			}
+		}
+		return record(tx, time.Since(start))
+	})
+}
+
+// 在同一连接上持有迁移锁，保证只有一个实例执行迁移
+func (m *Migrator) withLock(fn func(db *gorm.DB) error) error {
+	if m.DryRun {
+		return fn(m.DB)
+	}
+
+	return m.DB.Connection(func(conn *gorm.DB) error {
+		unlock, err := m.lock(conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if err := conn.AutoMigrate(&SchemaVersion{}); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) lock(conn *gorm.DB) (func(), error) {
+	timeout := m.LockTimeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+
+	switch m.dialect() {
+	case "mysql":
+		var acquired int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLockName, int(timeout.Seconds())).Scan(&acquired).Error; err != nil {
+			return nil, err
+		}
+		if acquired != 1 {
+			return nil, ErrMigrationLocked
+		}
+		return func() {
+			conn.Exec("SELECT RELEASE_LOCK(?)", migrationLockName)
+		}, nil
+
+	case "postgres":
+		h := fnv.New64a()
+		h.Write([]byte(migrationLockName))
+		key := int64(h.Sum64())
+
+		deadline := time.Now().Add(timeout)
+		for {
+			var acquired bool
+			if err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+				return nil, err
+			}
+			if acquired {
+				return func() {
+					conn.Exec("SELECT pg_advisory_unlock(?)", key)
+				}, nil
+			}
+			if time.Now().After(deadline) {
+				return nil, ErrMigrationLocked
+			}
+			time.Sleep(time.Second)
+		}
+
+	default:
+		logger.Warn("Schema migration lock not supported: ", m.dialect())
+		return func() {}, nil
+	}
+}
+
+// 按分号拆分脚本，忽略字符串、注释和 PostgreSQL $$ 块中的分号
+func SplitStatements(script string) []string {
+	var result []string
+	var current strings.Builder
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			result = append(result, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			// 行注释
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+			continue
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(script) && script[j] != c {
+				if script[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			current.WriteString(script[i:min(j+1, len(script))])
+			i = j
+			continue
+		case c == '$':
+			if end := strings.IndexByte(script[i+1:], '$'); end >= 0 && isDollarTag(script[i+1:i+1+end]) {
+				tag := script[i : i+end+2]
+				if close := strings.Index(script[i+len(tag):], tag); close >= 0 {
+					j := i + len(tag) + close + len(tag)
+					current.WriteString(script[i:j])
+					i = j - 1
+					continue
+				}
+			}
+		case c == ';':
+			flush()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	flush()
+	return result
+}
+
+func isDollarTag(tag string) bool {
+	for _, c := range tag {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
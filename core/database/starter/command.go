@@ -0,0 +1,169 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/gophab/gophrame/core/command"
+	"github.com/gophab/gophrame/core/database"
+	"github.com/gophab/gophrame/core/database/config"
+	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+func init() {
+	command.RegisterCommand(&command.Command{
+		Name:        "migrate up",
+		Usage:       "[--dry-run] [source...]",
+		Description: "Apply pending database migrations",
+		Bootstrap:   command.BOOTSTRAP_CONFIG,
+		Requires:    []func(){InitDB},
+		Run:         migrateUp,
+	})
+
+	command.RegisterCommand(&command.Command{
+		Name:        "migrate down",
+		Usage:       "[--dry-run] <source> [steps]",
+		Description: "Roll back the latest migrations of a source",
+		Bootstrap:   command.BOOTSTRAP_CONFIG,
+		Requires:    []func(){InitDB},
+		Run:         migrateDown,
+	})
+
+	command.RegisterCommand(&command.Command{
+		Name:        "migrate status",
+		Usage:       "[source...]",
+		Description: "Show applied and pending migrations",
+		Bootstrap:   command.BOOTSTRAP_CONFIG,
+		Requires:    []func(){InitDB},
+		Run:         migrateStatus,
+	})
+}
+
+// 只初始化数据库连接，不执行自动迁移；供子命令使用
+func InitDB() {
+	if config.Setting.Enabled {
+		var err error
+		if global.DB, err = database.InitDB(); err == nil {
+			inject.InjectValue("database", global.DB)
+		} else {
+			logger.Error("Initializing Database error: ", err.Error())
+		}
+	}
+}
+
+func migrator(args []string) (*database.Migrator, []string, error) {
+	if global.DB == nil {
+		return nil, nil, errors.New("database not enabled")
+	}
+
+	result := database.NewMigrator(global.DB)
+	var names []string
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			result.DryRun = true
+		} else {
+			names = append(names, arg)
+		}
+	}
+	return result, names, nil
+}
+
+func migrateUp(args []string) error {
+	migrator, names, err := migrator(args)
+	if err != nil {
+		return err
+	}
+
+	sources, err := migrationSources(names)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := migrator.Up(sources...)
+	printMigrations(migrations)
+	return err
+}
+
+func migrateDown(args []string) error {
+	migrator, names, err := migrator(args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errors.New("usage: migrate down <source> [steps]")
+	}
+
+	sources, err := migrationSources(names[:1])
+	if err != nil {
+		return err
+	}
+
+	steps := 1
+	if len(names) > 1 {
+		if steps, err = strconv.Atoi(names[1]); err != nil {
+			return err
+		}
+	}
+
+	migrations, err := migrator.Down(sources[0], steps)
+	printMigrations(migrations)
+	return err
+}
+
+func migrateStatus(args []string) error {
+	migrator, names, err := migrator(args)
+	if err != nil {
+		return err
+	}
+
+	sources, err := migrationSources(names)
+	if err != nil {
+		return err
+	}
+
+	result, err := migrator.Status(sources...)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tVERSION\tNAME\tSTATUS\tAPPLIED")
+	for _, s := range result {
+		status, applied := "pending", ""
+		if s.Applied {
+			status, applied = "applied", s.AppliedTime.Format("2006-01-02 15:04:05")
+			if s.Modified {
+				status = "modified"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", s.Source, s.Version, s.Name, status, applied)
+	}
+	return w.Flush()
+}
+
+func printMigrations(migrations []*database.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s\t%d\t%s\n", m.Source, m.Version, m.Name)
+	}
+}
+
+func migrationSources(names []string) ([]*database.MigrationSource, error) {
+	if len(names) == 0 {
+		return database.MigrationSources(), nil
+	}
+
+	var result []*database.MigrationSource
+	for _, name := range names {
+		source := database.GetMigrationSource(name)
+		if source == nil {
+			return nil, fmt.Errorf("unknown migration source: %s", name)
+		}
+		result = append(result, source)
+	}
+	return result, nil
+}
@@ -14,18 +14,26 @@ import (
 )
 
 func init() {
-	starter.RegisterInitializor(Init)
+	starter.RegisterInitializorFunc(Init, 0)
 }
 
-func Init() {
+// 迁移失败时中止启动，不在迁移了一半的表结构上运行
+func Init() error {
 	logger.Debug("Initializing Database: ...", config.Setting.Enabled)
 	if config.Setting.Enabled {
 		var err error
 		if global.DB, err = database.InitDB(); err == nil {
 			inject.InjectValue("database", global.DB)
 			logger.Info("Database initialized.")
+
+			// core 包的迁移，模块的迁移在 Module.Init 中执行
+			if err = database.AutoMigrateCore(); err != nil {
+				logger.Error("Migrate database error: ", err.Error())
+				return err
+			}
 		} else {
 			logger.Error("Initializing Database error: ", err.Error())
 		}
 	}
+	return nil
 }
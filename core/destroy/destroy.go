@@ -4,6 +4,7 @@ import (
 	"github.com/gophab/gophrame/core/eventbus"
 	"github.com/gophab/gophrame/core/global"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/starter"
 
 	"os"
 	"os/signal"
@@ -22,6 +23,7 @@ func init() {
 		signal.Notify(c, os.Interrupt, os.Kill, syscall.SIGQUIT, syscall.SIGINT, syscall.SIGTERM) // 监听可能的退出信号
 		received := <-c                                                                           //接收信号管道中的值
 		logger.Warn(ProcessKilled, "信号值", received.String())
+		starter.Terminate()
 		eventbus.FuzzyPublishEvent(global.EventDestroyPrefix)
 		close(c)
 		os.Exit(1)
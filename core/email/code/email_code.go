@@ -1,6 +1,8 @@
 package code
 
 import (
+	"errors"
+
 	"github.com/gophab/gophrame/core/code"
 	"github.com/gophab/gophrame/core/content"
 	"github.com/gophab/gophrame/core/email"
@@ -27,10 +29,14 @@ type EmailCodeValidator struct {
 }
 
 type EmailCodeSender struct {
-	EmailSender email.EmailSender `inject:"emailSender"`
+	EmailSender email.EmailSender `inject:"emailSender,optional"`
 }
 
 func (s *EmailCodeSender) SendVerificationCode(dest string, scene string, code string) error {
+	if s.EmailSender == nil {
+		return errors.New("email sender not configured")
+	}
+
 	params := make(map[string]string)
 	params["code"] = code
 
@@ -0,0 +1,310 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gophab/gophrame/core/eventbus/config"
+	"github.com/gophab/gophrame/core/i18n"
+	"github.com/gophab/gophrame/core/logger"
+	Errors "github.com/gophab/gophrame/errors"
+)
+
+// 事件
+type Event struct {
+	Topic  string
+	Args   []any
+	Async  bool
+	Locale string
+}
+
+// 事件处理器，返回的错误会交给 ErrorHandler 处理
+type Handler func(e *Event) error
+
+// 监听器执行异常（包括 panic）时的回调
+type ErrorHandler func(e *Event, s *Subscription, err error)
+
+// 拦截器：围绕每一个监听器的调用，可用于链路追踪、指标统计
+type Interceptor func(e *Event, s *Subscription, next func() error) error
+
+// 监听器 panic 时产生的错误
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("eventbus listener panic: %v", e.Value)
+}
+
+// 创建一个事件管理工厂
+func CreateEventbus() *Eventbus {
+	return &Eventbus{
+		topics: make(map[string][]*Subscription),
+		errorHandler: func(e *Event, s *Subscription, err error) {
+			logger.Error("Eventbus listener error: ", e.Topic, s.String(), err.Error())
+		},
+	}
+}
+
+// 定义一个事件管理结构体
+type Eventbus struct {
+	sync.RWMutex
+	topics       map[string][]*Subscription // 精确匹配
+	patterns     []*Subscription            // 通配符匹配
+	interceptors []Interceptor
+	errorHandler ErrorHandler
+	sequence     uint64
+
+	poolOnce sync.Once
+	pool     *workerPool
+}
+
+func (e *Eventbus) Use(interceptors ...Interceptor) {
+	e.Lock()
+	defer e.Unlock()
+	e.interceptors = append(e.interceptors, interceptors...)
+}
+
+func (e *Eventbus) SetErrorHandler(h ErrorHandler) {
+	e.Lock()
+	defer e.Unlock()
+	e.errorHandler = h
+}
+
+// 注册处理器，返回取消订阅的句柄
+func (e *Eventbus) Subscribe(topic string, handler Handler, opts ...SubscribeOption) *Subscription {
+	s := &Subscription{
+		bus:     e,
+		topic:   topic,
+		handler: handler,
+		id:      atomic.AddUint64(&e.sequence, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	if isPattern(topic) {
+		e.patterns = insertSubscription(e.patterns, s)
+	} else {
+		e.topics[topic] = insertSubscription(e.topics[topic], s)
+	}
+	return s
+}
+
+// 注册原有形式的监听器
+func (e *Eventbus) Listen(topic string, listener EventListener, opts ...SubscribeOption) *Subscription {
+	s := e.Subscribe(topic, func(ev *Event) error {
+		listener(ev.Topic, ev.Args...)
+		return nil
+	}, opts...)
+	s.listener = reflect.ValueOf(listener).Pointer()
+	return s
+}
+
+// 1.注册事件，返回是否为该事件的第一个监听器
+func (e *Eventbus) RegisterEventListener(event string, listener EventListener) bool {
+	_, exists := e.GetEventListeners(event)
+	e.Listen(event, listener)
+	return !exists
+}
+
+// 2.获取事件（精确匹配的订阅）
+func (e *Eventbus) GetEventListeners(event string) ([]*Subscription, bool) {
+	e.RLock()
+	defer e.RUnlock()
+
+	if queue, exists := e.topics[event]; exists && len(queue) > 0 {
+		return append([]*Subscription{}, queue...), true
+	}
+	return nil, false
+}
+
+func (e *Eventbus) unsubscribe(s *Subscription) {
+	e.Lock()
+	defer e.Unlock()
+
+	if isPattern(s.topic) {
+		e.patterns = removeSubscription(e.patterns, s)
+	} else if queue, b := e.topics[s.topic]; b {
+		if queue = removeSubscription(queue, s); len(queue) > 0 {
+			e.topics[s.topic] = queue
+		} else {
+			delete(e.topics, s.topic)
+		}
+	}
+}
+
+// 4.删除事件
+func (e *Eventbus) RemoveEventListeners(event string) {
+	e.Lock()
+	defer e.Unlock()
+
+	if isPattern(event) {
+		var j = 0
+		for _, s := range e.patterns {
+			if s.topic != event {
+				e.patterns[j] = s
+				j++
+			}
+		}
+		e.patterns = e.patterns[:j]
+	} else {
+		delete(e.topics, event)
+	}
+}
+
+// 4.删除事件
+func (e *Eventbus) RemoveEventListener(event string, listener EventListener) {
+	pointer := reflect.ValueOf(listener).Pointer()
+
+	var found []*Subscription
+	e.RLock()
+	queue := e.topics[event]
+	if isPattern(event) {
+		queue = e.patterns
+	}
+	for _, s := range queue {
+		if s.topic == event && s.listener == pointer {
+			found = append(found, s)
+		}
+	}
+	e.RUnlock()
+
+	for _, s := range found {
+		s.Unsubscribe()
+	}
+}
+
+// 匹配事件的所有订阅，按优先级从高到低、注册先后排序
+func (e *Eventbus) match(topic string) ([]*Subscription, []Interceptor, ErrorHandler) {
+	e.RLock()
+	defer e.RUnlock()
+
+	result := append([]*Subscription{}, e.topics[topic]...)
+	for _, s := range e.patterns {
+		if matchTopic(s.topic, topic) {
+			result = append(result, s)
+		}
+	}
+	if len(e.patterns) > 0 {
+		sort.SliceStable(result, func(i, j int) bool {
+			return less(result[i], result[j])
+		})
+	}
+	return result, e.interceptors, e.errorHandler
+}
+
+// 执行单个监听器：拦截器链 + panic 隔离
+func (e *Eventbus) invoke(ev *Event, s *Subscription, interceptors []Interceptor, errorHandler ErrorHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+		if err != nil && errorHandler != nil {
+			errorHandler(ev, s, err)
+		}
+	}()
+
+	var call func(int) error
+	call = func(i int) error {
+		if i == len(interceptors) {
+			return s.handler(ev)
+		}
+		return interceptors[i](ev, s, func() error { return call(i + 1) })
+	}
+	return call(0)
+}
+
+func (e *Eventbus) deliver(ev *Event, subscriptions []*Subscription, interceptors []Interceptor, errorHandler ErrorHandler) error {
+	var errs []error
+	for _, s := range subscriptions {
+		if s.isActive() {
+			if err := e.invoke(ev, s, interceptors, errorHandler); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 同步分发，返回所有监听器错误
+func (e *Eventbus) Publish(event string, args ...any) error {
+	subscriptions, interceptors, errorHandler := e.match(event)
+	if len(subscriptions) == 0 {
+		logger.Warn(Errors.ERROR_FUNC_EVENT_NOT_REGISTER, ", 无效键名：", event)
+		return nil
+	}
+
+	return e.deliver(&Event{Topic: event, Args: args}, subscriptions, interceptors, errorHandler)
+}
+
+// 3.执行事件
+func (e *Eventbus) PublishEvent(event string, args ...any) {
+	_ = e.Publish(event, args...)
+}
+
+// 3.执行事件：在工作池中按优先级依次执行该事件的监听器
+func (e *Eventbus) DispatchEvent(event string, args ...any) {
+	subscriptions, interceptors, errorHandler := e.match(event)
+	if len(subscriptions) == 0 {
+		logger.Error(Errors.ERROR_FUNC_EVENT_NOT_REGISTER, ", 无效键名：", event)
+		return
+	}
+
+	ev := &Event{Topic: event, Args: args, Async: true, Locale: i18n.GetEnableLanguage()}
+	e.workers().submit(func() {
+		i18n.SetCurrentLanguage(ev.Locale)
+		defer i18n.SetCurrentLanguage("")
+		_ = e.deliver(ev, subscriptions, interceptors, errorHandler)
+	})
+}
+
+func (e *Eventbus) workers() *workerPool {
+	e.poolOnce.Do(func() {
+		e.pool = newWorkerPool(config.Setting.Workers, config.Setting.QueueSize)
+	})
+	return e.pool
+}
+
+// 等待异步队列处理完成后停止工作池，之后的异步事件在调用方协程执行
+func (e *Eventbus) Close() {
+	e.workers().close()
+}
+
+func (e *Eventbus) topicsWithPrefix(eventPre string) []string {
+	e.RLock()
+	defer e.RUnlock()
+
+	var result []string
+	for event := range e.topics {
+		if strings.HasPrefix(event, eventPre) {
+			result = append(result, event)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// 5.根据键的前缀，模糊调用. 使用请谨慎.
+func (e *Eventbus) FuzzyPublishEvent(eventPre string, args ...any) {
+	for _, event := range e.topicsWithPrefix(eventPre) {
+		e.PublishEvent(event, args...)
+	}
+}
+
+// 6.根据键的前缀，模糊调用. 使用请谨慎.
+func (e *Eventbus) FuzzyDispatchEvent(eventPre string, args ...any) {
+	for _, event := range e.topicsWithPrefix(eventPre) {
+		e.DispatchEvent(event, args...)
+	}
+}
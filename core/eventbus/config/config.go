@@ -0,0 +1,19 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+)
+
+type EventbusSetting struct {
+	Workers   int `json:"workers" yaml:"workers"`     // 异步分发的工作协程数
+	QueueSize int `json:"queueSize" yaml:"queueSize"` // 异步分发的队列长度，队列满时由调用方协程执行
+}
+
+var Setting *EventbusSetting = &EventbusSetting{
+	Workers:   16,
+	QueueSize: 1024,
+}
+
+func init() {
+	config.RegisterConfig("eventbus", Setting, "Eventbus Settings")
+}
@@ -1,30 +1,62 @@
 package eventbus
 
 import (
-	"strings"
-	"sync"
-
-	"github.com/gophab/gophrame/core/i18n"
 	"github.com/gophab/gophrame/core/inject"
-	"github.com/gophab/gophrame/core/logger"
-	"github.com/gophab/gophrame/errors"
+	"github.com/gophab/gophrame/core/starter"
 )
 
 type EventListener func(event string, args ...any)
 
 // 公共消息总线
-var theEventbus *eventbus = CreateEventbus()
+var theEventbus *Eventbus = CreateEventbus()
 
 func init() {
+	theEventbus.Use(MetricsInterceptor())
 	inject.InjectValue("eventbus", theEventbus)
+	starter.RegisterTerminater(theEventbus.Close)
+}
+
+func Default() *Eventbus {
+	return theEventbus
 }
 
 func RegisterEventListener(event string, listener EventListener) bool {
 	return theEventbus.RegisterEventListener(event, listener)
 }
 
+// 按函数地址比较删除监听器，对闭包及方法值无法精确区分，建议使用 Listen 返回的 Subscription
 func RemoveEventListener(event string, listener EventListener) {
-	theEventbus.RegisterEventListener(event, listener)
+	theEventbus.RemoveEventListener(event, listener)
+}
+
+func RemoveEventListeners(event string) {
+	theEventbus.RemoveEventListeners(event)
+}
+
+/**
+ * 注册监听器，返回用于取消订阅的句柄
+ * event 支持通配符: USER_* / *_CREATED
+ */
+func Listen(event string, listener EventListener, opts ...SubscribeOption) *Subscription {
+	return theEventbus.Listen(event, listener, opts...)
+}
+
+/**
+ * 注册返回错误的处理器
+ */
+func Handle(event string, handler Handler, opts ...SubscribeOption) *Subscription {
+	return theEventbus.Subscribe(event, handler, opts...)
+}
+
+/**
+ * 增加拦截器（链路追踪、指标等）
+ */
+func Use(interceptors ...Interceptor) {
+	theEventbus.Use(interceptors...)
+}
+
+func SetErrorHandler(h ErrorHandler) {
+	theEventbus.SetErrorHandler(h)
 }
 
 /**
@@ -34,6 +66,13 @@ func PublishEvent(event string, args ...any) {
 	theEventbus.PublishEvent(event, args...)
 }
 
+/**
+ * 同步分发消息，返回监听器产生的错误
+ */
+func Publish(event string, args ...any) error {
+	return theEventbus.Publish(event, args...)
+}
+
 /**
  *	异步分发消息
  */
@@ -54,103 +93,3 @@ func FuzzyPublishEvent(eventPre string, args ...any) {
 func FuzzyDispatchEvent(eventPre string, args ...any) {
 	theEventbus.FuzzyDispatchEvent(eventPre, args...)
 }
-
-// 创建一个事件管理工厂
-func CreateEventbus() *eventbus {
-	return new(eventbus)
-}
-
-// 定义一个事件管理结构体
-type eventbus struct {
-	// 定义一个全局事件存储变量，本模块只负责存储 键 => 函数 ， 相对容器来说功能稍弱，但是调用更加简单、方便、快捷
-	eventListeners sync.Map
-}
-
-// 1.注册事件
-func (e *eventbus) RegisterEventListener(event string, listener EventListener) bool {
-	//判断key下是否已有事件
-	if queue, exists := e.GetEventListeners(event); !exists {
-		e.eventListeners.Store(event, []EventListener{listener})
-		return true
-	} else {
-		e.eventListeners.Store(event, append(queue, listener))
-	}
-	return false
-}
-
-// 2.获取事件
-func (e *eventbus) GetEventListeners(event string) ([]EventListener, bool) {
-	if queue, exists := e.eventListeners.Load(event); exists {
-		return queue.([]EventListener), exists
-	}
-	return nil, false
-}
-
-// 3.执行事件
-func (e *eventbus) PublishEvent(event string, args ...any) {
-	if queue, exists := e.GetEventListeners(event); exists {
-		for _, fn := range queue {
-			fn(event, args...)
-		}
-	} else {
-		logger.Warn(errors.ERROR_FUNC_EVENT_NOT_REGISTER, ", 无效键名：", event)
-	}
-}
-
-// 3.执行事件
-func (e *eventbus) DispatchEvent(event string, args ...any) {
-	if queue, exists := e.GetEventListeners(event); exists {
-		locale := i18n.GetEnableLanguage()
-		for _, fn := range queue {
-			go func(cb EventListener) {
-				i18n.SetCurrentLanguage(locale)
-				cb(event, args...)
-			}(fn)
-		}
-	} else {
-		logger.Error(errors.ERROR_FUNC_EVENT_NOT_REGISTER, ", 无效键名：", event)
-	}
-}
-
-// 4.删除事件
-func (e *eventbus) RemoveEventListeners(event string) {
-	e.eventListeners.Delete(event)
-}
-
-// 4.删除事件
-func (e *eventbus) RemoveEventListener(event string, listener EventListener) {
-	if queue, exists := e.GetEventListeners(event); exists {
-		var j = 0
-		for _, v := range queue {
-			if &listener != &v {
-				queue[j] = v
-				j++
-			}
-		}
-		e.eventListeners.Store(event, queue[:j])
-	}
-}
-
-// 5.根据键的前缀，模糊调用. 使用请谨慎.
-func (e *eventbus) FuzzyPublishEvent(eventPre string, args ...any) {
-	e.eventListeners.Range(func(eventKey, value any) bool {
-		if event, ok := eventKey.(string); ok {
-			if strings.HasPrefix(event, eventPre) {
-				e.PublishEvent(event, args...)
-			}
-		}
-		return true
-	})
-}
-
-// 6.根据键的前缀，模糊调用. 使用请谨慎.
-func (e *eventbus) FuzzyDispatchEvent(eventPre string, args ...any) {
-	e.eventListeners.Range(func(eventKey, value any) bool {
-		if event, ok := eventKey.(string); ok {
-			if strings.HasPrefix(event, eventPre) {
-				e.DispatchEvent(event, args...)
-			}
-		}
-		return true
-	})
-}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 每个主题的分发统计
+type TopicMetrics struct {
+	Topic    string        `json:"topic"`
+	Calls    int64         `json:"calls"`
+	Failures int64         `json:"failures"`
+	Panics   int64         `json:"panics"`
+	Duration time.Duration `json:"duration"` // 累计耗时
+}
+
+type metricsCollector struct {
+	sync.Mutex
+	topics map[string]*TopicMetrics
+}
+
+var collector = &metricsCollector{topics: make(map[string]*TopicMetrics)}
+
+// 指标拦截器：记录调用次数、失败次数、panic 次数与耗时
+func MetricsInterceptor() Interceptor {
+	return func(e *Event, s *Subscription, next func() error) (err error) {
+		start := time.Now()
+		panicked := true
+		defer func() {
+			collector.Lock()
+			defer collector.Unlock()
+
+			m, b := collector.topics[e.Topic]
+			if !b {
+				m = &TopicMetrics{Topic: e.Topic}
+				collector.topics[e.Topic] = m
+			}
+			m.Calls++
+			m.Duration += time.Since(start)
+			if panicked {
+				m.Panics++
+				m.Failures++
+			} else if err != nil {
+				m.Failures++
+			}
+		}()
+
+		err = next()
+		panicked = false
+		return err
+	}
+}
+
+// 链路追踪拦截器：以 Debug 级别输出每次监听器执行的耗时
+func TraceInterceptor() Interceptor {
+	return func(e *Event, s *Subscription, next func() error) error {
+		start := time.Now()
+		err := next()
+		logger.Slog().Debug("eventbus", "topic", e.Topic, "listener", s.String(), "async", e.Async, "duration", time.Since(start), "error", err)
+		return err
+	}
+}
+
+// 指标快照
+func Metrics() []TopicMetrics {
+	collector.Lock()
+	defer collector.Unlock()
+
+	result := make([]TopicMetrics, 0, len(collector.topics))
+	for _, m := range collector.topics {
+		result = append(result, *m)
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 有界工作池：队列满时由调用方协程直接执行，避免无限制创建协程
+type workerPool struct {
+	tasks  chan func()
+	wg     sync.WaitGroup
+	mutex  sync.RWMutex
+	closed bool
+}
+
+func newWorkerPool(workers, queueSize int) *workerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &workerPool{tasks: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.run()
+	}
+	return p
+}
+
+func (p *workerPool) run() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		task()
+	}
+}
+
+func (p *workerPool) submit(task func()) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.closed {
+		select {
+		case p.tasks <- task:
+			return
+		default:
+			logger.Warn("Eventbus dispatch queue is full, run in caller routine")
+		}
+	}
+	task()
+}
+
+func (p *workerPool) close() {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mutex.Unlock()
+
+	p.wg.Wait()
+}
@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// 订阅句柄
+type Subscription struct {
+	bus      *Eventbus
+	id       uint64
+	topic    string
+	name     string
+	priority int
+	handler  Handler
+	listener uintptr // EventListener 函数地址，用于 RemoveEventListener
+	removed  atomic.Bool
+}
+
+type SubscribeOption func(s *Subscription)
+
+// 优先级越高越先执行，相同优先级按注册顺序执行
+func WithPriority(priority int) SubscribeOption {
+	return func(s *Subscription) {
+		s.priority = priority
+	}
+}
+
+// 监听器名称，用于日志与指标
+func WithName(name string) SubscribeOption {
+	return func(s *Subscription) {
+		s.name = name
+	}
+}
+
+func (s *Subscription) Topic() string {
+	return s.topic
+}
+
+func (s *Subscription) Name() string {
+	return s.name
+}
+
+func (s *Subscription) Priority() int {
+	return s.priority
+}
+
+func (s *Subscription) String() string {
+	if s.name != "" {
+		return s.name
+	}
+	return fmt.Sprintf("%s#%d", s.topic, s.id)
+}
+
+// 取消订阅，可重复调用
+func (s *Subscription) Unsubscribe() {
+	if s.removed.CompareAndSwap(false, true) {
+		s.bus.unsubscribe(s)
+	}
+}
+
+func (s *Subscription) isActive() bool {
+	return !s.removed.Load()
+}
+
+func less(a, b *Subscription) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.id < b.id
+}
+
+// 写时复制：已在分发中的切片不受影响
+func insertSubscription(queue []*Subscription, s *Subscription) []*Subscription {
+	result := make([]*Subscription, 0, len(queue)+1)
+	inserted := false
+	for _, v := range queue {
+		if !inserted && less(s, v) {
+			result = append(result, s)
+			inserted = true
+		}
+		result = append(result, v)
+	}
+	if !inserted {
+		result = append(result, s)
+	}
+	return result
+}
+
+func removeSubscription(queue []*Subscription, s *Subscription) []*Subscription {
+	result := make([]*Subscription, 0, len(queue))
+	for _, v := range queue {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func isPattern(topic string) bool {
+	return strings.ContainsAny(topic, "*?")
+}
+
+// 通配符匹配: * 匹配任意长度字符，? 匹配单个字符
+func matchTopic(pattern, topic string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(topic); i++ {
+				if matchTopic(pattern, topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || topic[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0
+}
@@ -0,0 +1,81 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 类型化事件的主题: 包路径.类型名
+func TopicOf[T any]() string {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// 订阅类型化事件，主题由类型决定
+//
+//	eventbus.Subscribe[UserCreated](func(e UserCreated) error { ... })
+//	eventbus.Emit(UserCreated{...})
+func Subscribe[T any](handler func(payload T) error, opts ...SubscribeOption) *Subscription {
+	return SubscribeTopic(TopicOf[T](), handler, opts...)
+}
+
+// 订阅指定主题的类型化事件，第一个参数为事件负载
+func SubscribeTopic[T any](topic string, handler func(payload T) error, opts ...SubscribeOption) *Subscription {
+	return theEventbus.Subscribe(topic, typedHandler(handler), opts...)
+}
+
+func typedHandler[T any](handler func(payload T) error) Handler {
+	return func(e *Event) error {
+		if len(e.Args) == 0 {
+			var zero T
+			return handler(zero)
+		}
+		payload, ok := payloadOf[T](e.Args[0])
+		if !ok {
+			return fmt.Errorf("eventbus: event %s payload type %T is not %s", e.Topic, e.Args[0], reflect.TypeOf((*T)(nil)).Elem())
+		}
+		return handler(payload)
+	}
+}
+
+// 主题不区分指针和值，负载按订阅的类型转换：*X 取值，X 取地址（副本）
+func payloadOf[T any](arg any) (T, bool) {
+	if payload, ok := arg.(T); ok {
+		return payload, true
+	}
+
+	var zero T
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	v := reflect.ValueOf(arg)
+	if !v.IsValid() {
+		return zero, false
+	}
+	switch {
+	case v.Kind() == reflect.Pointer && v.Type().Elem() == target:
+		if v.IsNil() {
+			return zero, false
+		}
+		return v.Elem().Interface().(T), true
+	case target.Kind() == reflect.Pointer && target.Elem() == v.Type():
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface().(T), true
+	}
+	return zero, false
+}
+
+// 同步发送类型化事件
+func Emit[T any](payload T) error {
+	return theEventbus.Publish(TopicOf[T](), payload)
+}
+
+// 异步发送类型化事件
+func EmitAsync[T any](payload T) {
+	theEventbus.DispatchEvent(TopicOf[T](), payload)
+}
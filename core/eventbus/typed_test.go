@@ -0,0 +1,77 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type testCreated struct {
+	Id string
+}
+
+func TestTopicIgnoresPointer(t *testing.T) {
+	if TopicOf[testCreated]() != TopicOf[*testCreated]() {
+		t.Fatalf("topics differ: %s %s", TopicOf[testCreated](), TopicOf[*testCreated]())
+	}
+}
+
+func TestEmitPointerToValueSubscriber(t *testing.T) {
+	var got []string
+	s := Subscribe(func(e testCreated) error {
+		got = append(got, "value:"+e.Id)
+		return nil
+	})
+	defer s.Unsubscribe()
+	p := Subscribe(func(e *testCreated) error {
+		got = append(got, "pointer:"+e.Id)
+		return nil
+	})
+	defer p.Unsubscribe()
+
+	if err := Emit(&testCreated{Id: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Emit(testCreated{Id: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 deliveries, got %v", got)
+	}
+}
+
+func TestEmitNilPointer(t *testing.T) {
+	s := Subscribe(func(e testCreated) error { return nil })
+	defer s.Unsubscribe()
+
+	if err := Emit[*testCreated](nil); err == nil {
+		t.Fatal("expected type error for nil pointer payload")
+	}
+}
+
+func TestCloseConcurrentWithDispatch(t *testing.T) {
+	bus := CreateEventbus()
+	var count atomic.Int32
+	bus.Subscribe("test.close", func(e *Event) error {
+		count.Add(1)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bus.DispatchEvent("test.close")
+		}()
+	}
+	bus.Close()
+	wg.Wait()
+	bus.Close()
+
+	// 关闭后的异步事件在调用方协程执行
+	bus.DispatchEvent("test.close")
+	if count.Load() != 9 {
+		t.Fatalf("expected 9 deliveries, got %d", count.Load())
+	}
+}
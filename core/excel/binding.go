@@ -0,0 +1,180 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/i18n"
+)
+
+// 列定义，来自字段标签：
+//
+//	excel:"key,alias=别名1|别名2,ref=organization,width=20,format=2006-01-02"
+//
+// 导入时表头与 key、别名或 key 的 i18n 翻译匹配（忽略大小写和首尾空格），
+// ref 表示单元格内容需要解析为引用实体的 Id；导出时 width、format 指定列宽和格式
+type column struct {
+	field   []int
+	key     string
+	aliases []string
+	ref     string
+	width   float64
+	format  string
+	pos     int // 源文件中的列序号，-1 表示没有该列
+}
+
+func parseColumns(t reflect.Type) []*column {
+	var result = make([]*column, 0)
+	for _, f := range reflect.VisibleFields(t) {
+		tag, b := f.Tag.Lookup("excel")
+		if !b || tag == "-" || !f.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		c := &column{field: f.Index, key: strings.TrimSpace(parts[0]), pos: -1}
+		if c.key == "" {
+			c.key = f.Name
+		}
+		for _, part := range parts[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch k {
+			case "alias":
+				c.aliases = strings.Split(v, "|")
+			case "ref":
+				c.ref = v
+			case "width":
+				c.width, _ = strconv.ParseFloat(v, 64)
+			case "format":
+				c.format = v
+			}
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// 导出标题：key 的 i18n 翻译，没有翻译时取第一个别名
+func (c *column) title(locale string) string {
+	if name := i18n.LT(locale, c.key); name != c.key {
+		return name
+	}
+	if len(c.aliases) > 0 {
+		return c.aliases[0]
+	}
+	return c.key
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func (c *column) names(locale string) []string {
+	var result = []string{normalize(c.key)}
+	for _, alias := range c.aliases {
+		result = append(result, normalize(alias))
+	}
+	if name := i18n.LT(locale, c.key); name != c.key {
+		result = append(result, normalize(name))
+	}
+	return result
+}
+
+// 按表头确定各列位置
+func bindHeader(columns []*column, header []string, locale string) {
+	var positions = make(map[string]int, len(header))
+	for i, h := range header {
+		if h = normalize(h); h != "" {
+			if _, b := positions[h]; !b {
+				positions[h] = i
+			}
+		}
+	}
+
+	for _, c := range columns {
+		c.pos = -1
+		for _, name := range c.names(locale) {
+			if pos, b := positions[name]; b {
+				c.pos = pos
+				break
+			}
+		}
+	}
+}
+
+var dateLayouts = []string{
+	time.DateTime,
+	time.DateOnly,
+	time.RFC3339,
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006-01-02 15:04",
+	"01-02-06", // 未设置格式的日期单元格按 mm-dd-yy 输出
+}
+
+// 单元格内容写入字段，空内容保持零值
+func setValue(v reflect.Value, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), text); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range dateLayouts {
+			if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("无效的日期: %s", text)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimSuffix(text, ".0"), 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("无效的整数: %s", text)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(strings.TrimSuffix(text, ".0"), 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("无效的整数: %s", text)
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("无效的数字: %s", text)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		switch normalize(text) {
+		case "1", "true", "yes", "y", "是":
+			v.SetBool(true)
+		case "0", "false", "no", "n", "否":
+			v.SetBool(false)
+		default:
+			return fmt.Errorf("无效的布尔值: %s", text)
+		}
+	default:
+		return fmt.Errorf("不支持的字段类型: %s", v.Type())
+	}
+	return nil
+}
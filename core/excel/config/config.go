@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type ExportSetting struct {
+	Company     string `json:"company" yaml:"company"`         // 导出文件抬头的企业名称
+	HeaderColor string `json:"headerColor" yaml:"headerColor"` // 表头背景色，如 1F4E79
+	Footer      string `json:"footer" yaml:"footer"`           // PDF 页脚
+	BatchSize   int    `json:"batchSize" yaml:"batchSize"`     // 每次从数据源读取的行数
+	Font        string `json:"font" yaml:"font"`               // PDF 使用的 TrueType 字体文件，如 NotoSansSC-Regular.ttf，子集嵌入
+	MaxPdfRows  int    `json:"maxPdfRows" yaml:"maxPdfRows"`   // PDF 在内存中生成，超出行数时报错
+}
+
+var Setting *ExportSetting = &ExportSetting{
+	HeaderColor: "1F4E79",
+	BatchSize:   1000,
+	MaxPdfRows:  20000,
+}
+
+func init() {
+	logger.Debug("Register Export Config")
+	config.RegisterConfig("export", Setting, "Export Settings")
+}
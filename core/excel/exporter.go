@@ -14,9 +14,13 @@ import (
 )
 
 type ExcelColumn struct {
-	Title  string /* 标题 */
-	Path   string /* 路径 */
-	Column string /* 列 */
+	Title  string  /* 标题 */
+	Path   string  /* 路径 */
+	Column string  /* 列 */
+	Format string  /* 格式：时间为布局如 2006-01-02，其他为 fmt 格式如 %.2f */
+	Width  float64 /* 列宽（字符数），0 为默认 */
+
+	field []int
 }
 
 type Exporter struct {
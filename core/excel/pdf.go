@@ -0,0 +1,211 @@
+package excel
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/excel/config"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// PDF 表格：A4 横向，由 gopdf 生成，字体子集嵌入文件。字体取 export.font 配置的 TrueType 文件，
+// 未配置时使用 Go 字体，其中没有的字符（如中文）显示为 ?；列宽按比例分配，超出的内容截断
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfFontSize   = 9.0
+	pdfRowHeight  = 16.0
+	pdfFont       = "F1"
+)
+
+// 文档在内存中组装后一次写出，行数超出 export.maxPdfRows 时报错
+var ErrPdfTooLarge = errors.New("too many rows for pdf export")
+
+type pdfWriter struct {
+	w        io.Writer
+	template *Template
+	pdf      *gopdf.GoPdf
+	pages    int
+	rows     int
+
+	columns []ExcelColumn
+	widths  []float64
+	y       float64
+}
+
+func newPdfWriter(w io.Writer, template *Template) (*pdfWriter, error) {
+	font := goregular.TTF
+	if config.Setting.Font != "" {
+		data, err := os.ReadFile(config.Setting.Font)
+		if err != nil {
+			return nil, err
+		}
+		font = data
+	}
+
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{Unit: gopdf.UnitPT, PageSize: gopdf.Rect{W: pdfPageWidth, H: pdfPageHeight}})
+	if err := pdf.AddTTFFontDataWithOption(pdfFont, font, gopdf.TtfOption{
+		OnGlyphNotFoundSubstitute: func(r rune) rune { return '?' },
+	}); err != nil {
+		return nil, err
+	}
+	pdf.SetLineWidth(0.5)
+
+	return &pdfWriter{w: w, template: template, pdf: pdf}, nil
+}
+
+func (p *pdfWriter) WriteHeader(columns []ExcelColumn) error {
+	p.columns = columns
+
+	var total float64
+	for _, c := range columns {
+		total += max(c.Width, 8)
+	}
+	p.widths = make([]float64, len(columns))
+	for i, c := range columns {
+		p.widths[i] = (pdfPageWidth - 2*pdfMargin) * max(c.Width, 8) / max(total, 1)
+	}
+
+	return p.newPage()
+}
+
+func (p *pdfWriter) newPage() error {
+	p.pdf.AddPage()
+	p.pages++
+	p.y = pdfMargin
+
+	if p.template.Title != "" {
+		if err := p.text(pdfMargin, p.y, pdfPageWidth-2*pdfMargin, 18, 14, p.template.Title); err != nil {
+			return err
+		}
+		p.y += 22
+	}
+	info := strings.TrimSpace(p.template.Company + "  " + time.Now().Format(time.DateTime))
+	if err := p.text(pdfMargin, p.y, pdfPageWidth-2*pdfMargin, pdfRowHeight, pdfFontSize, info); err != nil {
+		return err
+	}
+	p.y += pdfRowHeight
+
+	var titles = make([]any, len(p.columns))
+	for i, c := range p.columns {
+		titles[i] = c.Title
+	}
+	if r, g, b, ok := parseColor(p.template.HeaderColor); ok {
+		p.pdf.SetFillColor(r, g, b)
+		p.pdf.RectFromUpperLeftWithStyle(pdfMargin, p.y, pdfPageWidth-2*pdfMargin, pdfRowHeight, "F")
+		p.pdf.SetTextColor(255, 255, 255)
+		defer p.pdf.SetTextColor(0, 0, 0)
+	}
+	return p.row(titles, false)
+}
+
+func (p *pdfWriter) row(values []any, format bool) error {
+	x := pdfMargin
+	for i, w := range p.widths {
+		var v any
+		if i < len(values) {
+			v = values[i]
+		}
+		if format {
+			v = printfValue(v, p.columns[i].Format)
+		}
+		if err := p.text(x+2, p.y, w-4, pdfRowHeight, pdfFontSize, stringValue(v)); err != nil {
+			return err
+		}
+		x += w
+	}
+	p.y += pdfRowHeight
+	p.pdf.Line(pdfMargin, p.y, pdfPageWidth-pdfMargin, p.y)
+	return nil
+}
+
+// 在 (x, y) 为左上角、宽 width 高 height 的区域内左对齐、垂直居中输出，超出宽度的部分截断
+func (p *pdfWriter) text(x, y, width, height, size float64, s string) error {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 {
+			return ' '
+		}
+		return r
+	}, s)
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	if err := p.pdf.SetFont(pdfFont, "", size); err != nil {
+		return err
+	}
+	s, err := p.truncate(s, width)
+	if err != nil {
+		return err
+	}
+
+	p.pdf.SetXY(x, y)
+	return p.pdf.CellWithOption(&gopdf.Rect{W: width, H: height}, s, gopdf.CellOption{Align: gopdf.Left | gopdf.Middle})
+}
+
+// 按字体实际宽度截断
+func (p *pdfWriter) truncate(s string, width float64) (string, error) {
+	var used float64
+	for i, r := range s {
+		w, err := p.pdf.MeasureTextWidth(string(r))
+		if err != nil {
+			return "", err
+		}
+		if used+w > width {
+			return s[:i], nil
+		}
+		used += w
+	}
+	return s, nil
+}
+
+func (p *pdfWriter) WriteRow(values []any) error {
+	if config.Setting.MaxPdfRows > 0 && p.rows >= config.Setting.MaxPdfRows {
+		return ErrPdfTooLarge
+	}
+	p.rows++
+
+	if p.y+pdfRowHeight > pdfPageHeight-pdfMargin {
+		if err := p.footer(); err != nil {
+			return err
+		}
+		if err := p.newPage(); err != nil {
+			return err
+		}
+	}
+	return p.row(values, true)
+}
+
+func (p *pdfWriter) footer() error {
+	footer := strings.TrimSpace(p.template.Footer + "  " + strconv.Itoa(p.pages))
+	return p.text(pdfMargin, pdfPageHeight-pdfMargin, pdfPageWidth-2*pdfMargin, pdfRowHeight, pdfFontSize, footer)
+}
+
+func (p *pdfWriter) Close() error {
+	if p.pages == 0 {
+		if err := p.newPage(); err != nil {
+			return err
+		}
+	}
+	if err := p.footer(); err != nil {
+		return err
+	}
+	_, err := p.pdf.WriteTo(p.w)
+	return err
+}
+
+func parseColor(hex string) (r, g, b uint8, ok bool) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(hex, "#")) != 6 {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
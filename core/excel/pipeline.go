@@ -0,0 +1,568 @@
+package excel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gophab/gophrame/core/validator"
+	"github.com/gophab/gophrame/service"
+
+	"github.com/gin-gonic/gin/binding"
+	v10 "github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	IMPORT_INSERT = "insert" // 只新增，已存在的记录报错
+	IMPORT_UPSERT = "upsert" // 已存在的记录更新
+)
+
+var (
+	ErrNoHeader    = errors.New("missing header row")
+	ErrTooManyRows = errors.New("too many rows")
+)
+
+// 最多在结果中返回的错误数，完整错误见错误报告
+const maxResultErrors = 100
+
+type ImportOptions struct {
+	Format    string `json:"format,omitempty"` // xlsx | csv
+	Mode      string `json:"mode,omitempty"`   // insert | upsert，默认 insert
+	DryRun    bool   `json:"dryRun,omitempty"` // 只校验，不写入
+	Locale    string `json:"locale,omitempty"` // 匹配表头翻译的语言
+	BatchSize int    `json:"batchSize,omitempty"`
+	MaxRows   int    `json:"maxRows,omitempty"`
+}
+
+// 引用解析：批量将单元格内容（如组织名称）解析为实体 Id，未找到的不出现在结果中
+type Resolver func(values []string) (map[string]string, error)
+
+// 导入目标
+type ImportTarget[T any] interface {
+	// 按业务键批量查找已存在的记录，返回 rows 下标 -> 记录 Id
+	Exists(rows []*T) (map[int]string, error)
+	Create(row *T) error
+	Update(id string, row *T) error
+}
+
+// 可选：返回行的业务键（如登录名、手机号），同一文件中业务键相同的行只导入第一行
+type ImportKeyer[T any] interface {
+	Keys(row *T) []string
+}
+
+// 导入进度：任务重试时从上次完成的批次继续。Existed 为当前批次写入前已存在的记录（行号 -> Id），
+// 中断后重新执行该批次时，据此区分之前已由本次导入写入的记录，避免重复报错或重复计数。
+// 已完成行的业务键和校验错误在继续时重新解析得到，只保存写入时的错误
+type ImportState struct {
+	Done     int            `json:"done"`
+	Created  int            `json:"created"`
+	Updated  int            `json:"updated"`
+	Existed  map[int]string `json:"existed"`
+	Rejected map[int]string `json:"rejected,omitempty"` // 写入失败的行号 -> 错误
+}
+
+type CellError struct {
+	Row     int    `json:"row"`              // 源文件行号，表头为第 1 行
+	Column  string `json:"column,omitempty"` // 表头，为空表示整行
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	Total     int          `json:"total"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Failed    int          `json:"failed"`
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Errors    []*CellError `json:"errors,omitempty"`
+	ErrorFile string       `json:"errorFile,omitempty"`
+}
+
+type importRow[T any] struct {
+	line   int
+	cells  []string
+	value  *T
+	errors map[int]string // 列序号 -> 错误，-1 为整行
+}
+
+func (r *importRow[T]) fail(pos int, message string) {
+	if _, b := r.errors[pos]; !b {
+		r.errors[pos] = message
+	}
+}
+
+// 声明式导入：按 T 的 excel 标签映射列，binding 标签校验，批量解析引用后写入目标，
+// 失败的行连同错误收集到错误报告
+type Pipeline[T any] struct {
+	Options    ImportOptions
+	Target     ImportTarget[T]
+	References map[string]Resolver
+	Progress   func(done, total int)
+
+	columns []*column
+	header  []string
+	failed  []*importRow[T]
+	keys    map[string]int // 业务键 -> 首次出现的行号
+	state   *ImportState
+	save    func(state *ImportState) error
+}
+
+func NewPipeline[T any](target ImportTarget[T], options ImportOptions) *Pipeline[T] {
+	if options.Mode == "" {
+		options.Mode = IMPORT_INSERT
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 200
+	}
+	return &Pipeline[T]{
+		Options:    options,
+		Target:     target,
+		References: make(map[string]Resolver),
+		columns:    parseColumns(reflect.TypeOf((*T)(nil)).Elem()),
+		keys:       make(map[string]int),
+		state:      &ImportState{},
+	}
+}
+
+// 未设置解析器的引用按实体 Id 校验存在性
+func (p *Pipeline[T]) WithReference(ref string, resolver Resolver) *Pipeline[T] {
+	p.References[ref] = resolver
+	return p
+}
+
+func (p *Pipeline[T]) WithProgress(progress func(done, total int)) *Pipeline[T] {
+	p.Progress = progress
+	return p
+}
+
+// 从 state 继续导入，并在每个批次写入前后通过 save 保存进度
+func (p *Pipeline[T]) WithCheckpoint(state *ImportState, save func(state *ImportState) error) *Pipeline[T] {
+	if state != nil {
+		p.state = state
+	}
+	p.save = save
+	return p
+}
+
+func (p *Pipeline[T]) Run(ctx context.Context, reader io.Reader) (*ImportResult, error) {
+	rows, err := p.read(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	state := p.state
+	if state.Rejected == nil {
+		state.Rejected = make(map[int]string)
+	}
+	if err := p.replay(rows[:min(state.Done, len(rows))]); err != nil {
+		return nil, err
+	}
+
+	for start := min(state.Done, len(rows)); start < len(rows); start += p.Options.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, context.Cause(ctx)
+		}
+
+		batch := rows[start:min(start+p.Options.BatchSize, len(rows))]
+		if err := p.process(batch); err != nil {
+			return nil, err
+		}
+
+		state.Done, state.Existed = start+len(batch), nil
+		if err := p.checkpoint(); err != nil {
+			return nil, err
+		}
+		if p.Progress != nil {
+			p.Progress(state.Done, len(rows))
+		}
+	}
+
+	var result = &ImportResult{Total: len(rows), Created: state.Created, Updated: state.Updated, DryRun: p.Options.DryRun}
+	result.Failed = len(p.failed)
+	for _, row := range p.failed {
+		for _, pos := range sortedKeys(row.errors) {
+			if len(result.Errors) >= maxResultErrors {
+				return result, nil
+			}
+			result.Errors = append(result.Errors, &CellError{Row: row.line, Column: p.columnName(pos), Message: row.errors[pos]})
+		}
+	}
+	return result, nil
+}
+
+func (p *Pipeline[T]) read(reader io.Reader) ([]*importRow[T], error) {
+	rr, err := NewRowReader(reader, p.Options.Format)
+	if err != nil {
+		return nil, err
+	}
+	defer rr.Close()
+
+	var rows = make([]*importRow[T], 0)
+	for line := 1; ; line++ {
+		cells, err := rr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if p.header == nil {
+			if isBlank(cells) {
+				return nil, ErrNoHeader
+			}
+			p.header = cells
+			bindHeader(p.columns, cells, p.Options.Locale)
+			continue
+		}
+
+		if isBlank(cells) {
+			continue
+		}
+		if p.Options.MaxRows > 0 && len(rows) >= p.Options.MaxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, &importRow[T]{line: line, cells: cells, errors: make(map[int]string)})
+	}
+
+	if p.header == nil {
+		return nil, ErrNoHeader
+	}
+	return rows, nil
+}
+
+func (p *Pipeline[T]) checkpoint() error {
+	if p.save == nil || p.Options.DryRun {
+		return nil
+	}
+	return p.save(p.state)
+}
+
+// 继续导入前按批次重新解析已完成的行，恢复业务键和失败的行，不写入
+func (p *Pipeline[T]) replay(rows []*importRow[T]) error {
+	for start := 0; start < len(rows); start += p.Options.BatchSize {
+		valid, err := p.prepare(rows[start:min(start+p.Options.BatchSize, len(rows))])
+		if err != nil {
+			return err
+		}
+		for _, row := range valid {
+			if message, b := p.state.Rejected[row.line]; b {
+				row.fail(-1, message)
+				p.failed = append(p.failed, row)
+			}
+		}
+	}
+	return nil
+}
+
+// 解析、校验并去重，返回通过校验的行，未通过的加入失败列表
+func (p *Pipeline[T]) prepare(rows []*importRow[T]) ([]*importRow[T], error) {
+	for _, row := range rows {
+		p.decode(row)
+	}
+
+	if err := p.resolve(rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		p.validate(row)
+	}
+	p.deduplicate(rows)
+
+	var valid = make([]*importRow[T], 0, len(rows))
+	for _, row := range rows {
+		if len(row.errors) == 0 {
+			valid = append(valid, row)
+		} else {
+			p.failed = append(p.failed, row)
+		}
+	}
+	return valid, nil
+}
+
+func (p *Pipeline[T]) process(rows []*importRow[T]) error {
+	valid, err := p.prepare(rows)
+	if err != nil || len(valid) == 0 {
+		return err
+	}
+
+	var values = make([]*T, len(valid))
+	for i, row := range valid {
+		values[i] = row.value
+	}
+	exists, err := p.Target.Exists(values)
+	if err != nil {
+		return err
+	}
+
+	// 写入前记录已存在的行；重新执行中断的批次时沿用上次记录的结果
+	existed := p.state.Existed
+	if existed == nil {
+		existed = make(map[int]string, len(exists))
+		for i, id := range exists {
+			existed[valid[i].line] = id
+		}
+		p.state.Existed = existed
+		if err := p.checkpoint(); err != nil {
+			return err
+		}
+	}
+
+	for i, row := range valid {
+		id, found := exists[i]
+		_, before := existed[row.line]
+		switch {
+		case before && p.Options.Mode != IMPORT_UPSERT:
+			row.fail(-1, "记录已存在")
+		case p.Options.DryRun:
+		case found:
+			// 包括中断前已由本次导入新增的记录，重新写入一次
+			err = p.Target.Update(id, row.value)
+		default:
+			err = p.Target.Create(row.value)
+		}
+		if err != nil {
+			row.fail(-1, err.Error())
+			err = nil
+		}
+
+		if len(row.errors) > 0 {
+			p.state.Rejected[row.line] = row.errors[-1]
+			p.failed = append(p.failed, row)
+		} else if before {
+			p.state.Updated++
+		} else {
+			p.state.Created++
+		}
+	}
+	return nil
+}
+
+// 业务键与之前的行重复时报错；按行号比较，重新执行同一批次不会与自身冲突
+func (p *Pipeline[T]) deduplicate(rows []*importRow[T]) {
+	keyer, b := p.Target.(ImportKeyer[T])
+	if !b {
+		return
+	}
+
+	for _, row := range rows {
+		if len(row.errors) > 0 {
+			continue
+		}
+
+		keys := keyer.Keys(row.value)
+		for _, key := range keys {
+			if line, b := p.keys[key]; b && line != row.line {
+				row.fail(-1, fmt.Sprintf("与第 %d 行重复", line))
+				break
+			}
+		}
+		if len(row.errors) == 0 {
+			for _, key := range keys {
+				p.keys[key] = row.line
+			}
+		}
+	}
+}
+
+func (p *Pipeline[T]) decode(row *importRow[T]) {
+	row.value = new(T)
+	v := reflect.ValueOf(row.value).Elem()
+
+	for _, c := range p.columns {
+		if c.pos < 0 || c.pos >= len(row.cells) || c.ref != "" {
+			continue
+		}
+		if err := setValue(v.FieldByIndex(c.field), row.cells[c.pos]); err != nil {
+			row.fail(c.pos, err.Error())
+		}
+	}
+}
+
+func (p *Pipeline[T]) validate(row *importRow[T]) {
+	if err := binding.Validator.ValidateStruct(row.value); err != nil {
+		var errs v10.ValidationErrors
+		if !errors.As(err, &errs) {
+			row.fail(-1, err.Error())
+			return
+		}
+		for _, fe := range errs {
+			row.fail(p.fieldPos(fe.StructField()), fe.Translate(validator.Trans))
+		}
+	}
+}
+
+// 按引用类型批量解析，同一批次每类引用只查询一次
+func (p *Pipeline[T]) resolve(rows []*importRow[T]) error {
+	for _, c := range p.columns {
+		if c.ref == "" || c.pos < 0 {
+			continue
+		}
+
+		var (
+			values = make([]string, 0, len(rows))
+			seen   = make(map[string]bool, len(rows))
+		)
+		for _, row := range rows {
+			if text := p.cell(row, c.pos); text != "" && !seen[text] {
+				seen[text] = true
+				values = append(values, text)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		ids, err := p.resolver(c.ref)(values)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			text := p.cell(row, c.pos)
+			if text == "" {
+				continue
+			}
+			if id, b := ids[text]; b {
+				if err := setValue(reflect.ValueOf(row.value).Elem().FieldByIndex(c.field), id); err != nil {
+					row.fail(c.pos, err.Error())
+				}
+			} else {
+				row.fail(c.pos, fmt.Sprintf("未找到: %s", text))
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline[T]) resolver(ref string) Resolver {
+	if resolver, b := p.References[ref]; b {
+		return resolver
+	}
+	return func(values []string) (map[string]string, error) {
+		entities, err := service.GetEntities(ref, values)
+		if err != nil {
+			return nil, err
+		}
+		var result = make(map[string]string, len(entities))
+		for id := range entities {
+			result[id] = id
+		}
+		return result, nil
+	}
+}
+
+func (p *Pipeline[T]) cell(row *importRow[T], pos int) string {
+	if pos < len(row.cells) {
+		return strings.TrimSpace(row.cells[pos])
+	}
+	return ""
+}
+
+func (p *Pipeline[T]) fieldPos(name string) int {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for _, c := range p.columns {
+		if t.FieldByIndex(c.field).Name == name {
+			return c.pos
+		}
+	}
+	return -1
+}
+
+func (p *Pipeline[T]) columnName(pos int) string {
+	if pos >= 0 && pos < len(p.header) {
+		return p.header[pos]
+	}
+	return ""
+}
+
+// 是否有失败的行
+func (p *Pipeline[T]) HasErrors() bool {
+	return len(p.failed) > 0
+}
+
+// 错误报告：失败的行按原样输出并追加错误列，出错的单元格标红并加批注
+func (p *Pipeline[T]) WriteErrorReport(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Sheet1"
+	style, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	width := len(p.header)
+	for _, row := range p.failed {
+		width = max(width, len(row.cells))
+	}
+
+	header := make([]any, width+1)
+	for i, h := range p.header {
+		header[i] = h
+	}
+	header[width] = "错误信息"
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+
+	for i, row := range p.failed {
+		line := i + 2
+		values := make([]any, width+1)
+		for j, cell := range row.cells {
+			values[j] = cell
+		}
+
+		var messages = make([]string, 0, len(row.errors))
+		for _, pos := range sortedKeys(row.errors) {
+			message := row.errors[pos]
+			if name := p.columnName(pos); name != "" {
+				message = name + ": " + message
+			}
+			messages = append(messages, message)
+
+			if pos < 0 {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(pos+1, line)
+			if err := f.SetCellStyle(sheet, cell, cell, style); err != nil {
+				return err
+			}
+			if err := f.AddComment(sheet, excelize.Comment{Cell: cell, Author: "import", Text: row.errors[pos]}); err != nil {
+				return err
+			}
+		}
+		values[width] = strings.Join(messages, "; ")
+
+		cell, _ := excelize.CoordinatesToCellName(1, line)
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
+
+func isBlank(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[int]string) []int {
+	var keys = make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,195 @@
+package excel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type accountRow struct {
+	Login string `excel:"login" binding:"required"`
+	Name  string `excel:"name"`
+}
+
+// 内存中的导入目标，按登录名匹配
+type accountTarget struct {
+	records map[string]string // login -> name
+	creates int
+	updates int
+	crashAt int // 第 n 次新增后中断，0 为不中断
+}
+
+func (t *accountTarget) Keys(row *accountRow) []string {
+	return []string{row.Login}
+}
+
+func (t *accountTarget) Exists(rows []*accountRow) (map[int]string, error) {
+	var result = make(map[int]string)
+	for i, row := range rows {
+		if _, b := t.records[row.Login]; b {
+			result[i] = row.Login
+		}
+	}
+	return result, nil
+}
+
+func (t *accountTarget) Create(row *accountRow) error {
+	t.records[row.Login] = row.Name
+	if t.creates++; t.creates == t.crashAt {
+		panic("crash")
+	}
+	return nil
+}
+
+func (t *accountTarget) Update(id string, row *accountRow) error {
+	t.records[id] = row.Name
+	t.updates++
+	return nil
+}
+
+func csvOf(lines ...string) *strings.Reader {
+	return strings.NewReader("login,name\n" + strings.Join(lines, "\n") + "\n")
+}
+
+func TestImportUpsert(t *testing.T) {
+	target := &accountTarget{records: map[string]string{"alice": "old"}}
+	pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV, Mode: IMPORT_UPSERT})
+
+	result, err := pipeline.Run(context.Background(), csvOf("alice,Alice", "bob,Bob", "carol,Carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 2 || result.Updated != 1 || result.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if target.records["alice"] != "Alice" || target.records["bob"] != "Bob" {
+		t.Fatalf("unexpected records: %v", target.records)
+	}
+}
+
+func TestImportInsertRejectsExisting(t *testing.T) {
+	target := &accountTarget{records: map[string]string{"alice": "old"}}
+	pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV})
+
+	result, err := pipeline.Run(context.Background(), csvOf("alice,Alice", "bob,Bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 1 || result.Failed != 1 || result.Errors[0].Row != 2 || result.Errors[0].Message != "记录已存在" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if target.records["alice"] != "old" {
+		t.Fatal("existing record overwritten in insert mode")
+	}
+}
+
+func TestImportDuplicateKeysInFile(t *testing.T) {
+	target := &accountTarget{records: map[string]string{}}
+	// 重复行跨批次
+	pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV, Mode: IMPORT_UPSERT, BatchSize: 1})
+
+	result, err := pipeline.Run(context.Background(), csvOf("bob,Bob", "carol,Carol", "bob,Robert"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 2 || result.Updated != 0 || result.Failed != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Errors[0].Row != 4 || result.Errors[0].Message != "与第 2 行重复" {
+		t.Fatalf("unexpected error: %+v", result.Errors[0])
+	}
+	if target.records["bob"] != "Bob" {
+		t.Fatalf("duplicate row applied: %v", target.records)
+	}
+}
+
+// 批次中途中断后重试：已写入的行不重复报错，计数与一次完成时相同
+func TestImportResumeFromCheckpoint(t *testing.T) {
+	target := &accountTarget{records: map[string]string{"alice": "old"}, crashAt: 3}
+	input := []string{"alice,Alice", "bob,Bob", "carol,Carol", "", "dave,Dave", "erin,Erin", "bad,", ",Nobody", "frank,Frank"}
+
+	var saved string
+	save := func(state *ImportState) error {
+		data, err := json.Marshal(state)
+		saved = string(data)
+		return err
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected crash")
+			}
+		}()
+		pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV, BatchSize: 3}).WithCheckpoint(nil, save)
+		_, _ = pipeline.Run(context.Background(), csvOf(input...))
+	}()
+
+	var state ImportState
+	if err := json.Unmarshal([]byte(saved), &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Done != 3 || state.Existed == nil || state.Rejected[2] != "记录已存在" {
+		t.Fatalf("unexpected checkpoint: %s", saved)
+	}
+	// 不保存单元格和业务键，继续时重新解析
+	if strings.Contains(saved, "Alice") || strings.Contains(saved, "carol") {
+		t.Fatalf("checkpoint contains row data: %s", saved)
+	}
+
+	target.crashAt = 0
+	pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV, BatchSize: 3}).WithCheckpoint(&state, save)
+	result, err := pipeline.Run(context.Background(), csvOf(input...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// alice 已存在；空行跳过；",Nobody" 缺少登录名
+	if result.Total != 8 || result.Created != 6 || result.Updated != 0 || result.Failed != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for _, login := range []string{"bob", "carol", "dave", "erin", "bad", "frank"} {
+		if _, b := target.records[login]; !b {
+			t.Fatalf("record %s not imported: %v", login, target.records)
+		}
+	}
+	if target.records["alice"] != "old" {
+		t.Fatal("existing record overwritten in insert mode")
+	}
+	if !pipeline.HasErrors() || result.Errors[0].Row != 2 || result.Errors[0].Message != "记录已存在" {
+		t.Fatalf("errors from the first attempt lost: %+v", result.Errors)
+	}
+}
+
+// 继续导入时重建已完成行的业务键，与之前批次重复的行仍然报错
+func TestImportResumeKeepsKeys(t *testing.T) {
+	target := &accountTarget{records: map[string]string{}}
+	state := ImportState{Done: 2, Created: 2}
+	target.records["bob"], target.records["carol"] = "Bob", "Carol"
+
+	pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV, Mode: IMPORT_UPSERT, BatchSize: 2}).
+		WithCheckpoint(&state, func(state *ImportState) error { return nil })
+	result, err := pipeline.Run(context.Background(), csvOf("bob,Bob", "carol,Carol", "bob,Robert", "dave,Dave"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 3 || result.Updated != 0 || result.Failed != 1 || result.Errors[0].Message != "与第 2 行重复" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if target.records["bob"] != "Bob" {
+		t.Fatalf("duplicate row applied: %v", target.records)
+	}
+}
+
+func TestImportDryRunSkipsCheckpoint(t *testing.T) {
+	target := &accountTarget{records: map[string]string{}}
+	pipeline := NewPipeline[accountRow](target, ImportOptions{Format: FORMAT_CSV, DryRun: true}).
+		WithCheckpoint(nil, func(state *ImportState) error { return errors.New("should not save") })
+
+	result, err := pipeline.Run(context.Background(), csvOf("bob,Bob"))
+	if err != nil || result.Created != 1 || len(target.records) != 0 {
+		t.Fatalf("dry run = %+v, %v", result, err)
+	}
+}
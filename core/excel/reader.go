@@ -0,0 +1,105 @@
+package excel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FORMAT_XLSX = "xlsx"
+	FORMAT_CSV  = "csv"
+)
+
+var ErrNoSheet = errors.New("no sheet in workbook")
+
+// 逐行读取源文件（xlsx 取第一个工作表），返回 io.EOF 表示结束
+type RowReader interface {
+	Next() ([]string, error)
+	Close() error
+}
+
+// 按文件名后缀识别格式，无法识别时按 xlsx 处理
+func FormatOf(fileName string) string {
+	if strings.HasSuffix(strings.ToLower(fileName), ".csv") {
+		return FORMAT_CSV
+	}
+	return FORMAT_XLSX
+}
+
+func NewRowReader(reader io.Reader, format string) (RowReader, error) {
+	if format == FORMAT_CSV {
+		return newCsvReader(reader), nil
+	}
+	return newXlsxReader(reader)
+}
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func newXlsxReader(reader io.Reader) (*xlsxReader, error) {
+	file, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		file.Close()
+		return nil, ErrNoSheet
+	}
+
+	rows, err := file.Rows(sheets[0])
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxReader{file: file, rows: rows}, nil
+}
+
+func (r *xlsxReader) Next() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return r.rows.Columns()
+}
+
+func (r *xlsxReader) Close() error {
+	_ = r.rows.Close()
+	return r.file.Close()
+}
+
+type csvReader struct {
+	reader *csv.Reader
+}
+
+func newCsvReader(reader io.Reader) *csvReader {
+	buffered := bufio.NewReader(reader)
+	// Excel 另存的 UTF-8 CSV 带 BOM
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = buffered.Discard(3)
+	}
+
+	r := csv.NewReader(buffered)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return &csvReader{reader: r}
+}
+
+func (r *csvReader) Next() ([]string, error) {
+	return r.reader.Read()
+}
+
+func (r *csvReader) Close() error {
+	return nil
+}
@@ -0,0 +1,197 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/gophab/gophrame/core/excel/config"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/service"
+
+	"gorm.io/gorm"
+)
+
+// 游标分页数据源：读取 cursor 之后的一批记录并返回下一个游标，首次调用 cursor 为 nil，
+// 返回空批次表示结束
+type Source[T any] func(cursor any, limit int) ([]*T, any, error)
+
+// 按 columns 排序的游标分页，key 返回记录在各列上的游标值，末列须唯一；desc 为 true 时倒序
+func GormSource[T any](tx func() *gorm.DB, columns []string, desc bool, key func(*T) []any) Source[T] {
+	op, direction := " > ?", " ASC"
+	if desc {
+		op, direction = " < ?", " DESC"
+	}
+
+	return func(cursor any, limit int) ([]*T, any, error) {
+		q := tx()
+		if values, b := cursor.([]any); b {
+			query, args := keyset(columns, values, op)
+			q = q.Where(query, args...)
+		}
+		for _, column := range columns {
+			q = q.Order(column + direction)
+		}
+
+		var results = make([]*T, 0, limit)
+		if err := q.Limit(limit).Find(&results).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(results) == 0 {
+			return results, nil, nil
+		}
+		return results, key(results[len(results)-1]), nil
+	}
+}
+
+// (a, b) > (x, y) 展开为 (a > x OR (a = x AND b > y))，不依赖数据库的行值比较
+func keyset(columns []string, values []any, op string) (string, []any) {
+	var (
+		terms []string
+		args  []any
+	)
+	for i := range columns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, columns[j]+" = ?")
+			args = append(args, values[j])
+		}
+		parts = append(parts, columns[i]+op)
+		args = append(args, values[i])
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+type ExportOptions struct {
+	Format string `json:"format,omitempty"` // xlsx | csv | pdf
+	Title  string `json:"title,omitempty"`
+	Locale string `json:"locale,omitempty"`
+}
+
+// 由 T 的 excel 标签生成导出列
+func ColumnsOf[T any](locale string) []ExcelColumn {
+	var result = make([]ExcelColumn, 0)
+	for _, c := range parseColumns(reflect.TypeOf((*T)(nil)).Elem()) {
+		result = append(result, ExcelColumn{
+			Title:  c.title(locale),
+			Path:   c.key,
+			Format: c.format,
+			Width:  c.width,
+			field:  c.field,
+		})
+	}
+	return result
+}
+
+// 流式导出，columns 为空时按 T 的 excel 标签生成；progress 在每批写出后回调已写出的行数
+func Export[T any](ctx context.Context, w io.Writer, source Source[T], columns []ExcelColumn, options ExportOptions, progress func(rows int)) (int, error) {
+	if len(columns) == 0 {
+		columns = ColumnsOf[T](options.Locale)
+	}
+
+	writer, err := NewTableWriter(w, options.Format, NewTemplate(options.Title))
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	var (
+		rows   int
+		cursor any
+		values = make([]any, len(columns))
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return rows, context.Cause(ctx)
+		}
+
+		batch, next, err := source(cursor, max(config.Setting.BatchSize, 1))
+		if err != nil {
+			return rows, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, record := range batch {
+			rows++
+			rowValues(values, columns, record, rows)
+			if err := writer.WriteRow(values); err != nil {
+				return rows, err
+			}
+		}
+		if progress != nil {
+			progress(rows)
+		}
+		cursor = next
+	}
+
+	return rows, writer.Close()
+}
+
+func rowValues[T any](values []any, columns []ExcelColumn, record *T, index int) {
+	var (
+		v   = reflect.ValueOf(record).Elem()
+		row map[string]any
+	)
+	for i, c := range columns {
+		var value any
+		switch {
+		case c.Path == "_index_":
+			value = index
+		case c.field != nil:
+			if f, err := v.FieldByIndexErr(c.field); err == nil {
+				if f.Kind() != reflect.Pointer {
+					value = f.Interface()
+				} else if !f.IsNil() {
+					value = f.Elem().Interface()
+				}
+			}
+		default:
+			// 按 json 路径取值
+			if row == nil {
+				row = util.Struct2Map(record)
+			}
+			value, _ = util.GetRecordField(row, c.Path)
+		}
+		values[i] = formatValue(value, c.Format)
+	}
+}
+
+// 在异步任务中导出到临时文件，上传后作为任务结果，total 大于 0 时按比例更新进度
+func ExportTask[T any](ctx service.TaskContext, source Source[T], columns []ExcelColumn, options ExportOptions, total int64) (int, error) {
+	if options.Format == "" {
+		options.Format = FORMAT_XLSX
+	}
+
+	file, err := os.CreateTemp("", "export-*."+options.Format)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+
+	rows, err := Export(ctx, file, source, columns, options, func(rows int) {
+		var progress float32
+		if total > 0 {
+			progress = float32(min(int64(rows)*100/total, 99))
+		}
+		_ = ctx.Progress(progress, fmt.Sprintf("%d", rows))
+	})
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return rows, err
+	}
+
+	if _, err := ctx.SetResultFile(file.Name(), "export"); err != nil {
+		return rows, err
+	}
+	return rows, nil
+}
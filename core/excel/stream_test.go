@@ -0,0 +1,67 @@
+package excel
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type logRecord struct {
+	Id           int64     `gorm:"column:id;primaryKey"`
+	OperatedTime time.Time `gorm:"column:operated_time"`
+}
+
+// 按时间倒序分页，同一时间的多条记录跨批次时不重复、不遗漏
+func TestGormSourceKeyset(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "stream.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&logRecord{}); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Id 与时间顺序不一致
+	records := []*logRecord{
+		{Id: 5, OperatedTime: base},
+		{Id: 1, OperatedTime: base.Add(time.Minute)},
+		{Id: 4, OperatedTime: base.Add(time.Minute)},
+		{Id: 2, OperatedTime: base.Add(time.Minute)},
+		{Id: 3, OperatedTime: base.Add(2 * time.Minute)},
+	}
+	if err := db.Create(records).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	source := GormSource(func() *gorm.DB {
+		return db.Model(&logRecord{})
+	}, []string{"operated_time", "id"}, true, func(r *logRecord) []any { return []any{r.OperatedTime, r.Id} })
+
+	var (
+		ids    []int64
+		cursor any
+	)
+	for {
+		batch, next, err := source(cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, r := range batch {
+			ids = append(ids, r.Id)
+		}
+		cursor = next
+	}
+
+	if got := fmt.Sprint(ids); got != "[3 4 2 1 5]" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
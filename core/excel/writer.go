@@ -0,0 +1,308 @@
+package excel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/excel/config"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const FORMAT_PDF = "pdf"
+
+// 导出模板：标题和企业信息，未设置的项取 export 配置
+type Template struct {
+	Title       string
+	Company     string
+	HeaderColor string
+	Footer      string
+}
+
+func NewTemplate(title string) *Template {
+	return &Template{
+		Title:       title,
+		Company:     config.Setting.Company,
+		HeaderColor: config.Setting.HeaderColor,
+		Footer:      config.Setting.Footer,
+	}
+}
+
+// 表格写入器：逐行写出，不在内存中保留已写出的行（PDF 除外）
+type TableWriter interface {
+	WriteHeader(columns []ExcelColumn) error
+	WriteRow(values []any) error
+	// 写出剩余内容，不关闭底层 io.Writer
+	Close() error
+}
+
+func NewTableWriter(w io.Writer, format string, template *Template) (TableWriter, error) {
+	if template == nil {
+		template = NewTemplate("")
+	}
+	switch format {
+	case FORMAT_XLSX, "":
+		return newXlsxWriter(w, template)
+	case FORMAT_CSV:
+		return newCsvWriter(w), nil
+	case FORMAT_PDF:
+		return newPdfWriter(w, template)
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+// 按列格式转换时间，默认输出到秒；其他类型由写入器按格式输出
+func formatValue(v any, format string) any {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		if value.IsZero() {
+			return nil
+		}
+		if format == "" {
+			format = time.DateTime
+		}
+		return value.Format(format)
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return formatValue(*value, format)
+	}
+	return v
+}
+
+// CSV、PDF 按 fmt 格式输出文本；时间格式等不含占位符的格式对其他类型无意义
+func printfValue(v any, format string) any {
+	if v == nil || !strings.Contains(format, "%") {
+		return v
+	}
+	if _, b := v.(string); b && numberFormat(format) != "" {
+		// 数值格式不适用于文本
+		return v
+	}
+	return fmt.Sprintf(format, v)
+}
+
+var printfNumber = regexp.MustCompile(`^([^%]*)%(?:\.(\d+))?([df])([^%]*)$`)
+
+// 只含一个 %d 或 %.nf 占位符的 fmt 格式转换为 Excel 数字格式，如 %.2f -> 0.00、%d 元 -> 0" 元"，
+// 不支持的格式返回空
+func numberFormat(format string) string {
+	m := printfNumber.FindStringSubmatch(strings.ReplaceAll(format, "%%", "\x00"))
+	if m == nil {
+		return ""
+	}
+
+	number := "0"
+	if m[3] == "f" {
+		digits := 6
+		if m[2] != "" {
+			digits, _ = strconv.Atoi(m[2])
+		}
+		if digits > 0 {
+			number += "." + strings.Repeat("0", min(digits, 30))
+		}
+	}
+
+	quote := func(text string) string {
+		if text = strings.ReplaceAll(text, "\x00", "%"); text == "" {
+			return ""
+		}
+		return `"` + strings.ReplaceAll(text, `"`, `\"`) + `"`
+	}
+	return quote(m[1]) + number + quote(m[4])
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+func stringValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+type xlsxWriter struct {
+	w        io.Writer
+	file     *excelize.File
+	stream   *excelize.StreamWriter
+	template *Template
+	row      int
+
+	columns []ExcelColumn
+	styles  []int // 列的数字格式样式，0 为无
+}
+
+func newXlsxWriter(w io.Writer, template *Template) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{w: w, file: file, stream: stream, template: template, row: 1}, nil
+}
+
+func (x *xlsxWriter) WriteHeader(columns []ExcelColumn) error {
+	// 数值按列格式设置数字格式，保持数值类型
+	x.columns, x.styles = columns, make([]int, len(columns))
+	for i, c := range columns {
+		if format := numberFormat(c.Format); format != "" {
+			style, err := x.file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+			if err != nil {
+				return err
+			}
+			x.styles[i] = style
+		}
+	}
+
+	// 列宽需在写入行之前设置
+	for i, c := range columns {
+		if c.Width > 0 {
+			if err := x.stream.SetColWidth(i+1, i+1, c.Width); err != nil {
+				return err
+			}
+		}
+	}
+
+	if x.template.Title != "" || x.template.Company != "" {
+		titleStyle, err := x.file.NewStyle(&excelize.Style{
+			Font:      &excelize.Font{Bold: true, Size: 14},
+			Alignment: &excelize.Alignment{Horizontal: "center"},
+		})
+		if err != nil {
+			return err
+		}
+
+		last, _ := excelize.CoordinatesToCellName(max(len(columns), 1), 1)
+		if err := x.stream.SetRow("A1", []any{excelize.Cell{StyleID: titleStyle, Value: x.template.Title}}); err != nil {
+			return err
+		}
+		if err := x.stream.MergeCell("A1", last); err != nil {
+			return err
+		}
+		info := x.template.Company + "  " + time.Now().Format(time.DateTime)
+		if err := x.stream.SetRow("A2", []any{info}); err != nil {
+			return err
+		}
+		x.row = 3
+	}
+
+	style := &excelize.Style{Font: &excelize.Font{Bold: true}}
+	if x.template.HeaderColor != "" {
+		style.Font.Color = "FFFFFF"
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{x.template.HeaderColor}}
+	}
+	headerStyle, err := x.file.NewStyle(style)
+	if err != nil {
+		return err
+	}
+
+	var header = make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: c.Title}
+	}
+	return x.writeRow(header)
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	var row = make([]any, len(values))
+	for i, v := range values {
+		switch {
+		case i >= len(x.columns):
+			row[i] = v
+		case x.styles[i] != 0 && isNumber(v):
+			row[i] = excelize.Cell{StyleID: x.styles[i], Value: v}
+		default:
+			row[i] = printfValue(v, x.columns[i].Format)
+		}
+	}
+	return x.writeRow(row)
+}
+
+func (x *xlsxWriter) writeRow(values []any) error {
+	cell, _ := excelize.CoordinatesToCellName(1, x.row)
+	if err := x.stream.SetRow(cell, values); err != nil {
+		return err
+	}
+	x.row++
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.w)
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	rows    int
+	columns []ExcelColumn
+}
+
+func newCsvWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteHeader(columns []ExcelColumn) error {
+	c.columns = columns
+	var header = make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Title
+	}
+	// 带 BOM，Excel 才能正确识别 UTF-8
+	if len(header) > 0 {
+		header[0] = "\uFEFF" + header[0]
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	var record = make([]string, len(values))
+	for i, v := range values {
+		if i < len(c.columns) {
+			v = printfValue(v, c.columns[i].Format)
+		}
+		record[i] = escapeFormula(stringValue(v))
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	if c.rows++; c.rows%1000 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+// 以 = + - @ 等开头的文本会被电子表格当作公式执行，前置单引号按文本显示；数值保持原样
+func escapeFormula(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return "'" + s
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,168 @@
+package excel
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/core/excel/config"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCsvEscapesFormula(t *testing.T) {
+	var buffer bytes.Buffer
+	w, err := NewTableWriter(&buffer, FORMAT_CSV, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader([]ExcelColumn{{Title: "a"}, {Title: "b"}, {Title: "c"}, {Title: "d"}, {Title: "e"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]any{`=HYPERLINK("http://x")`, "@SUM(A1)", "-1.5", -2, "+86 138"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\uFEFFa,b,c,d,e\n\"'=HYPERLINK(\"\"http://x\"\")\",'@SUM(A1),-1.5,-2,'+86 138\n"
+	if buffer.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buffer.String())
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	cases := []struct {
+		value  any
+		format string
+		want   any
+	}{
+		{at, "", "2026-01-02 03:04:05"},
+		{&at, time.DateOnly, "2026-01-02"},
+		{(*time.Time)(nil), time.DateOnly, nil},
+		{"text", time.DateOnly, "text"},
+		{12, time.DateOnly, 12},
+		{1.5, "%.2f", 1.5},
+	}
+	for _, c := range cases {
+		if got := formatValue(c.value, c.format); got != c.want {
+			t.Fatalf("formatValue(%v, %q) = %v, want %v", c.value, c.format, got, c.want)
+		}
+	}
+}
+
+func TestNumberFormat(t *testing.T) {
+	for format, want := range map[string]string{
+		"%.2f":       "0.00",
+		"%d":         "0",
+		"%.0f":       "0",
+		"%f":         "0.000000",
+		"%d 元":       `0" 元"`,
+		"¥%.2f":      `"¥"0.00`,
+		"%.1f%%":     `0.0"%"`,
+		"%s":         "",
+		"%d/%d":      "",
+		"%5.2f":      "",
+		"2006-01-02": "",
+	} {
+		if got := numberFormat(format); got != want {
+			t.Errorf("numberFormat(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestCsvAppliesPrintfFormat(t *testing.T) {
+	var buffer bytes.Buffer
+	w, _ := NewTableWriter(&buffer, FORMAT_CSV, nil)
+	_ = w.WriteHeader([]ExcelColumn{{Title: "a", Format: "%.2f"}, {Title: "b", Format: "%s 元"}, {Title: "c"}})
+	_ = w.WriteRow([]any{1.5, "3", 2.25})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\uFEFFa,b,c\n1.50,3 元,2.25\n"; buffer.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buffer.String())
+	}
+}
+
+// XLSX 中带格式的数值保持数值类型，由数字格式控制显示
+func TestXlsxKeepsNumbers(t *testing.T) {
+	var buffer bytes.Buffer
+	w, _ := NewTableWriter(&buffer, FORMAT_XLSX, &Template{})
+	if err := w.WriteHeader([]ExcelColumn{{Title: "amount", Format: "%.2f"}, {Title: "name", Format: "%s!"}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.WriteRow([]any{1.5, "x"})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := excelize.OpenReader(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if raw, _ := file.GetCellValue("Sheet1", "A2", excelize.Options{RawCellValue: true}); raw != "1.5" {
+		t.Fatalf("raw value = %q", raw)
+	}
+	if display, _ := file.GetCellValue("Sheet1", "A2"); display != "1.50" {
+		t.Fatalf("display value = %q", display)
+	}
+	if kind, _ := file.GetCellType("Sheet1", "A2"); kind != excelize.CellTypeUnset && kind != excelize.CellTypeNumber {
+		t.Fatalf("cell type = %v", kind)
+	}
+	if text, _ := file.GetCellValue("Sheet1", "B2"); text != "x!" {
+		t.Fatalf("text value = %q", text)
+	}
+}
+
+func TestPdfEmbedsFont(t *testing.T) {
+	var buffer bytes.Buffer
+	w, err := NewTableWriter(&buffer, FORMAT_PDF, &Template{Title: "Users", Footer: "page"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader([]ExcelColumn{{Title: "name"}, {Title: "amount", Format: "%.2f"}}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := w.WriteRow([]any{"alice 张三", 1.5}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.String()
+	if !strings.HasPrefix(data, "%PDF-") || !strings.Contains(data, "/FontFile2") || strings.Contains(data, "STSong") {
+		t.Fatalf("font not embedded: %.200q", data)
+	}
+	// 每页约 29 行
+	if !strings.Contains(data, "/Count 4") {
+		t.Fatal("unexpected page count")
+	}
+}
+
+func TestPdfRowLimit(t *testing.T) {
+	defer func(rows int) { config.Setting.MaxPdfRows = rows }(config.Setting.MaxPdfRows)
+	config.Setting.MaxPdfRows = 2
+
+	w, err := NewTableWriter(io.Discard, FORMAT_PDF, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.WriteHeader([]ExcelColumn{{Title: "a"}})
+	for i := 0; i < 2; i++ {
+		if err := w.WriteRow([]any{i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteRow([]any{3}); err != ErrPdfTooLarge {
+		t.Fatalf("expected ErrPdfTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package feign
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// 运行时绑定声明式客户端：结构体的函数字段通过标签声明请求
+//
+//	type UserClient struct {
+//		GetUser    func(ctx context.Context, id int64) (*User, error) `feign:"GET /users/{id}" params:"path:id"`
+//		FindUsers  func(name string, page int) ([]User, error)       `feign:"GET /users" params:"query:name,query:page"`
+//		CreateUser func(ctx context.Context, user *User) error       `feign:"POST /users" params:"body"`
+//	}
+//
+//	var userClient = &UserClient{}
+//	feign.MustBind(userClient, "http://user-service")
+//
+// 函数须返回 error 或 (T, error)；第一个参数可以是 context.Context，用于传递调用方令牌。
+func Bind(client any, baseUrl string, options ...ServiceOption) error {
+	value := reflect.ValueOf(client)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("feign client must be a pointer to struct, got %T", client)
+	}
+
+	service := NewService(baseUrl, options...)
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		request, ok := field.Tag.Lookup("feign")
+		if !ok {
+			continue
+		}
+
+		if field.Type.Kind() != reflect.Func || !field.IsExported() {
+			return fmt.Errorf("feign field %s.%s must be an exported func", value.Type().Name(), field.Name)
+		}
+
+		method, err := ParseMethod(request, field.Tag.Get("params"))
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", value.Type().Name(), field.Name, err)
+		}
+
+		fn, err := makeFunc(service, method, field.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", value.Type().Name(), field.Name, err)
+		}
+		value.Field(i).Set(fn)
+	}
+	return nil
+}
+
+func MustBind(client any, baseUrl string, options ...ServiceOption) {
+	if err := Bind(client, baseUrl, options...); err != nil {
+		panic(err)
+	}
+}
+
+func makeFunc(service *Service, method *Method, typ reflect.Type) (reflect.Value, error) {
+	withContext := typ.NumIn() > 0 && typ.In(0) == contextType
+	offset := 0
+	if withContext {
+		offset = 1
+	}
+
+	if typ.NumIn()-offset != len(method.Params) {
+		return reflect.Value{}, fmt.Errorf("expect %d parameters to bind, got %d", len(method.Params), typ.NumIn()-offset)
+	}
+	if typ.IsVariadic() {
+		return reflect.Value{}, fmt.Errorf("variadic parameters are not supported")
+	}
+
+	switch {
+	case typ.NumOut() == 1 && typ.Out(0) == errorType:
+	case typ.NumOut() == 2 && typ.Out(1) == errorType:
+	default:
+		return reflect.Value{}, fmt.Errorf("must return error or (T, error)")
+	}
+
+	return reflect.MakeFunc(typ, func(in []reflect.Value) []reflect.Value {
+		ctx := context.Background()
+		if withContext && !in[0].IsNil() {
+			ctx = in[0].Interface().(context.Context)
+		}
+
+		args := make([]any, 0, len(in)-offset)
+		for _, arg := range in[offset:] {
+			args = append(args, arg.Interface())
+		}
+
+		if typ.NumOut() == 1 {
+			return []reflect.Value{errorValue(service.Invoke(ctx, method, args, nil))}
+		}
+
+		out := reflect.New(typ.Out(0))
+		err := service.Invoke(ctx, method, args, out.Interface())
+		return []reflect.Value{out.Elem(), errorValue(err)}
+	}), nil
+}
+
+func errorValue(err error) reflect.Value {
+	if err == nil {
+		return reflect.Zero(errorType)
+	}
+	return reflect.ValueOf(&err).Elem()
+}
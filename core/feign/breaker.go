@@ -0,0 +1,184 @@
+package feign
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/feign/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 熔断器状态
+const (
+	BREAKER_CLOSED    = "CLOSED"
+	BREAKER_OPEN      = "OPEN"
+	BREAKER_HALF_OPEN = "HALF_OPEN"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerStatus struct {
+	State       string     `json:"state"`
+	Requests    int        `json:"requests"` // 当前窗口请求数
+	Failures    int        `json:"failures"` // 当前窗口失败数
+	OpenedAt    *time.Time `json:"openedAt,omitempty"`
+	RetryAfter  *time.Time `json:"retryAfter,omitempty"` // 进入半开的时间
+	TotalOpened int        `json:"totalOpened"`
+}
+
+type breaker struct {
+	sync.Mutex
+	service     string
+	state       string
+	windowStart time.Time
+	requests    int
+	failures    int
+	probes      int // 半开状态下进行中的探测请求
+	successes   int // 半开状态下成功的探测请求
+	openedAt    time.Time
+	totalOpened int
+}
+
+var (
+	breakersMutex sync.Mutex
+	breakers      = make(map[string]*breaker)
+)
+
+func getBreaker(service string) *breaker {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	if b, exists := breakers[service]; exists {
+		return b
+	}
+	b := &breaker{service: service, state: BREAKER_CLOSED, windowStart: time.Now()}
+	breakers[service] = b
+	return b
+}
+
+// 是否放行请求
+func (b *breaker) allow(setting *config.CircuitBreakerSetting) error {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case BREAKER_OPEN:
+		if now.Before(b.openedAt.Add(setting.OpenDuration)) {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, b.service)
+		}
+		b.transit(BREAKER_HALF_OPEN, now)
+		fallthrough
+	case BREAKER_HALF_OPEN:
+		if b.probes >= setting.HalfOpenRequests {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, b.service)
+		}
+		b.probes++
+	default:
+		if now.Sub(b.windowStart) > setting.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+	}
+	return nil
+}
+
+// 记录请求结果
+func (b *breaker) record(setting *config.CircuitBreakerSetting, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case BREAKER_HALF_OPEN:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if errors.Is(err, ErrBulkheadFull) {
+			// 本地拒绝，不代表下游状态
+			return
+		}
+		if err != nil {
+			b.transit(BREAKER_OPEN, now)
+		} else if b.successes++; b.successes >= setting.HalfOpenRequests {
+			b.transit(BREAKER_CLOSED, now)
+		}
+	case BREAKER_CLOSED:
+		if errors.Is(err, ErrBulkheadFull) {
+			return
+		}
+		b.requests++
+		if err != nil {
+			b.failures++
+		}
+		if b.requests >= setting.MinimumRequests && b.failures*100 >= b.requests*setting.FailureRate {
+			b.transit(BREAKER_OPEN, now)
+		}
+	}
+}
+
+func (b *breaker) transit(state string, now time.Time) {
+	logger.Warn("Circuit breaker ", b.service, ": ", b.state, " -> ", state)
+
+	b.state = state
+	b.probes, b.successes = 0, 0
+	switch state {
+	case BREAKER_OPEN:
+		b.openedAt = now
+		b.totalOpened++
+	case BREAKER_CLOSED:
+		b.windowStart, b.requests, b.failures = now, 0, 0
+	}
+}
+
+func breakerStatus() map[string]*BreakerStatus {
+	breakersMutex.Lock()
+	list := make([]*breaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMutex.Unlock()
+
+	result := make(map[string]*BreakerStatus, len(list))
+	for _, b := range list {
+		setting := config.For(b.service).CircuitBreaker
+
+		b.Lock()
+		status := &BreakerStatus{
+			State:       b.state,
+			Requests:    b.requests,
+			Failures:    b.failures,
+			TotalOpened: b.totalOpened,
+		}
+		if b.state != BREAKER_CLOSED {
+			openedAt, retryAfter := b.openedAt, b.openedAt.Add(setting.OpenDuration)
+			status.OpenedAt, status.RetryAfter = &openedAt, &retryAfter
+		}
+		b.Unlock()
+
+		result[b.service] = status
+	}
+	return result
+}
+
+// 熔断：失败率超过阈值后拒绝请求，等待后放行少量探测请求
+type CircuitBreakerInterceptor struct{}
+
+func (in *CircuitBreakerInterceptor) Do(chain *FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*RequestOptions) *FeignClient {
+	service := ServiceOf(urlPath)
+	setting := config.For(service).CircuitBreaker
+	if setting.Enabled == nil || !*setting.Enabled {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	b := getBreaker(service)
+	if err := b.allow(setting); err != nil {
+		return chain.Exit(err)
+	}
+
+	result := chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	b.record(setting, failure(result))
+	return result
+}
@@ -0,0 +1,102 @@
+package feign
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/feign/config"
+)
+
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+type BulkheadInfo struct {
+	MaxConcurrent int   `json:"maxConcurrent"`
+	Active        int   `json:"active"`
+	Rejected      int64 `json:"rejected"`
+}
+
+type bulkhead struct {
+	slots    chan struct{}
+	rejected int64
+}
+
+var (
+	bulkheadsMutex sync.Mutex
+	bulkheads      = make(map[string]*bulkhead)
+)
+
+// 并发上限变化时重建，已占用的名额在旧隔离舱中释放
+func getBulkhead(service string, maxConcurrent int) *bulkhead {
+	bulkheadsMutex.Lock()
+	defer bulkheadsMutex.Unlock()
+
+	if b, exists := bulkheads[service]; exists && cap(b.slots) == maxConcurrent {
+		return b
+	}
+	b := &bulkhead{slots: make(chan struct{}, maxConcurrent)}
+	bulkheads[service] = b
+	return b
+}
+
+func (b *bulkhead) acquire(wait time.Duration) bool {
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case b.slots <- struct{}{}:
+			return true
+		case <-timer.C:
+		}
+	}
+
+	atomic.AddInt64(&b.rejected, 1)
+	return false
+}
+
+func (b *bulkhead) release() {
+	<-b.slots
+}
+
+func bulkheadStatus() map[string]*BulkheadInfo {
+	bulkheadsMutex.Lock()
+	defer bulkheadsMutex.Unlock()
+
+	result := make(map[string]*BulkheadInfo, len(bulkheads))
+	for service, b := range bulkheads {
+		result[service] = &BulkheadInfo{
+			MaxConcurrent: cap(b.slots),
+			Active:        len(b.slots),
+			Rejected:      atomic.LoadInt64(&b.rejected),
+		}
+	}
+	return result
+}
+
+// 隔离：限制每个服务的并发请求数，避免慢服务占满调用方
+type BulkheadInterceptor struct{}
+
+func (in *BulkheadInterceptor) Do(chain *FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*RequestOptions) *FeignClient {
+	service := ServiceOf(urlPath)
+	setting := config.For(service).Bulkhead
+	if setting.MaxConcurrent <= 0 {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	b := getBulkhead(service, setting.MaxConcurrent)
+	if !b.acquire(setting.MaxWait) {
+		return chain.Exit(fmt.Errorf("%w: %s", ErrBulkheadFull, service))
+	}
+	defer b.release()
+
+	return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 按服务配置：microservice.feign.<service>，default 为所有服务的缺省值
+type FeignSetting map[string]*ServiceSetting
+
+type ServiceSetting struct {
+	Timeout        time.Duration          `json:"timeout" yaml:"timeout"` // 单次请求超时
+	Retry          *RetrySetting          `json:"retry" yaml:"retry"`
+	CircuitBreaker *CircuitBreakerSetting `json:"circuitBreaker" yaml:"circuitBreaker"`
+	Bulkhead       *BulkheadSetting       `json:"bulkhead" yaml:"bulkhead"`
+}
+
+type RetrySetting struct {
+	MaxAttempts     int           `json:"maxAttempts" yaml:"maxAttempts"`         // 总尝试次数，1 表示不重试
+	InitialInterval time.Duration `json:"initialInterval" yaml:"initialInterval"` // 首次重试等待
+	MaxInterval     time.Duration `json:"maxInterval" yaml:"maxInterval"`         // 最长等待
+	Multiplier      float64       `json:"multiplier" yaml:"multiplier"`           // 等待时间倍数
+	Jitter          float64       `json:"jitter" yaml:"jitter"`                   // 随机抖动比例 0~1
+	Methods         []string      `json:"methods" yaml:"methods"`                 // 允许重试的方法，缺省为幂等方法
+}
+
+type CircuitBreakerSetting struct {
+	Enabled          *bool         `json:"enabled" yaml:"enabled"`                   // 默认关闭，按服务开启
+	FailureRate      int           `json:"failureRate" yaml:"failureRate"`           // 失败率阈值（百分比）
+	MinimumRequests  int           `json:"minimumRequests" yaml:"minimumRequests"`   // 统计窗口内达到该请求数才计算失败率
+	Window           time.Duration `json:"window" yaml:"window"`                     // 统计窗口
+	OpenDuration     time.Duration `json:"openDuration" yaml:"openDuration"`         // 熔断后等待多久进入半开
+	HalfOpenRequests int           `json:"halfOpenRequests" yaml:"halfOpenRequests"` // 半开状态允许的探测请求数
+}
+
+type BulkheadSetting struct {
+	MaxConcurrent int           `json:"maxConcurrent" yaml:"maxConcurrent"` // 最大并发，0 不限制
+	MaxWait       time.Duration `json:"maxWait" yaml:"maxWait"`             // 并发已满时的最长等待，0 立即失败
+}
+
+var enabled = false
+
+// 内置缺省值
+var Default = &ServiceSetting{
+	Timeout: time.Second * 30,
+	Retry: &RetrySetting{
+		MaxAttempts:     3,
+		InitialInterval: time.Millisecond * 100,
+		MaxInterval:     time.Second * 2,
+		Multiplier:      2,
+		Jitter:          0.2,
+		Methods:         []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE"},
+	},
+	CircuitBreaker: &CircuitBreakerSetting{
+		Enabled:          &enabled,
+		FailureRate:      50,
+		MinimumRequests:  20,
+		Window:           time.Second * 10,
+		OpenDuration:     time.Second * 30,
+		HalfOpenRequests: 1,
+	},
+	Bulkhead: &BulkheadSetting{},
+}
+
+var Setting *FeignSetting = &FeignSetting{}
+
+// 服务的有效配置：内置缺省值 < default < 服务配置，未设置（零值）的项沿用上一级
+func For(service string) *ServiceSetting {
+	result := merge(Default, (*Setting)["default"])
+	if service != "default" {
+		result = merge(result, (*Setting)[service])
+	}
+	return result
+}
+
+func merge(base, over *ServiceSetting) *ServiceSetting {
+	result := *base
+	result.Retry = &RetrySetting{}
+	result.CircuitBreaker = &CircuitBreakerSetting{}
+	result.Bulkhead = &BulkheadSetting{}
+	*result.Retry = *base.Retry
+	*result.CircuitBreaker = *base.CircuitBreaker
+	*result.Bulkhead = *base.Bulkhead
+
+	if over == nil {
+		return &result
+	}
+
+	result.Timeout = cmp.Or(over.Timeout, result.Timeout)
+	if r := over.Retry; r != nil {
+		result.Retry.MaxAttempts = cmp.Or(r.MaxAttempts, result.Retry.MaxAttempts)
+		result.Retry.InitialInterval = cmp.Or(r.InitialInterval, result.Retry.InitialInterval)
+		result.Retry.MaxInterval = cmp.Or(r.MaxInterval, result.Retry.MaxInterval)
+		result.Retry.Multiplier = cmp.Or(r.Multiplier, result.Retry.Multiplier)
+		result.Retry.Jitter = cmp.Or(r.Jitter, result.Retry.Jitter)
+		if r.Methods != nil {
+			result.Retry.Methods = r.Methods
+		}
+	}
+	if c := over.CircuitBreaker; c != nil {
+		if c.Enabled != nil {
+			result.CircuitBreaker.Enabled = c.Enabled
+		}
+		result.CircuitBreaker.FailureRate = cmp.Or(c.FailureRate, result.CircuitBreaker.FailureRate)
+		result.CircuitBreaker.MinimumRequests = cmp.Or(c.MinimumRequests, result.CircuitBreaker.MinimumRequests)
+		result.CircuitBreaker.Window = cmp.Or(c.Window, result.CircuitBreaker.Window)
+		result.CircuitBreaker.OpenDuration = cmp.Or(c.OpenDuration, result.CircuitBreaker.OpenDuration)
+		result.CircuitBreaker.HalfOpenRequests = cmp.Or(c.HalfOpenRequests, result.CircuitBreaker.HalfOpenRequests)
+	}
+	if b := over.Bulkhead; b != nil {
+		result.Bulkhead.MaxConcurrent = cmp.Or(b.MaxConcurrent, result.Bulkhead.MaxConcurrent)
+		result.Bulkhead.MaxWait = cmp.Or(b.MaxWait, result.Bulkhead.MaxWait)
+	}
+	return &result
+}
+
+func init() {
+	logger.Debug("Register Feign Config")
+	config.RegisterConfig("microservice.feign", Setting, "Feign Client Settings")
+}
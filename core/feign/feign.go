@@ -2,12 +2,14 @@ package feign
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 var globalFeignClientInterceptors = []FeignClientInterceptor{}
@@ -47,6 +49,11 @@ func (f *FeignClientInterceptorChain) Exit(err error) *FeignClient {
 	return f.FeignClient
 }
 
+// 复制当前位置，可多次执行后续拦截器与请求（用于重试）
+func (f *FeignClientInterceptorChain) Fork() *FeignClientInterceptorChain {
+	return &FeignClientInterceptorChain{FeignClient: f.FeignClient, p: f.p}
+}
+
 /**
  *	Skip剩余的操作
  */
@@ -60,12 +67,32 @@ type FeignClient struct {
 	Request      *http.Request
 	Response     *http.Response
 	Error        error
+	Tried        []string // 本次调用已失败的实例，重试时由负载均衡排除
 	cloned       bool
 }
 
 type RequestOptions struct {
 	Headers     map[string]string
 	ContentType string
+	Timeout     time.Duration // 本次请求超时，覆盖服务配置
+	Retryable   bool          // 非幂等请求也允许重试
+	Fallback    FallbackFunc  // 本次请求的降级函数，优先于服务注册的降级函数
+	Context     context.Context
+}
+
+func requestOptions(options []*RequestOptions) *RequestOptions {
+	if len(options) > 0 && options[0] != nil {
+		return options[0]
+	}
+	return &DefaultRequestOptions
+}
+
+// 请求上下文，取消后中止请求与重试
+func (o *RequestOptions) context() context.Context {
+	if o.Context != nil {
+		return o.Context
+	}
+	return context.Background()
 }
 
 var DefaultRequestOptions = RequestOptions{
@@ -74,8 +101,9 @@ var DefaultRequestOptions = RequestOptions{
 
 func (m *FeignClient) clone() *FeignClient {
 	return &FeignClient{
-		HttpClient: &http.Client{},
-		cloned:     true,
+		HttpClient:   m.HttpClient,
+		Interceptors: m.Interceptors,
+		cloned:       true,
 	}
 }
 
@@ -92,6 +120,7 @@ func (m *FeignClient) Fetch(data any) error {
 		return errors.New("no response")
 	}
 
+	defer m.Response.Body.Close()
 	if resBytes, err := io.ReadAll(m.Response.Body); err != nil {
 		return err
 	} else {
@@ -116,6 +145,7 @@ func (m *FeignClient) Raw() ([]byte, error) {
 		return nil, errors.New("no response")
 	}
 
+	defer m.Response.Body.Close()
 	if resBytes, err := io.ReadAll(m.Response.Body); err != nil {
 		return nil, err
 	} else {
@@ -168,7 +198,7 @@ func (m *FeignClient) do(method string, url string, urlValues url.Values, bodyVa
 		bodyValueBytes = bytes
 	}
 
-	req, err := http.NewRequest(method, m.formatUrl(url, urlValues), bytes.NewReader(bodyValueBytes))
+	req, err := http.NewRequestWithContext(requestOptions(options).context(), method, m.formatUrl(url, urlValues), bytes.NewReader(bodyValueBytes))
 	if err != nil {
 		m.Error = err
 		return m
@@ -190,10 +220,7 @@ func (m *FeignClient) formatUrl(url string, urlValues url.Values) string {
 }
 
 func (m *FeignClient) doRequest(req *http.Request, options ...*RequestOptions) *FeignClient {
-	var option = &DefaultRequestOptions
-	if len(options) > 0 {
-		option = options[0]
-	}
+	var option = requestOptions(options)
 
 	req.Header.Set("Content-Type", option.ContentType)
 	for k, v := range option.Headers {
@@ -0,0 +1,326 @@
+// 根据接口声明生成 feign 客户端：
+//
+//	//go:generate go run github.com/gophab/gophrame/core/feign/gen -type UserService
+//	type UserService interface {
+//		// @GET /users/{id}
+//		GetUser(ctx context.Context, id int64) (*User, error)
+//
+//		// @GET /users
+//		// @Query page p
+//		FindUsers(ctx context.Context, name string, page int) ([]User, error)
+//
+//		// @POST /users
+//		// @Header X-Request-Id requestId
+//		CreateUser(ctx context.Context, requestId string, user *User) (*User, error)
+//	}
+//
+// 参数绑定：与路径变量 {name} 同名的参数绑定到路径；@Query <参数> [名称]、@Header <名称> <参数>、
+// @Body <参数> 显式指定；其余参数在 POST/PUT/PATCH 中最后一个作为请求体，其他作为同名查询参数。
+// 生成 <Type>Client 与 New<Type>Client(baseUrl, options...)，写入 <源文件>_feign.go。
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated interface names")
+	fileName  = flag.String("file", os.Getenv("GOFILE"), "source file, default $GOFILE")
+	output    = flag.String("output", "", "output file, default <file>_feign.go")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("feign-gen: ")
+	flag.Parse()
+
+	if *typeNames == "" || *fileName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	code, err := generate(*fileName, strings.Split(*typeNames, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	target := *output
+	if target == "" {
+		target = strings.TrimSuffix(*fileName, ".go") + "_feign.go"
+	}
+	if err := os.WriteFile(target, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type method struct {
+	name    string
+	request string
+	params  []string // 绑定规则，对应 args
+	args    []string // 参数名（不含 context）
+	ctx     string   // context 参数名，为空时不含 context
+	sig     string   // 参数列表
+	result  string   // 返回值类型（不含 error），为空时只返回 error
+	results string   // 返回值列表
+}
+
+func generate(file string, types []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	source, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{}
+	body := &bytes.Buffer{}
+	for _, name := range types {
+		name = strings.TrimSpace(name)
+		iface := findInterface(source, name)
+		if iface == nil {
+			return nil, fmt.Errorf("interface %s not found in %s", name, file)
+		}
+
+		var methods []*method
+		for _, field := range iface.Methods.List {
+			fn, ok := field.Type.(*ast.FuncType)
+			if !ok || len(field.Names) == 0 {
+				return nil, fmt.Errorf("%s: embedded interfaces are not supported", name)
+			}
+			m, err := parseMethod(fset, field.Names[0].Name, field.Doc, fn, used)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, field.Names[0].Name, err)
+			}
+			methods = append(methods, m)
+		}
+		writeClient(body, name, methods)
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated by github.com/gophab/gophrame/core/feign/gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(out, "package %s\n\nimport (\n", source.Name.Name)
+	for _, spec := range source.Imports {
+		if used[importName(spec)] {
+			if spec.Name != nil {
+				fmt.Fprintf(out, "\t%s %s\n", spec.Name.Name, spec.Path.Value)
+			} else {
+				fmt.Fprintf(out, "\t%s\n", spec.Path.Value)
+			}
+		}
+	}
+	fmt.Fprintf(out, "\n\t\"github.com/gophab/gophrame/core/feign\"\n)\n")
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+						return iface
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p, _ := strconv.Unquote(spec.Path.Value)
+	name := path.Base(p)
+	// 版本后缀：.../v2
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(p))
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func typeString(fset *token.FileSet, expr ast.Expr, used map[string]bool) string {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	buf := &bytes.Buffer{}
+	_ = printer.Fprint(buf, fset, expr)
+	return buf.String()
+}
+
+func parseMethod(fset *token.FileSet, name string, doc *ast.CommentGroup, fn *ast.FuncType, used map[string]bool) (*method, error) {
+	m := &method{name: name}
+
+	// 注释
+	queries, headers := map[string]string{}, map[string]string{}
+	body := ""
+	if doc != nil {
+		for _, comment := range doc.List {
+			fields := strings.Fields(strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")))
+			if len(fields) == 0 || !strings.HasPrefix(fields[0], "@") {
+				continue
+			}
+			switch annotation := strings.ToUpper(fields[0][1:]); annotation {
+			case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("invalid annotation %q", comment.Text)
+				}
+				m.request = annotation + " " + fields[1]
+			case "QUERY":
+				if len(fields) < 2 {
+					return nil, fmt.Errorf("invalid annotation %q", comment.Text)
+				}
+				queries[fields[1]] = fields[len(fields)-1]
+			case "HEADER":
+				if len(fields) != 3 {
+					return nil, fmt.Errorf("invalid annotation %q, expect @Header <Name> <param>", comment.Text)
+				}
+				headers[fields[2]] = fields[1]
+			case "BODY":
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("invalid annotation %q", comment.Text)
+				}
+				body = fields[1]
+			}
+		}
+	}
+	if m.request == "" {
+		return nil, fmt.Errorf("missing request annotation such as @GET /path")
+	}
+
+	// 参数
+	var sig []string
+	for i, field := range fn.Params.List {
+		typ := typeString(fset, field.Type, used)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("arg%d", i))}
+		}
+		for _, ident := range names {
+			sig = append(sig, ident.Name+" "+typ)
+			if typ == "context.Context" && m.ctx == "" && len(m.args) == 0 {
+				m.ctx = ident.Name
+				continue
+			}
+			m.args = append(m.args, ident.Name)
+		}
+	}
+	m.sig = strings.Join(sig, ", ")
+
+	httpMethod, urlPath, _ := strings.Cut(m.request, " ")
+	bodyMethod := slices.Contains([]string{"POST", "PUT", "PATCH"}, httpMethod)
+	if bodyMethod && body == "" {
+		// 最后一个未绑定的参数作为请求体
+		for i := len(m.args) - 1; i >= 0; i-- {
+			arg := m.args[i]
+			if _, b := queries[arg]; b {
+				continue
+			}
+			if _, b := headers[arg]; b {
+				continue
+			}
+			if strings.Contains(urlPath, "{"+arg+"}") {
+				continue
+			}
+			body = arg
+			break
+		}
+	}
+
+	for _, arg := range m.args {
+		switch {
+		case strings.Contains(urlPath, "{"+arg+"}"):
+			m.params = append(m.params, "path:"+arg)
+		case headers[arg] != "":
+			m.params = append(m.params, "header:"+headers[arg])
+		case queries[arg] != "":
+			m.params = append(m.params, "query:"+queries[arg])
+		case arg == body:
+			m.params = append(m.params, "body")
+		default:
+			m.params = append(m.params, "query:"+arg)
+		}
+	}
+
+	// 返回值
+	if fn.Results == nil || len(fn.Results.List) == 0 {
+		return nil, fmt.Errorf("must return error or (T, error)")
+	}
+	var results []string
+	for _, field := range fn.Results.List {
+		for range max(len(field.Names), 1) {
+			results = append(results, typeString(fset, field.Type, used))
+		}
+	}
+	switch {
+	case len(results) == 1 && results[0] == "error":
+	case len(results) == 2 && results[1] == "error":
+		m.result = results[0]
+	default:
+		return nil, fmt.Errorf("must return error or (T, error)")
+	}
+	m.results = strings.Join(results, ", ")
+	if len(results) > 1 {
+		m.results = "(" + m.results + ")"
+	}
+	return m, nil
+}
+
+func writeClient(out *bytes.Buffer, name string, methods []*method) {
+	client := name + "Client"
+
+	fmt.Fprintf(out, "\nvar (\n")
+	for _, m := range methods {
+		fmt.Fprintf(out, "\t_%s_%s = feign.MustParseMethod(%q, %q)\n", name, m.name, m.request, strings.Join(m.params, ","))
+	}
+	fmt.Fprintf(out, ")\n\n")
+
+	fmt.Fprintf(out, "type %s struct {\n\tservice *feign.Service\n}\n\n", client)
+	fmt.Fprintf(out, "var _ %s = (*%s)(nil)\n\n", name, client)
+	fmt.Fprintf(out, "func New%s(baseUrl string, options ...feign.ServiceOption) *%s {\n", client, client)
+	fmt.Fprintf(out, "\treturn &%s{service: feign.NewService(baseUrl, options...)}\n}\n", client)
+
+	for _, m := range methods {
+		ctx := "nil"
+		if m.ctx != "" {
+			ctx = m.ctx
+		}
+
+		fmt.Fprintf(out, "\nfunc (c *%s) %s(%s) %s {\n", client, m.name, m.sig, m.results)
+		call := fmt.Sprintf("c.service.Invoke(%s, _%s_%s, []any{%s}", ctx, name, m.name, strings.Join(m.args, ", "))
+		if m.result == "" {
+			fmt.Fprintf(out, "\treturn %s, nil)\n}\n", call)
+		} else {
+			fmt.Fprintf(out, "\tvar result %s\n\terr := %s, &result)\n\treturn result, err\n}\n", m.result, call)
+		}
+	}
+}
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s -type <Interface>[,<Interface>] [-file <source.go>] [-output <file>]\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
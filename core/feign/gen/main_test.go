@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const source = `package demo
+
+import (
+	"context"
+	"time"
+
+	model "example.com/demo/model"
+)
+
+type UserService interface {
+	// @GET /users/{id}
+	GetUser(ctx context.Context, id int64) (*model.User, error)
+
+	// @GET /users
+	// @Query page p
+	FindUsers(ctx context.Context, name string, page int) ([]model.User, error)
+
+	// @POST /users
+	// @Header X-Request-Id requestId
+	CreateUser(ctx context.Context, requestId string, user *model.User) (*model.User, error)
+
+	// @DELETE /users/{id}
+	DeleteUser(id int64) error
+}
+`
+
+func writeSource(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "service.go")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := generate(writeSource(t, source), []string{"UserService"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := string(code)
+	for _, expected := range []string{
+		`_UserService_GetUser    = feign.MustParseMethod("GET /users/{id}", "path:id")`,
+		`_UserService_FindUsers  = feign.MustParseMethod("GET /users", "query:name,query:p")`,
+		`_UserService_CreateUser = feign.MustParseMethod("POST /users", "header:X-Request-Id,body")`,
+		`_UserService_DeleteUser = feign.MustParseMethod("DELETE /users/{id}", "path:id")`,
+		`func NewUserServiceClient(baseUrl string, options ...feign.ServiceOption) *UserServiceClient`,
+		`err := c.service.Invoke(ctx, _UserService_GetUser, []any{id}, &result)`,
+		`return c.service.Invoke(nil, _UserService_DeleteUser, []any{id}, nil)`,
+		`model "example.com/demo/model"`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("generated code missing %q:\n%s", expected, out)
+		}
+	}
+	// 未使用的导入不输出
+	if strings.Contains(out, `"time"`) {
+		t.Errorf("unused import generated:\n%s", out)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing annotation": "package demo\n\ntype S interface {\n\tGet() error\n}\n",
+		"invalid results":    "package demo\n\ntype S interface {\n\t// @GET /x\n\tGet() (int, int)\n}\n",
+		"embedded":           "package demo\n\ntype S interface {\n\tfmt.Stringer\n}\n",
+	} {
+		if _, err := generate(writeSource(t, content), []string{"S"}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := generate(writeSource(t, source), []string{"Missing"}); err == nil {
+		t.Error("expected interface not found error")
+	}
+}
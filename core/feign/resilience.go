@@ -0,0 +1,143 @@
+package feign
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/gophab/gophrame/core/feign/config"
+)
+
+// 降级函数：请求最终失败时返回替代响应
+type FallbackFunc func(method string, url string, err error) (*http.Response, error)
+
+var (
+	fallbacksMutex sync.RWMutex
+	fallbacks      = make(map[string]FallbackFunc)
+)
+
+// 注册服务的降级函数
+func RegisterFallback(service string, fallback FallbackFunc) {
+	fallbacksMutex.Lock()
+	defer fallbacksMutex.Unlock()
+	fallbacks[service] = fallback
+}
+
+func getFallback(service string) FallbackFunc {
+	fallbacksMutex.RLock()
+	defer fallbacksMutex.RUnlock()
+	return fallbacks[service]
+}
+
+// 请求地址对应的服务：注册中心服务名或主机名
+func ServiceOf(urlPath string) string {
+	if u, err := url.Parse(urlPath); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	return "default"
+}
+
+// 连接错误与 5xx 视为失败
+func failure(result *FeignClient) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.Response != nil && result.Response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("status %d", result.Response.StatusCode)
+	}
+	return nil
+}
+
+// 降级：请求最终失败时调用降级函数
+type FallbackInterceptor struct{}
+
+func (in *FallbackInterceptor) Do(chain *FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*RequestOptions) *FeignClient {
+	result := chain.Next(method, urlPath, urlValues, bodyValue, options...)
+
+	err := failure(result)
+	if err == nil {
+		return result
+	}
+
+	fallback := requestOptions(options).Fallback
+	if fallback == nil {
+		fallback = getFallback(ServiceOf(urlPath))
+	}
+	if fallback == nil {
+		return result
+	}
+
+	response, err := fallback(method, urlPath, err)
+	if result.Response != nil && result.Response != response {
+		result.Response.Body.Close()
+	}
+	result.Response, result.Error = response, err
+	return result
+}
+
+// 超时：本次请求的超时优先，其次为服务配置
+type TimeoutInterceptor struct{}
+
+func (in *TimeoutInterceptor) Do(chain *FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*RequestOptions) *FeignClient {
+	timeout := requestOptions(options).Timeout
+	if timeout <= 0 {
+		timeout = config.For(ServiceOf(urlPath)).Timeout
+	}
+
+	if timeout > 0 && (chain.HttpClient == nil || chain.HttpClient.Timeout != timeout) {
+		client := http.Client{}
+		if chain.HttpClient != nil {
+			client = *chain.HttpClient
+		}
+		client.Timeout = timeout
+		chain.HttpClient = &client
+	}
+	return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+}
+
+// 服务的熔断与并发状态
+type ServiceStatus struct {
+	Service        string         `json:"service"`
+	CircuitBreaker *BreakerStatus `json:"circuitBreaker,omitempty"`
+	Bulkhead       *BulkheadInfo  `json:"bulkhead,omitempty"`
+}
+
+// 各服务的熔断器与隔离舱状态
+func Status() []*ServiceStatus {
+	services := make(map[string]*ServiceStatus)
+	get := func(name string) *ServiceStatus {
+		if s, b := services[name]; b {
+			return s
+		}
+		s := &ServiceStatus{Service: name}
+		services[name] = s
+		return s
+	}
+
+	for name, status := range breakerStatus() {
+		get(name).CircuitBreaker = status
+	}
+	for name, info := range bulkheadStatus() {
+		get(name).Bulkhead = info
+	}
+
+	result := make([]*ServiceStatus, 0, len(services))
+	for _, s := range services {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Service < result[j].Service
+	})
+	return result
+}
+
+func init() {
+	// 由外到内：降级 → 重试 → 熔断 → 隔离 → 超时
+	RegisterGlobalFeignClientInterceptor(&FallbackInterceptor{})
+	RegisterGlobalFeignClientInterceptor(&RetryInterceptor{})
+	RegisterGlobalFeignClientInterceptor(&CircuitBreakerInterceptor{})
+	RegisterGlobalFeignClientInterceptor(&BulkheadInterceptor{})
+	RegisterGlobalFeignClientInterceptor(&TimeoutInterceptor{})
+}
@@ -0,0 +1,72 @@
+package feign
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gophab/gophrame/core/feign/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 重试：指数退避加随机抖动，默认只重试幂等方法
+type RetryInterceptor struct{}
+
+func (in *RetryInterceptor) Do(chain *FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*RequestOptions) *FeignClient {
+	setting := config.For(ServiceOf(urlPath)).Retry
+	if setting.MaxAttempts <= 1 || !(requestOptions(options).Retryable || slices.Contains(setting.Methods, method)) {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	ctx := requestOptions(options).context()
+	for attempt := 1; ; attempt++ {
+		result := chain.Fork().Next(method, urlPath, urlValues, bodyValue, options...)
+		if attempt >= setting.MaxAttempts || ctx.Err() != nil || !shouldRetry(result) {
+			return result
+		}
+
+		logger.Debug("Retry feign request: ", method, urlPath, attempt)
+		if result.Response != nil {
+			result.Response.Body.Close()
+		}
+		result.Error, result.Request, result.Response = nil, nil, nil
+
+		timer := time.NewTimer(backoff(setting, attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return chain.Exit(ctx.Err())
+		}
+	}
+}
+
+// 熔断与隔离拒绝的请求不重试
+func shouldRetry(result *FeignClient) bool {
+	if result.Error != nil {
+		return !errors.Is(result.Error, ErrCircuitOpen) && !errors.Is(result.Error, ErrBulkheadFull)
+	}
+	if result.Response != nil {
+		switch result.Response.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// 第 attempt 次失败后的等待时间
+func backoff(setting *config.RetrySetting, attempt int) time.Duration {
+	interval := float64(setting.InitialInterval) * math.Pow(setting.Multiplier, float64(attempt-1))
+	if setting.MaxInterval > 0 {
+		interval = math.Min(interval, float64(setting.MaxInterval))
+	}
+	if setting.Jitter > 0 {
+		interval += interval * setting.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(interval)
+}
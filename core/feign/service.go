@@ -0,0 +1,313 @@
+package feign
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	gocontext "github.com/gophab/gophrame/core/context"
+	"github.com/gophab/gophrame/errors"
+)
+
+// 参数绑定方式
+const (
+	PARAM_PATH   = "path"
+	PARAM_QUERY  = "query"
+	PARAM_HEADER = "header"
+	PARAM_BODY   = "body"
+)
+
+type Param struct {
+	In   string // path|query|header|body
+	Name string // 路径变量、查询参数或请求头名称；query 未指定名称时参数须为 url.Values 或 map
+}
+
+// 声明式请求：METHOD /path/{var}，参数按顺序对应 Params（context.Context 参数除外）
+type Method struct {
+	HttpMethod string
+	Path       string
+	Params     []Param
+}
+
+// 解析 "GET /users/{id}" 与参数绑定 "path:id,query:name,header:X-Token,body"
+func ParseMethod(request string, params string) (*Method, error) {
+	fields := strings.Fields(request)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid feign request %q, expect \"METHOD /path\"", request)
+	}
+
+	method := &Method{HttpMethod: strings.ToUpper(fields[0]), Path: fields[1]}
+	for _, spec := range strings.Split(params, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+
+		in, name, _ := strings.Cut(spec, ":")
+		switch in {
+		case PARAM_PATH, PARAM_HEADER:
+			if name == "" {
+				return nil, fmt.Errorf("feign param %q requires a name", spec)
+			}
+		case PARAM_QUERY, PARAM_BODY:
+		default:
+			return nil, fmt.Errorf("unknown feign param %q", spec)
+		}
+		method.Params = append(method.Params, Param{In: in, Name: name})
+	}
+
+	// 路径变量必须全部绑定
+	for _, variable := range pathVariables(method.Path) {
+		found := false
+		for _, param := range method.Params {
+			if param.In == PARAM_PATH && param.Name == variable {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("path variable {%s} of %q is not bound", variable, request)
+		}
+	}
+	return method, nil
+}
+
+func MustParseMethod(request string, params string) *Method {
+	method, err := ParseMethod(request, params)
+	if err != nil {
+		panic(err)
+	}
+	return method
+}
+
+func pathVariables(path string) []string {
+	var result []string
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			return result
+		}
+		end := strings.Index(path[start:], "}")
+		if end < 0 {
+			return result
+		}
+		result = append(result, path[start+1:start+end])
+		path = path[start+end+1:]
+	}
+}
+
+// 获取请求令牌，返回空字符串时不设置 Authorization
+type TokenSource func(ctx context.Context) string
+
+// 转发调用方请求中的令牌
+func CallerToken(ctx context.Context) string {
+	if token := bearerToken(ctx); token != "" {
+		return token
+	}
+	// 当前请求（controller 中间件保存在协程上下文中）
+	return bearerToken(gocontext.GetContextValue("_current_context_"))
+}
+
+func bearerToken(value any) string {
+	if c, ok := value.(interface{ GetHeader(string) string }); ok {
+		if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			return strings.TrimPrefix(auth, "Bearer ")
+		}
+	}
+	return ""
+}
+
+func StaticToken(token string) TokenSource {
+	return func(context.Context) string { return token }
+}
+
+// 服务令牌：没有调用方令牌时（定时任务、消息消费等）使用
+var serviceTokenSource TokenSource
+
+func RegisterServiceTokenSource(source TokenSource) {
+	serviceTokenSource = source
+}
+
+// 缺省：优先转发调用方令牌，其次使用服务令牌
+func DefaultToken(ctx context.Context) string {
+	if token := CallerToken(ctx); token != "" {
+		return token
+	}
+	if serviceTokenSource != nil {
+		return serviceTokenSource(ctx)
+	}
+	return ""
+}
+
+// 声明式客户端的调用端：基础地址通常为 http://<服务名>，经注册中心拦截器解析
+type Service struct {
+	BaseUrl string
+	Token   TokenSource
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+type ServiceOption func(*Service)
+
+func WithToken(source TokenSource) ServiceOption {
+	return func(s *Service) { s.Token = source }
+}
+
+func WithHeader(name, value string) ServiceOption {
+	return func(s *Service) { s.Headers[name] = value }
+}
+
+func WithTimeout(timeout time.Duration) ServiceOption {
+	return func(s *Service) { s.Timeout = timeout }
+}
+
+func NewService(baseUrl string, options ...ServiceOption) *Service {
+	service := &Service{
+		BaseUrl: strings.TrimRight(baseUrl, "/"),
+		Token:   DefaultToken,
+		Headers: make(map[string]string),
+	}
+	for _, option := range options {
+		option(service)
+	}
+	return service
+}
+
+// 调用远程方法，out 为返回值指针，可为空
+func (s *Service) Invoke(ctx context.Context, method *Method, args []any, out any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(args) != len(method.Params) {
+		return fmt.Errorf("feign %s %s: expect %d arguments, got %d", method.HttpMethod, method.Path, len(method.Params), len(args))
+	}
+
+	options := &RequestOptions{
+		Headers:     make(map[string]string),
+		ContentType: DefaultRequestOptions.ContentType,
+		Timeout:     s.Timeout,
+		Context:     ctx,
+	}
+	for k, v := range s.Headers {
+		options.Headers[k] = v
+	}
+	if s.Token != nil {
+		if token := s.Token(ctx); token != "" {
+			options.Headers["Authorization"] = "Bearer " + token
+		}
+	}
+
+	path := method.Path
+	values := url.Values{}
+	var body any
+	for i, param := range method.Params {
+		switch param.In {
+		case PARAM_PATH:
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(fmt.Sprint(args[i])))
+		case PARAM_QUERY:
+			addQuery(values, param.Name, args[i])
+		case PARAM_HEADER:
+			if args[i] != nil {
+				options.Headers[param.Name] = fmt.Sprint(args[i])
+			}
+		case PARAM_BODY:
+			body = args[i]
+		}
+	}
+
+	result := NewClient().Do(method.HttpMethod, s.BaseUrl+path, values, body, options)
+	data, err := result.Raw()
+	if err != nil {
+		return err
+	}
+	return decode(result.Response.StatusCode, data, out)
+}
+
+func addQuery(values url.Values, name string, arg any) {
+	switch v := arg.(type) {
+	case nil:
+		return
+	case url.Values:
+		for key, items := range v {
+			values[key] = append(values[key], items...)
+		}
+		return
+	case map[string]string:
+		for key, item := range v {
+			values.Add(key, item)
+		}
+		return
+	}
+
+	value := reflect.ValueOf(arg)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < value.Len(); i++ {
+			values.Add(name, fmt.Sprint(value.Index(i).Interface()))
+		}
+		return
+	}
+	values.Add(name, fmt.Sprint(value.Interface()))
+}
+
+// 统一响应：{"code": 0, "msg": "", "data": ...}，或直接返回的数据；错误响应 {"code": .., "message": ..}
+func decode(status int, data []byte, out any) error {
+	var envelope struct {
+		Code    *int            `json:"code"`
+		Msg     string          `json:"msg"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+
+	isEnvelope := false
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var keys map[string]json.RawMessage
+		if json.Unmarshal(trimmed, &keys) == nil {
+			_, hasCode := keys["code"]
+			_, hasData := keys["data"]
+			_, hasMsg := keys["msg"]
+			_, hasMessage := keys["message"]
+			isEnvelope = hasCode && (hasData || ((hasMsg || hasMessage) && len(keys) <= 3))
+			if isEnvelope {
+				isEnvelope = json.Unmarshal(trimmed, &envelope) == nil && envelope.Code != nil
+			}
+		}
+	}
+
+	message := envelope.Msg
+	if message == "" {
+		message = envelope.Message
+	}
+
+	if status < 200 || status > 299 {
+		if isEnvelope && envelope.Code != nil {
+			return errors.Error{Code: *envelope.Code, Message: message}
+		}
+		return errors.Error{Code: status, Message: strings.TrimSpace(string(data))}
+	}
+
+	if isEnvelope {
+		if code := *envelope.Code; code != 0 && code != http.StatusOK {
+			return errors.Error{Code: code, Message: message}
+		}
+		data = envelope.Data
+	}
+
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
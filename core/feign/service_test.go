@@ -0,0 +1,98 @@
+package feign
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMethod(t *testing.T) {
+	method, err := ParseMethod("get /users/{id}", "path:id, query:name,header:X-Token,body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method.HttpMethod != "GET" || method.Path != "/users/{id}" || len(method.Params) != 4 {
+		t.Fatalf("unexpected method: %+v", method)
+	}
+	if method.Params[1] != (Param{In: PARAM_QUERY, Name: "name"}) || method.Params[3] != (Param{In: PARAM_BODY}) {
+		t.Fatalf("unexpected params: %+v", method.Params)
+	}
+
+	for _, c := range []struct{ request, params string }{
+		{"/users", ""},                     // 缺少方法
+		{"GET /users/{id}", ""},            // 路径变量未绑定
+		{"GET /users/{id}", "path"},        // path 缺少名称
+		{"GET /users", "header"},           // header 缺少名称
+		{"GET /users", "cookie:session"},   // 未知绑定方式
+		{"GET /users/{id}", "path:userId"}, // 名称不匹配
+		{"GET /users extra", "query:name"}, // 多余字段
+	} {
+		if _, err := ParseMethod(c.request, c.params); err == nil {
+			t.Errorf("expected error for %q %q", c.request, c.params)
+		}
+	}
+}
+
+func TestInvokeBinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"code": 0,
+			"data": map[string]string{
+				"path":   r.URL.Path,
+				"query":  r.URL.RawQuery,
+				"header": r.Header.Get("X-Token"),
+				"auth":   r.Header.Get("Authorization"),
+				"body":   strings.TrimSpace(string(body)),
+			},
+		})
+	}))
+	defer server.Close()
+
+	service := NewService(server.URL+"/", WithToken(StaticToken("t0")))
+	method := MustParseMethod("PUT /users/{id}", "path:id,query:name,header:X-Token,body")
+
+	var out map[string]string
+	if err := service.Invoke(context.Background(), method, []any{"a b", "tom", "x", map[string]int{"n": 1}}, &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"path": "/users/a b", "query": "name=tom", "header": "x", "auth": "Bearer t0", "body": `{"n":1}`}
+	for k, v := range expected {
+		if out[k] != v {
+			t.Errorf("%s = %q, expected %q", k, out[k], v)
+		}
+	}
+
+	if err := service.Invoke(context.Background(), method, []any{"1"}, nil); err == nil {
+		t.Fatal("expected argument count error")
+	}
+}
+
+func TestInvokeHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := NewService(server.URL).Invoke(ctx, MustParseMethod("GET /slow", ""), nil, nil)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("cancelled request took %s", elapsed)
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type InjectSetting struct {
+	Strict bool `json:"strict" yaml:"strict"` // 非 optional 的 inject 标签字段缺失时中止启动（默认），关闭后只记录警告
+}
+
+var Setting *InjectSetting = &InjectSetting{Strict: true}
+
+func init() {
+	logger.Debug("Register Inject Config")
+	config.RegisterConfig("inject", Setting, "Dependency Injection Settings")
+}
@@ -22,7 +22,10 @@
 // The first no value syntax is for the common case of a singleton dependency
 // of the associated type. The second triggers creation of a private instance
 // for the associated type. Finally the last form is asking for a named
-// dependency called "dev logger".
+// dependency called "dev logger". A named dependency may be suffixed with
+// ",optional" to allow it to be missing during startup validation.
+//
+// Constructors can be registered with Provide, see provider.go.
 package inject
 
 import (
@@ -570,9 +573,10 @@ var (
 )
 
 type tag struct {
-	Name    string
-	Inline  bool
-	Private bool
+	Name     string
+	Inline   bool
+	Private  bool
+	Optional bool // inject:"name,optional"，启动校验时允许缺失
 }
 
 func parseTag(t string) (*tag, error) {
@@ -592,7 +596,8 @@ func parseTag(t string) (*tag, error) {
 	if value == "private" {
 		return injectPrivate, nil
 	}
-	return &tag{Name: value}, nil
+	name, optional := parseName(value)
+	return &tag{Name: name, Optional: optional}, nil
 }
 
 func isStructPtr(t reflect.Type) bool {
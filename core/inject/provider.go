@@ -0,0 +1,574 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 实例作用域
+const (
+	SCOPE_SINGLETON = iota // 全局唯一，首次获取时创建
+	SCOPE_PROTOTYPE        // 每次获取创建新实例
+	SCOPE_REQUEST          // 每个请求作用域内唯一，见 BeginScope
+)
+
+var (
+	ErrNotFound  = errors.New("no provider found")
+	ErrAmbiguous = errors.New("more than one provider found")
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// 构造器: func(deps...) T 或 func(deps...) (T, error)
+type provider struct {
+	name        string
+	typ         reflect.Type
+	constructor reflect.Value
+	params      []string // 按位置指定依赖名称，空字符串按类型查找
+	bindings    []reflect.Type
+	scope       int
+	optional    []bool
+
+	mutex    sync.Mutex
+	instance any
+	built    bool
+}
+
+func (p *provider) String() string {
+	if p.name != "" {
+		return fmt.Sprintf("%s named %s", p.typ, p.name)
+	}
+	return p.typ.String()
+}
+
+type ProvideOption func(p *provider)
+
+// 命名实例，可通过 inject:"name" 或 GetNamed 获取
+func Named(name string) ProvideOption {
+	return func(p *provider) {
+		p.name = name
+	}
+}
+
+// 绑定接口，参数为接口指针，如 As(new(PermissionService))
+func As(ifaces ...any) ProvideOption {
+	return func(p *provider) {
+		for _, iface := range ifaces {
+			p.bindings = append(p.bindings, reflect.TypeOf(iface).Elem())
+		}
+	}
+}
+
+func InScope(scope int) ProvideOption {
+	return func(p *provider) {
+		p.scope = scope
+	}
+}
+
+// 按位置指定构造器参数的依赖名称，"" 表示按类型查找，"name,optional" 表示可选
+func WithParams(names ...string) ProvideOption {
+	return func(p *provider) {
+		p.params = names
+	}
+}
+
+type container struct {
+	sync.RWMutex
+	providers []*provider
+	named     map[string]*provider
+}
+
+var theContainer = &container{
+	named: make(map[string]*provider),
+}
+
+// 注册构造器
+func Provide(constructor any, opts ...ProvideOption) error {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("constructor must be a function: %T", constructor)
+	}
+
+	ft := fn.Type()
+	if ft.NumOut() == 0 || ft.NumOut() > 2 || (ft.NumOut() == 2 && ft.Out(1) != errorType) {
+		return fmt.Errorf("constructor must return T or (T, error): %s", ft)
+	}
+
+	p := &provider{
+		typ:         ft.Out(0),
+		constructor: fn,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if len(p.params) > ft.NumIn() {
+		return fmt.Errorf("too many parameter names for constructor: %s", ft)
+	}
+	p.optional = make([]bool, ft.NumIn())
+	for i := range p.params {
+		p.params[i], p.optional[i] = parseName(p.params[i])
+	}
+
+	for _, binding := range p.bindings {
+		if binding.Kind() != reflect.Interface || !p.typ.Implements(binding) {
+			return fmt.Errorf("%s does not implement %s", p.typ, binding)
+		}
+	}
+
+	return theContainer.add(p)
+}
+
+// 注册构造器，出错时 panic，用于 init()
+func MustProvide(constructor any, opts ...ProvideOption) {
+	if err := Provide(constructor, opts...); err != nil {
+		panic(err)
+	}
+}
+
+func parseName(value string) (string, bool) {
+	name, option, _ := strings.Cut(value, ",")
+	return name, option == "optional"
+}
+
+func (c *container) add(p *provider) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if p.name != "" {
+		if existing, b := c.named[p.name]; b {
+			if existing.constructor.IsValid() || p.constructor.IsValid() {
+				return fmt.Errorf("provided two instances named %s", p.name)
+			}
+			// InjectValue 重新绑定同名实例
+			c.remove(existing)
+		}
+		c.named[p.name] = p
+	}
+	c.providers = append(c.providers, p)
+	return nil
+}
+
+func (c *container) remove(p *provider) {
+	for i, existing := range c.providers {
+		if existing == p {
+			c.providers = append(c.providers[:i], c.providers[i+1:]...)
+			return
+		}
+	}
+}
+
+// InjectValue 的实例同时可按类型获取
+func (c *container) addValue(name string, value any) {
+	if value == nil {
+		return
+	}
+	_ = c.add(&provider{
+		name:     name,
+		typ:      reflect.TypeOf(value),
+		instance: value,
+		built:    true,
+	})
+}
+
+// 按类型查找：类型一致或显式绑定的接口；接口类型无显式绑定时按可赋值查找
+func (c *container) lookup(typ reflect.Type) []*provider {
+	c.RLock()
+	defer c.RUnlock()
+
+	var result []*provider
+	for _, p := range c.providers {
+		if p.typ == typ {
+			result = append(result, p)
+			continue
+		}
+		for _, binding := range p.bindings {
+			if binding == typ {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+
+	if len(result) == 0 && typ.Kind() == reflect.Interface {
+		for _, p := range c.providers {
+			if p.typ.Implements(typ) {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
+func (c *container) find(typ reflect.Type, name string) (*provider, error) {
+	if name != "" {
+		c.RLock()
+		p, b := c.named[name]
+		c.RUnlock()
+		if !b {
+			return nil, fmt.Errorf("%w: named %s", ErrNotFound, name)
+		}
+		if typ != nil && !p.typ.AssignableTo(typ) {
+			return nil, fmt.Errorf("%s is not assignable to %s", p, typ)
+		}
+		return p, nil
+	}
+
+	candidates := c.lookup(typ)
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, typ)
+	case 1:
+		return candidates[0], nil
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, p := range candidates {
+			names = append(names, p.String())
+		}
+		return nil, fmt.Errorf("%w for %s: %s", ErrAmbiguous, typ, strings.Join(names, ", "))
+	}
+}
+
+// 解析过程中的依赖链，用于检测循环依赖
+type resolving []*provider
+
+func (r resolving) String() string {
+	names := make([]string, 0, len(r))
+	for _, p := range r {
+		names = append(names, p.String())
+	}
+	return strings.Join(names, " -> ")
+}
+
+func (c *container) resolve(typ reflect.Type, name string, scope *Scope, chain resolving) (any, error) {
+	p, err := c.find(typ, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.instance(p, scope, chain)
+}
+
+func (c *container) instance(p *provider, scope *Scope, chain resolving) (any, error) {
+	for _, existing := range chain {
+		if existing == p {
+			return nil, fmt.Errorf("dependency cycle: %s -> %s", chain, p)
+		}
+	}
+	chain = append(chain, p)
+
+	switch p.scope {
+	case SCOPE_PROTOTYPE:
+		return c.build(p, scope, chain)
+	case SCOPE_REQUEST:
+		if scope == nil {
+			return nil, fmt.Errorf("%s is request scoped but no scope is active", p)
+		}
+		return scope.get(p, func() (any, error) {
+			return c.build(p, scope, chain)
+		})
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.built {
+		value, err := c.build(p, scope, chain)
+		if err != nil {
+			return nil, err
+		}
+		p.instance, p.built = value, true
+
+		if p.name != "" {
+			// 命名单例同时供 inject:"name" 注入
+			populate(p.name, value)
+		}
+	}
+	return p.instance, nil
+}
+
+func (c *container) build(p *provider, scope *Scope, chain resolving) (any, error) {
+	ft := p.constructor.Type()
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		var name string
+		if i < len(p.params) {
+			name = p.params[i]
+		}
+
+		value, err := c.resolve(ft.In(i), name, scope, chain)
+		if err != nil {
+			if p.optional[i] && errors.Is(err, ErrNotFound) {
+				args[i] = reflect.Zero(ft.In(i))
+				continue
+			}
+			return nil, fmt.Errorf("%s: parameter %d: %w", p, i, err)
+		}
+		if value == nil {
+			args[i] = reflect.Zero(ft.In(i))
+		} else {
+			args[i] = reflect.ValueOf(value)
+		}
+	}
+
+	out := p.constructor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, fmt.Errorf("%s: %w", p, out[1].Interface().(error))
+	}
+
+	value := out[0].Interface()
+	if err := c.injectFields(value, scope, chain); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// 为构造器创建的实例注入 inject 标签字段
+func (c *container) injectFields(value any, scope *Scope, chain resolving) error {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !isStructPtr(v.Type()) || v.IsNil() {
+		return nil
+	}
+
+	for i := 0; i < v.Elem().NumField(); i++ {
+		field := v.Elem().Field(i)
+		typeField := v.Type().Elem().Field(i)
+		tag, err := parseTag(string(typeField.Tag))
+		if err != nil || tag == nil || tag.Inline || tag.Private || !field.CanSet() || !isNilOrZero(field, field.Type()) {
+			continue
+		}
+
+		dep, err := c.resolve(field.Type(), tag.Name, scope, chain)
+		if err != nil {
+			if (tag.Optional || tag.Name == "") && errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return fmt.Errorf("field %s in type %s: %w", typeField.Name, v.Type(), err)
+		}
+		field.Set(reflect.ValueOf(dep))
+	}
+
+	if iface, ok := value.(AfterInitialize); ok {
+		iface.AfterInitialize()
+	}
+	return nil
+}
+
+// 按类型获取实例
+func Get[T any]() (T, error) {
+	var result T
+	value, err := theContainer.resolve(reflect.TypeOf((*T)(nil)).Elem(), "", currentScope(), nil)
+	if err == nil {
+		result, _ = value.(T)
+	}
+	return result, err
+}
+
+// 按名称获取实例
+func GetNamed[T any](name string) (T, error) {
+	var result T
+	value, err := theContainer.resolve(reflect.TypeOf((*T)(nil)).Elem(), name, currentScope(), nil)
+	if err == nil {
+		result, _ = value.(T)
+	}
+	return result, err
+}
+
+func MustGet[T any]() T {
+	result, err := Get[T]()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// 启动校验报告
+type Report struct {
+	Unsatisfied []string `json:"unsatisfied,omitempty"`
+	Ambiguous   []string `json:"ambiguous,omitempty"`
+	Cycles      []string `json:"cycles,omitempty"`
+	Failed      []string `json:"failed,omitempty"`
+}
+
+func (r *Report) empty() bool {
+	return len(r.Unsatisfied)+len(r.Ambiguous)+len(r.Cycles)+len(r.Failed) == 0
+}
+
+func (r *Report) Error() string {
+	var sb strings.Builder
+	sb.WriteString("dependency injection validation failed")
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{"unsatisfied", r.Unsatisfied},
+		{"ambiguous", r.Ambiguous},
+		{"cycles", r.Cycles},
+		{"failed", r.Failed},
+	} {
+		for _, item := range section.items {
+			fmt.Fprintf(&sb, "\n  [%s] %s", section.title, item)
+		}
+	}
+	return sb.String()
+}
+
+func (r *Report) merge(other *Report) {
+	r.Unsatisfied = append(r.Unsatisfied, other.Unsatisfied...)
+	r.Ambiguous = append(r.Ambiguous, other.Ambiguous...)
+	r.Cycles = append(r.Cycles, other.Cycles...)
+	r.Failed = append(r.Failed, other.Failed...)
+}
+
+func (r *Report) add(owner string, err error) {
+	message := owner + ": " + err.Error()
+	switch {
+	case errors.Is(err, ErrNotFound):
+		r.Unsatisfied = append(r.Unsatisfied, message)
+	case errors.Is(err, ErrAmbiguous):
+		r.Ambiguous = append(r.Ambiguous, message)
+	default:
+		r.Failed = append(r.Failed, message)
+	}
+}
+
+// 校验全部依赖：构造器参数、inject 标签字段与循环依赖，并创建全部单例
+func Validate() error {
+	return validate(true)
+}
+
+// strict 为 false 时，inject 标签字段缺失只记录警告
+func validate(strict bool) error {
+	report, fields := check()
+	if !fields.empty() {
+		if strict {
+			report.merge(fields)
+		} else {
+			logger.Warn(fields.Error())
+		}
+	}
+	if !report.empty() {
+		return report
+	}
+
+	// 创建单例，暴露构造错误
+	c := theContainer
+	c.RLock()
+	providers := append([]*provider(nil), c.providers...)
+	c.RUnlock()
+
+	for _, p := range providers {
+		if p.scope == SCOPE_SINGLETON {
+			if _, err := c.instance(p, nil, nil); err != nil {
+				report.add(p.String(), err)
+			}
+		}
+	}
+
+	if !report.empty() {
+		return report
+	}
+	return nil
+}
+
+func check() (*Report, *Report) {
+	report, fields := &Report{}, &Report{}
+	c := theContainer
+
+	c.RLock()
+	providers := append([]*provider(nil), c.providers...)
+	c.RUnlock()
+
+	// 1. 构造器参数与循环依赖
+	visiting := make(map[*provider]int)
+	var visit func(p *provider, chain resolving)
+	visit = func(p *provider, chain resolving) {
+		switch visiting[p] {
+		case 1:
+			report.Cycles = append(report.Cycles, fmt.Sprintf("%s -> %s", chain, p))
+			return
+		case 2:
+			return
+		}
+		if !p.constructor.IsValid() {
+			return
+		}
+
+		visiting[p] = 1
+		chain = append(chain, p)
+		ft := p.constructor.Type()
+		for i := 0; i < ft.NumIn(); i++ {
+			var name string
+			if i < len(p.params) {
+				name = p.params[i]
+			}
+
+			dep, err := c.find(ft.In(i), name)
+			if err != nil {
+				if !(p.optional[i] && errors.Is(err, ErrNotFound)) {
+					report.add(fmt.Sprintf("%s parameter %d", p, i), err)
+				}
+				continue
+			}
+			visit(dep, chain)
+		}
+		visiting[p] = 2
+	}
+	for _, p := range providers {
+		visit(p, nil)
+	}
+
+	// 2. inject 标签的命名字段
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := values[name]
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Struct && hasTags(v.Type()) {
+			// 结构体值无法被注入
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: value of type %s must be a pointer to be injected", name, v.Type()))
+			continue
+		}
+		validateFields(fields, name, value)
+	}
+
+	return report, fields
+}
+
+func validateFields(report *Report, name string, value any) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.Elem().NumField(); i++ {
+		field := v.Elem().Field(i)
+		typeField := v.Type().Elem().Field(i)
+		tag, err := parseTag(string(typeField.Tag))
+		if err != nil || tag == nil || tag.Name == "" || tag.Optional {
+			continue
+		}
+		if !isNilOrZero(field, field.Type()) {
+			continue
+		}
+
+		if _, err := theContainer.find(field.Type(), tag.Name); err != nil {
+			report.add(fmt.Sprintf("%s (%s) field %s", name, v.Type(), typeField.Name), err)
+		}
+	}
+}
+
+func hasTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if tag, _ := parseTag(string(t.Field(i).Tag)); tag != nil {
+			return true
+		}
+	}
+	return false
+}
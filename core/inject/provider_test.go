@@ -0,0 +1,35 @@
+package inject
+
+import (
+	"strings"
+	"testing"
+)
+
+type testDependency struct {
+	Name string
+}
+
+func TestValidateStrict(t *testing.T) {
+	InjectValue("test.optional", &struct {
+		Dependency *testDependency `inject:"test.dependency,optional"`
+	}{})
+	if err := validate(true); err != nil {
+		t.Fatalf("optional miss should pass: %v", err)
+	}
+
+	InjectValue("test.required", &struct {
+		Dependency *testDependency `inject:"test.dependency"`
+	}{})
+	err := validate(true)
+	if err == nil || !strings.Contains(err.Error(), "test.required") {
+		t.Fatalf("expected unsatisfied report, got %v", err)
+	}
+	if err := validate(false); err != nil {
+		t.Fatalf("non-strict should only warn: %v", err)
+	}
+
+	InjectValue("test.dependency", &testDependency{Name: "dep"})
+	if err := validate(true); err != nil {
+		t.Fatalf("satisfied dependency should pass: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package inject
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/gophab/gophrame/core/context"
+)
+
+const scopeContextKey = "_inject_scope_"
+
+// 请求作用域：保存 SCOPE_REQUEST 实例，结束时关闭实现 io.Closer 的实例
+type Scope struct {
+	sync.Mutex
+	instances map[*provider]any
+	order     []any
+}
+
+func NewScope() *Scope {
+	return &Scope{instances: make(map[*provider]any)}
+}
+
+func (s *Scope) get(p *provider, create func() (any, error)) (any, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if value, b := s.instances[p]; b {
+		return value, nil
+	}
+
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+	s.instances[p] = value
+	s.order = append(s.order, value)
+	return value, nil
+}
+
+// 按创建的逆序关闭实例
+func (s *Scope) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	var errs []error
+	for i := len(s.order) - 1; i >= 0; i-- {
+		if closer, ok := s.order[i].(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	s.instances, s.order = make(map[*provider]any), nil
+	return errors.Join(errs...)
+}
+
+// 在当前协程开始请求作用域
+func BeginScope() *Scope {
+	scope := NewScope()
+	context.SetContextValue(scopeContextKey, scope)
+	return scope
+}
+
+// 结束当前协程的请求作用域
+func EndScope() error {
+	if scope := currentScope(); scope != nil {
+		context.RemoveContextValue(scopeContextKey)
+		return scope.Close()
+	}
+	return nil
+}
+
+func currentScope() *Scope {
+	if scope, ok := context.GetContextValue(scopeContextKey).(*Scope); ok {
+		return scope
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package inject
 
 import (
+	"github.com/gophab/gophrame/core/inject/config"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/starter"
 )
 
 type AfterInitialize interface {
@@ -11,21 +13,29 @@ type AfterInitialize interface {
 var values = make(map[string]any)
 var graph Graph
 
-// 初始化依赖注入
-func Init() {
-	for _, value := range values {
-		_ = graph.Provide(&Object{Value: value})
-	}
+func init() {
+	// 全部初始化器执行完成后校验
+	starter.RegisterInitializorFunc(Init, 0x7FFFFFFF)
+}
 
-	if err := graph.Populate(); err != nil {
+// 校验依赖注入，存在未满足、歧义或循环依赖时返回完整报告
+func Init() error {
+	if err := validate(config.Setting.Strict); err != nil {
 		logger.Error("初始化依赖注入发生错误：", err.Error())
+		return err
 	}
+	return nil
 }
 
-func InjectValue(key string, value any) {
+func populate(key string, value any) {
 	_ = graph.Provide(&Object{Name: key, Value: value})
 	_ = graph.Populate()
 	values[key] = value
+}
+
+func InjectValue(key string, value any) {
+	populate(key, value)
+	theContainer.addValue(key, value)
 
 	if iface, ok := value.(AfterInitialize); ok {
 		iface.AfterInitialize()
@@ -34,8 +44,17 @@ func InjectValue(key string, value any) {
 
 func InjectValue_(key string, value any) {
 	values[key] = value
+	theContainer.addValue(key, value)
 }
 
 func GetValue(key string) any {
-	return values[key]
+	if value, b := values[key]; b {
+		return value
+	}
+
+	// 尚未创建的命名单例
+	if value, err := theContainer.resolve(nil, key, currentScope(), nil); err == nil {
+		return value
+	}
+	return nil
 }
@@ -1,15 +1,29 @@
 package config
 
-import "github.com/gophab/gophrame/core/config"
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+)
 
 type LogSetting struct {
-	LogName       string `json:"logName" yaml:"logName"`
-	TextFormat    string `json:"textFormat" yaml:"textFormat"`
-	TimePrecision string `json:"timePrecision" yaml:"timePrecision"`
-	MaxSize       int    `json:"maxSize" yaml:"maxSize"`
-	MaxBackups    int    `json:"maxBackups" yaml:"maxBackups"`
-	MaxAge        int    `json:"maxAge" yaml:"maxAge"`
-	Compress      bool   `json:"compress" yaml:"compress"`
+	LogName       string            `json:"logName" yaml:"logName"`
+	TextFormat    string            `json:"textFormat" yaml:"textFormat"` // console|json
+	TimePrecision string            `json:"timePrecision" yaml:"timePrecision"`
+	MaxSize       int               `json:"maxSize" yaml:"maxSize"`
+	MaxBackups    int               `json:"maxBackups" yaml:"maxBackups"`
+	MaxAge        int               `json:"maxAge" yaml:"maxAge"`
+	Compress      bool              `json:"compress" yaml:"compress"`
+	Level         string            `json:"level" yaml:"level"`
+	Levels        map[string]string `json:"levels" yaml:"levels"`
+	Output        string            `json:"output" yaml:"output"` // console|file|both
+	Sampling      *SamplingSetting  `json:"sampling" yaml:"sampling"`
+}
+
+type SamplingSetting struct {
+	Tick       time.Duration `json:"tick" yaml:"tick"`
+	Initial    int           `json:"initial" yaml:"initial"`
+	Thereafter int           `json:"thereafter" yaml:"thereafter"`
 }
 
 var Setting *LogSetting = &LogSetting{}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// 控制台格式: 2006/01/02 15:04:05 [INFO] file.go:12: message key=value ...
+type consoleHandler struct {
+	mu         *sync.Mutex
+	writer     io.Writer
+	timeFormat string
+	prefix     string // WithGroup 产生的键前缀
+	attrs      []byte // WithAttrs 预先格式化的字段
+}
+
+func newConsoleHandler(writer io.Writer, timeFormat string) *consoleHandler {
+	return &consoleHandler{mu: new(sync.Mutex), writer: writer, timeFormat: timeFormat}
+}
+
+func (h *consoleHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	buf.WriteString(r.Time.Format(h.timeFormat))
+	buf.WriteString(" [")
+	buf.WriteString(levelName(r.Level))
+	buf.WriteString("] ")
+
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		fmt.Fprintf(&buf, "%s:%d: ", filepath.Base(frame.File), frame.Line)
+	}
+
+	buf.WriteString(r.Message)
+	buf.Write(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&buf, h.prefix, a)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.writer.Write(buf.Bytes())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var buf bytes.Buffer
+	buf.Write(h.attrs)
+	for _, a := range attrs {
+		appendAttr(&buf, h.prefix, a)
+	}
+	return &consoleHandler{mu: h.mu, writer: h.writer, timeFormat: h.timeFormat, prefix: h.prefix, attrs: buf.Bytes()}
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &consoleHandler{mu: h.mu, writer: h.writer, timeFormat: h.timeFormat, prefix: h.prefix + name + ".", attrs: h.attrs}
+}
+
+func appendAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(buf, prefix, ga)
+		}
+		return
+	}
+
+	fmt.Fprintf(buf, " %s%s=%v", prefix, a.Key, a.Value.Any())
+}
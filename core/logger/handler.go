@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+
+	GlobalContext "github.com/gophab/gophrame/core/context"
+)
+
+const LevelFatal = slog.Level(12)
+
+// 请求上下文中的日志字段，由 core/controller 中间件写入
+const (
+	ContextKeyRequestId = "_REQUEST_ID_"
+	ContextKeyCurrent   = "_current_context_"
+	ContextKeyUserId    = "_CURRENT_USER_ID_"
+	ContextKeyTenantId  = "_CURRENT_TENANT_ID_"
+)
+
+// 请求上下文（*gin.Context）只需要 Value 方法
+type valuer interface {
+	Value(key any) any
+}
+
+// Handler 在底层输出之前完成：按包级别过滤、热点采样、附加请求上下文字段
+type Handler struct {
+	next    slog.Handler
+	levels  *levelRegistry
+	sampler *sampler
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.minimum()
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.levelOf(r.PC) {
+		return nil
+	}
+
+	if h.sampler != nil && r.Level < slog.LevelWarn && !h.sampler.allow(r.Level, r.Message) {
+		return nil
+	}
+
+	r.AddAttrs(contextAttrs()...)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs), levels: h.levels, sampler: h.sampler}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), levels: h.levels, sampler: h.sampler}
+}
+
+func contextAttrs() []slog.Attr {
+	var attrs []slog.Attr
+	if v, ok := GlobalContext.GetContextValue(ContextKeyRequestId).(string); ok && v != "" {
+		attrs = append(attrs, slog.String("request_id", v))
+	}
+
+	if c, ok := GlobalContext.GetContextValue(ContextKeyCurrent).(valuer); ok && c != nil {
+		if v, ok := c.Value(ContextKeyUserId).(string); ok && v != "" {
+			attrs = append(attrs, slog.String("user_id", v))
+		}
+		if v, ok := c.Value(ContextKeyTenantId).(string); ok && v != "" {
+			attrs = append(attrs, slog.String("tenant_id", v))
+		}
+	}
+	return attrs
+}
+
+func replaceLevelAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelName(level))
+		}
+	}
+	return a
+}
+
+func levelName(level slog.Level) string {
+	switch {
+	case level >= LevelFatal:
+		return "FATAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// 包级别设置，按最长包路径前缀匹配
+type levelRegistry struct {
+	sync.RWMutex
+	defaultLevel slog.Level            // 生效的默认级别
+	packages     map[string]slog.Level // 生效的包级别
+	min          slog.Level
+	cache        sync.Map // pc -> package path
+
+	configured slog.Level            // 配置中的默认级别
+	configPkgs map[string]slog.Level // 配置中的包级别
+	overrides  map[string]slog.Level // 运行时设置，重新加载配置后保留；键 "" 为默认级别
+}
+
+func newLevelRegistry() *levelRegistry {
+	return &levelRegistry{
+		packages:   make(map[string]slog.Level),
+		configPkgs: make(map[string]slog.Level),
+		overrides:  make(map[string]slog.Level),
+	}
+}
+
+func (r *levelRegistry) reset(defaultLevel slog.Level, packages map[string]slog.Level) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.configured = defaultLevel
+	r.configPkgs = packages
+	r.rebuild()
+}
+
+func (r *levelRegistry) set(pkg string, level slog.Level) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.overrides[pkg] = level
+	r.rebuild()
+}
+
+func (r *levelRegistry) remove(pkg string) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.overrides, pkg)
+	r.rebuild()
+}
+
+// 运行时设置优先于配置
+func (r *levelRegistry) rebuild() {
+	r.defaultLevel = r.configured
+	if level, b := r.overrides[""]; b {
+		r.defaultLevel = level
+	}
+
+	r.packages = make(map[string]slog.Level, len(r.configPkgs)+len(r.overrides))
+	for pkg, level := range r.configPkgs {
+		r.packages[pkg] = level
+	}
+	for pkg, level := range r.overrides {
+		if pkg != "" {
+			r.packages[pkg] = level
+		}
+	}
+	r.updateMinimum()
+}
+
+func (r *levelRegistry) updateMinimum() {
+	r.min = r.defaultLevel
+	for _, level := range r.packages {
+		if level < r.min {
+			r.min = level
+		}
+	}
+}
+
+func (r *levelRegistry) minimum() slog.Level {
+	r.RLock()
+	defer r.RUnlock()
+	return r.min
+}
+
+func (r *levelRegistry) snapshot() map[string]string {
+	r.RLock()
+	defer r.RUnlock()
+
+	result := map[string]string{"": levelName(r.defaultLevel)}
+	for pkg, level := range r.packages {
+		result[pkg] = levelName(level)
+	}
+	return result
+}
+
+func (r *levelRegistry) levelOf(pc uintptr) slog.Level {
+	r.RLock()
+	defer r.RUnlock()
+
+	if len(r.packages) == 0 || pc == 0 {
+		return r.defaultLevel
+	}
+
+	pkg := packageOf(&r.cache, pc)
+	level, matched := r.defaultLevel, 0
+	for prefix, l := range r.packages {
+		if len(prefix) > matched && (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) {
+			level, matched = l, len(prefix)
+		}
+	}
+	return level
+}
+
+// github.com/gophab/gophrame/core/config.(*ymlConfig).Get => github.com/gophab/gophrame/core/config
+func packageOf(cache *sync.Map, pc uintptr) string {
+	if v, ok := cache.Load(pc); ok {
+		return v.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := frame.Function
+	slash := strings.LastIndex(pkg, "/")
+	if dot := strings.Index(pkg[slash+1:], "."); dot >= 0 {
+		pkg = pkg[:slash+1+dot]
+	}
+
+	cache.Store(pc, pkg)
+	return pkg
+}
@@ -1,67 +1,261 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gophab/gophrame/core/global"
 
 	"github.com/astaxie/beego/validation"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Logger *log.Logger
-var once sync.Once
+// 日志输出选项，由 logger/starter 根据 log 配置节点生成
+type Options struct {
+	Level         string            // 默认级别: debug|info|warn|error
+	Levels        map[string]string // 按包路径前缀设置级别: github.com/gophab/gophrame/core/config: warn
+	Format        string            // console|json
+	TimePrecision string            // second|millisecond
+	Output        string            // console|file|both
+	File          string            // 输出文件，Output 为 file|both 时有效
+	MaxSize       int               // 单个文件最大尺寸(MB)
+	MaxBackups    int
+	MaxAge        int // 保留天数
+	Compress      bool
+	Sampling      *SamplingOptions
+}
+
+// 热点日志采样: 每个 Tick 周期内同级别同消息前 Initial 条全部输出，之后每 Thereafter 条输出一条
+// 仅对 Info 及以下级别生效
+type SamplingOptions struct {
+	Tick       time.Duration
+	Initial    int
+	Thereafter int
+}
+
+var current atomic.Pointer[slog.Logger]
+
+// 兼容原有使用方式，始终输出到当前配置的日志，重新配置时不替换
+var Logger *log.Logger = slog.NewLogLogger(currentHandler{}, slog.LevelInfo)
+
+var (
+	mutex sync.Mutex
+	file  *fileWriter // 当前日志文件，重新配置后关闭
+)
+
+// 级别设置在 Configure 之间共享，支持运行时调整
+var levels = newLevelRegistry()
 
 func init() {
-	once.Do(func() {
-		if global.Debug {
-			Logger = log.New(os.Stdout, "[DEBUG]\t", log.LstdFlags|log.Llongfile)
+	// 配置加载前输出全部日志
+	_ = configure(&Options{Level: "debug"}, os.Stdout)
+}
+
+// Configure 根据选项重建日志输出
+func Configure(opts *Options) error {
+	var writer io.Writer = os.Stdout
+	var next *fileWriter
+	switch opts.Output {
+	case "file", "both":
+		if opts.File == "" {
+			return fmt.Errorf("log file not specified")
+		}
+		next = &fileWriter{file: &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSize,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAge,
+			Compress:   opts.Compress,
+		}}
+		if opts.Output == "file" {
+			writer = next
 		} else {
-			Logger = log.New(os.Stdout, "[INFO]\t", log.LstdFlags|log.Lshortfile)
+			writer = io.MultiWriter(os.Stdout, next)
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if err := configure(opts, writer); err != nil {
+		if next != nil {
+			next.Close()
 		}
-	})
+		return err
+	}
+
+	if file != nil {
+		file.Close()
+	}
+	file = next
+	return nil
+}
+
+// 关闭后丢弃写入，避免切换配置时仍在输出的日志重新打开旧文件
+type fileWriter struct {
+	mutex  sync.Mutex
+	closed bool
+	file   *lumberjack.Logger
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return len(p), nil
+	}
+	return w.file.Write(p)
+}
+
+func (w *fileWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	return w.file.Close()
+}
+
+// 转发到当前日志对象
+type currentHandler struct{}
+
+func (currentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return current.Load().Handler().Enabled(ctx, level)
+}
+
+func (currentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return current.Load().Handler().Handle(ctx, r)
+}
+
+func (currentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return current.Load().Handler().WithAttrs(attrs)
+}
+
+func (currentHandler) WithGroup(name string) slog.Handler {
+	return current.Load().Handler().WithGroup(name)
+}
+
+func configure(opts *Options, writer io.Writer) error {
+	defaultLevel := slog.LevelInfo
+	if opts.Level != "" {
+		if err := defaultLevel.UnmarshalText([]byte(opts.Level)); err != nil {
+			return err
+		}
+	} else if global.Debug {
+		defaultLevel = slog.LevelDebug
+	}
+
+	packageLevels := make(map[string]slog.Level)
+	for pkg, text := range opts.Levels {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(text)); err != nil {
+			return fmt.Errorf("invalid log level for %s: %w", pkg, err)
+		}
+		packageLevels[pkg] = level
+	}
+	levels.reset(defaultLevel, packageLevels)
+
+	timeFormat := "2006/01/02 15:04:05"
+	if opts.TimePrecision == "millisecond" {
+		timeFormat = "2006/01/02 15:04:05.000"
+	}
+
+	var next slog.Handler
+	if opts.Format == "json" {
+		next = slog.NewJSONHandler(writer, &slog.HandlerOptions{
+			AddSource:   true,
+			Level:       slog.LevelDebug - 4, // 级别由 Handler 统一过滤
+			ReplaceAttr: replaceLevelAttr,
+		})
+	} else {
+		next = newConsoleHandler(writer, timeFormat)
+	}
+
+	var handler slog.Handler = &Handler{next: next, levels: levels}
+	if opts.Sampling != nil && opts.Sampling.Thereafter > 0 {
+		handler.(*Handler).sampler = newSampler(opts.Sampling)
+	}
+
+	current.Store(slog.New(handler))
+	return nil
+}
+
+// Slog 返回结构化日志对象，用于输出带字段的日志
+func Slog() *slog.Logger {
+	return current.Load()
+}
+
+// SetLevel 运行时调整日志级别，pkg 为空时调整默认级别
+func SetLevel(pkg string, level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	levels.set(pkg, l)
+	return nil
+}
+
+// ResetLevel 撤销运行时设置，恢复配置中的级别
+func ResetLevel(pkg string) {
+	levels.remove(pkg)
+}
+
+// Levels 返回当前级别设置，默认级别的键为 ""
+func Levels() map[string]string {
+	return levels.snapshot()
+}
+
+func output(level slog.Level, args ...any) {
+	l := current.Load()
+	if !l.Enabled(context.Background(), level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip [Callers, output, Info]
+	r := slog.NewRecord(time.Now(), level, strings.TrimSuffix(fmt.Sprintln(args...), "\n"), pcs[0])
+	_ = l.Handler().Handle(context.Background(), r)
 }
 
 func MarkErrors(errors []*validation.Error) {
 	for _, err := range errors {
-		Info(err.Key, err.Message)
+		output(slog.LevelInfo, err.Key, err.Message)
 	}
 }
 
 // Info 详情
 func Info(args ...any) {
-	Logger.SetPrefix("[INFO]\t")
-	Logger.Output(2, fmt.Sprintln(args...))
+	output(slog.LevelInfo, args...)
 }
 
 // Danger 错误 为什么不命名为 error？避免和 error 类型重名
 func Danger(args ...any) {
-	Logger.SetPrefix("[ERROR]\t")
-	Logger.Output(2, fmt.Sprintln(args...))
+	output(slog.LevelError, args...)
 	os.Exit(1)
 }
 
 // Warn 警告
 func Warn(args ...any) {
-	Logger.SetPrefix("[WARNING]\t")
-	Logger.Output(2, fmt.Sprintln(args...))
+	output(slog.LevelWarn, args...)
 }
 
 // Debug debug
 func Debug(args ...any) {
-	Logger.SetPrefix("[DEBUG]\t")
-	Logger.Output(2, fmt.Sprintln(args...))
+	output(slog.LevelDebug, args...)
 }
 
 func Error(args ...any) {
-	Logger.SetPrefix("[ERROR]\t")
-	Logger.Output(2, fmt.Sprintln(args...))
+	output(slog.LevelError, args...)
 }
 
 func Fatal(args ...any) {
-	Logger.SetPrefix("[FATAL]\t")
-	Logger.Output(2, fmt.Sprintln(args...))
+	output(LevelFatal, args...)
 	os.Exit(1)
 }
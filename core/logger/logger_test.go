@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeLevelSurvivesReload(t *testing.T) {
+	t.Cleanup(func() {
+		ResetLevel("")
+		ResetLevel("github.com/gophab/gophrame/core/config")
+		_ = configure(&Options{Level: "debug"}, os.Stdout)
+	})
+
+	if err := configure(&Options{Level: "info", Levels: map[string]string{"github.com/gophab/gophrame/core/config": "warn"}}, os.Stdout); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLevel("github.com/gophab/gophrame/core/config", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLevel("", "error"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新加载配置不覆盖运行时设置
+	if err := configure(&Options{Level: "info", Levels: map[string]string{"github.com/gophab/gophrame/core/config": "warn"}}, os.Stdout); err != nil {
+		t.Fatal(err)
+	}
+	levels := Levels()
+	if levels[""] != "ERROR" || levels["github.com/gophab/gophrame/core/config"] != "DEBUG" {
+		t.Fatalf("runtime levels lost after reload: %v", levels)
+	}
+
+	// 撤销后恢复配置中的级别
+	ResetLevel("github.com/gophab/gophrame/core/config")
+	ResetLevel("")
+	levels = Levels()
+	if levels[""] != "INFO" || levels["github.com/gophab/gophrame/core/config"] != "WARNING" {
+		t.Fatalf("configured levels not restored: %v", levels)
+	}
+}
+
+func TestReconfigureClosesLogFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		_ = Configure(&Options{Level: "debug"})
+	})
+
+	first := filepath.Join(dir, "first.log")
+	if err := Configure(&Options{Level: "info", Output: "file", File: first}); err != nil {
+		t.Fatal(err)
+	}
+	Info("to first")
+	Logger.Print("legacy to first")
+	previous := file
+
+	second := filepath.Join(dir, "second.log")
+	if err := Configure(&Options{Level: "info", Output: "file", File: second}); err != nil {
+		t.Fatal(err)
+	}
+	if !previous.closed {
+		t.Fatal("previous log file not closed")
+	}
+	Info("to second")
+	Logger.Print("legacy to second")
+
+	// 旧文件关闭后的写入被丢弃
+	_, _ = previous.Write([]byte("late write\n"))
+
+	data, _ := os.ReadFile(first)
+	if !strings.Contains(string(data), "to first") || !strings.Contains(string(data), "legacy to first") || strings.Contains(string(data), "second") || strings.Contains(string(data), "late") {
+		t.Fatalf("unexpected first log: %s", data)
+	}
+	data, _ = os.ReadFile(second)
+	if !strings.Contains(string(data), "to second") || !strings.Contains(string(data), "legacy to second") {
+		t.Fatalf("unexpected second log: %s", data)
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type sampleCounter struct {
+	window int64
+	count  int
+}
+
+// 按 (级别, 消息) 计数的采样器
+type sampler struct {
+	sync.Mutex
+	tick       time.Duration
+	initial    int
+	thereafter int
+	counters   map[string]*sampleCounter
+}
+
+func newSampler(opts *SamplingOptions) *sampler {
+	s := &sampler{
+		tick:       opts.Tick,
+		initial:    opts.Initial,
+		thereafter: opts.Thereafter,
+		counters:   make(map[string]*sampleCounter),
+	}
+	if s.tick <= 0 {
+		s.tick = time.Second
+	}
+	return s
+}
+
+func (s *sampler) allow(level slog.Level, message string) bool {
+	window := time.Now().UnixNano() / int64(s.tick)
+	key := level.String() + "|" + message
+
+	s.Lock()
+	defer s.Unlock()
+
+	counter, b := s.counters[key]
+	if !b || counter.window != window {
+		// 新周期：清理过期计数，避免 map 无限增长
+		if !b && len(s.counters) > 10000 {
+			for k, c := range s.counters {
+				if c.window != window {
+					delete(s.counters, k)
+				}
+			}
+		}
+		counter = &sampleCounter{window: window}
+		s.counters[key] = counter
+	}
+
+	counter.count++
+	if counter.count <= s.initial {
+		return true
+	}
+	return (counter.count-s.initial)%s.thereafter == 0
+}
@@ -0,0 +1,42 @@
+package starter
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+	LogConfig "github.com/gophab/gophrame/core/logger/config"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+func init() {
+	starter.RegisterInitializorEx(Init, -0x0FFFFFFF)
+	config.RegisterConfigChangeCallback(Init)
+}
+
+func Init() {
+	setting := LogConfig.Setting
+
+	opts := &logger.Options{
+		Level:         setting.Level,
+		Levels:        setting.Levels,
+		Format:        setting.TextFormat,
+		TimePrecision: setting.TimePrecision,
+		Output:        setting.Output,
+		File:          setting.LogName,
+		MaxSize:       setting.MaxSize,
+		MaxBackups:    setting.MaxBackups,
+		MaxAge:        setting.MaxAge,
+		Compress:      setting.Compress,
+	}
+
+	if setting.Sampling != nil {
+		opts.Sampling = &logger.SamplingOptions{
+			Tick:       setting.Sampling.Tick,
+			Initial:    setting.Sampling.Initial,
+			Thereafter: setting.Sampling.Thereafter,
+		}
+	}
+
+	if err := logger.Configure(opts); err != nil {
+		logger.Error("Configure logger error: ", err.Error())
+	}
+}
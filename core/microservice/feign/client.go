@@ -1,45 +1,97 @@
 package feign
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/gophab/gophrame/core/feign"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/microservice/registry"
-	"github.com/gophab/gophrame/core/microservice/registry/config"
 
 	"github.com/patrickmn/go-cache"
 )
 
+// 按服务名解析请求地址：每次请求经负载均衡选择实例
+// 重试只由 feign 的重试拦截器负责，重试时排除本次调用已失败的实例
 type RegistryFeignClientInterceptor struct {
-	RegistryClient registry.Client
+	RegistryClient registry.Client `inject:"registryClient,optional"`
 	Cache          *cache.Cache
 }
 
 func (in *RegistryFeignClientInterceptor) Do(chain *feign.FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*feign.RequestOptions) *feign.FeignClient {
-	if in.RegistryClient != nil {
-		url, err := url.Parse(urlPath)
-		if err == nil && url.Host != "" {
-			if v, b := in.getCache().Get(url.Host); b {
-				urlPrefix := v.(string)
-				if urlPrefix != "-" {
-					// - 标识非服务名，忽略
-					urlPath = urlPrefix + url.Path
-				}
-			} else {
-				// 假设Host为ServiceName
-				urlPrefix, _ := in.RegistryClient.GetServiceEntry(url.Host)
-				if urlPrefix != "" {
-					urlPath = urlPrefix + url.Path
-					in.getCache().SetDefault(url.Host, urlPrefix)
-				} else {
-					in.getCache().SetDefault(url.Host, "-")
-				}
-			}
+	client := in.client()
+	if client == nil {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	url, err := url.Parse(urlPath)
+	if err != nil || url.Host == "" {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	// - 标识非服务名，忽略
+	if _, b := in.getCache().Get(url.Host); b {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	// 假设Host为ServiceName
+	endpoint, err := client.Choose(url.Host, chain.Tried...)
+	if err != nil && len(chain.Tried) > 0 && !errors.Is(err, registry.ErrServiceNotFound) {
+		// 实例都已失败过，不再排除
+		endpoint, err = client.Choose(url.Host)
+	}
+	if err != nil {
+		if errors.Is(err, registry.ErrServiceNotFound) {
+			in.getCache().SetDefault(url.Host, "-")
+			return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+		}
+		return chain.Exit(fmt.Errorf("service %s: %w", url.Host, err))
+	}
+
+	result := in.call(chain, endpoint, method, endpoint.Url+url.Path, urlValues, bodyValue, options...)
+	if failed(result) {
+		result.Tried = append(result.Tried, endpoint.Id)
+	}
+	return result
+}
+
+func (in *RegistryFeignClientInterceptor) call(chain *feign.FeignClientInterceptorChain, endpoint *registry.Endpoint, method string, urlPath string, urlValues url.Values, bodyValue any, options ...*feign.RequestOptions) *feign.FeignClient {
+	endpoint.Start()
+	result := chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	if result.Error != nil {
+		endpoint.Done(result.Error)
+	} else if result.Response != nil && result.Response.StatusCode >= http.StatusInternalServerError {
+		endpoint.Done(fmt.Errorf("status %d", result.Response.StatusCode))
+	} else {
+		endpoint.Done(nil)
+	}
+	return result
+}
+
+// 连接错误与网关类错误，重试时换实例
+func failed(result *feign.FeignClient) bool {
+	if result.Error != nil {
+		return true
+	}
+	if result.Response != nil {
+		switch result.Response.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
 		}
 	}
-	return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	return false
+}
+
+func (in *RegistryFeignClientInterceptor) client() registry.Client {
+	if in.RegistryClient == nil {
+		if client, ok := inject.GetValue("registryClient").(registry.Client); ok {
+			in.RegistryClient = client
+		}
+	}
+	return in.RegistryClient
 }
 
 func (in *RegistryFeignClientInterceptor) getCache() *cache.Cache {
@@ -50,9 +102,8 @@ func (in *RegistryFeignClientInterceptor) getCache() *cache.Cache {
 }
 
 func init() {
-	if config.Setting.Enabled {
-		var interceptor = &RegistryFeignClientInterceptor{}
-		inject.InjectValue("registryFeignInterceptor", interceptor)
-		feign.RegisterGlobalFeignClientInterceptor(interceptor)
-	}
+	// 配置在 init 之后加载，未启用注册中心时 registryClient 不存在，请求原样发出
+	var interceptor = &RegistryFeignClientInterceptor{}
+	inject.InjectValue("registryFeignInterceptor", interceptor)
+	feign.RegisterGlobalFeignClientInterceptor(interceptor)
 }
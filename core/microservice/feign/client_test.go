@@ -0,0 +1,82 @@
+package feign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/core/feign"
+	FeignConfig "github.com/gophab/gophrame/core/feign/config"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/microservice/registry"
+)
+
+type testRegistry struct {
+	endpoints []*registry.Endpoint
+}
+
+func (r *testRegistry) GetServiceEntry(service string) (string, error) {
+	return r.endpoints[0].Url, nil
+}
+
+func (r *testRegistry) Choose(service string, excludes ...string) (*registry.Endpoint, error) {
+	for _, endpoint := range r.endpoints {
+		if !slices.Contains(excludes, endpoint.Id) {
+			return endpoint, nil
+		}
+	}
+	return nil, registry.ErrNoInstance
+}
+
+func testServer(status int, hits *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestSingleRetryLayer(t *testing.T) {
+	defer func(setting FeignConfig.FeignSetting) { *FeignConfig.Setting = setting }(*FeignConfig.Setting)
+	*FeignConfig.Setting = FeignConfig.FeignSetting{
+		"default": {Retry: &FeignConfig.RetrySetting{InitialInterval: time.Millisecond}},
+	}
+
+	var failedHits, okHits atomic.Int32
+	failed, ok := testServer(http.StatusServiceUnavailable, &failedHits), testServer(http.StatusOK, &okHits)
+	defer failed.Close()
+	defer ok.Close()
+
+	interceptor := inject.GetValue("registryFeignInterceptor").(*RegistryFeignClientInterceptor)
+	defer func(client registry.Client) { interceptor.RegistryClient = client }(interceptor.RegistryClient)
+
+	// 失败的实例在重试时被排除
+	interceptor.RegistryClient = &testRegistry{endpoints: []*registry.Endpoint{
+		{Service: "demo", Id: "a", Url: failed.URL},
+		{Service: "demo", Id: "b", Url: ok.URL},
+	}}
+	result := feign.NewClient().Get("http://demo/ping", nil)
+	if result.Response == nil || result.Response.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	result.Response.Body.Close()
+	if failedHits.Load() != 1 || okHits.Load() != 1 {
+		t.Fatalf("failed=%d ok=%d", failedHits.Load(), okHits.Load())
+	}
+
+	// 全部失败时总尝试次数等于重试配置，不叠加负载均衡重试
+	failedHits.Store(0)
+	interceptor.RegistryClient = &testRegistry{endpoints: []*registry.Endpoint{
+		{Service: "demo", Id: "a", Url: failed.URL},
+		{Service: "demo", Id: "c", Url: failed.URL},
+	}}
+	result = feign.NewClient().Get("http://demo/ping", nil)
+	if result.Response != nil {
+		result.Response.Body.Close()
+	}
+	if failedHits.Load() != int32(FeignConfig.Default.Retry.MaxAttempts) {
+		t.Fatalf("expected %d attempts, got %d", FeignConfig.Default.Retry.MaxAttempts, failedHits.Load())
+	}
+}
@@ -2,16 +2,20 @@ package microservice
 
 import (
 	"log"
-	"net"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
 
+	_ "github.com/gophab/gophrame/core/microservice/feign"
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/nacos"
+	NacosConfig "github.com/gophab/gophrame/core/microservice/registry/nacos/config"
+	_ "github.com/gophab/gophrame/core/microservice/registry/starter"
+
 	"github.com/nacos-group/nacos-sdk-go/clients"
 	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/common/constant"
-	"github.com/nacos-group/nacos-sdk-go/vo"
 )
 
 var app naming_client.INamingClient
@@ -20,6 +24,7 @@ func Application() naming_client.INamingClient {
 	return app
 }
 
+// Deprecated: 配置 microservice.registry.type 为 nacos，由注册中心启动器完成注册
 func Service(configs []constant.ServerConfig, serverName, group string, serverPort uint64) {
 	client, _ := clients.CreateNamingClient(map[string]any{
 		"serverConfigs": configs,
@@ -31,20 +36,19 @@ func Service(configs []constant.ServerConfig, serverName, group string, serverPo
 			NotLoadCacheAtStart: true,
 		},
 	})
-	ip := getIpAddr()
-	RegisterServiceInstance(client, vo.RegisterInstanceParam{
-		Ip:          ip,
-		Port:        serverPort,
+
+	setting := *NacosConfig.Setting
+	setting.Group = group
+	setting.Ephemeral = true
+	setting.Metadata = map[string]string{
+		"preserved.heart.beat.interval": strconv.Itoa(1000 * 10), //25s
+		"preserved.register.source":     "SPRING_CLOUD",
+	}
+
+	RegisterServiceInstance(nacos.NewNacosDiscoveryClient(client, &setting), &registry.InstanceInfo{
 		ServiceName: serverName,
-		Weight:      1,
-		GroupName:   group,
-		Enable:      true,
-		Healthy:     true,
-		Ephemeral:   true,
-		Metadata: map[string]string{
-			"preserved.heart.beat.interval": strconv.Itoa(1000 * 10), //25s
-			"preserved.register.source":     "SPRING_CLOUD",
-		},
+		IpAddr:      getIpAddr(),
+		Port:        registry.PortInfo{Port: int(serverPort), Enabled: true},
 	})
 
 	//连接
@@ -52,37 +56,25 @@ func Service(configs []constant.ServerConfig, serverName, group string, serverPo
 }
 
 func getIpAddr() string {
-	address, _ := net.InterfaceAddrs()
-	for _, address := range address {
-		if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
-			if ipnet.IP.To4() != nil {
-				return ipnet.IP.String()
-			}
-		}
+	if ip := registry.LocalIP(); ip != "" {
+		return ip
 	}
 	return "127.0.0.1"
 }
 
-func RegisterServiceInstance(client naming_client.INamingClient, param vo.RegisterInstanceParam) {
-	success, _ := client.RegisterInstance(param)
+func RegisterServiceInstance(client registry.DiscoveryClient, instance *registry.InstanceInfo) {
+	success, _ := client.Register(instance)
 	if success {
-		log.Printf("[INFO] 服务名 [%s] 注册成功  address [%s:%d] \n", param.ServiceName, param.Ip, param.Port)
+		log.Printf("[INFO] 服务名 [%s] 注册成功  address [%s:%d] \n", instance.ServiceName, instance.IpAddr, instance.Port.Port)
 	} else {
-		log.Fatalf("[ERROR] 服务名 [%s] 注册失败  address [%s:%d] \n", param.ServiceName, param.Ip, param.Port)
+		log.Fatalf("[ERROR] 服务名 [%s] 注册失败  address [%s:%d] \n", instance.ServiceName, instance.IpAddr, instance.Port.Port)
 	}
 	go func() {
 		exitChan := make(chan os.Signal, 100)
 		signal.Notify(exitChan, os.Interrupt, syscall.SIGTERM, os.Kill)
 		<-exitChan
-		log.Printf("[EXIT] 服务关闭 [%s]  address [%s:%d] \n", param.ServiceName, param.Ip, param.Port)
-		_, _ = client.DeregisterInstance(vo.DeregisterInstanceParam{
-			Ip:          param.Ip,
-			Port:        param.Port,
-			Cluster:     param.ClusterName,
-			ServiceName: param.ServiceName,
-			GroupName:   param.GroupName,
-			Ephemeral:   true, //立刻删除服务
-		})
+		log.Printf("[EXIT] 服务关闭 [%s]  address [%s:%d] \n", instance.ServiceName, instance.IpAddr, instance.Port.Port)
+		_ = client.Deregister(instance)
 		os.Exit(1)
 	}()
 
@@ -0,0 +1,356 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/microservice/registry/config"
+)
+
+// 负载均衡策略
+const (
+	BALANCER_ROUND_ROBIN    = "round-robin"
+	BALANCER_WEIGHTED       = "weighted"
+	BALANCER_LEAST_REQUESTS = "least-requests"
+	BALANCER_RANDOM         = "random"
+)
+
+var (
+	ErrServiceNotFound = errors.New("service not found")
+	ErrNoInstance      = errors.New("no available instance")
+)
+
+// 服务实例端点
+type Endpoint struct {
+	Service  string
+	Id       string // 实例标识
+	Url      string // scheme://host:port
+	Zone     string
+	Weight   int
+	Instance *InstanceInfo // 来源实例，Consul 等直接返回端点时为空
+
+	stats *instanceStats
+}
+
+// 开始一次调用
+func (e *Endpoint) Start() {
+	if e.stats != nil {
+		atomic.AddInt64(&e.stats.active, 1)
+	}
+}
+
+// 结束一次调用，err 不为空时计入连续失败
+func (e *Endpoint) Done(err error) {
+	if e.stats != nil {
+		atomic.AddInt64(&e.stats.active, -1)
+		e.stats.balancer.report(e.stats, err)
+	}
+}
+
+// 进行中的调用数
+func (e *Endpoint) Active() int64 {
+	if e.stats != nil {
+		return atomic.LoadInt64(&e.stats.active)
+	}
+	return 0
+}
+
+// 注册中心实例转换为端点，只保留健康实例
+func Endpoints(instances []InstanceInfo) []*Endpoint {
+	result := make([]*Endpoint, 0, len(instances))
+	for i := range instances {
+		if instance := &instances[i]; instance.Status == "" || instance.Status == STATUS_UP {
+			result = append(result, instance.Endpoint())
+		}
+	}
+	return result
+}
+
+func (i *InstanceInfo) Endpoint() *Endpoint {
+	host := i.HostName
+	if host == "" {
+		host = i.IpAddr
+	}
+
+	url := fmt.Sprintf("http://%s:%d", host, i.Port.Port)
+	if i.SecurePort.Port > 0 && isEnabled(i.SecurePort.Enabled) {
+		url = fmt.Sprintf("https://%s:%d", host, i.SecurePort.Port)
+	}
+
+	id := i.InstanceId
+	if id == "" {
+		id = fmt.Sprintf("%s:%d", host, i.Port.Port)
+	}
+
+	return &Endpoint{
+		Service:  i.ServiceName,
+		Id:       id,
+		Url:      url,
+		Zone:     i.Metadata.Zone,
+		Weight:   ParseWeight(i.Metadata.Weight),
+		Instance: i,
+	}
+}
+
+func isEnabled(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// 解析实例权重，缺省为 1
+func ParseWeight(value string) int {
+	if weight, err := strconv.Atoi(value); err == nil && weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// 负载均衡策略：从可用端点中选择一个
+type Strategy interface {
+	Choose(service string, endpoints []*Endpoint) *Endpoint
+}
+
+var (
+	strategiesMutex sync.RWMutex
+	strategies      = map[string]func() Strategy{
+		BALANCER_ROUND_ROBIN:    func() Strategy { return &roundRobin{counters: make(map[string]*uint64)} },
+		BALANCER_WEIGHTED:       func() Strategy { return &weighted{} },
+		BALANCER_LEAST_REQUESTS: func() Strategy { return &leastRequests{} },
+		BALANCER_RANDOM:         func() Strategy { return &random{} },
+	}
+)
+
+// 注册自定义负载均衡策略
+func RegisterStrategy(name string, factory func() Strategy) {
+	strategiesMutex.Lock()
+	defer strategiesMutex.Unlock()
+	strategies[name] = factory
+}
+
+type roundRobin struct {
+	sync.Mutex
+	counters map[string]*uint64
+}
+
+func (s *roundRobin) Choose(service string, endpoints []*Endpoint) *Endpoint {
+	s.Lock()
+	counter, b := s.counters[service]
+	if !b {
+		counter = new(uint64)
+		s.counters[service] = counter
+	}
+	s.Unlock()
+
+	return endpoints[(atomic.AddUint64(counter, 1)-1)%uint64(len(endpoints))]
+}
+
+// 平滑加权轮询
+type weighted struct {
+	sync.Mutex
+}
+
+func (s *weighted) Choose(service string, endpoints []*Endpoint) *Endpoint {
+	s.Lock()
+	defer s.Unlock()
+
+	var best *Endpoint
+	total := 0
+	for _, endpoint := range endpoints {
+		endpoint.stats.current += endpoint.Weight
+		total += endpoint.Weight
+		if best == nil || endpoint.stats.current > best.stats.current {
+			best = endpoint
+		}
+	}
+	best.stats.current -= total
+	return best
+}
+
+// 随机取两个，选择进行中调用较少的
+type leastRequests struct{}
+
+func (s *leastRequests) Choose(service string, endpoints []*Endpoint) *Endpoint {
+	a := endpoints[rand.Intn(len(endpoints))]
+	if len(endpoints) == 1 {
+		return a
+	}
+	b := endpoints[rand.Intn(len(endpoints))]
+	if b.Active() < a.Active() {
+		return b
+	}
+	return a
+}
+
+type random struct{}
+
+func (s *random) Choose(service string, endpoints []*Endpoint) *Endpoint {
+	return endpoints[rand.Intn(len(endpoints))]
+}
+
+// 实例调用统计，用于异常实例摘除
+type instanceStats struct {
+	balancer     *Balancer
+	service      string
+	id           string
+	active       int64
+	current      int // 加权轮询的当前权重
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+	lastSeen     time.Time
+}
+
+func (s *instanceStats) ejected(now time.Time) bool {
+	return now.Before(s.ejectedUntil)
+}
+
+type Balancer struct {
+	sync.Mutex
+	strategy Strategy
+	stats    map[string]*instanceStats
+	sizes    map[string]int
+}
+
+func NewBalancer(strategy string) *Balancer {
+	strategiesMutex.RLock()
+	factory, b := strategies[strategy]
+	strategiesMutex.RUnlock()
+
+	if !b {
+		logger.Warn("Unknown load balancer strategy, use round-robin: ", strategy)
+		factory = strategies[BALANCER_ROUND_ROBIN]
+	}
+
+	return &Balancer{
+		strategy: factory(),
+		stats:    make(map[string]*instanceStats),
+		sizes:    make(map[string]int),
+	}
+}
+
+var (
+	defaultBalancer     *Balancer
+	defaultBalancerOnce sync.Once
+)
+
+// 按配置创建的全局负载均衡器，实例统计在所有注册中心客户端间共享
+func DefaultBalancer() *Balancer {
+	defaultBalancerOnce.Do(func() {
+		defaultBalancer = NewBalancer(config.Setting.LoadBalancer.Strategy)
+	})
+	return defaultBalancer
+}
+
+// 选择端点：排除本次请求已失败及被摘除的实例，优先同区实例
+func (b *Balancer) Choose(service string, endpoints []*Endpoint, excludes ...string) (*Endpoint, error) {
+	now := time.Now()
+
+	b.Lock()
+	b.sizes[service] = len(endpoints)
+
+	candidates := make([]*Endpoint, 0, len(endpoints))
+	available := make([]*Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if slices.Contains(excludes, endpoint.Id) {
+			continue
+		}
+
+		key := service + "/" + endpoint.Id
+		stats, exists := b.stats[key]
+		if !exists {
+			stats = &instanceStats{balancer: b, service: service, id: endpoint.Id}
+			b.stats[key] = stats
+		}
+		stats.lastSeen = now
+		endpoint.stats = stats
+
+		candidates = append(candidates, endpoint)
+		if !stats.ejected(now) {
+			available = append(available, endpoint)
+		}
+	}
+	b.prune(now)
+	b.Unlock()
+
+	if len(candidates) == 0 {
+		return nil, ErrNoInstance
+	}
+
+	// 全部被摘除时仍然尝试，避免服务完全不可用
+	if len(available) == 0 {
+		available = candidates
+	}
+
+	if zone := config.Setting.LoadBalancer.Zone; zone != "" {
+		local := make([]*Endpoint, 0, len(available))
+		for _, endpoint := range available {
+			if endpoint.Zone == zone {
+				local = append(local, endpoint)
+			}
+		}
+		if len(local) > 0 {
+			available = local
+		}
+	}
+
+	return b.strategy.Choose(service, available), nil
+}
+
+// 清理长时间未出现的实例
+func (b *Balancer) prune(now time.Time) {
+	for key, stats := range b.stats {
+		if now.Sub(stats.lastSeen) > time.Hour && atomic.LoadInt64(&stats.active) == 0 {
+			delete(b.stats, key)
+		}
+	}
+}
+
+func (b *Balancer) report(stats *instanceStats, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err == nil {
+		stats.failures, stats.ejections = 0, 0
+		return
+	}
+
+	setting := config.Setting.LoadBalancer
+	stats.failures++
+	if setting.ConsecutiveFailures <= 0 || stats.failures < setting.ConsecutiveFailures {
+		return
+	}
+
+	now := time.Now()
+	if stats.ejected(now) {
+		return
+	}
+
+	// 限制同一服务被摘除的实例数
+	ejected := 0
+	for _, s := range b.stats {
+		if s.service == stats.service && s.ejected(now) {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > b.sizes[stats.service]*setting.MaxEjectionPercent {
+		return
+	}
+
+	stats.ejections++
+	stats.failures = 0
+	stats.ejectedUntil = now.Add(setting.EjectionTime * time.Duration(min(stats.ejections, 10)))
+	logger.Warn("Eject instance: ", stats.service, stats.id, " until ", stats.ejectedUntil.Format(time.RFC3339))
+}
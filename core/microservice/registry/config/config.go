@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	ConsulConfig "github.com/gophab/gophrame/core/microservice/registry/consul/config"
 	DubboConfig "github.com/gophab/gophrame/core/microservice/registry/dubbo/config"
 	EurekaConfig "github.com/gophab/gophrame/core/microservice/registry/eureka/config"
@@ -11,6 +13,7 @@ import (
 
 type RegistrySetting struct {
 	Enabled            bool
+	Type               string `json:"type" yaml:"type"` // eureka|consul|nacos|dubbo，为空时使用第一个启用的注册中心
 	EnableAutoRegister bool   `json:"enableAutoRegister" yaml:"enabledAutoRegister"`
 	ServiceName        string `json:"serviceName" yaml:"serviceName"`
 	InstanceId         string `json:"instanceId" yaml:"instanceId"`
@@ -20,10 +23,47 @@ type RegistrySetting struct {
 	Consul             *ConsulConfig.ConsulSetting
 	Nacos              *NacosConfig.NacosSetting
 	Dubbo              *DubboConfig.DubboSetting
+	LoadBalancer       *LoadBalancerSetting `json:"loadBalancer" yaml:"loadBalancer"`
+}
+
+// 客户端负载均衡
+type LoadBalancerSetting struct {
+	Strategy            string        `json:"strategy" yaml:"strategy"`                       // round-robin|weighted|least-requests|random
+	Zone                string        `json:"zone" yaml:"zone"`                               // 优先选择同区实例，为空不启用
+	ConsecutiveFailures int           `json:"consecutiveFailures" yaml:"consecutiveFailures"` // 连续失败多少次摘除实例
+	EjectionTime        time.Duration `json:"ejectionTime" yaml:"ejectionTime"`               // 摘除时长，重复摘除时按次数递增
+	MaxEjectionPercent  int           `json:"maxEjectionPercent" yaml:"maxEjectionPercent"`   // 同一服务最多摘除的实例比例
 }
 
 var Setting = &RegistrySetting{
 	Enabled:            false,
 	EnableAutoRegister: false,
 	InstanceId:         uuid.NewString(),
+	Eureka:             EurekaConfig.Setting,
+	Consul:             ConsulConfig.Setting,
+	Nacos:              NacosConfig.Setting,
+	Dubbo:              DubboConfig.Setting,
+	LoadBalancer: &LoadBalancerSetting{
+		Strategy:            "round-robin",
+		ConsecutiveFailures: 5,
+		EjectionTime:        time.Second * 30,
+		MaxEjectionPercent:  50,
+	},
+}
+
+// 当前使用的注册中心类型
+func (s *RegistrySetting) Backend() string {
+	switch {
+	case s.Type != "":
+		return s.Type
+	case s.Eureka != nil && s.Eureka.Enabled:
+		return "eureka"
+	case s.Consul != nil && s.Consul.Enabled:
+		return "consul"
+	case s.Nacos != nil && s.Nacos.Enabled:
+		return "nacos"
+	case s.Dubbo != nil && s.Dubbo.Enabled:
+		return "dubbo"
+	}
+	return ""
 }
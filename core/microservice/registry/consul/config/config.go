@@ -1,9 +1,21 @@
 package config
 
+import "time"
+
 type ConsulSetting struct {
-	Enabled bool
+	Enabled         bool
+	Address         string            `json:"address" yaml:"address"` // host:port，为空时使用 CONSUL_HTTP_ADDR 或 127.0.0.1:8500
+	Scheme          string            `json:"scheme" yaml:"scheme"`
+	Datacenter      string            `json:"datacenter" yaml:"datacenter"`
+	Token           string            `json:"token" yaml:"token"`
+	Tags            []string          `json:"tags" yaml:"tags"`
+	Metadata        map[string]string `json:"metadata" yaml:"metadata"`
+	TTL             time.Duration     `json:"ttl" yaml:"ttl"`                         // 健康检查 TTL，需大于心跳间隔（1 分钟）
+	DeregisterAfter time.Duration     `json:"deregisterAfter" yaml:"deregisterAfter"` // 检查持续失败多久后注销实例
 }
 
 var Setting *ConsulSetting = &ConsulSetting{
-	Enabled: false,
+	Enabled:         false,
+	TTL:             time.Second * 90,
+	DeregisterAfter: time.Minute * 5,
 }
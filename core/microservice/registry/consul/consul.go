@@ -1,12 +1,12 @@
 package consul
 
 import (
-	"errors"
 	"fmt"
-	"math/rand"
 	"net/url"
 	"strconv"
 
+	"github.com/gophab/gophrame/core/microservice/registry"
+
 	"github.com/hashicorp/consul/api"
 )
 
@@ -15,17 +15,35 @@ type ConsulRegistryClient struct {
 }
 
 func (m *ConsulRegistryClient) GetServiceEntry(service string) (string, error) {
-	serviceEntries, _, err := m.ConsulClient.Health().Service(service, "", true, nil)
+	endpoint, err := m.Choose(service)
 	if err != nil {
 		return "", err
 	}
+	return endpoint.Url, nil
+}
+
+// 只在健康检查通过的实例中选择
+func (m *ConsulRegistryClient) Choose(service string, excludes ...string) (*registry.Endpoint, error) {
+	serviceEntries, _, err := m.ConsulClient.Health().Service(service, "", false, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(serviceEntries) == 0 {
-		return "", errors.New("no service to connect")
+		return nil, registry.ErrServiceNotFound
+	}
+
+	endpoints := make([]*registry.Endpoint, 0, len(serviceEntries))
+	for _, serviceEntry := range serviceEntries {
+		if serviceEntry.Checks.AggregatedStatus() == api.HealthPassing {
+			endpoints = append(endpoints, endpoint(service, serviceEntry))
+		}
 	}
 
-	serviceEntry := serviceEntries[rand.Intn(len(serviceEntries))]
+	return registry.DefaultBalancer().Choose(service, endpoints, excludes...)
+}
 
+func endpoint(service string, serviceEntry *api.ServiceEntry) *registry.Endpoint {
 	scheme := "http"
 	if meta, ok := serviceEntry.Service.Meta["secure"]; ok {
 		if secure, err := strconv.ParseBool(meta); err == nil && secure {
@@ -33,10 +51,26 @@ func (m *ConsulRegistryClient) GetServiceEntry(service string) (string, error) {
 		}
 	}
 
+	address := serviceEntry.Service.Address
+	if address == "" {
+		address = serviceEntry.Node.Address
+	}
+
 	url := &url.URL{
 		Scheme: scheme,
-		Host:   fmt.Sprintf("%s:%d", serviceEntry.Service.Address, serviceEntry.Service.Port),
+		Host:   fmt.Sprintf("%s:%d", address, serviceEntry.Service.Port),
 	}
 
-	return url.String(), nil
+	weight := serviceEntry.Service.Weights.Passing
+	if meta, ok := serviceEntry.Service.Meta["weight"]; ok {
+		weight = registry.ParseWeight(meta)
+	}
+
+	return &registry.Endpoint{
+		Service: service,
+		Id:      serviceEntry.Service.ID,
+		Url:     url.String(),
+		Zone:    serviceEntry.Service.Meta["zone"],
+		Weight:  max(weight, 1),
+	}
 }
@@ -0,0 +1,223 @@
+package consul
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/consul/config"
+
+	"github.com/hashicorp/consul/api"
+)
+
+func NewConsulClient(setting *config.ConsulSetting) (*api.Client, error) {
+	conf := api.DefaultConfig()
+	if setting.Address != "" {
+		conf.Address = setting.Address
+	}
+	if setting.Scheme != "" {
+		conf.Scheme = setting.Scheme
+	}
+	if setting.Datacenter != "" {
+		conf.Datacenter = setting.Datacenter
+	}
+	if setting.Token != "" {
+		conf.Token = setting.Token
+	}
+	return api.NewClient(conf)
+}
+
+// Consul 服务发现：实例注册 TTL 检查，由 RegistryClient 的心跳刷新
+type ConsulDiscoveryClient struct {
+	registry.AbstractDiscoveryClient
+
+	client  *api.Client
+	setting *config.ConsulSetting
+}
+
+func CreateConsulDiscoveryClient() (*ConsulDiscoveryClient, error) {
+	client, err := NewConsulClient(config.Setting)
+	if err != nil {
+		return nil, err
+	}
+	return NewConsulDiscoveryClient(client, config.Setting), nil
+}
+
+func NewConsulDiscoveryClient(client *api.Client, setting *config.ConsulSetting) *ConsulDiscoveryClient {
+	return &ConsulDiscoveryClient{client: client, setting: setting}
+}
+
+func (c *ConsulDiscoveryClient) Client() *api.Client {
+	return c.client
+}
+
+// 实例标识：未指定时由服务名、地址和端口组成
+func serviceId(instance *registry.InstanceInfo) string {
+	if instance.InstanceId != "" {
+		return instance.InstanceId
+	}
+	return fmt.Sprintf("%s-%s-%d", instance.ServiceName, instance.IpAddr, port(instance))
+}
+
+func checkId(instance *registry.InstanceInfo) string {
+	return "service:" + serviceId(instance)
+}
+
+func port(instance *registry.InstanceInfo) int {
+	if instance.SecurePort.Port > 0 {
+		return instance.SecurePort.Port
+	}
+	return instance.Port.Port
+}
+
+func (c *ConsulDiscoveryClient) Register(instance *registry.InstanceInfo) (bool, error) {
+	metadata := make(map[string]string, len(c.setting.Metadata)+4)
+	for k, v := range c.setting.Metadata {
+		metadata[k] = v
+	}
+	if instance.Metadata.Zone != "" {
+		metadata["zone"] = instance.Metadata.Zone
+	}
+	if instance.Metadata.Weight != "" {
+		metadata["weight"] = instance.Metadata.Weight
+	}
+	if instance.Metadata.ManagementPort != "" {
+		metadata["management.port"] = instance.Metadata.ManagementPort
+	}
+	if instance.SecurePort.Port > 0 {
+		metadata["secure"] = "true"
+	}
+
+	err := c.client.Agent().ServiceRegister(&api.AgentServiceRegistration{
+		ID:      serviceId(instance),
+		Name:    instance.ServiceName,
+		Address: instance.IpAddr,
+		Port:    port(instance),
+		Tags:    c.setting.Tags,
+		Meta:    metadata,
+		Check: &api.AgentServiceCheck{
+			CheckID:                        checkId(instance),
+			TTL:                            c.setting.TTL.String(),
+			DeregisterCriticalServiceAfter: c.setting.DeregisterAfter.String(),
+			Status:                         api.HealthPassing,
+		},
+	})
+	return err == nil, err
+}
+
+func (c *ConsulDiscoveryClient) Deregister(instance *registry.InstanceInfo) error {
+	return c.client.Agent().ServiceDeregister(serviceId(instance))
+}
+
+// 不含实例
+func (c *ConsulDiscoveryClient) GetServices() ([]registry.ServiceInfo, error) {
+	services, _, err := c.client.Catalog().Services(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]registry.ServiceInfo, 0, len(services))
+	for name := range services {
+		if name == "consul" {
+			continue
+		}
+		result = append(result, registry.ServiceInfo{Name: name})
+	}
+	return result, nil
+}
+
+func (c *ConsulDiscoveryClient) GetService(serviceId string) (*registry.ServiceInfo, error) {
+	instances, err := c.GetInstances(serviceId)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, registry.ErrServiceNotFound
+	}
+	return &registry.ServiceInfo{Name: serviceId, Instances: instances}, nil
+}
+
+// 包含检查未通过的实例（状态为 DOWN），由负载均衡器过滤
+func (c *ConsulDiscoveryClient) GetInstances(serviceId string) ([]registry.InstanceInfo, error) {
+	entries, _, err := c.client.Health().Service(serviceId, "", false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]registry.InstanceInfo, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *MapInstance(serviceId, entry))
+	}
+	return result, nil
+}
+
+func (c *ConsulDiscoveryClient) GetInstance(serviceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return registry.ChooseInstance(serviceId, instances)
+	}
+	return nil
+}
+
+func (c *ConsulDiscoveryClient) GetInstanceById(serviceId string, instanceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		for i := range instances {
+			if instances[i].InstanceId == instanceId {
+				return &instances[i]
+			}
+		}
+	}
+	return nil
+}
+
+// 刷新 TTL 检查；检查不存在（实例已被注销）时返回 false，由 RegistryClient 重新注册
+func (c *ConsulDiscoveryClient) SendHeartBeat(instance *registry.InstanceInfo, status string) (bool, error) {
+	health := api.HealthPassing
+	if status != "" && status != registry.STATUS_UP {
+		health = api.HealthCritical
+	}
+
+	if err := c.client.Agent().UpdateTTL(checkId(instance), status, health); err != nil {
+		var statusErr api.StatusError
+		if errors.As(err, &statusErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func MapInstance(serviceId string, entry *api.ServiceEntry) *registry.InstanceInfo {
+	status := registry.STATUS_UP
+	if entry.Checks.AggregatedStatus() != api.HealthPassing {
+		status = registry.STATUS_DOWN
+	}
+
+	address := entry.Service.Address
+	if address == "" {
+		address = entry.Node.Address
+	}
+
+	weight := entry.Service.Meta["weight"]
+	if weight == "" {
+		weight = strconv.Itoa(max(entry.Service.Weights.Passing, 1))
+	}
+
+	result := &registry.InstanceInfo{
+		ServiceName: serviceId,
+		InstanceId:  entry.Service.ID,
+		IpAddr:      address,
+		Port:        registry.PortInfo{Port: entry.Service.Port, Enabled: true},
+		Status:      status,
+		Metadata: registry.Metadata{
+			ManagementPort: entry.Service.Meta["management.port"],
+			Weight:         weight,
+			Zone:           entry.Service.Meta["zone"],
+		},
+	}
+
+	if secure, err := strconv.ParseBool(entry.Service.Meta["secure"]); err == nil && secure {
+		result.SecurePort = registry.PortInfo{Port: entry.Service.Port, Enabled: true}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/consul/config"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// 模拟 Consul agent 的注册、TTL 与健康查询接口
+type fakeAgent struct {
+	mutex    sync.Mutex
+	services map[string]*api.AgentServiceRegistration
+	checks   map[string]string
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch {
+	case r.URL.Path == "/v1/agent/service/register":
+		var service api.AgentServiceRegistration
+		_ = json.NewDecoder(r.Body).Decode(&service)
+		a.services[service.ID] = &service
+		a.checks[service.Check.CheckID] = service.Check.Status
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/")
+		delete(a.services, id)
+		delete(a.checks, "service:"+id)
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/check/update/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/agent/check/update/")
+		if _, b := a.checks[id]; !b {
+			http.Error(w, "Unknown check ID", http.StatusNotFound)
+			return
+		}
+		var update struct{ Status string }
+		_ = json.NewDecoder(r.Body).Decode(&update)
+		a.checks[id] = update.Status
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		name := strings.TrimPrefix(r.URL.Path, "/v1/health/service/")
+		entries := []*api.ServiceEntry{}
+		for id, service := range a.services {
+			if service.Name != name {
+				continue
+			}
+			entries = append(entries, &api.ServiceEntry{
+				Node:    &api.Node{Address: "10.0.0.1"},
+				Service: &api.AgentService{ID: id, Service: service.Name, Address: service.Address, Port: service.Port, Meta: service.Meta},
+				Checks:  api.HealthChecks{{CheckID: "service:" + id, Status: a.checks["service:"+id]}},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestConsulDiscoveryClient(t *testing.T) {
+	agent := &fakeAgent{services: map[string]*api.AgentServiceRegistration{}, checks: map[string]string{}}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	client, err := NewConsulClient(&config.ConsulSetting{Address: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	discovery := NewConsulDiscoveryClient(client, config.Setting)
+
+	instance := &registry.InstanceInfo{
+		ServiceName: "demo",
+		IpAddr:      "192.168.1.2",
+		Port:        registry.PortInfo{Port: 8080},
+		Metadata:    registry.Metadata{Zone: "z1", Weight: "5"},
+	}
+	if ok, err := discovery.Register(instance); !ok || err != nil {
+		t.Fatalf("register = %v, %v", ok, err)
+	}
+
+	instances, err := discovery.GetInstances("demo")
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("instances = %+v, %v", instances, err)
+	}
+	found := instances[0]
+	if found.InstanceId != "demo-192.168.1.2-8080" || found.Status != registry.STATUS_UP || found.Metadata.Zone != "z1" || found.Metadata.Weight != "5" {
+		t.Fatalf("unexpected instance: %+v", found)
+	}
+
+	// 心跳：实例下线状态使检查失败
+	if ok, err := discovery.SendHeartBeat(instance, registry.STATUS_OUT_OF_SERVICE); !ok || err != nil {
+		t.Fatalf("heartbeat = %v, %v", ok, err)
+	}
+	if instances, _ = discovery.GetInstances("demo"); instances[0].Status != registry.STATUS_DOWN {
+		t.Fatalf("expected DOWN, got %s", instances[0].Status)
+	}
+
+	// 注销后心跳返回 false，触发重新注册
+	if err := discovery.Deregister(instance); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := discovery.SendHeartBeat(instance, registry.STATUS_UP); ok || err != nil {
+		t.Fatalf("heartbeat after deregister = %v, %v", ok, err)
+	}
+	if _, err := discovery.GetService("demo"); err != registry.ErrServiceNotFound {
+		t.Fatalf("expected service not found, got %v", err)
+	}
+}
@@ -1 +1,12 @@
 package starter
+
+import (
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/consul"
+)
+
+func init() {
+	registry.RegisterDiscoveryClient("consul", func() (registry.DiscoveryClient, error) {
+		return consul.CreateConsulDiscoveryClient()
+	})
+}
@@ -1,9 +1,16 @@
 package config
 
 type DubboSetting struct {
-	Enabled bool
+	Enabled  bool
+	Registry string            `json:"registry" yaml:"registry"` // 注册数据所在的注册中心，目前支持 nacos，连接参数使用 nacos 配置
+	Protocol string            `json:"protocol" yaml:"protocol"` // 通过 HTTP 调用的协议：tri|rest
+	Group    string            `json:"group" yaml:"group"`       // 接口级服务的分组与版本
+	Version  string            `json:"version" yaml:"version"`
+	Services map[string]string `json:"services" yaml:"services"` // 服务名 -> 接口名，映射到接口级注册的提供者
 }
 
 var Setting *DubboSetting = &DubboSetting{
-	Enabled: false,
+	Enabled:  false,
+	Registry: "nacos",
+	Protocol: "tri",
 }
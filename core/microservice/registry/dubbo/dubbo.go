@@ -0,0 +1,132 @@
+package dubbo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/dubbo/config"
+	"github.com/gophab/gophrame/core/microservice/registry/nacos"
+	NacosConfig "github.com/gophab/gophrame/core/microservice/registry/nacos/config"
+
+	"github.com/nacos-group/nacos-sdk-go/model"
+)
+
+const (
+	METADATA_ENDPOINTS = "dubbo.endpoints" // 应用级服务发现：[{"port":50051,"protocol":"tri"}]
+	METADATA_PROTOCOL  = "protocol"        // 接口级服务发现
+)
+
+type endpoint struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// Dubbo 服务发现适配：读取 Dubbo 写入 Nacos 的注册数据，通过 HTTP 协议（triple/rest）调用提供者
+//
+//   - 应用级（Dubbo 3）：Nacos 服务名即应用名，端口取 dubbo.endpoints 中与 Protocol 匹配的端口
+//   - 接口级（Dubbo 2.7）：Services 中配置的服务名映射为 providers:<接口>:<版本>:<分组>
+//
+// 本服务注册为应用级实例并声明 dubbo.endpoints，不提供 Dubbo 元数据服务，Dubbo 消费者无法以 RPC 方式调用
+type DubboDiscoveryClient struct {
+	*nacos.NacosDiscoveryClient
+	setting      *config.DubboSetting
+	nacosSetting *NacosConfig.NacosSetting
+}
+
+func CreateDubboDiscoveryClient() (*DubboDiscoveryClient, error) {
+	if config.Setting.Registry != "" && config.Setting.Registry != "nacos" {
+		return nil, fmt.Errorf("unsupported dubbo registry: %q", config.Setting.Registry)
+	}
+
+	client, err := nacos.NewNamingClient(NacosConfig.Setting)
+	if err != nil {
+		return nil, err
+	}
+
+	// 注册时声明协议端点
+	setting := *NacosConfig.Setting
+	setting.Metadata = make(map[string]string, len(NacosConfig.Setting.Metadata)+1)
+	for k, v := range NacosConfig.Setting.Metadata {
+		setting.Metadata[k] = v
+	}
+	if config.Setting.Protocol != "" {
+		setting.Metadata[METADATA_PROTOCOL] = config.Setting.Protocol
+	}
+
+	c := &DubboDiscoveryClient{
+		NacosDiscoveryClient: nacos.NewNacosDiscoveryClient(client, &setting),
+		setting:              config.Setting,
+		nacosSetting:         &setting,
+	}
+	c.ServiceName = c.serviceName
+	c.Mapper = c.mapInstance
+	return c, nil
+}
+
+func (c *DubboDiscoveryClient) Register(instance *registry.InstanceInfo) (bool, error) {
+	endpoints, _ := json.Marshal([]endpoint{{Port: instance.Port.Port, Protocol: c.setting.Protocol}})
+	c.nacosSetting.Metadata[METADATA_ENDPOINTS] = string(endpoints)
+	return c.NacosDiscoveryClient.Register(instance)
+}
+
+// 应用级服务，以及 Services 中映射的接口级服务
+func (c *DubboDiscoveryClient) GetServices() ([]registry.ServiceInfo, error) {
+	services, err := c.NacosDiscoveryClient.GetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]registry.ServiceInfo, 0, len(services)+len(c.setting.Services))
+	for _, service := range services {
+		if !strings.HasPrefix(service.Name, "providers:") && !strings.HasPrefix(service.Name, "consumers:") {
+			result = append(result, service)
+		}
+	}
+	for name := range c.setting.Services {
+		result = append(result, registry.ServiceInfo{Name: name})
+	}
+	return result, nil
+}
+
+func (c *DubboDiscoveryClient) serviceName(serviceId string) string {
+	if iface, exists := c.setting.Services[serviceId]; exists {
+		return fmt.Sprintf("providers:%s:%s:%s", iface, c.setting.Version, c.setting.Group)
+	}
+	return serviceId
+}
+
+// 没有匹配协议端点的实例不可通过 HTTP 调用，忽略
+func (c *DubboDiscoveryClient) mapInstance(serviceId string, instance *model.Instance) *registry.InstanceInfo {
+	protocol := c.setting.Protocol
+
+	if value, exists := instance.Metadata[METADATA_ENDPOINTS]; exists {
+		var endpoints []endpoint
+		if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+			return nil
+		}
+
+		found := false
+		for _, e := range endpoints {
+			if protocol == "" || e.Protocol == protocol {
+				copied := *instance
+				copied.Port = uint64(e.Port)
+				instance, found = &copied, true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	} else if value, exists := instance.Metadata[METADATA_PROTOCOL]; exists && protocol != "" && value != protocol {
+		return nil
+	}
+
+	result := nacos.MapInstance(serviceId, instance)
+	if result.InstanceId == "" {
+		result.InstanceId = instance.Ip + ":" + strconv.FormatUint(instance.Port, 10)
+	}
+	return result
+}
@@ -1 +1,12 @@
 package starter
+
+import (
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/dubbo"
+)
+
+func init() {
+	registry.RegisterDiscoveryClient("dubbo", func() (registry.DiscoveryClient, error) {
+		return dubbo.CreateDubboDiscoveryClient()
+	})
+}
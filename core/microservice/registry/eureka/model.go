@@ -93,4 +93,6 @@ type WrapperLeaseInfo struct {
 type WrapperMetadata struct {
 	ManagementPort string `json:"management.port,omitempty"`
 	JmxPort        string `json:"jmx.port,omitempty"`
+	Weight         string `json:"weight,omitempty"`
+	Zone           string `json:"zone,omitempty"`
 }
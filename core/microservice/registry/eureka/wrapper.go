@@ -2,7 +2,6 @@ package eureka
 
 import (
 	"fmt"
-	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -12,7 +11,10 @@ import (
 )
 
 func CreateEurekaDiscoveryClient() (*EurekaDiscoveryClient, error) {
-	return nil, nil
+	if len(config.Setting.ServiceUrls) == 0 {
+		return nil, fmt.Errorf("eureka service urls are not configured")
+	}
+	return &EurekaDiscoveryClient{}, nil
 }
 
 type EurekaDiscoveryClient struct {
@@ -71,7 +73,7 @@ func (c *EurekaDiscoveryClient) GetInstances(serviceId string) ([]registry.Insta
 
 func (c *EurekaDiscoveryClient) GetInstance(serviceId string) *registry.InstanceInfo {
 	if appInfo, err := c.Client().GetApplication(serviceId); err == nil && appInfo != nil {
-		return mapInstanceInfo(&appInfo.Application.Instance[rand.Intn(len(appInfo.Application.Instance))])
+		return registry.ChooseInstance(serviceId, mapInstanceInfos(appInfo.Application.Instance))
 	} else {
 		return nil
 	}
@@ -135,6 +137,8 @@ func wrapperInstanceInfo(instance *registry.InstanceInfo) *WrapperInstanceInfo {
 		},
 		Metadata: &WrapperMetadata{
 			ManagementPort: fmt.Sprintf("%d", instance.Port.Port),
+			Weight:         instance.Metadata.Weight,
+			Zone:           instance.Metadata.Zone,
 		},
 		VipAddress:           instance.ServiceName,
 		SecureVipAddress:     instance.ServiceName,
@@ -179,9 +183,7 @@ func mapInstanceInfo(instance *WrapperInstanceInfo) *registry.InstanceInfo {
 			RenewalIntervalInSecs: instance.LeaseInfo.RenewalIntervalInSecs,
 			DurationInSecs:        instance.LeaseInfo.DurationInSecs,
 		},
-		Metadata: registry.Metadata{
-			ManagementPort: instance.Metadata.ManagementPort,
-		},
+		Metadata:             mapMetadata(instance.Metadata),
 		VipAddress:           instance.VipAddress,
 		SecureVipAddress:     instance.SecureVipAddress,
 		LastUpdatedTimestamp: instance.LastUpdatedTimestamp,
@@ -189,6 +191,18 @@ func mapInstanceInfo(instance *WrapperInstanceInfo) *registry.InstanceInfo {
 	}
 }
 
+func mapMetadata(metadata *WrapperMetadata) registry.Metadata {
+	if metadata == nil {
+		return registry.Metadata{}
+	}
+	return registry.Metadata{
+		ManagementPort: metadata.ManagementPort,
+		JmxPort:        metadata.JmxPort,
+		Weight:         metadata.Weight,
+		Zone:           metadata.Zone,
+	}
+}
+
 func mapInstanceInfos(instances []WrapperInstanceInfo) []registry.InstanceInfo {
 	var result []registry.InstanceInfo
 	for _, ii := range instances {
@@ -1,9 +1,32 @@
 package config
 
 type NacosSetting struct {
-	Enabled bool
+	Enabled      bool
+	ServerAddrs  []string          `json:"serverAddrs" yaml:"serverAddrs"` // host:port 或 http://host:port/nacos
+	Namespace    string            `json:"namespace" yaml:"namespace"`     // 命名空间 ID，public 为空
+	Group        string            `json:"group" yaml:"group"`
+	Cluster      string            `json:"cluster" yaml:"cluster"`
+	Username     string            `json:"username" yaml:"username"`
+	Password     string            `json:"password" yaml:"password"`
+	Weight       float64           `json:"weight" yaml:"weight"`
+	Ephemeral    bool              `json:"ephemeral" yaml:"ephemeral"` // 临时实例由客户端心跳保持
+	Metadata     map[string]string `json:"metadata" yaml:"metadata"`
+	TimeoutMs    uint64            `json:"timeoutMs" yaml:"timeoutMs"`
+	BeatInterval int64             `json:"beatInterval" yaml:"beatInterval"` // 心跳间隔（毫秒）
+	CacheDir     string            `json:"cacheDir" yaml:"cacheDir"`
+	LogDir       string            `json:"logDir" yaml:"logDir"`
+	LogLevel     string            `json:"logLevel" yaml:"logLevel"`
 }
 
 var Setting *NacosSetting = &NacosSetting{
-	Enabled: false,
+	Enabled:      false,
+	Group:        "DEFAULT_GROUP",
+	Cluster:      "DEFAULT",
+	Weight:       1,
+	Ephemeral:    true,
+	TimeoutMs:    10 * 1000,
+	BeatInterval: 5 * 1000,
+	CacheDir:     "data/nacos/cache",
+	LogDir:       "data/nacos/log",
+	LogLevel:     "warn",
 }
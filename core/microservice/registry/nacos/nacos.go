@@ -0,0 +1,327 @@
+package nacos
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/nacos/config"
+
+	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+// 解析服务端地址：host:port 或 http(s)://host:port/nacos
+func ServerConfigs(addrs []string) ([]constant.ServerConfig, error) {
+	result := make([]constant.ServerConfig, 0, len(addrs))
+	for _, addr := range addrs {
+		if !strings.Contains(addr, "://") {
+			addr = "http://" + addr
+		}
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nacos server address %q: %w", addr, err)
+		}
+
+		host, port, err := net.SplitHostPort(u.Host)
+		if err != nil {
+			host, port = u.Host, "8848"
+		}
+		p, err := strconv.ParseUint(port, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nacos server address %q: %w", addr, err)
+		}
+
+		result = append(result, constant.ServerConfig{
+			Scheme:      u.Scheme,
+			ContextPath: strings.TrimRight(u.Path, "/"),
+			IpAddr:      host,
+			Port:        p,
+		})
+	}
+	return result, nil
+}
+
+func NewNamingClient(setting *config.NacosSetting) (naming_client.INamingClient, error) {
+	if len(setting.ServerAddrs) == 0 {
+		return nil, fmt.Errorf("nacos server address is not configured")
+	}
+
+	serverConfigs, err := ServerConfigs(setting.ServerAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return clients.NewNamingClient(vo.NacosClientParam{
+		ServerConfigs: serverConfigs,
+		ClientConfig: &constant.ClientConfig{
+			NamespaceId:         setting.Namespace,
+			TimeoutMs:           setting.TimeoutMs,
+			BeatInterval:        setting.BeatInterval,
+			Username:            setting.Username,
+			Password:            setting.Password,
+			CacheDir:            setting.CacheDir,
+			LogDir:              setting.LogDir,
+			LogLevel:            setting.LogLevel,
+			NotLoadCacheAtStart: true,
+		},
+	})
+}
+
+// Nacos 服务发现：临时实例的心跳由 SDK 发送，实例变化通过订阅推送
+type NacosDiscoveryClient struct {
+	registry.AbstractDiscoveryClient
+
+	// 服务名到 Nacos 服务名的映射，以及实例的转换（返回 nil 时忽略该实例），供 Dubbo 等适配器定制
+	ServiceName func(serviceId string) string
+	Mapper      func(serviceId string, instance *model.Instance) *registry.InstanceInfo
+
+	client        naming_client.INamingClient
+	setting       *config.NacosSetting
+	mutex         sync.Mutex
+	subscriptions map[string]*vo.SubscribeParam
+}
+
+func CreateNacosDiscoveryClient() (*NacosDiscoveryClient, error) {
+	client, err := NewNamingClient(config.Setting)
+	if err != nil {
+		return nil, err
+	}
+	return NewNacosDiscoveryClient(client, config.Setting), nil
+}
+
+func NewNacosDiscoveryClient(client naming_client.INamingClient, setting *config.NacosSetting) *NacosDiscoveryClient {
+	return &NacosDiscoveryClient{
+		ServiceName:   func(serviceId string) string { return serviceId },
+		Mapper:        MapInstance,
+		client:        client,
+		setting:       setting,
+		subscriptions: make(map[string]*vo.SubscribeParam),
+	}
+}
+
+func (c *NacosDiscoveryClient) Client() naming_client.INamingClient {
+	return c.client
+}
+
+func (c *NacosDiscoveryClient) Register(instance *registry.InstanceInfo) (bool, error) {
+	weight := c.setting.Weight
+	if instance.Metadata.Weight != "" {
+		weight = float64(registry.ParseWeight(instance.Metadata.Weight))
+	}
+
+	metadata := make(map[string]string, len(c.setting.Metadata)+4)
+	for k, v := range c.setting.Metadata {
+		metadata[k] = v
+	}
+	if instance.Metadata.Zone != "" {
+		metadata["zone"] = instance.Metadata.Zone
+	}
+	if instance.Metadata.ManagementPort != "" {
+		metadata["management.port"] = instance.Metadata.ManagementPort
+	}
+	if instance.SecurePort.Port > 0 {
+		metadata["secure"] = "true"
+	}
+
+	port := instance.Port.Port
+	if instance.SecurePort.Port > 0 {
+		port = instance.SecurePort.Port
+	}
+
+	return c.client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip:          instance.IpAddr,
+		Port:        uint64(port),
+		Weight:      max(weight, 0.01),
+		Enable:      true,
+		Healthy:     true,
+		Metadata:    metadata,
+		ClusterName: c.setting.Cluster,
+		ServiceName: instance.ServiceName,
+		GroupName:   c.setting.Group,
+		Ephemeral:   c.setting.Ephemeral,
+	})
+}
+
+func (c *NacosDiscoveryClient) Deregister(instance *registry.InstanceInfo) error {
+	port := instance.Port.Port
+	if instance.SecurePort.Port > 0 {
+		port = instance.SecurePort.Port
+	}
+
+	_, err := c.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          instance.IpAddr,
+		Port:        uint64(port),
+		Cluster:     c.setting.Cluster,
+		ServiceName: instance.ServiceName,
+		GroupName:   c.setting.Group,
+		Ephemeral:   c.setting.Ephemeral,
+	})
+	return err
+}
+
+// 分页获取分组下的全部服务，不含实例
+func (c *NacosDiscoveryClient) GetServices() ([]registry.ServiceInfo, error) {
+	const pageSize = 100
+
+	result := make([]registry.ServiceInfo, 0)
+	for page := uint32(1); ; page++ {
+		list, err := c.client.GetAllServicesInfo(vo.GetAllServiceInfoParam{
+			NameSpace: c.setting.Namespace,
+			GroupName: c.setting.Group,
+			PageNo:    page,
+			PageSize:  pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range list.Doms {
+			result = append(result, registry.ServiceInfo{Name: name})
+		}
+		if len(list.Doms) < pageSize || int64(len(result)) >= list.Count {
+			return result, nil
+		}
+	}
+}
+
+func (c *NacosDiscoveryClient) GetService(serviceId string) (*registry.ServiceInfo, error) {
+	instances, err := c.GetInstances(serviceId)
+	if err != nil {
+		return nil, err
+	}
+	return &registry.ServiceInfo{Name: serviceId, Instances: instances}, nil
+}
+
+// 包含不健康的实例（状态为 DOWN），由负载均衡器过滤
+func (c *NacosDiscoveryClient) GetInstances(serviceId string) ([]registry.InstanceInfo, error) {
+	instances, err := c.client.SelectAllInstances(vo.SelectAllInstancesParam{
+		ServiceName: c.ServiceName(serviceId),
+		GroupName:   c.setting.Group,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.mapInstances(serviceId, instances), nil
+}
+
+func (c *NacosDiscoveryClient) mapInstances(serviceId string, instances []model.Instance) []registry.InstanceInfo {
+	result := make([]registry.InstanceInfo, 0, len(instances))
+	for i := range instances {
+		if instance := c.Mapper(serviceId, &instances[i]); instance != nil {
+			result = append(result, *instance)
+		}
+	}
+	return result
+}
+
+func (c *NacosDiscoveryClient) GetInstance(serviceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return registry.ChooseInstance(serviceId, instances)
+	}
+	return nil
+}
+
+func (c *NacosDiscoveryClient) GetInstanceById(serviceId string, instanceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		for i := range instances {
+			if instances[i].InstanceId == instanceId {
+				return &instances[i]
+			}
+		}
+	}
+	return nil
+}
+
+// 临时实例的心跳由 SDK 定时发送，持久实例由服务端探测
+func (c *NacosDiscoveryClient) SendHeartBeat(instance *registry.InstanceInfo, status string) (bool, error) {
+	return true, nil
+}
+
+func (c *NacosDiscoveryClient) Subscribe(serviceId string, callback func(instances []registry.InstanceInfo)) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.subscriptions[serviceId]; exists {
+		return nil
+	}
+
+	param := &vo.SubscribeParam{
+		ServiceName: c.ServiceName(serviceId),
+		GroupName:   c.setting.Group,
+		SubscribeCallback: func(services []model.SubscribeService, err error) {
+			if err != nil {
+				logger.Warn("Nacos subscribe callback error: ", serviceId, err.Error())
+				return
+			}
+
+			instances := make([]model.Instance, 0, len(services))
+			for _, service := range services {
+				instances = append(instances, model.Instance{
+					InstanceId:  service.InstanceId,
+					Ip:          service.Ip,
+					Port:        service.Port,
+					Weight:      service.Weight,
+					Metadata:    service.Metadata,
+					ClusterName: service.ClusterName,
+					ServiceName: service.ServiceName,
+					Enable:      service.Enable,
+					Healthy:     service.Healthy,
+				})
+			}
+			callback(c.mapInstances(serviceId, instances))
+		},
+	}
+	if err := c.client.Subscribe(param); err != nil {
+		return err
+	}
+	c.subscriptions[serviceId] = param
+	return nil
+}
+
+func (c *NacosDiscoveryClient) Unsubscribe(serviceId string) error {
+	c.mutex.Lock()
+	param, exists := c.subscriptions[serviceId]
+	delete(c.subscriptions, serviceId)
+	c.mutex.Unlock()
+
+	if !exists {
+		return nil
+	}
+	return c.client.Unsubscribe(param)
+}
+
+// 权重按 100 倍取整，保持实例间的比例
+func MapInstance(serviceId string, instance *model.Instance) *registry.InstanceInfo {
+	status := registry.STATUS_UP
+	if !instance.Enable || !instance.Healthy || instance.Weight <= 0 {
+		status = registry.STATUS_DOWN
+	}
+
+	result := &registry.InstanceInfo{
+		ServiceName: serviceId,
+		InstanceId:  instance.InstanceId,
+		IpAddr:      instance.Ip,
+		Port:        registry.PortInfo{Port: int(instance.Port), Enabled: true},
+		Status:      status,
+		Metadata: registry.Metadata{
+			ManagementPort: instance.Metadata["management.port"],
+			Weight:         strconv.Itoa(max(int(math.Round(instance.Weight*100)), 1)),
+			Zone:           instance.Metadata["zone"],
+		},
+	}
+
+	if secure, err := strconv.ParseBool(instance.Metadata["secure"]); err == nil && secure {
+		result.SecurePort = registry.PortInfo{Port: int(instance.Port), Enabled: true}
+	}
+	return result
+}
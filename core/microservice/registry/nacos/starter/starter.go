@@ -1 +1,12 @@
 package starter
+
+import (
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/nacos"
+)
+
+func init() {
+	registry.RegisterDiscoveryClient("nacos", func() (registry.DiscoveryClient, error) {
+		return nacos.CreateNacosDiscoveryClient()
+	})
+}
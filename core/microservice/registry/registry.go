@@ -3,7 +3,6 @@ package registry
 import (
 	"errors"
 	"fmt"
-	"math/rand"
 	"net"
 	"sync"
 	"time"
@@ -49,6 +48,8 @@ type LeaseInfo struct {
 type Metadata struct {
 	ManagementPort string `json:"management.port,omitempty"`
 	JmxPort        string `json:"jmx.port,omitempty"`
+	Weight         string `json:"weight,omitempty"` // 负载均衡权重
+	Zone           string `json:"zone,omitempty"`   // 所在区域
 }
 
 type InstanceInfo struct {
@@ -75,8 +76,24 @@ type InstanceInfo struct {
 	ActionType                    string         `json:"actionType,omitempty"`
 }
 
-// 服务发现
+// 服务发现：Register/Deregister 传入 RegistryClient 持有的实例信息，
+// 旧版无参数的实现通过 AdaptLegacyDiscoveryClient 接入
 type DiscoveryClient interface {
+	Register(instance *InstanceInfo) (bool, error)
+	Deregister(instance *InstanceInfo) error
+
+	GetServices() ([]ServiceInfo, error)
+	GetService(serviceId string) (*ServiceInfo, error)
+
+	GetInstances(serviceId string) ([]InstanceInfo, error)
+	GetInstance(serviceId string) *InstanceInfo
+	GetInstanceById(serviceId string, instanceId string) *InstanceInfo
+
+	SendHeartBeat(instance *InstanceInfo, status string) (bool, error)
+}
+
+// 旧版服务发现接口：实现自行持有注册的实例信息
+type LegacyDiscoveryClient interface {
 	Register() (bool, error)
 	Deregister() error
 
@@ -90,6 +107,52 @@ type DiscoveryClient interface {
 	SendHeartBeat(instance *InstanceInfo, status string) (bool, error)
 }
 
+type legacyDiscoveryClient struct {
+	LegacyDiscoveryClient
+}
+
+func (c *legacyDiscoveryClient) Register(*InstanceInfo) (bool, error) {
+	return c.LegacyDiscoveryClient.Register()
+}
+
+func (c *legacyDiscoveryClient) Deregister(*InstanceInfo) error {
+	return c.LegacyDiscoveryClient.Deregister()
+}
+
+// 旧版实现适配为 DiscoveryClient，忽略传入的实例信息
+func AdaptLegacyDiscoveryClient(client LegacyDiscoveryClient) DiscoveryClient {
+	return &legacyDiscoveryClient{LegacyDiscoveryClient: client}
+}
+
+// 支持推送实例变化的注册中心（如 Nacos），回调参数为服务的全部实例
+type Subscriber interface {
+	Subscribe(serviceId string, callback func(instances []InstanceInfo)) error
+	Unsubscribe(serviceId string) error
+}
+
+var (
+	discoveryClientsMutex sync.RWMutex
+	discoveryClients      = make(map[string]func() (DiscoveryClient, error))
+)
+
+// 注册服务发现实现，由 RegistrySetting.Type 选择
+func RegisterDiscoveryClient(name string, factory func() (DiscoveryClient, error)) {
+	discoveryClientsMutex.Lock()
+	defer discoveryClientsMutex.Unlock()
+	discoveryClients[name] = factory
+}
+
+func CreateDiscoveryClient(name string) (DiscoveryClient, error) {
+	discoveryClientsMutex.RLock()
+	factory, exists := discoveryClients[name]
+	discoveryClientsMutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown registry type: %q", name)
+	}
+	return factory()
+}
+
 type AbstractDiscoveryClient struct {
 }
 
@@ -121,16 +184,16 @@ func (c *AbstractDiscoveryClient) GetInstances(serviceId string) ([]InstanceInfo
 }
 
 func (c *AbstractDiscoveryClient) GetInstance(serviceId string) *InstanceInfo {
-	if instances, err := c.GetInstances(serviceId); err == nil && len(instances) > 0 {
-		activeInstances := []*InstanceInfo{}
-		for i, ii := range instances {
-			if ii.Status == "UP" {
-				activeInstances = append(activeInstances, &instances[i])
-			}
-		}
-		if len(activeInstances) > 0 {
-			return activeInstances[rand.Intn(len(activeInstances))]
-		}
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return ChooseInstance(serviceId, instances)
+	}
+	return nil
+}
+
+// 使用全局负载均衡器从实例中选择一个健康实例
+func ChooseInstance(serviceId string, instances []InstanceInfo) *InstanceInfo {
+	if endpoint, err := DefaultBalancer().Choose(serviceId, Endpoints(instances)); err == nil {
+		return endpoint.Instance
 	}
 	return nil
 }
@@ -146,7 +209,7 @@ func (c *AbstractDiscoveryClient) GetInstanceById(serviceId string, instanceId s
 	return nil
 }
 
-func (*AbstractDiscoveryClient) SendHeartBeat() (bool, error) {
+func (*AbstractDiscoveryClient) SendHeartBeat(instance *InstanceInfo, status string) (bool, error) {
 	return true, nil
 }
 
@@ -161,17 +224,21 @@ func (*AbstractDiscoveryClient) SendHeartBeat() (bool, error) {
 
 type Client interface {
 	GetServiceEntry(service string) (string, error)
+
+	// 按负载均衡策略选择实例，excludes 为本次请求已失败的实例
+	Choose(service string, excludes ...string) (*Endpoint, error)
 }
 
 type RegistryClient struct {
 	InstanceInfo
-	discoveryClient DiscoveryClient `inject:"discoveryClient"`
+	discoveryClient DiscoveryClient
 	services        *cache.Cache
+	subscribed      sync.Map
 	wg              sync.WaitGroup
 	closeChan       chan struct{}
 }
 
-func NewRegistryClient() *RegistryClient {
+func NewRegistryClient(discoveryClient DiscoveryClient) *RegistryClient {
 	currentTimeStr := fmt.Sprintf("%d", time.Now().UnixNano()/1000000)
 	return &RegistryClient{
 		InstanceInfo: InstanceInfo{
@@ -184,14 +251,18 @@ func NewRegistryClient() *RegistryClient {
 				Port:    config.Setting.Port,
 				Enabled: true,
 			},
-			OverriddenStatus:     STATUS_UP,
+			OverriddenStatus: STATUS_UP,
+			Metadata: Metadata{
+				Zone: config.Setting.LoadBalancer.Zone,
+			},
 			VipAddress:           config.Setting.ServiceName,
 			SecureVipAddress:     config.Setting.ServiceName,
 			LastUpdatedTimestamp: currentTimeStr,
 			LastDirtyTimestamp:   currentTimeStr,
 		},
-		services:  cache.New(time.Minute*5, time.Minute),
-		closeChan: make(chan struct{}),
+		discoveryClient: discoveryClient,
+		services:        cache.New(time.Minute*5, time.Minute),
+		closeChan:       make(chan struct{}),
 	}
 }
 
@@ -296,16 +367,36 @@ func (s *RegistryClient) GetService(name string) *ServiceInfo {
 	}
 
 	// 从注册中心获取服务
-	if service, err := s.discoveryClient.GetService(name); err == nil {
+	if service, err := s.discoveryClient.GetService(name); err == nil && service != nil {
 		if instances, err := s.discoveryClient.GetInstances(service.Name); err == nil {
 			service.Instances = instances
 		}
 		s.services.SetDefault(name, service)
+		s.subscribe(name)
 		return service
 	}
 	return nil
 }
 
+// 订阅实例变化，推送到达时直接更新缓存
+func (s *RegistryClient) subscribe(name string) {
+	subscriber, ok := s.discoveryClient.(Subscriber)
+	if !ok {
+		return
+	}
+	if _, loaded := s.subscribed.LoadOrStore(name, true); loaded {
+		return
+	}
+
+	err := subscriber.Subscribe(name, func(instances []InstanceInfo) {
+		s.services.SetDefault(name, &ServiceInfo{Name: name, Instances: instances})
+	})
+	if err != nil {
+		s.subscribed.Delete(name)
+		logger.Warn("Subscribe service error: ", name, err.Error())
+	}
+}
+
 func (s *RegistryClient) GetInstances(serviceName string) []InstanceInfo {
 	if si := s.GetService(serviceName); si != nil {
 		return si.Instances
@@ -315,9 +406,7 @@ func (s *RegistryClient) GetInstances(serviceName string) []InstanceInfo {
 
 func (s *RegistryClient) GetInstance(serviceName string) *InstanceInfo {
 	if si := s.GetService(serviceName); si != nil {
-		if len(si.Instances) > 0 {
-			return &si.Instances[rand.Intn(len(si.Instances))]
-		}
+		return ChooseInstance(serviceName, si.Instances)
 	}
 	return nil
 }
@@ -337,28 +426,39 @@ func (s *RegistryClient) GetInstanceById(serviceName string, instanceId string)
 
 func (s *RegistryClient) Register() (bool, error) {
 	// 微服务应用启动时调用此进行注册
-	return s.discoveryClient.Register()
+	return s.discoveryClient.Register(&s.InstanceInfo)
 }
 
 func (s *RegistryClient) Deregister() error {
 	// 微服务应用启动时调用此进行注册
-	return s.discoveryClient.Deregister()
+	return s.discoveryClient.Deregister(&s.InstanceInfo)
 }
 
-func (s *RegistryClient) GetSerivceEntry(serviceName string) (string, error) {
+func (s *RegistryClient) GetServiceEntry(serviceName string) (string, error) {
+	if endpoint, err := s.Choose(serviceName); err == nil {
+		return endpoint.Url, nil
+	} else if errors.Is(err, ErrServiceNotFound) {
+		return "", nil
+	} else {
+		return "", err
+	}
+}
+
+func (s *RegistryClient) Choose(serviceName string, excludes ...string) (*Endpoint, error) {
 	if si := s.GetService(serviceName); si != nil {
-		if len(si.Instances) > 0 {
-			instanceInfo := si.Instances[rand.Intn(len(si.Instances))]
-			return fmt.Sprintf("http://%s:%d", instanceInfo.HostName, instanceInfo.Port.Port), nil
-		} else {
-			return "", errors.New("no instance")
-		}
+		return DefaultBalancer().Choose(serviceName, Endpoints(si.Instances), excludes...)
 	}
-	return "", nil
+	return nil, ErrServiceNotFound
 }
 
 func (s *RegistryClient) Shutdown() {
 	close(s.closeChan)
+	if subscriber, ok := s.discoveryClient.(Subscriber); ok {
+		s.subscribed.Range(func(name, _ any) bool {
+			_ = subscriber.Unsubscribe(name.(string))
+			return true
+		})
+	}
 	s.Deregister()
 	s.wg.Wait()
 }
@@ -367,12 +467,12 @@ func getPreferIP() string {
 	if config.Setting.PreferIP != "" {
 		return config.Setting.PreferIP
 	} else {
-		return getLocalIP()
+		return LocalIP()
 	}
 }
 
 // get local ip address
-func getLocalIP() string {
+func LocalIP() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
 		return ""
@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/gophab/gophrame/core/microservice/registry/config"
+	ConsulConfig "github.com/gophab/gophrame/core/microservice/registry/consul/config"
+	NacosConfig "github.com/gophab/gophrame/core/microservice/registry/nacos/config"
+)
+
+type legacyClient struct {
+	AbstractDiscoveryClient
+	registered bool
+}
+
+func (c *legacyClient) Register() (bool, error) {
+	c.registered = true
+	return true, nil
+}
+
+func (c *legacyClient) Deregister() error {
+	c.registered = false
+	return nil
+}
+
+func TestAdaptLegacyDiscoveryClient(t *testing.T) {
+	legacy := &legacyClient{}
+	RegisterDiscoveryClient("legacy", func() (DiscoveryClient, error) {
+		return AdaptLegacyDiscoveryClient(legacy), nil
+	})
+
+	client, err := CreateDiscoveryClient("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistryClient(client)
+	if ok, err := registry.Register(); !ok || err != nil || !legacy.registered {
+		t.Fatalf("register = %v, %v", ok, err)
+	}
+	if err := registry.Deregister(); err != nil || legacy.registered {
+		t.Fatalf("deregister = %v", err)
+	}
+
+	if _, err := CreateDiscoveryClient("unknown"); err == nil {
+		t.Fatal("expected unknown registry type error")
+	}
+}
+
+func TestBackend(t *testing.T) {
+	setting := &config.RegistrySetting{
+		Consul: &ConsulConfig.ConsulSetting{Enabled: true},
+		Nacos:  &NacosConfig.NacosSetting{Enabled: true},
+	}
+	if backend := setting.Backend(); backend != "consul" {
+		t.Fatalf("expected consul, got %q", backend)
+	}
+	setting.Type = "nacos"
+	if backend := setting.Backend(); backend != "nacos" {
+		t.Fatalf("expected nacos, got %q", backend)
+	}
+}
@@ -7,21 +7,39 @@ import (
 	_ "github.com/gophab/gophrame/core/microservice/registry/nacos/starter"
 
 	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/microservice/registry"
 	"github.com/gophab/gophrame/core/microservice/registry/config"
 	"github.com/gophab/gophrame/core/starter"
 )
 
+var registryClient *registry.RegistryClient
+
 func init() {
 	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
 }
 
 func Start() {
 	if config.Setting.Enabled {
+		// 按配置选择注册中心
+		discoveryClient, err := registry.CreateDiscoveryClient(config.Setting.Backend())
+		if err != nil {
+			logger.Error("Create discovery client error: ", err.Error())
+			return
+		}
+		inject.InjectValue("discoveryClient", discoveryClient)
+
 		// 启动RegistryClient
-		registryClient := registry.NewRegistryClient()
+		registryClient = registry.NewRegistryClient(discoveryClient)
 		inject.InjectValue("registryClient", registryClient)
 
 		registryClient.Init()
 	}
 }
+
+func Terminate() {
+	if registryClient != nil {
+		registryClient.Shutdown()
+	}
+}
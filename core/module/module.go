@@ -1,9 +1,15 @@
 package module
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/gophab/gophrame/core/database"
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/starter"
 )
@@ -13,13 +19,13 @@ type IModule interface {
 	Register()
 
 	// Call when initializing, after init config
-	Init()
+	Init() error
 
 	// Call when start, after root router initialized
-	Start()
+	Start() error
 
 	// Call before exit
-	Terminate()
+	Terminate() error
 }
 
 const (
@@ -28,86 +34,327 @@ const (
 	STATUS_INITIALIZED
 	STATUS_STARTED
 	STATUS_TERMINATED
+	STATUS_FAILED
 )
 
+var statusNames = map[int]string{
+	STATUS:             "UNKNOWN",
+	STATUS_REGISTERED:  "REGISTERED",
+	STATUS_INITIALIZED: "INITIALIZED",
+	STATUS_STARTED:     "STARTED",
+	STATUS_TERMINATED:  "TERMINATED",
+	STATUS_FAILED:      "FAILED",
+}
+
+// 默认终止超时
+const DEFAULT_TERMINATE_TIMEOUT = 10 * time.Second
+
 type Module struct {
 	IModule
 	Name        string
 	Description string
+	DependsOn   []string // 依赖的模块名，依赖先于本模块初始化、启动，后于本模块终止
 	Initializor func(m *Module)
 	Starter     func(m *Module)
 	Terminater  func(m *Module)
-	Priority    int
+	OnInit      func(m *Module) error // 与 Initializor 相同，返回错误时启动失败
+	OnStart     func(m *Module) error
+	OnTerminate func(m *Module) error
+	Migrations  fs.FS         // <dialect>/<version>_<name>.up.sql，Init 时自动执行
+	Priority    int           // 无依赖关系的模块之间按 Priority 排序
+	Timeout     time.Duration // 终止超时，默认 DEFAULT_TERMINATE_TIMEOUT
 	Status      int
+	Error       error
 }
 
-func (m *Module) Terminate() {
-	if m.Terminater != nil {
+func (m *Module) Terminate() error {
+	if m.Terminater != nil || m.OnTerminate != nil {
 		logger.Debug("[MODULE] Terminating module: ", m.Name)
+	}
+	if m.Terminater != nil {
 		m.Terminater(m)
 	}
+	if m.OnTerminate != nil {
+		return m.OnTerminate(m)
+	}
+	return nil
 }
 
-func (m *Module) Start() {
-	if m.Starter != nil {
+func (m *Module) Start() error {
+	if m.Starter != nil || m.OnStart != nil {
 		logger.Debug("[MODULE] Starting module: ", m.Name)
+	}
+	if m.Starter != nil {
 		m.Starter(m)
 	}
+	if m.OnStart != nil {
+		return m.OnStart(m)
+	}
+	return nil
 }
 
-func (m *Module) Init() {
-	if m.Initializor != nil {
+func (m *Module) Init() error {
+	if m.Migrations != nil {
+		logger.Debug("[MODULE] Migrating module: ", m.Name)
+		if err := database.AutoMigrate(database.GetMigrationSource(m.Name)); err != nil {
+			return err
+		}
+	}
+
+	if m.Initializor != nil || m.OnInit != nil {
 		logger.Debug("[MODULE] Initializing module: ", m.Name)
+	}
+	if m.Initializor != nil {
 		m.Initializor(m)
 	}
+	if m.OnInit != nil {
+		return m.OnInit(m)
+	}
+	return nil
 }
 
 func (*Module) Register() {}
 
-var modules = make([]*Module, 0)
+// 模块状态
+type ModuleInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	DependsOn   []string `json:"dependsOn,omitempty"`
+	Priority    int      `json:"priority"`
+	Status      string   `json:"status"`
+	Error       string   `json:"error,omitempty"`
+}
+
+var (
+	mutex   sync.RWMutex
+	modules = make([]*Module, 0)
+	ordered []*Module
+)
 
 func RegisterModule(mod *Module) {
+	mutex.Lock()
 	modules = append(modules, mod)
+	mutex.Unlock()
+
+	if mod.Migrations != nil {
+		database.RegisterModuleMigrations(mod.Name, mod.Migrations)
+	}
 	mod.Register()
 	mod.Status = STATUS_REGISTERED
 }
 
+func GetModule(name string) *Module {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, mod := range modules {
+		if mod.Name == name {
+			return mod
+		}
+	}
+	return nil
+}
+
+// 按启动顺序返回模块状态
+func Modules() []*ModuleInfo {
+	mutex.RLock()
+	list := ordered
+	if list == nil {
+		list = modules
+	}
+	mutex.RUnlock()
+
+	result := make([]*ModuleInfo, 0, len(list))
+	for _, mod := range list {
+		info := &ModuleInfo{
+			Name:        mod.Name,
+			Description: mod.Description,
+			DependsOn:   mod.DependsOn,
+			Priority:    mod.Priority,
+			Status:      statusNames[mod.Status],
+		}
+		if mod.Error != nil {
+			info.Error = mod.Error.Error()
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// 按 DependsOn 拓扑排序，同一层级按 Priority、注册顺序排序
+func Sort(mods []*Module) ([]*Module, error) {
+	index := make(map[string]*Module, len(mods))
+	for _, mod := range mods {
+		if _, b := index[mod.Name]; b {
+			return nil, fmt.Errorf("duplicated module: %s", mod.Name)
+		}
+		index[mod.Name] = mod
+	}
+
+	degree := make(map[*Module]int, len(mods))
+	dependents := make(map[*Module][]*Module, len(mods))
+	for _, mod := range mods {
+		for _, name := range mod.DependsOn {
+			dep, b := index[name]
+			if !b {
+				return nil, fmt.Errorf("module %s depends on unknown module: %s", mod.Name, name)
+			}
+			degree[mod]++
+			dependents[dep] = append(dependents[dep], mod)
+		}
+	}
+
+	position := make(map[*Module]int, len(mods))
+	for i, mod := range mods {
+		position[mod] = i
+	}
+
+	var ready []*Module
+	for _, mod := range mods {
+		if degree[mod] == 0 {
+			ready = append(ready, mod)
+		}
+	}
+
+	result := make([]*Module, 0, len(mods))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool {
+			if ready[i].Priority != ready[j].Priority {
+				return ready[i].Priority < ready[j].Priority
+			}
+			return position[ready[i]] < position[ready[j]]
+		})
+
+		mod := ready[0]
+		ready = ready[1:]
+		result = append(result, mod)
+
+		for _, dependent := range dependents[mod] {
+			if degree[dependent]--; degree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(result) < len(mods) {
+		var cycle []string
+		for _, mod := range mods {
+			if degree[mod] > 0 {
+				cycle = append(cycle, mod.Name)
+			}
+		}
+		return nil, fmt.Errorf("module dependency cycle: %s", strings.Join(cycle, ", "))
+	}
+	return result, nil
+}
+
+// 执行模块生命周期函数，panic 视为失败
+func call(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f()
+}
+
+func fail(mod *Module, stage string, err error) error {
+	mod.Status = STATUS_FAILED
+	mod.Error = err
+	logger.Error("[MODULE] ", stage, " module error: ", mod.Name, err.Error())
+	return fmt.Errorf("%s module %s: %w", strings.ToLower(stage), mod.Name, err)
+}
+
 var (
-	onceInit, onceStart sync.Once
+	onceInit, onceStart, onceTerminate sync.Once
+	initError, startError              error
 )
 
-func Init() {
+func Init() error {
 	onceInit.Do(func() {
-		sort.Slice(modules, func(i, j int) bool {
-			return modules[i].Priority < modules[j].Priority
-		})
-		for _, mod := range modules {
-			mod.Init()
+		mutex.Lock()
+		ordered, initError = Sort(modules)
+		list := ordered
+		mutex.Unlock()
+
+		if initError != nil {
+			return
+		}
+
+		for _, mod := range list {
+			if err := call(mod.Init); err != nil {
+				initError = fail(mod, "Initializing", err)
+				return
+			}
 			mod.Status = STATUS_INITIALIZED
 		}
 	})
+	return initError
 }
 
-func Start() {
+func Start() error {
 	onceStart.Do(func() {
-		for _, mod := range modules {
-			mod.Start()
+		mutex.RLock()
+		list := ordered
+		mutex.RUnlock()
+
+		for _, mod := range list {
+			if mod.Status != STATUS_INITIALIZED {
+				continue
+			}
+			if err := call(mod.Start); err != nil {
+				startError = fail(mod, "Starting", err)
+				return
+			}
 			mod.Status = STATUS_STARTED
 		}
 	})
+	return startError
 }
 
-func Terminate() {
-	onceStart.Do(func() {
-		for _, mod := range modules {
-			mod.Terminate()
-			mod.Status = STATUS_TERMINATED
+// 按启动的逆序终止，单个模块超时或失败不影响其他模块
+func Terminate() error {
+	var errs []error
+	onceTerminate.Do(func() {
+		mutex.RLock()
+		list := ordered
+		mutex.RUnlock()
+
+		for i := len(list) - 1; i >= 0; i-- {
+			mod := list[i]
+			if mod.Status != STATUS_INITIALIZED && mod.Status != STATUS_STARTED {
+				continue
+			}
+
+			timeout := mod.Timeout
+			if timeout <= 0 {
+				timeout = DEFAULT_TERMINATE_TIMEOUT
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- call(mod.Terminate)
+			}()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(timeout):
+				err = fmt.Errorf("timeout after %s", timeout)
+			}
+
+			if err != nil {
+				errs = append(errs, fail(mod, "Terminating", err))
+			} else {
+				mod.Status = STATUS_TERMINATED
+			}
 		}
 	})
+	return errors.Join(errs...)
 }
 
 func init() {
-	starter.RegisterInitializorEx(Init, 0x0FFFFFFF)
-	starter.RegisterStarterEx(Start, 0x0FFFFFFF)
-	starter.RegisterTerminaterEx(Terminate, -0x0FFFFFFF)
+	starter.RegisterInitializorFunc(Init, 0x0FFFFFFF)
+	starter.RegisterStarterFunc(Start, 0x0FFFFFFF)
+	starter.RegisterTerminaterFunc(Terminate, -0x0FFFFFFF)
 }
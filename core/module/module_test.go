@@ -0,0 +1,74 @@
+package module
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func names(mods []*Module) string {
+	var result []string
+	for _, mod := range mods {
+		result = append(result, mod.Name)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestSortByDependency(t *testing.T) {
+	mods := []*Module{
+		{Name: "SystemV1", DependsOn: []string{"System"}},
+		{Name: "Slink", Priority: -1},
+		{Name: "System", DependsOn: []string{"Common"}},
+		{Name: "Common"},
+	}
+
+	result, err := Sort(mods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(result); got != "Slink,Common,System,SystemV1" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
+
+func TestSortErrors(t *testing.T) {
+	if _, err := Sort([]*Module{{Name: "A", DependsOn: []string{"B"}}}); err == nil || !strings.Contains(err.Error(), "unknown module") {
+		t.Fatalf("expected unknown module error, got %v", err)
+	}
+	if _, err := Sort([]*Module{{Name: "A", DependsOn: []string{"B"}}, {Name: "B", DependsOn: []string{"A"}}}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	if _, err := Sort([]*Module{{Name: "A"}, {Name: "A"}}); err == nil {
+		t.Fatal("expected duplicated module error")
+	}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	var calls []string
+	mod := &Module{
+		Name:        "Hooks",
+		Initializor: func(m *Module) { calls = append(calls, "init") },
+		OnInit:      func(m *Module) error { calls = append(calls, "onInit"); return nil },
+		Starter:     func(m *Module) { calls = append(calls, "start") },
+		OnStart:     func(m *Module) error { return errors.New("start failed") },
+		Terminater:  func(m *Module) { calls = append(calls, "terminate") },
+	}
+
+	if err := mod.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(mod.Start); err == nil || err.Error() != "start failed" {
+		t.Fatalf("expected start error, got %v", err)
+	}
+	if err := mod.Terminate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ","); got != "init,onInit,start,terminate" {
+		t.Fatalf("unexpected calls: %s", got)
+	}
+
+	panicking := &Module{Name: "Panic", Starter: func(m *Module) { panic("boom") }}
+	if err := call(panicking.Start); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected panic error, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	CoreConfig "github.com/gophab/gophrame/core/config"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/json"
 	"github.com/gophab/gophrame/core/logger"
@@ -99,7 +100,7 @@ func init() {
 
 func InitDB() (*MongoDB, error) {
 	Setting := config.Setting
-	logger.Debug("Mongo Settings: ", json.String(Setting))
+	logger.Debug("Mongo Settings: ", CoreConfig.Mask(json.String(Setting)))
 
 	clientOpts := options.Client().
 		ApplyURI(fmt.Sprintf("mongodb://%s:%d/?connect=direct", config.Setting.Host, config.Setting.Port)).
@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/gophab/gophrame/core/email"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/sms"
+	"github.com/gophab/gophrame/core/social"
+	"github.com/gophab/gophrame/core/websocket"
+)
+
+func init() {
+	RegisterChannel(&WebsocketChannel{})
+	RegisterChannel(emailChannel)
+	RegisterChannel(smsChannel)
+	RegisterChannel(&WorkMessageChannel{Channel: CHANNEL_WECOM, SocialType: "ww"})
+	RegisterChannel(&WorkMessageChannel{Channel: CHANNEL_DINGTALK, SocialType: "dt"})
+	RegisterChannel(&WorkMessageChannel{Channel: CHANNEL_FEISHU, SocialType: "fs"})
+}
+
+// 推送给用户在集群内的全部连接
+type WebsocketChannel struct{}
+
+type WebsocketMessage struct {
+	Type    string `json:"type"`
+	Scene   string `json:"scene"`
+	From    string `json:"from,omitempty"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+func (c *WebsocketChannel) Name() string {
+	return CHANNEL_WEBSOCKET
+}
+
+func (c *WebsocketChannel) Send(ctx context.Context, recipient *Recipient, message *Message) error {
+	if websocket.GetHub() == nil {
+		return ErrUnavailable
+	}
+	if !websocket.IsOnline(recipient.UserId) {
+		return ErrOffline
+	}
+
+	return websocket.SendToUser(recipient.UserId, &WebsocketMessage{
+		Type:    "notification",
+		Scene:   message.Scene,
+		From:    message.From,
+		Title:   message.Title,
+		Content: message.Content,
+	})
+}
+
+// 邮件：Title 为主题，Content 为 HTML 正文
+type EmailChannel struct {
+	Sender email.EmailSender `inject:"emailSender,optional"`
+}
+
+var emailChannel = &EmailChannel{}
+
+func init() {
+	inject.InjectValue("emailChannel", emailChannel)
+}
+
+func (c *EmailChannel) Name() string {
+	return CHANNEL_EMAIL
+}
+
+func (c *EmailChannel) Send(ctx context.Context, recipient *Recipient, message *Message) error {
+	if c.Sender == nil {
+		return ErrUnavailable
+	}
+	if recipient.Email == "" {
+		return ErrNoAddress
+	}
+
+	params := make(map[string]string, len(message.Params)+1)
+	for k, v := range message.Params {
+		params[k] = v
+	}
+	params["title"] = message.Title
+	return c.Sender.SendTemplateEmail(recipient.Email, message.Content, params)
+}
+
+// 短信：使用服务商模板，Template 为模板编号
+type SmsChannel struct {
+	Sender sms.SmsSender `inject:"smsSender,optional"`
+}
+
+var smsChannel = &SmsChannel{}
+
+func init() {
+	inject.InjectValue("smsChannel", smsChannel)
+}
+
+func (c *SmsChannel) Name() string {
+	return CHANNEL_SMS
+}
+
+func (c *SmsChannel) Send(ctx context.Context, recipient *Recipient, message *Message) error {
+	if c.Sender == nil {
+		return ErrUnavailable
+	}
+	if recipient.Mobile == "" {
+		return ErrNoAddress
+	}
+	return c.Sender.SendTemplateMessage(recipient.Mobile, cmp.Or(message.Template, message.Scene), message.Params)
+}
+
+// IM 工作消息：发送给接收人绑定的企业微信、钉钉、飞书账号
+type WorkMessageChannel struct {
+	Channel    string
+	SocialType string
+}
+
+func (c *WorkMessageChannel) Name() string {
+	return c.Channel
+}
+
+func (c *WorkMessageChannel) Send(ctx context.Context, recipient *Recipient, message *Message) error {
+	messenger, b := social.GetSocialService(c.SocialType).(social.WorkMessenger)
+	if !b {
+		return ErrUnavailable
+	}
+
+	user, b := recipient.Socials[c.SocialType]
+	if !b || user == nil {
+		return ErrNoAddress
+	}
+	return messenger.SendWorkMessage(ctx, user, message.Title, message.Content)
+}
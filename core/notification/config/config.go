@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type NotificationSetting struct {
+	Enabled       bool     `json:"enabled"`
+	Channels      []string `json:"channels" yaml:"channels"`           // 通知未指定渠道时使用的默认渠道
+	MaxRetries    int      `json:"maxRetries" yaml:"maxRetries"`       // 投递失败后的最大重试次数
+	RetryInterval int      `json:"retryInterval" yaml:"retryInterval"` // 首次重试间隔（秒），之后逐次翻倍
+}
+
+var Setting *NotificationSetting = &NotificationSetting{
+	Enabled:       true,
+	Channels:      []string{"inbox", "websocket"},
+	MaxRetries:    3,
+	RetryInterval: 60,
+}
+
+func init() {
+	logger.Debug("Register Notification Config")
+	config.RegisterConfig("notification", Setting, "Notification Settings")
+}
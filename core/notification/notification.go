@@ -0,0 +1,136 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/social"
+)
+
+// 内置渠道
+const (
+	CHANNEL_INBOX     = "inbox"     // 站内信（sys_message）
+	CHANNEL_WEBSOCKET = "websocket" // 在线推送
+	CHANNEL_EMAIL     = "email"
+	CHANNEL_SMS       = "sms"
+	CHANNEL_WECOM     = "wecom"    // 企业微信工作消息
+	CHANNEL_DINGTALK  = "dingtalk" // 钉钉工作通知
+	CHANNEL_FEISHU    = "feishu"   // 飞书消息
+)
+
+var (
+	// 接收人缺少该渠道的地址（邮箱、手机号、社交账号等），不再重试
+	ErrNoAddress = errors.New("recipient has no address for channel")
+	// 渠道未启用或未配置，不再重试
+	ErrUnavailable = errors.New("channel unavailable")
+	// 用户不在线，推送不再重试
+	ErrOffline = errors.New("recipient is offline")
+)
+
+// 不可重试的错误：没有地址、渠道不可用、不在线
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrNoAddress) || errors.Is(err, ErrUnavailable) || errors.Is(err, ErrOffline)
+}
+
+// 接收人集合，按用户、角色、组织、租户展开
+type Recipients struct {
+	Users         []string `json:"users,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	Organizations []string `json:"organizations,omitempty"`
+	Tenants       []string `json:"tenants,omitempty"`
+}
+
+func (r *Recipients) IsEmpty() bool {
+	return len(r.Users) == 0 && len(r.Roles) == 0 && len(r.Organizations) == 0 && len(r.Tenants) == 0
+}
+
+// 展开后的接收人
+type Recipient struct {
+	UserId   string
+	TenantId string
+	Name     string
+	Mobile   string
+	Email    string
+	Socials  map[string]*social.SocialUser // 社交类型（ww/dt/fs）-> 绑定的社交账号
+}
+
+// 通知请求：Scene 对应内容模板的场景，Params 用于渲染模板
+type Notification struct {
+	Scene      string            `json:"scene"`
+	From       string            `json:"from,omitempty"`
+	TenantId   string            `json:"tenantId,omitempty"`
+	Recipients Recipients        `json:"recipients"`
+	Channels   []string          `json:"channels,omitempty"` // 为空时使用配置的默认渠道
+	Params     map[string]string `json:"params,omitempty"`
+}
+
+// 按渠道、语言渲染后的消息
+type Message struct {
+	Scene    string
+	From     string
+	Title    string
+	Content  string
+	Template string // 渠道侧模板编号（如短信模板），为空时使用 Scene
+	Params   map[string]string
+}
+
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, recipient *Recipient, message *Message) error
+}
+
+var channels sync.Map
+
+func RegisterChannel(channel Channel) {
+	channels.Store(channel.Name(), channel)
+}
+
+func GetChannel(name string) Channel {
+	if channel, b := channels.Load(name); b {
+		return channel.(Channel)
+	}
+	return nil
+}
+
+// 接收人展开，由用户模块实现
+type RecipientResolver interface {
+	ResolveRecipients(tenantId string, recipients *Recipients) ([]*Recipient, error)
+}
+
+// 通知发送，由通知服务实现
+type Notifier interface {
+	Notify(notification *Notification) error
+}
+
+type NotificationWrapper struct {
+	Resolver RecipientResolver `inject:"recipientResolver,optional"`
+	Notifier Notifier          `inject:"notificationService,optional"`
+}
+
+var wrapper = &NotificationWrapper{}
+
+func init() {
+	inject.InjectValue("notificationWrapper", wrapper)
+}
+
+// 未注册接收人展开时，仅支持直接指定用户
+func ResolveRecipients(tenantId string, recipients *Recipients) ([]*Recipient, error) {
+	if wrapper.Resolver != nil {
+		return wrapper.Resolver.ResolveRecipients(tenantId, recipients)
+	}
+
+	result := make([]*Recipient, 0, len(recipients.Users))
+	for _, userId := range recipients.Users {
+		result = append(result, &Recipient{UserId: userId, TenantId: tenantId})
+	}
+	return result, nil
+}
+
+func Notify(notification *Notification) error {
+	if wrapper.Notifier != nil {
+		return wrapper.Notifier.Notify(notification)
+	}
+	return ErrUnavailable
+}
@@ -1,27 +1,33 @@
 package aliyun
 
 import (
+	"context"
+	"errors"
+	"io"
 	"mime/multipart"
+	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
-	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
+	CoreOSS "github.com/gophab/gophrame/core/oss"
 	"github.com/gophab/gophrame/core/oss/aliyun/config"
 	"github.com/gophab/gophrame/core/starter"
-	"github.com/gophab/gophrame/core/util"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
 
+const metaPrefix = "X-Oss-Meta-"
+
 type AliyunOSS struct {
 	Client *oss.Client
 	Bucket *oss.Bucket
 }
 
+var _ CoreOSS.OSS = (*AliyunOSS)(nil)
+
 func CreateAliyunOSS() (*AliyunOSS, error) {
 	endpoint := config.Setting.Endpoint
 	if config.Setting.UseCname {
@@ -48,77 +54,186 @@ func CreateAliyunOSS() (*AliyunOSS, error) {
 	}, nil
 }
 
-var (
-	_re = regexp.MustCompile(("^/"))
-	re_ = regexp.MustCompile(("/.$"))
-)
-
 func (s *AliyunOSS) Upload(file *multipart.FileHeader, prefix string) (string, string, error) {
-	// 读取本地文件。
-	f, err := file.Open()
+	return CoreOSS.UploadFile(s, config.Setting.Path, file, prefix)
+}
+
+func (s *AliyunOSS) UploadLocal(fileName string, prefix string) (string, string, error) {
+	return CoreOSS.UploadLocalFile(s, config.Setting.Path, fileName, prefix)
+}
+
+func putOptions(key string, options *CoreOSS.PutOptions) []oss.Option {
+	var result = []oss.Option{oss.ContentType(CoreOSS.ContentTypeOf(key, options))}
+	if options != nil {
+		for k, v := range options.Metadata {
+			result = append(result, oss.Meta(k, v))
+		}
+	}
+	return result
+}
+
+// 转换对象不存在的错误
+func wrapError(err error) error {
+	var e oss.ServiceError
+	if errors.As(err, &e) && (e.StatusCode == http.StatusNotFound || e.Code == "NoSuchKey") {
+		return CoreOSS.ErrNotFound
+	}
+	return err
+}
+
+func (s *AliyunOSS) Put(ctx context.Context, key string, reader io.Reader, size int64, options *CoreOSS.PutOptions) (*CoreOSS.ObjectInfo, error) {
+	if err := CoreOSS.CheckKey(key); err != nil {
+		return nil, err
+	}
+	opts := append(putOptions(key, options), oss.WithContext(ctx))
+	if size >= 0 {
+		opts = append(opts, oss.ContentLength(size))
+	}
+	if err := s.Bucket.PutObject(key, reader, opts...); err != nil {
+		return nil, err
+	}
+	return s.Stat(ctx, key)
+}
+
+func (s *AliyunOSS) Get(ctx context.Context, key string) (io.ReadCloser, *CoreOSS.ObjectInfo, error) {
+	result, err := s.Bucket.DoGetObject(&oss.GetObjectRequest{ObjectKey: key}, []oss.Option{oss.WithContext(ctx)})
 	if err != nil {
-		return "", "", err
-	}
-	defer f.Close() // 创建文件 defer 关闭
-	// 上传阿里云路径 文件名格式 自己可以改 建议保证唯一性
-	// yunFileTmpPath := filepath.Join("uploads", time.Now().Format("2006-01-02")) + "/" + file.Filename
-	ext := filepath.Ext(file.Filename)
-	yunFileTmpPath := _re.ReplaceAllString(
-		re_.ReplaceAllString(re_.ReplaceAllString(config.Setting.Path, "")+"/"+_re.ReplaceAllString(prefix, ""), "")+
-			"/"+
-			time.Now().Format("20060102/150405_")+util.GenerateRandomString(6)+ext,
-		"")
-
-	// 上传文件流。
-	err = s.Bucket.PutObject(yunFileTmpPath, f)
+		return nil, nil, wrapError(err)
+	}
+	return result.Response.Body, CoreOSS.ObjectInfoOf(key, result.Response.Headers, metaPrefix), nil
+}
+
+func (s *AliyunOSS) Stat(ctx context.Context, key string) (*CoreOSS.ObjectInfo, error) {
+	header, err := s.Bucket.GetObjectDetailedMeta(key, oss.WithContext(ctx))
 	if err != nil {
-		return "", "", err
+		return nil, wrapError(err)
 	}
+	return CoreOSS.ObjectInfoOf(key, header, metaPrefix), nil
+}
 
-	if len(config.Setting.BucketUrl) == 0 {
-		u, err := url.Parse(config.Setting.Endpoint)
-		if err == nil {
-			u.Host = config.Setting.Bucket + "." + u.Host
-			return re_.ReplaceAllString(u.String(), "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
-		}
-		return re_.ReplaceAllString(config.Setting.Endpoint, "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
-	} else {
-		return re_.ReplaceAllString(config.Setting.BucketUrl, "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
+func (s *AliyunOSS) Delete(ctx context.Context, key string) error {
+	return wrapError(s.Bucket.DeleteObject(key, oss.WithContext(ctx)))
+}
+
+func (s *AliyunOSS) List(ctx context.Context, options *CoreOSS.ListOptions) (*CoreOSS.ListResult, error) {
+	if options == nil {
+		options = &CoreOSS.ListOptions{}
+	}
+	if options.Limit <= 0 {
+		options.Limit = CoreOSS.DefaultListLimit
+	}
+
+	opts := []oss.Option{oss.Prefix(options.Prefix), oss.MaxKeys(options.Limit), oss.WithContext(ctx)}
+	if options.Delimiter != "" {
+		opts = append(opts, oss.Delimiter(options.Delimiter))
+	}
+	if options.Marker != "" {
+		opts = append(opts, oss.ContinuationToken(options.Marker))
+	}
+
+	objects, err := s.Bucket.ListObjectsV2(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = &CoreOSS.ListResult{
+		Objects:    make([]*CoreOSS.ObjectInfo, 0, len(objects.Objects)),
+		Prefixes:   objects.CommonPrefixes,
+		NextMarker: objects.NextContinuationToken,
+		Truncated:  objects.IsTruncated,
 	}
+	for _, object := range objects.Objects {
+		result.Objects = append(result.Objects, &CoreOSS.ObjectInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			ETag:         strings.Trim(object.ETag, "\""),
+			LastModified: object.LastModified,
+		})
+	}
+	return result, nil
 }
 
-func (s *AliyunOSS) UploadLocal(fileName string, prefix string) (string, string, error) {
-	// 读取本地文件。
-	f, err := os.Open(fileName)
+func (s *AliyunOSS) Copy(ctx context.Context, src, dst string) error {
+	if err := CoreOSS.CheckKey(dst); err != nil {
+		return err
+	}
+	_, err := s.Bucket.CopyObject(src, dst, oss.WithContext(ctx))
+	return wrapError(err)
+}
+
+func (s *AliyunOSS) URL(key string) string {
+	if len(config.Setting.BucketUrl) > 0 && s.Bucket.BucketName == config.Setting.Bucket {
+		return strings.TrimSuffix(config.Setting.BucketUrl, "/") + "/" + key
+	}
+	if u, err := url.Parse(config.Setting.Endpoint); err == nil && u.Host != "" {
+		u.Host = s.Bucket.BucketName + "." + u.Host
+		return strings.TrimSuffix(u.String(), "/") + "/" + key
+	}
+	return strings.TrimSuffix(config.Setting.Endpoint, "/") + "/" + key
+}
+
+func (s *AliyunOSS) PresignGet(key string, expires time.Duration) (string, error) {
+	return s.Bucket.SignURL(key, oss.HTTPGet, int64(expires.Seconds()))
+}
+
+func (s *AliyunOSS) PresignPut(key string, expires time.Duration, contentType string) (string, error) {
+	if err := CoreOSS.CheckKey(key); err != nil {
+		return "", err
+	}
+	var opts []oss.Option
+	if contentType != "" {
+		opts = append(opts, oss.ContentType(contentType))
+	}
+	return s.Bucket.SignURL(key, oss.HTTPPut, int64(expires.Seconds()), opts...)
+}
+
+func (s *AliyunOSS) multipart(key, uploadId string) oss.InitiateMultipartUploadResult {
+	return oss.InitiateMultipartUploadResult{Bucket: s.Bucket.BucketName, Key: key, UploadID: uploadId}
+}
+
+func (s *AliyunOSS) CreateMultipart(ctx context.Context, key string, options *CoreOSS.PutOptions) (string, error) {
+	if err := CoreOSS.CheckKey(key); err != nil {
+		return "", err
+	}
+	result, err := s.Bucket.InitiateMultipartUpload(key, append(putOptions(key, options), oss.WithContext(ctx))...)
 	if err != nil {
-		return "", "", err
-	}
-	defer f.Close() // 创建文件 defer 关闭
-	// 上传阿里云路径 文件名格式 自己可以改 建议保证唯一性
-	// yunFileTmpPath := filepath.Join("uploads", time.Now().Format("2006-01-02")) + "/" + file.Filename
-	ext := filepath.Ext(fileName)
-	yunFileTmpPath := _re.ReplaceAllString(
-		re_.ReplaceAllString(re_.ReplaceAllString(config.Setting.Path, "")+"/"+_re.ReplaceAllString(prefix, ""), "")+
-			"/"+
-			time.Now().Format("20060102/150405_")+util.GenerateRandomString(6)+ext,
-		"")
-
-	// 上传文件流。
-	err = s.Bucket.PutObject(yunFileTmpPath, f)
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+func (s *AliyunOSS) UploadPart(ctx context.Context, key, uploadId string, number int, reader io.Reader, size int64) (*CoreOSS.Part, error) {
+	part, err := s.Bucket.UploadPart(s.multipart(key, uploadId), reader, size, number, oss.WithContext(ctx))
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
+	return &CoreOSS.Part{Number: part.PartNumber, ETag: part.ETag}, nil
+}
 
-	if len(config.Setting.BucketUrl) == 0 {
-		u, err := url.Parse(config.Setting.Endpoint)
-		if err == nil {
-			u.Host = config.Setting.Bucket + "." + u.Host
-			return re_.ReplaceAllString(u.String(), "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
-		}
-		return re_.ReplaceAllString(config.Setting.Endpoint, "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
-	} else {
-		return re_.ReplaceAllString(config.Setting.BucketUrl, "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
+func (s *AliyunOSS) CompleteMultipart(ctx context.Context, key, uploadId string, parts []*CoreOSS.Part) (*CoreOSS.ObjectInfo, error) {
+	var uploaded = make([]oss.UploadPart, len(parts))
+	for i, part := range parts {
+		uploaded[i] = oss.UploadPart{PartNumber: part.Number, ETag: part.ETag}
+	}
+	if _, err := s.Bucket.CompleteMultipartUpload(s.multipart(key, uploadId), uploaded, oss.WithContext(ctx)); err != nil {
+		return nil, err
+	}
+	return s.Stat(ctx, key)
+}
+
+func (s *AliyunOSS) AbortMultipart(ctx context.Context, key, uploadId string) error {
+	return s.Bucket.AbortMultipartUpload(s.multipart(key, uploadId), oss.WithContext(ctx))
+}
+
+func (s *AliyunOSS) WithBucket(name string) (CoreOSS.OSS, error) {
+	if name == "" {
+		name = config.Setting.Bucket
+	}
+	bucket, err := s.Client.Bucket(name)
+	if err != nil {
+		return nil, err
 	}
+	return &AliyunOSS{Client: s.Client, Bucket: bucket}, nil
 }
 
 func init() {
@@ -5,25 +5,45 @@ import (
 	"github.com/gophab/gophrame/core/logger"
 
 	AliyunConfig "github.com/gophab/gophrame/core/oss/aliyun/config"
+	LocalConfig "github.com/gophab/gophrame/core/oss/local/config"
 	QcloudConfig "github.com/gophab/gophrame/core/oss/qcloud/config"
+	S3Config "github.com/gophab/gophrame/core/oss/s3/config"
 )
 
 type OssSetting struct {
-	Enabled bool `json:"enabled" yaml:"enabled"`
-	Storage struct {
+	Enabled        bool     `json:"enabled" yaml:"enabled"`
+	PresignExpires int      `json:"presignExpires" yaml:"presignExpires"` // 直传地址有效期（秒）
+	PresignTypes   []string `json:"presignTypes" yaml:"presignTypes"`     // 允许直传的 Content-Type
+	Storage        struct {
 		Aliyun *AliyunConfig.AliyunSetting `json:"aliyun" yaml:"aliyun"`
 		Qcloud *QcloudConfig.QcloudSetting `json:"qcloud" yaml:"qcloud"`
+		Local  *LocalConfig.LocalSetting   `json:"local" yaml:"local"`
+		S3     *S3Config.S3Setting         `json:"s3" yaml:"s3"`
 	} `json:"storage" yaml:"storage"`
 }
 
 var Setting *OssSetting = &OssSetting{
-	Enabled: false,
+	Enabled:        false,
+	PresignExpires: 900,
+	PresignTypes: []string{
+		"image/jpeg", "image/png", "image/gif", "image/webp",
+		"application/pdf", "application/zip", "text/plain", "text/csv", "video/mp4",
+		"application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"application/octet-stream",
+	},
 	Storage: struct {
 		Aliyun *AliyunConfig.AliyunSetting `json:"aliyun" yaml:"aliyun"`
 		Qcloud *QcloudConfig.QcloudSetting `json:"qcloud" yaml:"qcloud"`
+		Local  *LocalConfig.LocalSetting   `json:"local" yaml:"local"`
+		S3     *S3Config.S3Setting         `json:"s3" yaml:"s3"`
 	}{
 		Aliyun: AliyunConfig.Setting,
 		Qcloud: QcloudConfig.Setting,
+		Local:  LocalConfig.Setting,
+		S3:     S3Config.Setting,
 	},
 }
 
@@ -1,10 +1,19 @@
 package oss
 
 import (
+	"mime"
+	"mime/multipart"
+	"slices"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/oss/config"
+	"github.com/gophab/gophrame/core/security"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
 	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/service"
 )
 
 type OssController struct {
@@ -20,11 +29,7 @@ func (c *OssController) UploadFile(context *gin.Context) {
 		return
 	}
 
-	if url, _, err := c.Oss.Upload(header, "file"); err == nil {
-		response.Success(context, url)
-	} else {
-		response.SystemErrorMessage(context, 500, err.Error())
-	}
+	c.upload(context, header, "file", "file")
 }
 
 func (c *OssController) UploadImage(context *gin.Context) {
@@ -34,18 +39,91 @@ func (c *OssController) UploadImage(context *gin.Context) {
 		return
 	}
 
-	if url, _, err := c.Oss.Upload(header, "images"); err == nil {
+	c.upload(context, header, "image", "images")
+}
+
+// 启用文件登记时按策略校验并去重保存，否则直接上传到 prefix 目录
+func (c *OssController) upload(context *gin.Context, header *multipart.FileHeader, policy string, prefix string) {
+	if fileService := service.GetFileService(); fileService != nil {
+		file, err := fileService.Save(header, policy, SecurityUtil.GetCurrentUserId(context), SecurityUtil.GetCurrentTenantId(context))
+		switch err {
+		case nil:
+			response.Success(context, file.Url)
+			return
+		case service.ErrFileStorageUnavailable:
+			// 未启用文件登记
+		case service.ErrUnknownFilePolicy, service.ErrFileTooLarge, service.ErrFileTypeNotAllowed:
+			response.FailMessage(context, 400, err.Error())
+			return
+		default:
+			response.SystemErrorMessage(context, 500, err.Error())
+			return
+		}
+	}
+
+	if url, _, err := c.Oss.Upload(header, prefix); err == nil {
 		response.Success(context, url)
 	} else {
 		response.SystemErrorMessage(context, 500, err.Error())
 	}
 }
 
+type PresignRequest struct {
+	FileName    string `json:"fileName" binding:"required"`
+	ContentType string `json:"contentType"`
+	Prefix      string `json:"prefix"`
+}
+
+type PresignResponse struct {
+	Key     string            `json:"key"`
+	Method  string            `json:"method"`
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // 上传时须携带的请求头
+	Expires time.Time         `json:"expires"`
+}
+
+// 浏览器直传：生成对象键和签名的 PUT 地址，上传完成后以对象键访问
+func (c *OssController) PresignUpload(context *gin.Context) {
+	var request PresignRequest
+	if err := context.ShouldBindJSON(&request); err != nil {
+		response.FailMessage(context, 400, err.Error())
+		return
+	}
+	if request.Prefix == "" {
+		request.Prefix = "file"
+	}
+	// 签名总是包含 Content-Type，上传时不能改为其他类型
+	if request.ContentType == "" {
+		request.ContentType = ContentTypeOf(request.FileName, nil)
+	}
+	if mediaType, _, err := mime.ParseMediaType(request.ContentType); err != nil || !slices.Contains(config.Setting.PresignTypes, mediaType) {
+		response.FailMessage(context, 400, "不允许上传的文件类型")
+		return
+	}
+
+	key := GenerateKey("", request.Prefix, request.FileName)
+	expires := time.Duration(config.Setting.PresignExpires) * time.Second
+	url, err := c.Oss.PresignPut(key, expires, request.ContentType)
+	if err != nil {
+		response.SystemErrorMessage(context, 500, err.Error())
+		return
+	}
+
+	response.Success(context, &PresignResponse{
+		Key:     key,
+		Method:  "PUT",
+		Url:     url,
+		Headers: map[string]string{"Content-Type": request.ContentType},
+		Expires: time.Now().Add(expires),
+	})
+}
+
 func (c *OssController) InitRouter(g *gin.RouterGroup) *gin.RouterGroup {
 	oss := g.Group("openapi/oss")
 	{
-		oss.POST("/file", c.UploadFile)   //  上传文件
-		oss.POST("/image", c.UploadImage) //  上传图片
+		oss.POST("/file", c.UploadFile)                                     //  上传文件
+		oss.POST("/image", c.UploadImage)                                   //  上传图片
+		oss.POST("/presign", security.HandleTokenVerify(), c.PresignUpload) // 直传地址
 	}
 	return oss
 }
@@ -0,0 +1,53 @@
+package oss
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 只实现直传签名的存储，记录签名时的 Content-Type
+type presignOSS struct {
+	OSS
+	contentType string
+}
+
+func (o *presignOSS) PresignPut(key string, expires time.Duration, contentType string) (string, error) {
+	o.contentType = contentType
+	return "/upload/" + key, nil
+}
+
+func TestPresignUploadContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := &presignOSS{}
+	router := gin.New()
+	router.POST("/presign", (&OssController{Oss: storage}).PresignUpload)
+
+	presign := func(body string) int {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/presign", bytes.NewBufferString(body)))
+		return recorder.Code
+	}
+
+	for _, body := range []string{
+		`{"fileName":"a.html","contentType":"text/html"}`,
+		`{"fileName":"a.svg","contentType":"image/svg+xml"}`,
+		`{"fileName":"a.html"}`,
+	} {
+		if code := presign(body); code != http.StatusBadRequest {
+			t.Fatalf("%s = %d", body, code)
+		}
+	}
+
+	// 未指定时按扩展名推断，签名总是包含 Content-Type
+	if code := presign(`{"fileName":"a.png"}`); code != http.StatusOK || storage.contentType != "image/png" {
+		t.Fatalf("inferred = %d %q", code, storage.contentType)
+	}
+	if code := presign(`{"fileName":"a.bin","contentType":"application/pdf"}`); code != http.StatusOK || storage.contentType != "application/pdf" {
+		t.Fatalf("explicit = %d %q", code, storage.contentType)
+	}
+}
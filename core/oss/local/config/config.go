@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type LocalSetting struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Root    string `json:"root" yaml:"root"`       // 存储根目录，每个存储桶一个子目录
+	Bucket  string `json:"bucket" yaml:"bucket"`   // 默认存储桶
+	BaseUrl string `json:"baseUrl" yaml:"baseUrl"` // 对象访问地址前缀，需指向本服务的 /openapi/oss/local
+	Secret  string `json:"secret" yaml:"secret"`   // 签名密钥，为空时生成并保存在 Root/.secret
+	Public  bool   `json:"public" yaml:"public"`   // 允许不带签名读取
+	Path    string `json:"path" yaml:"path"`
+
+	MaxPutSize int64 `json:"maxPutSize" yaml:"maxPutSize"` // 直传对象的大小上限（字节），0 为不限制
+}
+
+var Setting *LocalSetting = &LocalSetting{
+	Enabled: false,
+	Root:    "storage",
+	Bucket:  "default",
+	BaseUrl: "/openapi/oss/local",
+
+	MaxPutSize: 100 << 20,
+}
+
+func init() {
+	logger.Debug("Register Oss Config - local")
+	config.RegisterConfig("oss.local", Setting, "Local Storage Settings")
+}
@@ -0,0 +1,138 @@
+package local
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/oss/local/config"
+	"github.com/gophab/gophrame/core/starter"
+	"github.com/gophab/gophrame/core/webservice/response"
+)
+
+// 本地存储的对象访问，供签名地址和浏览器直传使用
+type LocalController struct {
+	Storage *LocalOSS
+}
+
+// 先校验签名再打开存储桶，未签名的请求不会访问文件系统
+func (c *LocalController) storage(ctx *gin.Context, method string, contentType string) (*LocalOSS, string, bool) {
+	bucket, key := ctx.Param("bucket"), strings.TrimPrefix(ctx.Param("key"), "/")
+	public := method == http.MethodGet && config.Setting.Public
+	if !public && !c.Storage.Verify(method, bucket, key, ctx.Query("expires"), ctx.Query("signature"), contentType) {
+		response.Forbidden(ctx, "签名无效或已过期")
+		return nil, "", false
+	}
+
+	storage, err := c.Storage.Open(bucket)
+	if err != nil {
+		response.NotFound(ctx, "存储桶不存在")
+		return nil, "", false
+	}
+	return storage, key, true
+}
+
+func (c *LocalController) GetObject(ctx *gin.Context) {
+	storage, key, ok := c.storage(ctx, http.MethodGet, "")
+	if !ok {
+		return
+	}
+
+	reader, info, err := storage.Get(ctx, key)
+	if err != nil {
+		if err == oss.ErrNotFound || err == oss.ErrInvalidKey {
+			response.NotFound(ctx, "文件不存在")
+		} else {
+			response.SystemError(ctx, err)
+		}
+		return
+	}
+	defer reader.Close()
+
+	// 图片以外的类型（如 SVG、HTML）只作为附件下载，防止在站点域名下执行脚本
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	if !oss.IsInline(info.ContentType) {
+		ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+	}
+	ctx.Header("Content-Type", info.ContentType)
+	if info.ETag != "" {
+		ctx.Header("ETag", "\""+info.ETag+"\"")
+	}
+	for k, v := range info.Metadata {
+		ctx.Header("X-Oss-Meta-"+k, v)
+	}
+	if f, b := reader.(io.ReadSeeker); b {
+		http.ServeContent(ctx.Writer, ctx.Request, "", info.LastModified, f)
+	} else {
+		ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, reader, nil)
+	}
+}
+
+func (c *LocalController) PutObject(ctx *gin.Context) {
+	contentType := ctx.GetHeader("Content-Type")
+	storage, key, ok := c.storage(ctx, http.MethodPut, contentType)
+	if !ok {
+		return
+	}
+
+	if limit := config.Setting.MaxPutSize; limit > 0 {
+		if ctx.Request.ContentLength > limit {
+			response.ErrorMessage(ctx, http.StatusRequestEntityTooLarge, http.StatusRequestEntityTooLarge, "文件过大")
+			return
+		}
+		// 未声明长度时在读取中截断
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+	}
+
+	info, err := storage.Put(ctx, key, ctx.Request.Body, ctx.Request.ContentLength, &oss.PutOptions{ContentType: contentType})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.ErrorMessage(ctx, http.StatusRequestEntityTooLarge, http.StatusRequestEntityTooLarge, "文件过大")
+		} else if err == oss.ErrInvalidKey {
+			response.NotFound(ctx, "文件不存在")
+		} else {
+			response.SystemError(ctx, err)
+		}
+		return
+	}
+	ctx.Header("ETag", "\""+info.ETag+"\"")
+	ctx.Status(http.StatusOK)
+}
+
+func (c *LocalController) InitRouter(g *gin.RouterGroup) *gin.RouterGroup {
+	local := g.Group("openapi/oss/local")
+	{
+		local.GET("/:bucket/*key", c.GetObject)
+		local.HEAD("/:bucket/*key", c.GetObject)
+		local.PUT("/:bucket/*key", c.PutObject)
+	}
+	return local
+}
+
+func init() {
+	starter.RegisterInitializor(Init)
+}
+
+// 控制器需在启动器挂载路由之前注册，因此在初始化阶段创建存储
+func Init() {
+	if config.Setting.Enabled {
+		logger.Info("Start Local OSS...")
+		storage, err := CreateLocalOSS()
+		if err != nil {
+			logger.Error("Create local storage error: ", err.Error())
+			return
+		}
+		inject.InjectValue("oss", storage)
+		controller.AddController(&LocalController{Storage: storage})
+	}
+}
@@ -0,0 +1,524 @@
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/oss/local/config"
+)
+
+// 目录结构：root/<bucket>/<key> 为对象内容，root/.meta/<bucket>/<key>.json 为元数据，
+// root/.uploads/<uploadId>/ 为未完成的分片上传
+const (
+	metaDir    = ".meta"
+	uploadDir  = ".uploads"
+	secretFile = ".secret"
+)
+
+var (
+	bucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	uploadPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+type LocalOSS struct {
+	root   string
+	bucket string
+	secret []byte
+}
+
+var _ oss.OSS = (*LocalOSS)(nil)
+
+type metadata struct {
+	ContentType string            `json:"contentType,omitempty"`
+	ETag        string            `json:"etag,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// 未完成的分片上传
+type upload struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	metadata
+}
+
+func CreateLocalOSS() (*LocalOSS, error) {
+	secret := []byte(config.Setting.Secret)
+	if len(secret) == 0 {
+		var err error
+		if secret, err = loadSecret(config.Setting.Root); err != nil {
+			return nil, err
+		}
+	}
+	return NewLocalOSS(config.Setting.Root, config.Setting.Bucket, secret)
+}
+
+// 未配置密钥时使用存储根目录下保存的密钥，重启和共享同一目录的实例签出的地址仍然有效
+func loadSecret(root string) ([]byte, error) {
+	fileName := filepath.Join(root, secretFile)
+	if data, err := os.ReadFile(fileName); err == nil && len(data) > 0 {
+		return data, nil
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	secret = []byte(hex.EncodeToString(secret))
+
+	// 写完整后再链接到目标文件，多个实例同时启动时以先链接的为准
+	temp, err := os.CreateTemp(root, secretFile+"-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(secret)
+	if e := temp.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Link(temp.Name(), fileName); errors.Is(err, fs.ErrExist) {
+		return os.ReadFile(fileName)
+	} else if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func NewLocalOSS(root string, bucket string, secret []byte) (*LocalOSS, error) {
+	if !bucketPattern.MatchString(bucket) {
+		return nil, errors.New("oss: invalid bucket name: " + bucket)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(root, bucket), 0755); err != nil {
+		return nil, err
+	}
+	return &LocalOSS{root: root, bucket: bucket, secret: secret}, nil
+}
+
+func (s *LocalOSS) file(key string) string {
+	return filepath.Join(s.root, s.bucket, filepath.FromSlash(key))
+}
+
+func (s *LocalOSS) metaFile(key string) string {
+	return filepath.Join(s.root, metaDir, s.bucket, filepath.FromSlash(key)+".json")
+}
+
+func (s *LocalOSS) Upload(file *multipart.FileHeader, prefix string) (string, string, error) {
+	return oss.UploadFile(s, config.Setting.Path, file, prefix)
+}
+
+func (s *LocalOSS) UploadLocal(fileName string, prefix string) (string, string, error) {
+	return oss.UploadLocalFile(s, config.Setting.Path, fileName, prefix)
+}
+
+// 先写入临时文件再改名，读取方不会看到写了一半的对象
+func (s *LocalOSS) write(key string, reader io.Reader, size int64, meta *metadata) (*oss.ObjectInfo, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return nil, err
+	}
+
+	temp, err := os.CreateTemp(filepath.Join(s.root, s.bucket), ".put-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temp.Name())
+
+	hash := md5.New()
+	n, err := io.Copy(io.MultiWriter(temp, hash), reader)
+	if e := temp.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && n != size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	meta.ETag = hex.EncodeToString(hash.Sum(nil))
+
+	if err := os.MkdirAll(filepath.Dir(s.file(key)), 0755); err != nil {
+		return nil, err
+	}
+	if err := writeMeta(s.metaFile(key), meta); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(temp.Name(), s.file(key)); err != nil {
+		return nil, err
+	}
+	return s.Stat(context.Background(), key)
+}
+
+func writeMeta(fileName string, meta any) error {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, data, 0644)
+}
+
+func (s *LocalOSS) Put(ctx context.Context, key string, reader io.Reader, size int64, options *oss.PutOptions) (*oss.ObjectInfo, error) {
+	var meta = &metadata{ContentType: oss.ContentTypeOf(key, options)}
+	if options != nil {
+		meta.Metadata = options.Metadata
+	}
+	return s.write(key, reader, size, meta)
+}
+
+func (s *LocalOSS) Get(ctx context.Context, key string) (io.ReadCloser, *oss.ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(s.file(key))
+	if err != nil {
+		return nil, nil, wrapError(err)
+	}
+	return f, info, nil
+}
+
+func wrapError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return oss.ErrNotFound
+	}
+	return err
+}
+
+func (s *LocalOSS) Stat(ctx context.Context, key string) (*oss.ObjectInfo, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(s.file(key))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if stat.IsDir() {
+		return nil, oss.ErrNotFound
+	}
+
+	var info = &oss.ObjectInfo{Key: key, Size: stat.Size(), LastModified: stat.ModTime()}
+	var meta metadata
+	if data, err := os.ReadFile(s.metaFile(key)); err == nil && json.Unmarshal(data, &meta) == nil {
+		info.ContentType, info.ETag, info.Metadata = meta.ContentType, meta.ETag, meta.Metadata
+	}
+	if info.ContentType == "" {
+		info.ContentType = oss.ContentTypeOf(key, nil)
+	}
+	return info, nil
+}
+
+func (s *LocalOSS) Delete(ctx context.Context, key string) error {
+	if err := oss.CheckKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(s.file(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(s.metaFile(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// 遍历存储桶目录，按对象键排序后分页；Marker 为上一页最后一个键
+func (s *LocalOSS) List(ctx context.Context, options *oss.ListOptions) (*oss.ListResult, error) {
+	if options == nil {
+		options = &oss.ListOptions{}
+	}
+	if options.Limit <= 0 {
+		options.Limit = oss.DefaultListLimit
+	}
+
+	base := filepath.Join(s.root, s.bucket)
+	start := base
+	if i := strings.LastIndex(options.Prefix, "/"); i >= 0 {
+		start = filepath.Join(base, filepath.FromSlash(options.Prefix[:i]))
+	}
+
+	var keys = make([]string, 0)
+	err := filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".put-") {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, options.Prefix) && key > options.Marker {
+			keys = append(keys, key)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	var result = &oss.ListResult{Objects: make([]*oss.ObjectInfo, 0)}
+	for _, key := range keys {
+		if len(result.Objects)+len(result.Prefixes) >= options.Limit {
+			result.Truncated = true
+			break
+		}
+
+		if options.Delimiter != "" {
+			if i := strings.Index(key[len(options.Prefix):], options.Delimiter); i >= 0 {
+				prefix := key[:len(options.Prefix)+i+len(options.Delimiter)]
+				if n := len(result.Prefixes); n == 0 || result.Prefixes[n-1] != prefix {
+					result.Prefixes = append(result.Prefixes, prefix)
+				}
+				result.NextMarker = key
+				continue
+			}
+		}
+
+		info, err := s.Stat(ctx, key)
+		if err != nil {
+			continue
+		}
+		result.Objects = append(result.Objects, info)
+		result.NextMarker = key
+	}
+	if !result.Truncated {
+		result.NextMarker = ""
+	}
+	return result, nil
+}
+
+func (s *LocalOSS) Copy(ctx context.Context, src, dst string) error {
+	reader, info, err := s.Get(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = s.write(dst, reader, info.Size, &metadata{ContentType: info.ContentType, Metadata: info.Metadata})
+	return err
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (s *LocalOSS) URL(key string) string {
+	return strings.TrimSuffix(config.Setting.BaseUrl, "/") + "/" + s.bucket + "/" + escapeKey(key)
+}
+
+// 签名覆盖方法、存储桶、对象键、过期时间和 Content-Type
+func (s *LocalOSS) sign(method, bucket, key string, expires int64, contentType string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(method + "\n" + bucket + "/" + key + "\n" + strconv.FormatInt(expires, 10) + "\n" + contentType))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalOSS) presign(method, key string, expires time.Duration, contentType string) (string, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return "", err
+	}
+	deadline := time.Now().Add(expires).Unix()
+	query := url.Values{
+		"expires":   []string{strconv.FormatInt(deadline, 10)},
+		"signature": []string{s.sign(method, s.bucket, key, deadline, contentType)},
+	}
+	return s.URL(key) + "?" + query.Encode(), nil
+}
+
+// 校验 bucket 中对象的签名地址，只做计算不访问文件系统；contentType 仅对 PUT 有效
+func (s *LocalOSS) Verify(method, bucket, key string, expires string, signature string, contentType string) bool {
+	deadline, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > deadline {
+		return false
+	}
+	if hmac.Equal([]byte(signature), []byte(s.sign(method, bucket, key, deadline, contentType))) {
+		return true
+	}
+	// 签名时未指定 Content-Type
+	return contentType != "" && hmac.Equal([]byte(signature), []byte(s.sign(method, bucket, key, deadline, "")))
+}
+
+func (s *LocalOSS) PresignGet(key string, expires time.Duration) (string, error) {
+	return s.presign("GET", key, expires, "")
+}
+
+func (s *LocalOSS) PresignPut(key string, expires time.Duration, contentType string) (string, error) {
+	return s.presign("PUT", key, expires, contentType)
+}
+
+func (s *LocalOSS) uploadPath(uploadId string) (string, error) {
+	if !uploadPattern.MatchString(uploadId) {
+		return "", oss.ErrInvalidUpload
+	}
+	return filepath.Join(s.root, uploadDir, uploadId), nil
+}
+
+func (s *LocalOSS) loadUpload(key, uploadId string) (string, *upload, error) {
+	dir, err := s.uploadPath(uploadId)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "upload.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil, oss.ErrInvalidUpload
+		}
+		return "", nil, err
+	}
+	var u upload
+	if err := json.Unmarshal(data, &u); err != nil {
+		return "", nil, err
+	}
+	if u.Bucket != s.bucket || u.Key != key {
+		return "", nil, oss.ErrInvalidUpload
+	}
+	return dir, &u, nil
+}
+
+func (s *LocalOSS) CreateMultipart(ctx context.Context, key string, options *oss.PutOptions) (string, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return "", err
+	}
+
+	var id = make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	uploadId := hex.EncodeToString(id)
+
+	var u = &upload{Bucket: s.bucket, Key: key}
+	u.ContentType = oss.ContentTypeOf(key, options)
+	if options != nil {
+		u.Metadata = options.Metadata
+	}
+
+	dir, _ := s.uploadPath(uploadId)
+	if err := writeMeta(filepath.Join(dir, "upload.json"), u); err != nil {
+		return "", err
+	}
+	return uploadId, nil
+}
+
+func (s *LocalOSS) UploadPart(ctx context.Context, key, uploadId string, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	dir, _, err := s.loadUpload(key, uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > 10000 {
+		return nil, oss.ErrInvalidUpload
+	}
+
+	f, err := os.Create(filepath.Join(dir, strconv.Itoa(number)))
+	if err != nil {
+		return nil, err
+	}
+	hash := md5.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), reader)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && n != size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return &oss.Part{Number: number, ETag: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func (s *LocalOSS) CompleteMultipart(ctx context.Context, key, uploadId string, parts []*oss.Part) (*oss.ObjectInfo, error) {
+	dir, u, err := s.loadUpload(key, uploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	var readers = make([]io.Reader, 0, len(parts))
+	for i, part := range parts {
+		if part.Number < 1 || (i > 0 && part.Number <= parts[i-1].Number) {
+			return nil, oss.ErrInvalidUpload
+		}
+		f, err := os.Open(filepath.Join(dir, strconv.Itoa(part.Number)))
+		if err != nil {
+			return nil, oss.ErrInvalidUpload
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+
+	info, err := s.write(key, io.MultiReader(readers...), -1, &u.metadata)
+	if err != nil {
+		return nil, err
+	}
+	_ = os.RemoveAll(dir)
+	return info, nil
+}
+
+func (s *LocalOSS) AbortMultipart(ctx context.Context, key, uploadId string) error {
+	dir, _, err := s.loadUpload(key, uploadId)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// 打开已存在的存储桶，不存在时返回 ErrNotFound 而不创建目录
+func (s *LocalOSS) Open(name string) (*LocalOSS, error) {
+	if name == s.bucket {
+		return s, nil
+	}
+	if !bucketPattern.MatchString(name) {
+		return nil, oss.ErrNotFound
+	}
+	if info, err := os.Stat(filepath.Join(s.root, name)); err != nil || !info.IsDir() {
+		return nil, oss.ErrNotFound
+	}
+	return &LocalOSS{root: s.root, bucket: name, secret: s.secret}, nil
+}
+
+func (s *LocalOSS) WithBucket(name string) (oss.OSS, error) {
+	if name == "" {
+		name = config.Setting.Bucket
+	}
+	if name == s.bucket {
+		return s, nil
+	}
+	return NewLocalOSS(s.root, name, s.secret)
+}
@@ -0,0 +1,193 @@
+package local
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/oss/local/config"
+)
+
+func newRouter(t *testing.T) (*LocalOSS, *gin.Engine) {
+	t.Helper()
+	storage, err := NewLocalOSS(t.TempDir(), "default", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	(&LocalController{Storage: storage}).InitRouter(&router.RouterGroup)
+	return storage, router
+}
+
+func serve(router *gin.Engine, method, target string, body []byte, contentType string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// 签名地址中路由内的路径和查询参数
+func requestURI(t *testing.T, signed string) string {
+	t.Helper()
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "/openapi/oss/local" + strings.TrimPrefix(u.EscapedPath(), strings.TrimSuffix(config.Setting.BaseUrl, "/")) + "?" + u.RawQuery
+}
+
+func TestVerify(t *testing.T) {
+	storage, _ := newRouter(t)
+	deadline := time.Now().Add(time.Minute).Unix()
+	expires := strconv.FormatInt(deadline, 10)
+	signature := storage.sign(http.MethodPut, "default", "a.txt", deadline, "text/plain")
+
+	cases := []struct {
+		method, bucket, key, expires, contentType string
+		valid                                     bool
+	}{
+		{http.MethodPut, "default", "a.txt", expires, "text/plain", true},
+		{http.MethodGet, "default", "a.txt", expires, "text/plain", false},
+		{http.MethodPut, "other", "a.txt", expires, "text/plain", false},
+		{http.MethodPut, "default", "b.txt", expires, "text/plain", false},
+		{http.MethodPut, "default", "a.txt", expires, "text/html", false},
+		{http.MethodPut, "default", "a.txt", strconv.FormatInt(deadline+1, 10), "text/plain", false},
+	}
+	for _, c := range cases {
+		if got := storage.Verify(c.method, c.bucket, c.key, c.expires, signature, c.contentType); got != c.valid {
+			t.Fatalf("Verify(%+v) = %v", c, got)
+		}
+	}
+
+	past := time.Now().Add(-time.Minute).Unix()
+	if storage.Verify(http.MethodGet, "default", "a.txt", strconv.FormatInt(past, 10), storage.sign(http.MethodGet, "default", "a.txt", past, ""), "") {
+		t.Fatal("expired signature accepted")
+	}
+}
+
+func TestPresignedPutAndGet(t *testing.T) {
+	storage, router := newRouter(t)
+
+	put, err := storage.PresignPut("docs/a b.txt", time.Minute, "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := serve(router, http.MethodPut, requestURI(t, put), []byte("hello"), "text/html"); r.Code != http.StatusForbidden {
+		t.Fatalf("content type mismatch = %d", r.Code)
+	}
+	if r := serve(router, http.MethodPut, requestURI(t, put), []byte("hello"), "text/plain"); r.Code != http.StatusOK {
+		t.Fatalf("put = %d %s", r.Code, r.Body.String())
+	}
+
+	get, err := storage.PresignGet("docs/a b.txt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := serve(router, http.MethodGet, requestURI(t, get), nil, "")
+	if r.Code != http.StatusOK || r.Body.String() != "hello" {
+		t.Fatalf("get = %d %s", r.Code, r.Body.String())
+	}
+	// 图片以外的类型作为附件下载
+	if r.Header().Get("X-Content-Type-Options") != "nosniff" || r.Header().Get("Content-Disposition") != `attachment; filename="a b.txt"` {
+		t.Fatalf("unexpected headers: %v", r.Header())
+	}
+	// PUT 的签名不能用于读取
+	if r := serve(router, http.MethodGet, requestURI(t, put), nil, ""); r.Code != http.StatusForbidden {
+		t.Fatalf("get with put signature = %d", r.Code)
+	}
+}
+
+// 未签名的请求不会创建存储桶目录
+func TestUnsignedRequestDoesNotTouchStorage(t *testing.T) {
+	storage, router := newRouter(t)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		if r := serve(router, method, "/openapi/oss/local/intruder/a.txt", []byte("x"), ""); r.Code != http.StatusForbidden {
+			t.Fatalf("%s = %d", method, r.Code)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(storage.root, "intruder")); !os.IsNotExist(err) {
+		t.Fatalf("bucket created by unsigned request: %v", err)
+	}
+
+	// 签名有效但存储桶不存在
+	deadline := time.Now().Add(time.Minute).Unix()
+	target := "/openapi/oss/local/missing/a.txt?expires=" + strconv.FormatInt(deadline, 10) + "&signature=" + storage.sign(http.MethodGet, "missing", "a.txt", deadline, "")
+	if r := serve(router, http.MethodGet, target, nil, ""); r.Code != http.StatusNotFound {
+		t.Fatalf("missing bucket = %d", r.Code)
+	}
+	if _, err := os.Stat(filepath.Join(storage.root, "missing")); !os.IsNotExist(err) {
+		t.Fatalf("bucket created by request: %v", err)
+	}
+}
+
+func TestPutSizeLimit(t *testing.T) {
+	defer func(limit int64) { config.Setting.MaxPutSize = limit }(config.Setting.MaxPutSize)
+	config.Setting.MaxPutSize = 4
+
+	storage, router := newRouter(t)
+	put, err := storage.PresignPut("big.bin", time.Minute, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := serve(router, http.MethodPut, requestURI(t, put), []byte("too large"), ""); r.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("declared length = %d", r.Code)
+	}
+
+	// 未声明长度的请求在读取时截断
+	req := httptest.NewRequest(http.MethodPut, requestURI(t, put), strings.NewReader("too large"))
+	req.ContentLength = -1
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunked = %d", recorder.Code)
+	}
+	if _, err := os.Stat(storage.file("big.bin")); !os.IsNotExist(err) {
+		t.Fatal("oversized object stored")
+	}
+}
+
+func TestSecretPersisted(t *testing.T) {
+	root := t.TempDir()
+	first, err := loadSecret(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := loadSecret(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) == 0 || string(first) != string(second) {
+		t.Fatalf("secret not reused: %q %q", first, second)
+	}
+	if info, err := os.Stat(filepath.Join(root, secretFile)); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("secret file: %v %v", info, err)
+	}
+}
+
+func TestImageServedInline(t *testing.T) {
+	storage, router := newRouter(t)
+
+	put, _ := storage.PresignPut("a.png", time.Minute, "image/png")
+	if r := serve(router, http.MethodPut, requestURI(t, put), []byte("png"), "image/png"); r.Code != http.StatusOK {
+		t.Fatalf("put = %d", r.Code)
+	}
+	get, _ := storage.PresignGet("a.png", time.Minute)
+	r := serve(router, http.MethodGet, requestURI(t, get), nil, "")
+	if r.Code != http.StatusOK || r.Header().Get("Content-Disposition") != "" || r.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("get = %d %v", r.Code, r.Header())
+	}
+}
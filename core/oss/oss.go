@@ -1,8 +1,79 @@
 package oss
 
-import "mime/multipart"
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("oss: object not found")
+	ErrInvalidKey    = errors.New("oss: invalid object key")
+	ErrInvalidUpload = errors.New("oss: invalid multipart upload")
+)
 
 type OSS interface {
+	// 上传文件到 prefix 目录下自动生成的对象键，返回访问地址和对象键
 	Upload(file *multipart.FileHeader, prefix string) (string, string, error)
 	UploadLocal(fileName string, prefix string) (string, string, error)
+
+	Put(ctx context.Context, key string, reader io.Reader, size int64, options *PutOptions) (*ObjectInfo, error)
+	// 调用方负责关闭返回的 io.ReadCloser
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// 对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, options *ListOptions) (*ListResult, error)
+	// 同一存储桶内复制，保留元数据
+	Copy(ctx context.Context, src, dst string) error
+
+	// 公开访问地址，私有存储桶需使用 PresignGet
+	URL(key string) string
+	PresignGet(key string, expires time.Duration) (string, error)
+	// 浏览器直传地址，contentType 不为空时上传请求须携带相同的 Content-Type
+	PresignPut(key string, expires time.Duration, contentType string) (string, error)
+
+	// 分片上传：除最后一片外每片至少 5MB，分片号从 1 开始
+	CreateMultipart(ctx context.Context, key string, options *PutOptions) (string, error)
+	UploadPart(ctx context.Context, key, uploadId string, number int, reader io.Reader, size int64) (*Part, error)
+	CompleteMultipart(ctx context.Context, key, uploadId string, parts []*Part) (*ObjectInfo, error)
+	AbortMultipart(ctx context.Context, key, uploadId string) error
+
+	// 同一账号下的其他存储桶，name 为空时为默认存储桶
+	WithBucket(name string) (OSS, error)
+}
+
+type PutOptions struct {
+	ContentType string
+	Metadata    map[string]string
+}
+
+type ObjectInfo struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ContentType  string            `json:"contentType,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified time.Time         `json:"lastModified"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+type ListOptions struct {
+	Prefix    string
+	Delimiter string // 为 / 时按目录列出，子目录在 Prefixes 中返回
+	Marker    string // 上一页返回的 NextMarker
+	Limit     int    // 默认 1000
+}
+
+type ListResult struct {
+	Objects    []*ObjectInfo `json:"objects"`
+	Prefixes   []string      `json:"prefixes,omitempty"`
+	NextMarker string        `json:"nextMarker,omitempty"`
+	Truncated  bool          `json:"truncated"`
+}
+
+type Part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
 }
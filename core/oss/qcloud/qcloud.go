@@ -2,29 +2,37 @@ package qcloud
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
-	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/oss"
 	"github.com/gophab/gophrame/core/oss/qcloud/config"
 	"github.com/gophab/gophrame/core/starter"
-	"github.com/gophab/gophrame/core/util"
 
 	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
+const metaPrefix = "X-Cos-Meta-"
+
 type QcloudOSS struct {
 	Client *cos.Client
+	bucket string
 }
 
+var _ oss.OSS = (*QcloudOSS)(nil)
+
 func CreateQcloudOSS() (*QcloudOSS, error) {
-	urlStr, err := url.Parse("https://" + config.Setting.Bucket + ".cos." + config.Setting.Region + ".myqcloud.com")
+	return createQcloudOSS(config.Setting.Bucket)
+}
+
+func createQcloudOSS(bucket string) (*QcloudOSS, error) {
+	urlStr, err := url.Parse("https://" + bucket + ".cos." + config.Setting.Region + ".myqcloud.com")
 	if err != nil {
 		return nil, err
 	}
@@ -39,56 +47,193 @@ func CreateQcloudOSS() (*QcloudOSS, error) {
 
 	return &QcloudOSS{
 		Client: client,
+		bucket: bucket,
 	}, nil
 }
 
-var (
-	_re = regexp.MustCompile(("^/"))
-	re_ = regexp.MustCompile(("/.$"))
-)
-
 func (s *QcloudOSS) Upload(file *multipart.FileHeader, prefix string) (string, string, error) {
-	f, err := file.Open()
+	return oss.UploadFile(s, config.Setting.Path, file, prefix)
+}
+
+func (s *QcloudOSS) UploadLocal(fileName string, prefix string) (string, string, error) {
+	return oss.UploadLocalFile(s, config.Setting.Path, fileName, prefix)
+}
+
+func putHeader(key string, options *oss.PutOptions) *cos.ObjectPutHeaderOptions {
+	var header = &cos.ObjectPutHeaderOptions{ContentType: oss.ContentTypeOf(key, options)}
+	if options != nil && len(options.Metadata) > 0 {
+		meta := http.Header{}
+		for k, v := range options.Metadata {
+			meta.Set(metaPrefix+k, v)
+		}
+		header.XCosMetaXXX = &meta
+	}
+	return header
+}
+
+func wrapError(err error) error {
+	if err != nil && cos.IsNotFoundError(err) {
+		return oss.ErrNotFound
+	}
+	return err
+}
+
+func (s *QcloudOSS) Put(ctx context.Context, key string, reader io.Reader, size int64, options *oss.PutOptions) (*oss.ObjectInfo, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return nil, err
+	}
+	header := putHeader(key, options)
+	if size >= 0 {
+		header.ContentLength = size
+	}
+	if _, err := s.Client.Object.Put(ctx, key, reader, &cos.ObjectPutOptions{ObjectPutHeaderOptions: header}); err != nil {
+		return nil, err
+	}
+	return s.Stat(ctx, key)
+}
+
+func (s *QcloudOSS) Get(ctx context.Context, key string) (io.ReadCloser, *oss.ObjectInfo, error) {
+	resp, err := s.Client.Object.Get(ctx, key, nil)
 	if err != nil {
-		return "", "", err
+		return nil, nil, wrapError(err)
 	}
-	defer f.Close() // 创建文件 defer 关闭
+	return resp.Body, oss.ObjectInfoOf(key, resp.Header, metaPrefix), nil
+}
 
-	ext := filepath.Ext(file.Filename)
-	yunFileTmpPath := _re.ReplaceAllString(
-		re_.ReplaceAllString(re_.ReplaceAllString(config.Setting.Path, "")+"/"+_re.ReplaceAllString(prefix, ""), "")+
-			"/"+
-			time.Now().Format("20060102/150405_")+util.GenerateRandomString(6)+ext,
-		"")
+func (s *QcloudOSS) Stat(ctx context.Context, key string) (*oss.ObjectInfo, error) {
+	resp, err := s.Client.Object.Head(ctx, key, nil)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return oss.ObjectInfoOf(key, resp.Header, metaPrefix), nil
+}
+
+func (s *QcloudOSS) Delete(ctx context.Context, key string) error {
+	_, err := s.Client.Object.Delete(ctx, key)
+	return wrapError(err)
+}
 
-	_, err = s.Client.Object.Put(context.Background(), yunFileTmpPath, f, nil)
+func (s *QcloudOSS) List(ctx context.Context, options *oss.ListOptions) (*oss.ListResult, error) {
+	if options == nil {
+		options = &oss.ListOptions{}
+	}
+	if options.Limit <= 0 {
+		options.Limit = oss.DefaultListLimit
+	}
+
+	objects, _, err := s.Client.Bucket.Get(ctx, &cos.BucketGetOptions{
+		Prefix:    options.Prefix,
+		Delimiter: options.Delimiter,
+		Marker:    options.Marker,
+		MaxKeys:   options.Limit,
+	})
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return re_.ReplaceAllString(config.Setting.BucketUrl, "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
+	var result = &oss.ListResult{
+		Objects:    make([]*oss.ObjectInfo, 0, len(objects.Contents)),
+		Prefixes:   objects.CommonPrefixes,
+		NextMarker: objects.NextMarker,
+		Truncated:  objects.IsTruncated,
+	}
+	for _, object := range objects.Contents {
+		info := &oss.ObjectInfo{
+			Key:  object.Key,
+			Size: object.Size,
+			ETag: strings.Trim(object.ETag, "\""),
+		}
+		info.LastModified, _ = time.Parse(time.RFC3339, object.LastModified)
+		result.Objects = append(result.Objects, info)
+	}
+	return result, nil
 }
 
-func (s *QcloudOSS) UploadLocal(fileName string, prefix string) (string, string, error) {
-	f, err := os.Open(fileName)
+func (s *QcloudOSS) Copy(ctx context.Context, src, dst string) error {
+	if err := oss.CheckKey(dst); err != nil {
+		return err
+	}
+	source := s.Client.BaseURL.BucketURL.Host + "/" + src
+	_, _, err := s.Client.Object.Copy(ctx, dst, source, nil)
+	return wrapError(err)
+}
+
+func (s *QcloudOSS) URL(key string) string {
+	if config.Setting.BucketUrl != "" && s.bucket == config.Setting.Bucket {
+		return strings.TrimSuffix(config.Setting.BucketUrl, "/") + "/" + key
+	}
+	return s.Client.BaseURL.BucketURL.String() + "/" + key
+}
+
+func (s *QcloudOSS) presign(method, key string, expires time.Duration, header *http.Header) (string, error) {
+	var opt any
+	if header != nil {
+		opt = &cos.PresignedURLOptions{Header: header}
+	}
+	u, err := s.Client.Object.GetPresignedURL(context.Background(), method, key, config.Setting.AppId, config.Setting.AppKey, expires, opt)
 	if err != nil {
-		return "", "", err
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *QcloudOSS) PresignGet(key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, expires, nil)
+}
+
+func (s *QcloudOSS) PresignPut(key string, expires time.Duration, contentType string) (string, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return "", err
 	}
-	defer f.Close() // 创建文件 defer 关闭
+	if contentType == "" {
+		return s.presign(http.MethodPut, key, expires, nil)
+	}
+	return s.presign(http.MethodPut, key, expires, &http.Header{"Content-Type": []string{contentType}})
+}
 
-	ext := filepath.Ext(fileName)
-	yunFileTmpPath := _re.ReplaceAllString(
-		re_.ReplaceAllString(re_.ReplaceAllString(config.Setting.Path, "")+"/"+_re.ReplaceAllString(prefix, ""), "")+
-			"/"+
-			time.Now().Format("20060102/150405_")+util.GenerateRandomString(6)+ext,
-		"")
+func (s *QcloudOSS) CreateMultipart(ctx context.Context, key string, options *oss.PutOptions) (string, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return "", err
+	}
+	result, _, err := s.Client.Object.InitiateMultipartUpload(ctx, key, &cos.InitiateMultipartUploadOptions{ObjectPutHeaderOptions: putHeader(key, options)})
+	if err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
 
-	_, err = s.Client.Object.Put(context.Background(), yunFileTmpPath, f, nil)
+func (s *QcloudOSS) UploadPart(ctx context.Context, key, uploadId string, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	resp, err := s.Client.Object.UploadPart(ctx, key, uploadId, number, reader, &cos.ObjectUploadPartOptions{ContentLength: size})
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
+	return &oss.Part{Number: number, ETag: resp.Header.Get("ETag")}, nil
+}
 
-	return re_.ReplaceAllString(config.Setting.BucketUrl, "") + "/" + yunFileTmpPath, yunFileTmpPath, nil
+func (s *QcloudOSS) CompleteMultipart(ctx context.Context, key, uploadId string, parts []*oss.Part) (*oss.ObjectInfo, error) {
+	var options = &cos.CompleteMultipartUploadOptions{}
+	for _, part := range parts {
+		options.Parts = append(options.Parts, cos.Object{PartNumber: part.Number, ETag: part.ETag})
+	}
+	if _, _, err := s.Client.Object.CompleteMultipartUpload(ctx, key, uploadId, options); err != nil {
+		return nil, err
+	}
+	return s.Stat(ctx, key)
+}
+
+func (s *QcloudOSS) AbortMultipart(ctx context.Context, key, uploadId string) error {
+	_, err := s.Client.Object.AbortMultipartUpload(ctx, key, uploadId)
+	return err
+}
+
+func (s *QcloudOSS) WithBucket(name string) (oss.OSS, error) {
+	if name == "" {
+		name = config.Setting.Bucket
+	}
+	if name == s.bucket {
+		return s, nil
+	}
+	return createQcloudOSS(name)
 }
 
 func init() {
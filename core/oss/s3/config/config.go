@@ -0,0 +1,33 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type S3Setting struct {
+	Enabled   bool   `json:"enabled" yaml:"enabled"`
+	Endpoint  string `json:"endpoint" yaml:"endpoint"` // 如 http://localhost:9000，为空时使用 AWS S3
+	Region    string `json:"region" yaml:"region"`
+	AccessKey string `json:"accessKey" yaml:"accessKey"`
+	SecretKey string `json:"secretKey" yaml:"secretKey"`
+	Bucket    string `json:"bucket" yaml:"bucket"`
+	PathStyle bool   `json:"pathStyle" yaml:"pathStyle"` // MinIO 等使用路径形式访问存储桶
+	BucketUrl string `json:"bucketUrl" yaml:"bucketUrl"` // 公开访问地址，为空时由 Endpoint 生成
+	Path      string `json:"path" yaml:"path"`
+
+	Timeout time.Duration `json:"timeout" yaml:"timeout"` // 连接和等待响应头的超时，不限制传输大文件的时长
+}
+
+var Setting *S3Setting = &S3Setting{
+	Enabled: false,
+	Region:  "us-east-1",
+	Timeout: 30 * time.Second,
+}
+
+func init() {
+	logger.Debug("Register Oss Config - s3")
+	config.RegisterConfig("oss.s3", Setting, "S3 Settings")
+}
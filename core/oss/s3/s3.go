@@ -0,0 +1,405 @@
+package s3
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/oss/s3/config"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+const metaPrefix = "X-Amz-Meta-"
+
+// S3 兼容存储（AWS S3、MinIO 等），直接使用 REST 接口
+type S3OSS struct {
+	Client   *http.Client
+	endpoint *url.URL
+	bucket   string
+	signer   *signer
+}
+
+var _ oss.OSS = (*S3OSS)(nil)
+
+// 服务端返回的错误
+type Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+	RequestId  string `xml:"RequestId"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("s3: %d %s: %s (RequestId=%s)", e.StatusCode, e.Code, e.Message, e.RequestId)
+}
+
+func CreateS3OSS() (*S3OSS, error) {
+	endpoint := config.Setting.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + config.Setting.Region + ".amazonaws.com"
+	}
+	return NewS3OSS(endpoint, config.Setting.Region, config.Setting.AccessKey, config.Setting.SecretKey, config.Setting.Bucket)
+}
+
+func NewS3OSS(endpoint, region, accessKey, secretKey, bucket string) (*S3OSS, error) {
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("s3: invalid endpoint: %s", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("s3: bucket required")
+	}
+	return &S3OSS{
+		Client:   newClient(cmp.Or(config.Setting.Timeout, 30*time.Second)),
+		endpoint: u,
+		bucket:   bucket,
+		signer:   &signer{accessKey: accessKey, secretKey: secretKey, region: region},
+	}, nil
+}
+
+// 整体超时会中断大文件的流式读写，因此只限制建连、握手和等待响应头
+func newClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: transport}
+}
+
+// 对象地址：路径形式为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *S3OSS) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	if config.Setting.PathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, true)
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+func (s *S3OSS) do(ctx context.Context, method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil && size >= 0 {
+		req.ContentLength = size
+	}
+	s.signer.sign(req, time.Now().UTC())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, readError(resp)
+	}
+	return resp, nil
+}
+
+func readError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return oss.ErrNotFound
+	}
+	var e = &Error{StatusCode: resp.StatusCode}
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); err == nil {
+		_ = xml.Unmarshal(data, e)
+	}
+	if e.Code == "" {
+		e.Code = resp.Status
+	}
+	return e
+}
+
+// 执行请求并解析 XML 响应
+func (s *S3OSS) call(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte, result any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	resp, err := s.do(ctx, method, key, query, header, reader, int64(len(body)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	// CopyObject、CompleteMultipartUpload 可能在 200 响应中返回错误
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data[:min(len(data), 256)], []byte("<Error>")) {
+		var e = &Error{StatusCode: resp.StatusCode}
+		_ = xml.Unmarshal(data, e)
+		return e
+	}
+	return xml.Unmarshal(data, result)
+}
+
+func putHeader(key string, options *oss.PutOptions) http.Header {
+	header := http.Header{}
+	header.Set("Content-Type", oss.ContentTypeOf(key, options))
+	if options != nil {
+		for k, v := range options.Metadata {
+			header.Set(metaPrefix+k, v)
+		}
+	}
+	return header
+}
+
+func (s *S3OSS) Upload(file *multipart.FileHeader, prefix string) (string, string, error) {
+	return oss.UploadFile(s, config.Setting.Path, file, prefix)
+}
+
+func (s *S3OSS) UploadLocal(fileName string, prefix string) (string, string, error) {
+	return oss.UploadLocalFile(s, config.Setting.Path, fileName, prefix)
+}
+
+func (s *S3OSS) Put(ctx context.Context, key string, reader io.Reader, size int64, options *oss.PutOptions) (*oss.ObjectInfo, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return nil, err
+	}
+	// PUT 需要 Content-Length，未知大小时读入内存
+	if size < 0 {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, nil, putHeader(key, options), reader, size)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s.Stat(ctx, key)
+}
+
+func (s *S3OSS) Get(ctx context.Context, key string) (io.ReadCloser, *oss.ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, oss.ObjectInfoOf(key, resp.Header, metaPrefix), nil
+}
+
+func (s *S3OSS) Stat(ctx context.Context, key string) (*oss.ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return oss.ObjectInfoOf(key, resp.Header, metaPrefix), nil
+}
+
+func (s *S3OSS) Delete(ctx context.Context, key string) error {
+	if err := s.call(ctx, http.MethodDelete, key, nil, nil, nil, nil); err != nil && err != oss.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []string `xml:"CommonPrefixes>Prefix"`
+}
+
+func (s *S3OSS) List(ctx context.Context, options *oss.ListOptions) (*oss.ListResult, error) {
+	if options == nil {
+		options = &oss.ListOptions{}
+	}
+	if options.Limit <= 0 {
+		options.Limit = oss.DefaultListLimit
+	}
+
+	query := url.Values{
+		"list-type": []string{"2"},
+		"max-keys":  []string{strconv.Itoa(options.Limit)},
+	}
+	if options.Prefix != "" {
+		query.Set("prefix", options.Prefix)
+	}
+	if options.Delimiter != "" {
+		query.Set("delimiter", options.Delimiter)
+	}
+	if options.Marker != "" {
+		query.Set("continuation-token", options.Marker)
+	}
+
+	var objects listBucketResult
+	if err := s.call(ctx, http.MethodGet, "", query, nil, nil, &objects); err != nil {
+		return nil, err
+	}
+
+	var result = &oss.ListResult{
+		Objects:    make([]*oss.ObjectInfo, 0, len(objects.Contents)),
+		Prefixes:   objects.CommonPrefixes,
+		NextMarker: objects.NextContinuationToken,
+		Truncated:  objects.IsTruncated,
+	}
+	for _, object := range objects.Contents {
+		result.Objects = append(result.Objects, &oss.ObjectInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			ETag:         strings.Trim(object.ETag, "\""),
+			LastModified: object.LastModified,
+		})
+	}
+	return result, nil
+}
+
+func (s *S3OSS) Copy(ctx context.Context, src, dst string) error {
+	if err := oss.CheckKey(dst); err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", uriEncode("/"+s.bucket+"/"+src, true))
+
+	var result struct {
+		ETag string `xml:"ETag"`
+	}
+	return s.call(ctx, http.MethodPut, dst, nil, header, nil, &result)
+}
+
+func (s *S3OSS) URL(key string) string {
+	if config.Setting.BucketUrl != "" && s.bucket == config.Setting.Bucket {
+		return strings.TrimSuffix(config.Setting.BucketUrl, "/") + "/" + uriEncode(key, true)
+	}
+	return s.objectURL(key, nil).String()
+}
+
+func (s *S3OSS) PresignGet(key string, expires time.Duration) (string, error) {
+	return s.signer.presign(http.MethodGet, s.objectURL(key, nil), nil, time.Now().UTC(), expires), nil
+}
+
+func (s *S3OSS) PresignPut(key string, expires time.Duration, contentType string) (string, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return "", err
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return s.signer.presign(http.MethodPut, s.objectURL(key, nil), header, time.Now().UTC(), expires), nil
+}
+
+func (s *S3OSS) CreateMultipart(ctx context.Context, key string, options *oss.PutOptions) (string, error) {
+	if err := oss.CheckKey(key); err != nil {
+		return "", err
+	}
+	var result struct {
+		UploadId string `xml:"UploadId"`
+	}
+	if err := s.call(ctx, http.MethodPost, key, url.Values{"uploads": []string{""}}, putHeader(key, options), nil, &result); err != nil {
+		return "", err
+	}
+	return result.UploadId, nil
+}
+
+func (s *S3OSS) UploadPart(ctx context.Context, key, uploadId string, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	query := url.Values{"partNumber": []string{strconv.Itoa(number)}, "uploadId": []string{uploadId}}
+	resp, err := s.do(ctx, http.MethodPut, key, query, nil, reader, size)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return &oss.Part{Number: number, ETag: resp.Header.Get("ETag")}, nil
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+func (s *S3OSS) CompleteMultipart(ctx context.Context, key, uploadId string, parts []*oss.Part) (*oss.ObjectInfo, error) {
+	var request completeMultipartUpload
+	for _, part := range parts {
+		request.Parts = append(request.Parts, struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+		}{part.Number, part.ETag})
+	}
+	body, err := xml.Marshal(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		ETag string `xml:"ETag"`
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	if err := s.call(ctx, http.MethodPost, key, url.Values{"uploadId": []string{uploadId}}, header, body, &result); err != nil {
+		return nil, err
+	}
+	return s.Stat(ctx, key)
+}
+
+func (s *S3OSS) AbortMultipart(ctx context.Context, key, uploadId string) error {
+	return s.call(ctx, http.MethodDelete, key, url.Values{"uploadId": []string{uploadId}}, nil, nil, nil)
+}
+
+func (s *S3OSS) WithBucket(name string) (oss.OSS, error) {
+	if name == "" {
+		name = config.Setting.Bucket
+	}
+	if name == s.bucket {
+		return s, nil
+	}
+	return &S3OSS{Client: s.Client, endpoint: s.endpoint, bucket: name, signer: s.signer}, nil
+}
+
+func init() {
+	starter.RegisterStarter(Start)
+}
+
+func Start() {
+	if config.Setting.Enabled {
+		logger.Info("Start S3 OSS...")
+		if oss, err := CreateS3OSS(); err == nil && oss != nil {
+			inject.InjectValue("oss", oss)
+		} else if err != nil {
+			logger.Error("Create S3 storage error: ", err.Error())
+		}
+	}
+}
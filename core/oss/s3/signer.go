@@ -0,0 +1,154 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+type signer struct {
+	accessKey string
+	secretKey string
+	region    string
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// RFC 3986 编码，path 为 true 时保留 /
+func uriEncode(s string, path bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' || (path && c == '/') {
+			b.WriteByte(c)
+		} else {
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	var keys = make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs = make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// 签名 host 以及 header 中的全部 x-amz-* 和 content-type
+func canonicalHeaders(host string, header http.Header) (string, string) {
+	var values = map[string]string{"host": host}
+	for k, v := range header {
+		name := strings.ToLower(k)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "content-md5" {
+			values[name] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	var names = make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+func (s *signer) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s *signer) signature(t time.Time, canonicalRequest string) string {
+	stringToSign := algorithm + "\n" + t.Format(amzDateFormat) + "\n" + s.scope(t) + "\n" + sha256Hex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// 请求头签名，请求体不参与签名
+func (s *signer) sign(r *http.Request, t time.Time) {
+	r.Header.Set("X-Amz-Date", t.Format(amzDateFormat))
+	if r.Header.Get("X-Amz-Content-Sha256") == "" {
+		r.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+
+	headers, signedHeaders := canonicalHeaders(r.URL.Host, r.Header)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		uriEncode(r.URL.Path, true),
+		canonicalQuery(r.URL.Query()),
+		headers,
+		signedHeaders,
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	r.Header.Set("Authorization", algorithm+
+		" Credential="+s.accessKey+"/"+s.scope(t)+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+s.signature(t, canonicalRequest))
+}
+
+// 查询参数签名，header 为请求时必须携带的头
+func (s *signer) presign(method string, u *url.URL, header http.Header, t time.Time, expires time.Duration) string {
+	headers, signedHeaders := canonicalHeaders(u.Host, header)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(t))
+	query.Set("X-Amz-Date", t.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires.Seconds()), 10))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		uriEncode(u.Path, true),
+		canonicalQuery(query),
+		headers,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	result := *u
+	result.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(t, canonicalRequest)
+	return result.String()
+}
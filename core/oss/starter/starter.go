@@ -12,7 +12,9 @@ import (
 	"github.com/gophab/gophrame/core/oss/config"
 
 	_ "github.com/gophab/gophrame/core/oss/aliyun"
+	_ "github.com/gophab/gophrame/core/oss/local"
 	_ "github.com/gophab/gophrame/core/oss/qcloud"
+	_ "github.com/gophab/gophrame/core/oss/s3"
 )
 
 var (
@@ -0,0 +1,188 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/util"
+)
+
+const (
+	// 超过该大小的文件使用分片上传
+	MultipartThreshold int64 = 64 << 20
+	PartSize           int64 = 16 << 20
+
+	DefaultListLimit = 1000
+)
+
+// 生成对象键：path/prefix/日期/时间_随机串.扩展名
+func GenerateKey(path string, prefix string, fileName string) string {
+	var segments = make([]string, 0, 3)
+	for _, s := range []string{path, prefix} {
+		if s = strings.Trim(s, "/"); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	segments = append(segments, time.Now().Format("20060102/150405_")+util.GenerateRandomString(6)+filepath.Ext(fileName))
+	return strings.Join(segments, "/")
+}
+
+// 校验对象键，不允许空键、绝对路径和 .. 路径段
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return ErrInvalidKey
+	}
+	for _, s := range strings.Split(key, "/") {
+		if s == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// 浏览器可直接显示且不会执行脚本的类型，其他类型只作为附件下载
+var inlineTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+func IsInline(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return inlineTypes[mediaType]
+}
+
+// 未指定时按扩展名推断 Content-Type
+func ContentTypeOf(key string, options *PutOptions) string {
+	if options != nil && options.ContentType != "" {
+		return options.ContentType
+	}
+	if t := mime.TypeByExtension(filepath.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// 上传表单文件，供各存储实现 Upload
+func UploadFile(o OSS, path string, file *multipart.FileHeader, prefix string) (string, string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	key := GenerateKey(path, prefix, file.Filename)
+	if _, err := PutLarge(context.Background(), o, key, f, file.Size, &PutOptions{ContentType: file.Header.Get("Content-Type")}); err != nil {
+		return "", "", err
+	}
+	return o.URL(key), key, nil
+}
+
+// 上传本地文件，供各存储实现 UploadLocal
+func UploadLocalFile(o OSS, path string, fileName string, prefix string) (string, string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return "", "", err
+	}
+
+	key := GenerateKey(path, prefix, fileName)
+	if _, err := PutLarge(context.Background(), o, key, f, stat.Size(), nil); err != nil {
+		return "", "", err
+	}
+	return o.URL(key), key, nil
+}
+
+// 大文件自动分片上传，失败时放弃已上传的分片
+func PutLarge(ctx context.Context, o OSS, key string, reader io.Reader, size int64, options *PutOptions) (*ObjectInfo, error) {
+	if size >= 0 && size <= MultipartThreshold {
+		return o.Put(ctx, key, reader, size, options)
+	}
+
+	uploadId, err := o.CreateMultipart(ctx, key, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts = make([]*Part, 0)
+	for number := 1; ; number++ {
+		n := PartSize
+		if size >= 0 {
+			n = min(PartSize, size-int64(len(parts))*PartSize)
+			if n <= 0 {
+				break
+			}
+		}
+
+		// 未知大小时先读满一片，确定分片长度
+		var body io.Reader = io.LimitReader(reader, n)
+		if size < 0 {
+			buffer, err := io.ReadAll(body)
+			if err != nil {
+				_ = o.AbortMultipart(ctx, key, uploadId)
+				return nil, err
+			}
+			if len(buffer) == 0 && number > 1 {
+				break
+			}
+			n, body = int64(len(buffer)), bytes.NewReader(buffer)
+		}
+
+		part, err := o.UploadPart(ctx, key, uploadId, number, body, n)
+		if err != nil {
+			_ = o.AbortMultipart(ctx, key, uploadId)
+			return nil, err
+		}
+		parts = append(parts, part)
+
+		if size < 0 && n < PartSize {
+			break
+		}
+	}
+
+	info, err := o.CompleteMultipart(ctx, key, uploadId, parts)
+	if err != nil {
+		_ = o.AbortMultipart(ctx, key, uploadId)
+		return nil, err
+	}
+	return info, nil
+}
+
+// 由响应头生成对象信息，metaPrefix 为自定义元数据头前缀，如 X-Amz-Meta-
+func ObjectInfoOf(key string, header http.Header, metaPrefix string) *ObjectInfo {
+	var info = &ObjectInfo{
+		Key:         key,
+		ContentType: header.Get("Content-Type"),
+		ETag:        strings.Trim(header.Get("ETag"), "\""),
+	}
+	if size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if t, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	for k, v := range header {
+		if len(v) > 0 && len(k) > len(metaPrefix) && strings.EqualFold(k[:len(metaPrefix)], metaPrefix) {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			info.Metadata[strings.ToLower(k[len(metaPrefix):])] = v[0]
+		}
+	}
+	return info
+}
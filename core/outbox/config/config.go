@@ -0,0 +1,42 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type OutboxSetting struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled"`
+	Publisher    string        `json:"publisher" yaml:"publisher"`       // rabbitmq|redis
+	StreamPrefix string        `json:"streamPrefix" yaml:"streamPrefix"` // redis stream 名称前缀，stream = 前缀 + topic
+	StreamMaxLen int64         `json:"streamMaxLen" yaml:"streamMaxLen"` // redis stream 近似最大长度
+	PollInterval time.Duration `json:"pollInterval" yaml:"pollInterval"`
+	BatchSize    int           `json:"batchSize" yaml:"batchSize"`
+	LeaseTime    time.Duration `json:"leaseTime" yaml:"leaseTime"` // 单条消息投递的占用时长，超时后可被其他实例重新投递
+	MaxAttempts  int           `json:"maxAttempts" yaml:"maxAttempts"`
+	Backoff      time.Duration `json:"backoff" yaml:"backoff"`       // 首次重试间隔，之后指数增长
+	MaxBackoff   time.Duration `json:"maxBackoff" yaml:"maxBackoff"` // 最大重试间隔
+	Retention    time.Duration `json:"retention" yaml:"retention"`   // 已发送消息的保留时长
+	Forward      []string      `json:"forward" yaml:"forward"`       // 需要写入 outbox 的 eventbus 事件，支持通配符
+}
+
+var Setting *OutboxSetting = &OutboxSetting{
+	Enabled:      false,
+	Publisher:    "rabbitmq",
+	StreamPrefix: "outbox:",
+	StreamMaxLen: 100000,
+	PollInterval: time.Second,
+	BatchSize:    100,
+	LeaseTime:    time.Minute,
+	MaxAttempts:  10,
+	Backoff:      time.Second,
+	MaxBackoff:   time.Minute * 10,
+	Retention:    time.Hour * 24 * 7,
+}
+
+func init() {
+	logger.Debug("Register Outbox Config")
+	config.RegisterConfig("outbox", Setting, "Transactional Outbox Settings")
+}
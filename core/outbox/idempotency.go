@@ -0,0 +1,143 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 消费端幂等存储
+type IdempotencyStore interface {
+	// 占用幂等键，返回 false 表示已处理过
+	Acquire(key string, ttl time.Duration) (bool, error)
+	// 处理失败时释放，允许重试
+	Release(key string) error
+}
+
+// Once 保证同一幂等键的处理函数只成功执行一次
+func Once(store IdempotencyStore, key string, ttl time.Duration, fn func() error) error {
+	acquired, err := store.Acquire(key, ttl)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		logger.Debug("Skip duplicated message: ", key)
+		return nil
+	}
+
+	if err = fn(); err != nil {
+		if e := store.Release(key); e != nil {
+			logger.Warn("Release idempotency key error: ", key, e.Error())
+		}
+	}
+	return err
+}
+
+func ParseEnvelope(data string) (*Envelope, error) {
+	var result Envelope
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Consumer 包装消费回调：解析消息体并按幂等键去重，可直接用于 rabbitmq consumer.Received
+func Consumer(store IdempotencyStore, ttl time.Duration, handler func(envelope *Envelope) error) func(data string) {
+	return func(data string) {
+		envelope, err := ParseEnvelope(data)
+		if err != nil {
+			logger.Error("Parse outbox envelope error: ", err.Error())
+			return
+		}
+
+		if err := Once(store, envelope.Topic+":"+envelope.Key, ttl, func() error {
+			return handler(envelope)
+		}); err != nil {
+			logger.Error("Handle outbox message error: ", envelope.Topic, envelope.Key, err.Error())
+		}
+	}
+}
+
+// 基于 Redis SET NX 的幂等存储
+type RedisIdempotencyStore struct {
+	Prefix string
+}
+
+func (s *RedisIdempotencyStore) Acquire(key string, ttl time.Duration) (bool, error) {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return false, ErrPublishFailed
+	}
+	defer client.ReleaseOneRedisClient()
+
+	args := []any{s.Prefix + key, time.Now().Unix(), "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	reply, err := client.Execute("SET", args...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+func (s *RedisIdempotencyStore) Release(key string) error {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return ErrPublishFailed
+	}
+	defer client.ReleaseOneRedisClient()
+
+	_, err := client.Execute("DEL", s.Prefix+key)
+	return err
+}
+
+// 已消费记录
+type Consumed struct {
+	Key          string     `gorm:"column:key;primaryKey" json:"key"`
+	ConsumedTime time.Time  `gorm:"column:consumed_time;autoCreateTime" json:"consumedTime"`
+	ExpiredTime  *time.Time `gorm:"column:expired_time" json:"expiredTime,omitempty"`
+}
+
+func (m *Consumed) TableName() string {
+	return "sys_outbox_consumed"
+}
+
+// 基于数据库唯一键的幂等存储，可与消费端业务处于同一事务
+type DatabaseIdempotencyStore struct {
+	*gorm.DB `inject:"database"`
+}
+
+var databaseIdempotencyStore = &DatabaseIdempotencyStore{}
+
+func init() {
+	inject.InjectValue("outboxIdempotencyStore", databaseIdempotencyStore)
+}
+
+func DefaultIdempotencyStore() *DatabaseIdempotencyStore {
+	return databaseIdempotencyStore
+}
+
+func (s *DatabaseIdempotencyStore) Acquire(key string, ttl time.Duration) (bool, error) {
+	record := &Consumed{Key: key}
+	if ttl > 0 {
+		expired := time.Now().Add(ttl)
+		record.ExpiredTime = &expired
+	}
+
+	// 先清理已过期的同名记录
+	s.Where(&Consumed{Key: key}).Where("expired_time < ?", time.Now()).Delete(&Consumed{})
+
+	res := s.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (s *DatabaseIdempotencyStore) Release(key string) error {
+	return s.Where(&Consumed{Key: key}).Delete(&Consumed{}).Error
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS sys_outbox (
+    id BIGINT NOT NULL,
+    topic VARCHAR(255),
+    dedup_key VARCHAR(255) UNIQUE,
+    payload TEXT,
+    headers TEXT,
+    status VARCHAR(255),
+    attempts INT,
+    next_attempt_time DATETIME(3),
+    locked_until DATETIME(3),
+    last_error TEXT,
+    created_time DATETIME(3),
+    sent_time DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_sys_outbox_status_next_attempt_time (status, next_attempt_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_outbox_consumed (
+    `key` VARCHAR(64) NOT NULL,
+    consumed_time DATETIME(3),
+    expired_time DATETIME(3),
+    PRIMARY KEY (`key`),
+    KEY idx_sys_outbox_consumed_expired_time (expired_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
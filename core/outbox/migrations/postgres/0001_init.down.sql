@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sys_outbox_consumed;
+DROP TABLE IF EXISTS sys_outbox;
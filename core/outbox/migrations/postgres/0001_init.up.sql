@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS sys_outbox (
+    id BIGINT NOT NULL,
+    topic VARCHAR(255),
+    dedup_key VARCHAR(255) UNIQUE,
+    payload TEXT,
+    headers TEXT,
+    status VARCHAR(255),
+    attempts INT,
+    next_attempt_time TIMESTAMP(3),
+    locked_until TIMESTAMP(3),
+    last_error TEXT,
+    created_time TIMESTAMP(3),
+    sent_time TIMESTAMP(3),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_outbox_status_next_attempt_time ON sys_outbox (status, next_attempt_time);
+
+CREATE TABLE IF NOT EXISTS sys_outbox_consumed (
+    "key" VARCHAR(64) NOT NULL,
+    consumed_time TIMESTAMP(3),
+    expired_time TIMESTAMP(3),
+    PRIMARY KEY ("key")
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_outbox_consumed_expired_time ON sys_outbox_consumed (expired_time);
@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/outbox/config"
+	"github.com/gophab/gophrame/core/snowflake"
+	"github.com/gophab/gophrame/core/starter"
+	"github.com/gophab/gophrame/core/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	STATUS_PENDING = "PENDING" // 待投递
+	STATUS_SENDING = "SENDING" // 投递中（已被某实例占用）
+	STATUS_SENT    = "SENT"    // 已投递
+	STATUS_DEAD    = "DEAD"    // 超过最大重试次数
+)
+
+// Outbox 消息，与业务数据在同一事务中写入
+type Message struct {
+	Id              int64      `gorm:"column:id;primaryKey" json:"id"`
+	Topic           string     `gorm:"column:topic" json:"topic"`
+	DedupKey        *string    `gorm:"column:dedup_key;unique" json:"dedupKey,omitempty"` // 去重键，相同键只写入一次
+	Payload         string     `gorm:"column:payload" json:"payload"`
+	Headers         string     `gorm:"column:headers" json:"headers,omitempty"`
+	Status          string     `gorm:"column:status" json:"status"`
+	Attempts        int        `gorm:"column:attempts" json:"attempts"`
+	NextAttemptTime time.Time  `gorm:"column:next_attempt_time" json:"nextAttemptTime"`
+	LockedUntil     *time.Time `gorm:"column:locked_until" json:"lockedUntil,omitempty"`
+	LastError       string     `gorm:"column:last_error" json:"lastError,omitempty"`
+	CreatedTime     time.Time  `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
+	SentTime        *time.Time `gorm:"column:sent_time" json:"sentTime,omitempty"`
+}
+
+func (m *Message) TableName() string {
+	return "sys_outbox"
+}
+
+func (m *Message) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.Id == 0 {
+		m.Id = snowflake.SnowflakeIdGenerator().GetId()
+	}
+	return
+}
+
+// 投递给消息中间件的消息体
+type Envelope struct {
+	Id          int64             `json:"id"`
+	Topic       string            `json:"topic"`
+	Key         string            `json:"key"` // 消费端幂等键：去重键，未设置时为消息 Id
+	Payload     json.RawMessage   `json:"payload"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	CreatedTime time.Time         `json:"createdTime"`
+}
+
+func (m *Message) Envelope() *Envelope {
+	result := &Envelope{
+		Id:          m.Id,
+		Topic:       m.Topic,
+		Payload:     json.RawMessage(m.Payload),
+		CreatedTime: m.CreatedTime,
+	}
+
+	if m.DedupKey != nil && *m.DedupKey != "" {
+		result.Key = *m.DedupKey
+	} else {
+		result.Key = snowflakeKey(m.Id)
+	}
+
+	if m.Headers != "" {
+		_ = json.Unmarshal([]byte(m.Headers), &result.Headers)
+	}
+	return result
+}
+
+type Option func(m *Message)
+
+// 去重键：相同键的消息只会写入一次，并作为消费端的幂等键
+func WithKey(key string) Option {
+	return func(m *Message) {
+		if key != "" {
+			m.DedupKey = &key
+		}
+	}
+}
+
+func WithHeaders(headers map[string]string) Option {
+	return func(m *Message) {
+		if data, err := json.Marshal(headers); err == nil {
+			m.Headers = string(data)
+		}
+	}
+}
+
+// 延迟投递
+func WithDelay(delay time.Duration) Option {
+	return func(m *Message) {
+		m.NextAttemptTime = time.Now().Add(delay)
+	}
+}
+
+// Publish 在当前事务（transaction.Session()）中写入 outbox 消息，由 Relay 在事务提交后投递
+func Publish(topic string, payload any, opts ...Option) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	message := &Message{
+		Topic:           topic,
+		Payload:         string(data),
+		Status:          STATUS_PENDING,
+		NextAttemptTime: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(message)
+	}
+
+	return transaction.Session().Clauses(clause.OnConflict{DoNothing: true}).Create(message).Error
+}
+
+// Forward 将 eventbus 事件写入 outbox。
+// 通过 PublishEvent 同步分发的事件与发布方处于同一协程，因此与业务处于同一事务；
+// 通过 DispatchEvent 异步分发的事件在工作协程中独立写入。
+func Forward(patterns ...string) []*eventbus.Subscription {
+	var result []*eventbus.Subscription
+	for _, pattern := range patterns {
+		result = append(result, eventbus.Handle(pattern, func(e *eventbus.Event) error {
+			return Publish(e.Topic, e.Args)
+		}, eventbus.WithName("outbox:"+pattern), eventbus.WithPriority(-1000)))
+	}
+	return result
+}
+
+var relay *Relay
+
+func init() {
+	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Stop)
+}
+
+func Start() {
+	logger.Debug("Starting Outbox Relay: ...", config.Setting.Enabled)
+	if config.Setting.Enabled {
+		if len(config.Setting.Forward) > 0 {
+			Forward(config.Setting.Forward...)
+		}
+
+		publisher, err := CreatePublisher(config.Setting.Publisher)
+		if err != nil {
+			logger.Error("Create outbox publisher error: ", err.Error())
+			return
+		}
+
+		relay = NewRelay(outboxRepository, publisher)
+		relay.Start()
+	}
+}
+
+func Stop() {
+	if relay != nil {
+		relay.Stop()
+	}
+}
@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gophab/gophrame/core/outbox/config"
+	"github.com/gophab/gophrame/core/rabbitmq/topics"
+	"github.com/gophab/gophrame/core/redis"
+)
+
+var ErrPublishFailed = errors.New("outbox publish failed")
+
+// 消息投递目标
+type Publisher interface {
+	Publish(envelope *Envelope) error
+	Close() error
+}
+
+var publishers = map[string]func() (Publisher, error){
+	"rabbitmq": func() (Publisher, error) { return &RabbitMQPublisher{}, nil },
+	"redis":    func() (Publisher, error) { return &RedisStreamPublisher{}, nil },
+}
+
+// 注册自定义投递目标
+func RegisterPublisher(name string, factory func() (Publisher, error)) {
+	publishers[name] = factory
+}
+
+func CreatePublisher(name string) (Publisher, error) {
+	if factory, b := publishers[name]; b {
+		return factory()
+	}
+	return nil, fmt.Errorf("unknown outbox publisher: %s", name)
+}
+
+// RabbitMQ Topics 模式投递，routeKey 为消息 topic
+type RabbitMQPublisher struct {
+	sync.Mutex
+	producer interface {
+		Send(routeKey, data string, delayMillisecond int) bool
+		Close()
+	}
+}
+
+func (p *RabbitMQPublisher) Publish(envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.producer == nil {
+		producer, err := topics.CreateProducer()
+		if err != nil {
+			return err
+		}
+		p.producer = producer
+	}
+
+	if !p.producer.Send(envelope.Topic, string(data), 0) {
+		// 连接可能已失效，下次重新创建
+		p.producer.Close()
+		p.producer = nil
+		return ErrPublishFailed
+	}
+	return nil
+}
+
+func (p *RabbitMQPublisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.producer != nil {
+		p.producer.Close()
+		p.producer = nil
+	}
+	return nil
+}
+
+// Redis Stream 投递: XADD <prefix><topic> MAXLEN ~ n * key <key> envelope <json>
+type RedisStreamPublisher struct{}
+
+func (p *RedisStreamPublisher) Publish(envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return ErrPublishFailed
+	}
+	defer client.ReleaseOneRedisClient()
+
+	args := []any{config.Setting.StreamPrefix + envelope.Topic}
+	if config.Setting.StreamMaxLen > 0 {
+		args = append(args, "MAXLEN", "~", config.Setting.StreamMaxLen)
+	}
+	args = append(args, "*", "key", envelope.Key, "envelope", string(data))
+
+	_, err = client.Execute("XADD", args...)
+	return err
+}
+
+func (p *RedisStreamPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/outbox/config"
+)
+
+// Relay 轮询 outbox 表并投递消息
+type Relay struct {
+	repository *OutboxRepository
+	publisher  Publisher
+	stop       chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+	lastPurge  time.Time
+}
+
+func NewRelay(repository *OutboxRepository, publisher Publisher) *Relay {
+	return &Relay{
+		repository: repository,
+		publisher:  publisher,
+		stop:       make(chan struct{}),
+	}
+}
+
+func (r *Relay) Start() {
+	logger.Info("Starting outbox relay routine...")
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(config.Setting.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.RunOnce()
+			}
+		}
+	}()
+}
+
+// 可重复调用
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		r.wg.Wait()
+		_ = r.publisher.Close()
+	})
+}
+
+// 投递一批消息，返回成功投递的数量
+func (r *Relay) RunOnce() int {
+	messages, err := r.repository.FindDeliverable(config.Setting.BatchSize)
+	if err != nil {
+		logger.Error("Load outbox messages error: ", err.Error())
+		return 0
+	}
+
+	sent := 0
+	for _, message := range messages {
+		if claimed, err := r.repository.Claim(message.Id, config.Setting.LeaseTime); err != nil || !claimed {
+			continue
+		}
+
+		if err := r.publisher.Publish(message.Envelope()); err != nil {
+			r.fail(message, err)
+			continue
+		}
+
+		if err := r.repository.MarkSent(message.Id); err != nil {
+			// 已投递但状态未更新，占用过期后会重复投递，由消费端幂等处理
+			logger.Error("Mark outbox message sent error: ", message.Id, err.Error())
+			continue
+		}
+		sent++
+	}
+
+	r.purge()
+	return sent
+}
+
+func (r *Relay) fail(message *Message, cause error) {
+	attempts := message.Attempts + 1
+	dead := config.Setting.MaxAttempts > 0 && attempts >= config.Setting.MaxAttempts
+
+	if dead {
+		logger.Error("Outbox message dead after retries: ", message.Id, message.Topic, cause.Error())
+	} else {
+		logger.Warn("Publish outbox message error: ", message.Id, message.Topic, cause.Error())
+	}
+
+	if err := r.repository.MarkFailed(message.Id, attempts, time.Now().Add(backoff(attempts)), dead, cause.Error()); err != nil {
+		logger.Error("Mark outbox message failed error: ", message.Id, err.Error())
+	}
+}
+
+func (r *Relay) purge() {
+	if config.Setting.Retention <= 0 || time.Since(r.lastPurge) < time.Hour {
+		return
+	}
+	r.lastPurge = time.Now()
+
+	if count, err := r.repository.PurgeSent(time.Now().Add(-config.Setting.Retention)); err != nil {
+		logger.Warn("Purge outbox messages error: ", err.Error())
+	} else if count > 0 {
+		logger.Debug("Purged outbox messages: ", count)
+	}
+}
+
+// 指数退避: backoff * 2^(attempts-1)，不超过 maxBackoff
+func backoff(attempts int) time.Duration {
+	d := config.Setting.Backoff
+	for i := 1; i < attempts && d < config.Setting.MaxBackoff; i++ {
+		d *= 2
+	}
+	if config.Setting.MaxBackoff > 0 && d > config.Setting.MaxBackoff {
+		d = config.Setting.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,27 @@
+package outbox
+
+import "testing"
+
+type countingPublisher struct {
+	closed int
+}
+
+func (p *countingPublisher) Publish(envelope *Envelope) error { return nil }
+
+func (p *countingPublisher) Close() error {
+	p.closed++
+	return nil
+}
+
+func TestRelayStopTwice(t *testing.T) {
+	publisher := &countingPublisher{}
+	relay := NewRelay(&OutboxRepository{}, publisher)
+	relay.Start()
+
+	relay.Stop()
+	relay.Stop()
+
+	if publisher.closed != 1 {
+		t.Fatalf("publisher closed %d times", publisher.closed)
+	}
+}
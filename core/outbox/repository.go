@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gophab/gophrame/core/inject"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	*gorm.DB `inject:"database"`
+}
+
+var outboxRepository = &OutboxRepository{}
+
+func init() {
+	inject.InjectValue("outboxRepository", outboxRepository)
+}
+
+// 待投递消息：PENDING 且到达重试时间，或 SENDING 但占用已过期
+func (r *OutboxRepository) FindDeliverable(limit int) ([]*Message, error) {
+	var result []*Message
+	now := time.Now()
+	err := r.Model(&Message{}).
+		Where("(status = ? AND next_attempt_time <= ?) OR (status = ? AND locked_until < ?)", STATUS_PENDING, now, STATUS_SENDING, now).
+		Order("id").
+		Limit(limit).
+		Find(&result).Error
+	return result, err
+}
+
+// 占用消息，多实例时只有一个实例能占用成功
+func (r *OutboxRepository) Claim(id int64, lease time.Duration) (bool, error) {
+	now := time.Now()
+	res := r.Model(&Message{}).
+		Where("id = ? AND ((status = ? AND next_attempt_time <= ?) OR (status = ? AND locked_until < ?))", id, STATUS_PENDING, now, STATUS_SENDING, now).
+		Updates(map[string]any{
+			"status":       STATUS_SENDING,
+			"locked_until": now.Add(lease),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *OutboxRepository) MarkSent(id int64) error {
+	return r.Model(&Message{}).Where("id = ?", id).Updates(map[string]any{
+		"status":       STATUS_SENT,
+		"sent_time":    time.Now(),
+		"locked_until": nil,
+		"last_error":   "",
+	}).Error
+}
+
+func (r *OutboxRepository) MarkFailed(id int64, attempts int, next time.Time, dead bool, cause string) error {
+	status := STATUS_PENDING
+	if dead {
+		status = STATUS_DEAD
+	}
+	return r.Model(&Message{}).Where("id = ?", id).Updates(map[string]any{
+		"status":            status,
+		"attempts":          attempts,
+		"next_attempt_time": next,
+		"locked_until":      nil,
+		"last_error":        cause,
+	}).Error
+}
+
+// 重新投递死信消息
+func (r *OutboxRepository) Requeue(id int64) error {
+	return r.Model(&Message{}).Where("id = ? AND status = ?", id, STATUS_DEAD).Updates(map[string]any{
+		"status":            STATUS_PENDING,
+		"attempts":          0,
+		"next_attempt_time": time.Now(),
+	}).Error
+}
+
+func (r *OutboxRepository) PurgeSent(before time.Time) (int64, error) {
+	res := r.Where("status = ? AND sent_time < ?", STATUS_SENT, before).Delete(&Message{})
+	return res.RowsAffected, res.Error
+}
+
+func snowflakeKey(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -14,11 +14,11 @@ import (
 )
 
 var __ = struct {
-	PermissionService PermissionService `inject:"permissionService"`
+	PermissionService PermissionService `inject:"permissionService,optional"`
 }{}
 
 func init() {
-	inject.InjectValue("permission", __)
+	inject.InjectValue("permission", &__)
 }
 
 // 系统用户可以访问
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type PluginSetting struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled"`           // 默认关闭，开启后加载插件目录
+	Dir          string        `json:"dir" yaml:"dir"`                   // 插件目录，每个子目录包含 plugin.json
+	Disabled     []string      `json:"disabled" yaml:"disabled"`         // 启动时禁用的插件 UUID
+	StartTimeout time.Duration `json:"startTimeout" yaml:"startTimeout"` // 等待插件握手的超时
+	CallTimeout  time.Duration `json:"callTimeout" yaml:"callTimeout"`   // 单次调用插件的超时
+	MaxBodySize  int64         `json:"maxBodySize" yaml:"maxBodySize"`   // 转发给插件的请求体上限
+}
+
+var Setting *PluginSetting = &PluginSetting{
+	Dir:          "plugins",
+	StartTimeout: time.Second * 10,
+	CallTimeout:  time.Second * 30,
+	MaxBodySize:  10 << 20,
+}
+
+func init() {
+	logger.Debug("Register Plugin Config")
+	config.RegisterConfig("plugin", Setting, "Plugin Settings")
+}
@@ -1,59 +1,261 @@
 package plugin
 
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"slices"
+	"sync"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/plugin/protocol"
+	"github.com/gophab/gophrame/core/util"
+)
+
+// 宿主入口点：供插件调用
 type EntryPoint struct {
 	NameSpace string
 	Code      string
 	Entry     func(args ...any)
+	Func      func(args ...any) (any, error) // 有返回值的入口，优先于 Entry
 }
 
+// 宿主回调点：宿主在 Callback 时通知订阅者
 type CallbackPoint struct {
 	NameSpace string
 	Code      string
 	Callbacks []func(args ...any)
 }
 
-var EntryPoints = make(map[string]EntryPoint)
-var CallbackPoints = make(map[string]CallbackPoint)
+var (
+	pointsMutex    sync.RWMutex
+	EntryPoints    = make(map[string]*EntryPoint)
+	CallbackPoints = make(map[string]*CallbackPoint)
+)
+
+func pointKey(namespace, code string) string {
+	return namespace + ":" + code
+}
 
 func RegisterEntryPoint(entryPoint *EntryPoint) {
-	EntryPoints[entryPoint.NameSpace+":"+entryPoint.Code] = *entryPoint
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+	EntryPoints[pointKey(entryPoint.NameSpace, entryPoint.Code)] = entryPoint
 }
 
 func RegisterCallbackPoint(callback *CallbackPoint) {
-	CallbackPoints[callback.NameSpace+":"+callback.Code] = *callback
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+	if existing, b := CallbackPoints[pointKey(callback.NameSpace, callback.Code)]; b {
+		// 保留已注册的回调
+		callback.Callbacks = append(existing.Callbacks, callback.Callbacks...)
+	}
+	CallbackPoints[pointKey(callback.NameSpace, callback.Code)] = callback
+}
+
+func getEntryPoint(key string) (*EntryPoint, bool) {
+	pointsMutex.RLock()
+	defer pointsMutex.RUnlock()
+	entry, b := EntryPoints[key]
+	return entry, b
 }
 
-// call from internal
+// call from internal：通知进程内回调与订阅该回调点的插件
 func Callback(namespace, code string, args ...any) {
-	if cp, b := CallbackPoints[namespace+":"+code]; b {
-		for _, callback := range cp.Callbacks {
-			callback(args)
+	pointsMutex.RLock()
+	var callbacks []func(args ...any)
+	if cp, b := CallbackPoints[pointKey(namespace, code)]; b {
+		callbacks = append(callbacks, cp.Callbacks...)
+	}
+	pointsMutex.RUnlock()
+
+	for _, callback := range callbacks {
+		callback(args...)
+	}
+
+	key := pointKey(namespace, code)
+	for _, plugin := range startedPlugins() {
+		if plugin.Manifest == nil || !slices.Contains(plugin.Manifest.Callbacks, key) {
+			continue
 		}
+		go func(plugin *Plugin) {
+			if err := plugin.callback(namespace, code, args...); err != nil {
+				logger.Warn("[PLUGIN] Callback plugin error: ", plugin.Name, key, err.Error())
+			}
+		}(plugin)
 	}
 }
 
+// 进程外插件运行时
+type Runtime interface {
+	Serve(request *protocol.HttpRequest) (*protocol.HttpResponse, error)
+	Callback(namespace, code string, args ...any) error
+	Stop() error
+	Done() <-chan struct{}
+	Err() error
+}
+
 type Engine struct {
+	sync.RWMutex
+	listener net.Listener
+	tokens   map[string]*Plugin
+}
+
+var engine = &Engine{
+	tokens: make(map[string]*Plugin),
 }
 
-var engine = &Engine{}
+// 启动宿主回调服务，仅监听本机回环地址
+func (e *Engine) serve() (string, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.listener != nil {
+		return e.listener.Addr().String(), nil
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("Host", &hostService{engine: e}); err != nil {
+		return "", err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	e.listener = listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	return listener.Addr().String(), nil
+}
+
+func (e *Engine) pluginOf(token string) *Plugin {
+	e.RLock()
+	defer e.RUnlock()
+	return e.tokens[token]
+}
 
 func (e *Engine) InitPlugin(plugin *Plugin) error {
-	return nil
+	if plugin.Manifest == nil {
+		return nil
+	}
+	return plugin.Manifest.checkEntryPoints()
 }
 
-func (*Engine) StartPlugin(plugin *Plugin) error {
+// 启动进程外插件；进程内插件无需运行时
+func (e *Engine) StartPlugin(plugin *Plugin) error {
+	if plugin.Manifest == nil {
+		return nil
+	}
+
+	addr, err := e.serve()
+	if err != nil {
+		return err
+	}
+
+	token := util.UUID()
+	e.Lock()
+	e.tokens[token] = plugin
+	e.Unlock()
+
+	runtime, err := startProcess(plugin.Manifest, addr, token)
+	if err != nil {
+		e.Lock()
+		delete(e.tokens, token)
+		e.Unlock()
+		return err
+	}
+	plugin.runtime, plugin.token = runtime, token
+
+	// 进程意外退出时标记失败
+	go func() {
+		<-runtime.Done()
+		plugin.exited(runtime)
+	}()
 	return nil
 }
 
-func (*Engine) RegisterCallback(namespace, code string, f func(args ...any)) {
-	if cp, b := CallbackPoints[namespace+":"+code]; b {
+func (e *Engine) StopPlugin(plugin *Plugin) error {
+	if plugin.runtime == nil {
+		return nil
+	}
+
+	e.Lock()
+	delete(e.tokens, plugin.token)
+	e.Unlock()
+
+	runtime := plugin.runtime
+	plugin.runtime, plugin.token = nil, ""
+	return runtime.Stop()
+}
+
+func (e *Engine) RegisterCallback(namespace, code string, f func(args ...any)) {
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+
+	key := pointKey(namespace, code)
+	if cp, b := CallbackPoints[key]; b {
 		cp.Callbacks = append(cp.Callbacks, f)
+	} else {
+		CallbackPoints[key] = &CallbackPoint{NameSpace: namespace, Code: code, Callbacks: []func(args ...any){f}}
 	}
 }
 
 // Call from plugin
-func (*Engine) CallEntryPoint(namespace, code string, args any) {
-	if entry, b := EntryPoints[namespace+":"+code]; b {
-		entry.Entry(args)
+func (e *Engine) CallEntryPoint(namespace, code string, args ...any) (any, error) {
+	entry, b := getEntryPoint(pointKey(namespace, code))
+	if !b {
+		return nil, fmt.Errorf("entry point not found: %s", pointKey(namespace, code))
+	}
+
+	if entry.Func != nil {
+		return entry.Func(args...)
+	}
+	if entry.Entry != nil {
+		entry.Entry(args...)
 	}
+	return nil, nil
+}
+
+func RegisterCallback(namespace, code string, f func(args ...any)) {
+	engine.RegisterCallback(namespace, code, f)
+}
+
+func CallEntryPoint(namespace, code string, args ...any) (any, error) {
+	return engine.CallEntryPoint(namespace, code, args...)
+}
+
+// 宿主 RPC 服务：Host.Call
+type hostService struct {
+	engine *Engine
+}
+
+func (h *hostService) Call(request *protocol.CallRequest, response *protocol.CallResponse) error {
+	plugin := h.engine.pluginOf(request.Token)
+	if plugin == nil {
+		return errors.New("invalid plugin token")
+	}
+
+	// 只允许调用清单中声明的入口点
+	key := pointKey(request.NameSpace, request.Code)
+	if !slices.Contains(plugin.Manifest.EntryPoints, key) {
+		return fmt.Errorf("entry point not declared in manifest: %s", key)
+	}
+
+	result, err := h.engine.CallEntryPoint(request.NameSpace, request.Code, request.Args...)
+	if err != nil {
+		return err
+	}
+	response.Result = result
+	return nil
 }
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const MANIFEST_FILE = "plugin.json"
+
+// 插件运行方式：目前只支持进程外插件，WASM 模块不在支持范围内
+const (
+	RUNTIME_PROCESS = "process" // 进程外可执行文件，stdout 握手后经本机回环 TCP 走 net/rpc
+)
+
+var ErrUnsupportedRuntime = errors.New("unsupported plugin runtime")
+
+// 插件清单：<dir>/<plugin>/plugin.json
+type Manifest struct {
+	UUID        string   `json:"uuid"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description,omitempty"`
+	Runtime     string   `json:"runtime"`    // 仅支持 process，默认 process
+	Executable  string   `json:"executable"` // 相对插件目录
+	Args        []string `json:"args,omitempty"`
+	RouterPath  string   `json:"routerPath,omitempty"`  // 路由挂载路径，为空时不转发 HTTP 请求
+	EntryPoints []string `json:"entryPoints,omitempty"` // 依赖的宿主入口点 namespace:code
+	Callbacks   []string `json:"callbacks,omitempty"`   // 订阅的宿主回调点 namespace:code
+	Priority    int      `json:"priority,omitempty"`
+
+	Dir string `json:"-"`
+}
+
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MANIFEST_FILE))
+	if err != nil {
+		return nil, err
+	}
+
+	var result Manifest
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%s: %w", MANIFEST_FILE, err)
+	}
+	result.Dir = dir
+	if result.Runtime == "" {
+		result.Runtime = RUNTIME_PROCESS
+	}
+	return &result, result.Validate()
+}
+
+func (m *Manifest) Validate() error {
+	var errs []error
+	if m.UUID == "" {
+		errs = append(errs, errors.New("uuid is required"))
+	}
+	if m.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if m.Version == "" {
+		errs = append(errs, errors.New("version is required"))
+	}
+
+	switch m.Runtime {
+	case RUNTIME_PROCESS:
+		if m.Executable == "" {
+			errs = append(errs, errors.New("executable is required"))
+		} else if filepath.IsAbs(m.Executable) || strings.HasPrefix(filepath.Clean(m.Executable), "..") {
+			errs = append(errs, errors.New("executable must be inside the plugin directory"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%w: %s", ErrUnsupportedRuntime, m.Runtime))
+	}
+
+	for _, key := range append(append([]string{}, m.EntryPoints...), m.Callbacks...) {
+		if !strings.Contains(key, ":") {
+			errs = append(errs, fmt.Errorf("invalid point %q, expected namespace:code", key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 检查依赖的宿主入口点均已注册
+func (m *Manifest) checkEntryPoints() error {
+	var missing []string
+	for _, key := range m.EntryPoints {
+		if _, b := getEntryPoint(key); !b {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing entry points: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -1,11 +1,23 @@
 package plugin
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gophab/gophrame/core/global"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/plugin/config"
+	"github.com/gophab/gophrame/core/plugin/protocol"
+	"github.com/gophab/gophrame/core/router"
 	"github.com/gophab/gophrame/core/starter"
 )
 
@@ -15,8 +27,20 @@ const (
 	STATUS_INITIALIZED
 	STATUS_STARTED
 	STATUS_TERMINATED
+	STATUS_FAILED
 )
 
+var statusNames = map[int]string{
+	STATUS:             "UNKNOWN",
+	STATUS_REGISTERED:  "REGISTERED",
+	STATUS_INITIALIZED: "INITIALIZED",
+	STATUS_STARTED:     "STARTED",
+	STATUS_TERMINATED:  "TERMINATED",
+	STATUS_FAILED:      "FAILED",
+}
+
+var ErrPluginNotFound = errors.New("plugin not found")
+
 // Plugin 插件接口
 type IPlugin interface {
 	// Register 注册路由
@@ -31,9 +55,17 @@ type Plugin struct {
 	Name        string
 	Description string
 	UUID        string // universal identify, used by Plugin Market
+	Version     string
 	Priority    int
 	Status      int
 	Enabled     bool
+	Manifest    *Manifest // 从插件目录加载的进程外插件
+	Error       error
+
+	mutex   sync.Mutex
+	runtime Runtime
+	token   string
+	mounted bool
 }
 
 func (*Plugin) Terminate() {}
@@ -44,71 +76,420 @@ func (*Plugin) Init() {}
 
 func (*Plugin) Register() {}
 
-var plugins = make([]*Plugin, 0)
+func (p *Plugin) routerPath() string {
+	if p.IPlugin == nil {
+		return ""
+	}
+	return p.IPlugin.RouterPath()
+}
+
+func (p *Plugin) fail(err error) {
+	p.Status = STATUS_FAILED
+	p.Error = err
+	logger.Error("[PLUGIN] Plugin [", p.Name, "] error: ", err.Error())
+}
+
+// 启动插件运行时并挂载路由
+func (p *Plugin) start() error {
+	if err := engine.StartPlugin(p); err != nil {
+		p.fail(err)
+		return err
+	}
+	p.Start()
+	p.mount()
+	p.Status, p.Error = STATUS_STARTED, nil
+	return nil
+}
+
+func (p *Plugin) initialize() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Status == STATUS_FAILED {
+		return
+	}
+
+	p.Init()
+
+	// Plugin Engine
+	if err := engine.InitPlugin(p); err != nil {
+		p.fail(err)
+		return
+	}
+	p.Status = STATUS_INITIALIZED
+}
+
+func (p *Plugin) stop() error {
+	p.Terminate()
+	err := engine.StopPlugin(p)
+	p.Status = STATUS_INITIALIZED
+	return err
+}
+
+// 路由只挂载一次，禁用后由 guard 拒绝请求
+func (p *Plugin) mount() {
+	path := p.routerPath()
+	if p.mounted || path == "" || p.IPlugin == nil {
+		return
+	}
+	p.mounted = true
+
+	group := router.Root().Group(path, p.guard())
+	p.IPlugin.Register(group)
+	logger.Info("[PLUGIN] Mounted plugin [", p.Name, "] at: ", path)
+}
+
+func (p *Plugin) guard() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p.mutex.Lock()
+		available := p.Enabled && p.Status == STATUS_STARTED
+		p.mutex.Unlock()
+
+		if !available {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func (p *Plugin) exited(runtime Runtime) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// 正常停止时 runtime 已被清除
+	if p.runtime == runtime {
+		p.runtime, p.token = nil, ""
+		p.fail(fmt.Errorf("plugin process exited: %v", runtime.Err()))
+	}
+}
+
+func (p *Plugin) callback(namespace, code string, args ...any) error {
+	p.mutex.Lock()
+	runtime := p.runtime
+	p.mutex.Unlock()
+
+	if runtime == nil {
+		return nil
+	}
+	return runtime.Callback(namespace, code, args...)
+}
+
+// 进程外插件：按清单挂载路由，请求转发给插件进程
+type remotePlugin struct {
+	plugin *Plugin
+}
+
+func (r *remotePlugin) RouterPath() string {
+	if r.plugin.Manifest.RouterPath == "" {
+		return ""
+	}
+	return normalizePath(r.plugin.Manifest.RouterPath)
+}
+
+func (r *remotePlugin) Register(group *gin.RouterGroup) {
+	group.Any("/*path", r.serve)
+}
+
+func (r *remotePlugin) serve(ctx *gin.Context) {
+	r.plugin.mutex.Lock()
+	runtime := r.plugin.runtime
+	r.plugin.mutex.Unlock()
+
+	if runtime == nil {
+		ctx.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, config.Setting.MaxBodySize+1))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > config.Setting.MaxBodySize {
+		ctx.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	response, err := runtime.Serve(&protocol.HttpRequest{
+		Method:   ctx.Request.Method,
+		Path:     ctx.Param("path"),
+		RawQuery: ctx.Request.URL.RawQuery,
+		Header:   ctx.Request.Header,
+		Body:     body,
+		ClientIP: ctx.ClientIP(),
+	})
+	if err != nil {
+		logger.Warn("[PLUGIN] Serve plugin request error: ", r.plugin.Name, err.Error())
+		ctx.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+
+	for key, values := range response.Header {
+		for _, value := range values {
+			ctx.Writer.Header().Add(key, value)
+		}
+	}
+	if response.Status == 0 {
+		response.Status = http.StatusOK
+	}
+	ctx.Status(response.Status)
+	_, _ = ctx.Writer.Write(response.Body)
+}
+
+// 插件状态
+type PluginInfo struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	Runtime     string `json:"runtime"`
+	RouterPath  string `json:"routerPath,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+var (
+	pluginsMutex sync.RWMutex
+	plugins      = make([]*Plugin, 0)
+)
 
 func RegisterPlugin(plugin *Plugin) {
+	pluginsMutex.Lock()
 	plugins = append(plugins, plugin)
+	pluginsMutex.Unlock()
+
 	plugin.Register()
+	plugin.mutex.Lock()
 	plugin.Status = STATUS_REGISTERED
+	plugin.mutex.Unlock()
+}
+
+func GetPlugin(uuid string) *Plugin {
+	pluginsMutex.RLock()
+	defer pluginsMutex.RUnlock()
+
+	for _, plugin := range plugins {
+		if plugin.UUID == uuid {
+			return plugin
+		}
+	}
+	return nil
+}
+
+func Plugins() []*PluginInfo {
+	pluginsMutex.RLock()
+	list := append([]*Plugin(nil), plugins...)
+	pluginsMutex.RUnlock()
+
+	result := make([]*PluginInfo, 0, len(list))
+	for _, plugin := range list {
+		plugin.mutex.Lock()
+		info := &PluginInfo{
+			UUID:        plugin.UUID,
+			Name:        plugin.Name,
+			Version:     plugin.Version,
+			Description: plugin.Description,
+			Runtime:     "builtin",
+			RouterPath:  plugin.routerPath(),
+			Enabled:     plugin.Enabled,
+			Status:      statusNames[plugin.Status],
+		}
+		if plugin.Manifest != nil {
+			info.Runtime = plugin.Manifest.Runtime
+		}
+		if plugin.Error != nil {
+			info.Error = plugin.Error.Error()
+		}
+		plugin.mutex.Unlock()
+		result = append(result, info)
+	}
+	return result
+}
+
+func startedPlugins() []*Plugin {
+	pluginsMutex.RLock()
+	defer pluginsMutex.RUnlock()
+
+	var result []*Plugin
+	for _, plugin := range plugins {
+		plugin.mutex.Lock()
+		started := plugin.Status == STATUS_STARTED
+		plugin.mutex.Unlock()
+		if started {
+			result = append(result, plugin)
+		}
+	}
+	return result
+}
+
+// 加载插件目录下的全部插件清单
+func Load(dir string) error {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(global.BasePath, dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifest, err := LoadManifest(filepath.Join(dir, entry.Name()))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			// 清单无效的插件不注册
+			logger.Error("[PLUGIN] Invalid plugin manifest [", entry.Name(), "]: ", err.Error())
+			continue
+		}
+		if existing := GetPlugin(manifest.UUID); existing != nil {
+			logger.Error("[PLUGIN] Duplicated plugin uuid [", entry.Name(), "]: ", manifest.UUID)
+			continue
+		}
+
+		plugin := &Plugin{
+			Name:        manifest.Name,
+			UUID:        manifest.UUID,
+			Version:     manifest.Version,
+			Description: manifest.Description,
+			Priority:    manifest.Priority,
+			Enabled:     !slices.Contains(config.Setting.Disabled, manifest.UUID),
+			Manifest:    manifest,
+		}
+		plugin.IPlugin = &remotePlugin{plugin: plugin}
+
+		RegisterPlugin(plugin)
+	}
+	return nil
+}
+
+// 运行时启用插件
+func Enable(uuid string) error {
+	plugin := GetPlugin(uuid)
+	if plugin == nil {
+		return ErrPluginNotFound
+	}
+
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	plugin.Enabled = true
+	if plugin.Status == STATUS_STARTED {
+		return nil
+	}
+	if plugin.Status == STATUS_FAILED && plugin.Manifest != nil {
+		// 重新加载清单，允许修正后启用
+		manifest, err := LoadManifest(plugin.Manifest.Dir)
+		if err != nil {
+			return err
+		}
+		if manifest.UUID != plugin.UUID {
+			return fmt.Errorf("plugin uuid changed: %s", manifest.UUID)
+		}
+		plugin.Manifest, plugin.Version = manifest, manifest.Version
+		if err := engine.InitPlugin(plugin); err != nil {
+			return err
+		}
+	}
+	return plugin.start()
+}
+
+// 运行时禁用插件：停止插件进程，路由返回 404
+func Disable(uuid string) error {
+	plugin := GetPlugin(uuid)
+	if plugin == nil {
+		return ErrPluginNotFound
+	}
+
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	plugin.Enabled = false
+	if plugin.Status != STATUS_STARTED {
+		return nil
+	}
+	return plugin.stop()
 }
 
 var (
-	onceInit, onceStart sync.Once
+	onceInit, onceStart, onceTerminate sync.Once
 )
 
 func Init() {
 	onceInit.Do(func() {
-		sort.Slice(plugins, func(i, j int) bool {
+		if config.Setting.Enabled && config.Setting.Dir != "" {
+			if err := Load(config.Setting.Dir); err != nil {
+				logger.Error("[PLUGIN] Loading plugins error: ", err.Error())
+			}
+		}
+
+		pluginsMutex.Lock()
+		sort.SliceStable(plugins, func(i, j int) bool {
 			return plugins[i].Priority < plugins[j].Priority
 		})
-		for _, plugin := range plugins {
-			plugin.Init()
-			plugin.Status = STATUS_INITIALIZED
-
-			// Plugin Engine
-			if plugin.Enabled {
-				if err := engine.InitPlugin(plugin); err != nil {
-					// 引擎初始化
-					logger.Error("[PLUGIN] Initializing plugin [", plugin.Name, "] error: ", err.Error())
-				}
-			}
+		list := append([]*Plugin(nil), plugins...)
+		pluginsMutex.Unlock()
+
+		for _, plugin := range list {
+			plugin.initialize()
 		}
 	})
 }
 
 func Start() {
 	onceStart.Do(func() {
-		for _, plugin := range plugins {
-			// Plugin Engine
-			if plugin.Enabled && plugin.Status == STATUS_INITIALIZED {
-				plugin.Start()
-				plugin.Status = STATUS_STARTED
-
-				if err := engine.StartPlugin(plugin); err != nil {
-					// 引擎初始化
-					logger.Error("[PLUGIN] Starting plugin [", plugin.Name, "] error: ", err.Error())
-				}
+		pluginsMutex.RLock()
+		list := append([]*Plugin(nil), plugins...)
+		pluginsMutex.RUnlock()
+
+		for _, plugin := range list {
+			plugin.mutex.Lock()
+			if plugin.Status == STATUS_INITIALIZED && plugin.Enabled {
+				// 启动失败不影响宿主，插件标记为失败
+				_ = plugin.start()
 			}
+			// 禁用或失败的插件也挂载路由，以便运行时启用
+			plugin.mount()
+			plugin.mutex.Unlock()
 		}
 	})
 }
 
 func Terminate() {
-	onceStart.Do(func() {
-		for _, plugin := range plugins {
-			// Plugin Engine
-			if plugin.Enabled && plugin.Status == STATUS_STARTED {
-				plugin.Terminate()
-				plugin.Status = STATUS_TERMINATED
-				if err := engine.StartPlugin(plugin); err != nil {
-					// 引擎初始化
-					logger.Error("[PLUGIN] Starting plugin [", plugin.Name, "] error: ", err.Error())
+	onceTerminate.Do(func() {
+		pluginsMutex.RLock()
+		list := append([]*Plugin(nil), plugins...)
+		pluginsMutex.RUnlock()
+
+		for i := len(list) - 1; i >= 0; i-- {
+			plugin := list[i]
+			plugin.mutex.Lock()
+			if plugin.Status == STATUS_STARTED {
+				if err := plugin.stop(); err != nil {
+					logger.Error("[PLUGIN] Terminating plugin [", plugin.Name, "] error: ", err.Error())
 				}
+				plugin.Status = STATUS_TERMINATED
 			}
+			plugin.mutex.Unlock()
 		}
 	})
 }
 
+// 插件路由前缀规范化
+func normalizePath(path string) string {
+	return "/" + strings.Trim(path, "/")
+}
+
 func init() {
 	starter.RegisterInitializorEx(Init, 0x1FFFFFFF)
 	starter.RegisterStarterEx(Start, 0x1FFFFFFF)
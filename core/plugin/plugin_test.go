@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func writeManifest(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name, MANIFEST_FILE), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	m := &Manifest{UUID: "u", Name: "n", Version: "1", Runtime: "wasm", Executable: "bin"}
+	if err := m.Validate(); !errors.Is(err, ErrUnsupportedRuntime) {
+		t.Fatalf("expected unsupported runtime, got %v", err)
+	}
+	m.Runtime, m.Executable = RUNTIME_PROCESS, "../bin"
+	if err := m.Validate(); err == nil {
+		t.Fatal("expected executable outside plugin directory to fail")
+	}
+	m.Executable = "bin"
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSkipsInvalidManifests(t *testing.T) {
+	defer func(list []*Plugin) { plugins = list }(plugins)
+	plugins = nil
+
+	dir := t.TempDir()
+	writeManifest(t, dir, "a-valid", `{"uuid":"p-1","name":"valid","version":"1.0","executable":"bin"}`)
+	writeManifest(t, dir, "b-duplicated", `{"uuid":"p-1","name":"duplicated","version":"1.0","executable":"bin"}`)
+	writeManifest(t, dir, "no-uuid", `{"name":"no-uuid","version":"1.0","executable":"bin"}`)
+	writeManifest(t, dir, "wasm", `{"uuid":"p-2","name":"wasm","version":"1.0","runtime":"wasm","executable":"a.wasm"}`)
+	writeManifest(t, dir, "broken", `{`)
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 目录按名称顺序加载，后出现的重复 UUID 被跳过
+	if err := Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	list := Plugins()
+	if len(list) != 1 || list[0].UUID != "p-1" || list[0].Name != "valid" {
+		t.Fatalf("unexpected plugins: %+v", list)
+	}
+}
+
+func TestGuardConcurrentWithToggle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p := &Plugin{Name: "guarded", Status: STATUS_STARTED}
+
+	r := gin.New()
+	r.GET("/p", p.guard(), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	serve := func() int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/p", nil))
+		return w.Code
+	}
+	if code := serve(); code != http.StatusNotFound {
+		t.Fatalf("disabled plugin served: %d", code)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			p.mutex.Lock()
+			p.Enabled = !p.Enabled
+			p.mutex.Unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			serve()
+		}()
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	p.Enabled = true
+	p.mutex.Unlock()
+	if code := serve(); code != http.StatusOK {
+		t.Fatalf("enabled plugin rejected: %d", code)
+	}
+}
+
+func TestStartedPluginsConcurrentWithStatus(t *testing.T) {
+	defer func(list []*Plugin) { plugins = list }(plugins)
+	p := &Plugin{Name: "toggled", Status: STATUS_STARTED}
+	plugins = []*Plugin{p}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			p.mutex.Lock()
+			p.Status = STATUS_INITIALIZED + STATUS_STARTED - p.Status
+			p.mutex.Unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			startedPlugins()
+		}()
+	}
+	wg.Wait()
+
+	if list := startedPlugins(); len(list) != 1 || list[0] != p {
+		t.Fatalf("unexpected started plugins: %+v", list)
+	}
+}
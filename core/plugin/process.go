@@ -0,0 +1,216 @@
+package plugin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/plugin/config"
+	"github.com/gophab/gophrame/core/plugin/protocol"
+)
+
+var (
+	ErrPluginTimeout = errors.New("plugin call timeout")
+	ErrPluginExited  = errors.New("plugin exited")
+)
+
+// 进程外插件：子进程 + 本机回环 net/rpc
+type processRuntime struct {
+	manifest *Manifest
+	token    string
+	cmd      *exec.Cmd
+	client   *rpc.Client
+	done     chan struct{}
+	err      error
+}
+
+func startProcess(manifest *Manifest, hostAddr string, token string) (*processRuntime, error) {
+	cmd := exec.Command(filepath.Join(manifest.Dir, manifest.Executable), manifest.Args...)
+	cmd.Dir = manifest.Dir
+	cmd.Env = append(os.Environ(),
+		protocol.ENV_MAGIC_COOKIE+"="+protocol.MAGIC_COOKIE,
+		protocol.ENV_TOKEN+"="+token,
+		protocol.ENV_HOST_ADDR+"="+hostAddr,
+		protocol.ENV_ROUTER_PATH+"="+manifest.RouterPath,
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	r := &processRuntime{
+		manifest: manifest,
+		token:    token,
+		cmd:      cmd,
+		done:     make(chan struct{}),
+	}
+	go r.log(stderr, true)
+	go func() {
+		r.err = cmd.Wait()
+		close(r.done)
+	}()
+
+	// 1. 握手：读取插件监听地址
+	addr, err := r.handshake(bufio.NewReader(stdout))
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, fmt.Errorf("plugin %s handshake: %w", manifest.Name, err)
+	}
+
+	// 2. 连接插件
+	conn, err := net.DialTimeout("tcp", addr, config.Setting.StartTimeout)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+	r.client = rpc.NewClientWithCodec(jsonrpc.NewClientCodec(conn))
+
+	// 3. 启动
+	if err := r.call("Plugin.Start", &protocol.Request{Token: token}, &protocol.Empty{}); err != nil {
+		_ = r.client.Close()
+		_ = cmd.Process.Kill()
+		return nil, fmt.Errorf("plugin %s start: %w", manifest.Name, err)
+	}
+
+	logger.Info("[PLUGIN] Started plugin process: ", manifest.Name, cmd.Process.Pid)
+	return r, nil
+}
+
+func (r *processRuntime) handshake(reader *bufio.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+
+	lines := make(chan result, 1)
+	go func() {
+		// 握手之前的输出写入日志
+		for {
+			line, err := reader.ReadString('\n')
+			line = strings.TrimSpace(line)
+			if err != nil || strings.HasPrefix(line, protocol.HANDSHAKE_PREFIX+"|") {
+				lines <- result{line, err}
+				break
+			}
+			logger.Info("[PLUGIN] ", r.manifest.Name, ": ", line)
+		}
+		r.log(reader, false)
+	}()
+
+	select {
+	case res := <-lines:
+		if res.err != nil {
+			return "", res.err
+		}
+
+		// gophrame-plugin|<version>|tcp|<addr>
+		parts := strings.Split(res.line, "|")
+		if len(parts) != 4 || parts[0] != protocol.HANDSHAKE_PREFIX {
+			return "", fmt.Errorf("invalid handshake: %q", res.line)
+		}
+		if version, _ := strconv.Atoi(parts[1]); version != protocol.VERSION {
+			return "", fmt.Errorf("incompatible protocol version: %s", parts[1])
+		}
+		if parts[2] != "tcp" {
+			return "", fmt.Errorf("unsupported network: %s", parts[2])
+		}
+		if host, _, err := net.SplitHostPort(parts[3]); err != nil || !net.ParseIP(host).IsLoopback() {
+			return "", fmt.Errorf("plugin must listen on loopback: %s", parts[3])
+		}
+		return parts[3], nil
+	case <-r.done:
+		return "", fmt.Errorf("%w: %v", ErrPluginExited, r.err)
+	case <-time.After(config.Setting.StartTimeout):
+		return "", ErrPluginTimeout
+	}
+}
+
+// 插件输出写入日志
+func (r *processRuntime) log(reader io.Reader, stderr bool) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if stderr {
+			logger.Warn("[PLUGIN] ", r.manifest.Name, ": ", scanner.Text())
+		} else {
+			logger.Info("[PLUGIN] ", r.manifest.Name, ": ", scanner.Text())
+		}
+	}
+}
+
+func (r *processRuntime) call(method string, args any, reply any) error {
+	call := r.client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-r.done:
+		return fmt.Errorf("%w: %v", ErrPluginExited, r.err)
+	case <-time.After(config.Setting.CallTimeout):
+		return ErrPluginTimeout
+	}
+}
+
+func (r *processRuntime) Serve(request *protocol.HttpRequest) (*protocol.HttpResponse, error) {
+	request.Token = r.token
+	var response protocol.HttpResponse
+	if err := r.call("Plugin.Serve", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (r *processRuntime) Callback(namespace, code string, args ...any) error {
+	return r.call("Plugin.Callback", &protocol.CallbackRequest{
+		Request:   protocol.Request{Token: r.token},
+		NameSpace: namespace,
+		Code:      code,
+		Args:      args,
+	}, &protocol.Empty{})
+}
+
+// 通知插件停止，超时后结束进程
+func (r *processRuntime) Stop() error {
+	err := r.call("Plugin.Stop", &protocol.Request{Token: r.token}, &protocol.Empty{})
+	_ = r.client.Close()
+
+	select {
+	case <-r.done:
+	case <-time.After(config.Setting.StartTimeout):
+		_ = r.cmd.Process.Kill()
+		<-r.done
+	}
+
+	logger.Info("[PLUGIN] Stopped plugin process: ", r.manifest.Name)
+	if errors.Is(err, ErrPluginExited) && r.err == nil {
+		// 插件应答前已正常退出
+		return nil
+	}
+	return err
+}
+
+func (r *processRuntime) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *processRuntime) Err() error {
+	return r.err
+}
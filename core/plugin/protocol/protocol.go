@@ -0,0 +1,67 @@
+// 宿主与进程外插件之间的协议：net/rpc + JSON 编码
+//
+// 握手：宿主通过环境变量传递 MagicCookie、Token 与宿主回调地址并启动插件，
+// 插件在本机回环地址监听后向标准输出写入一行（之前的输出被视为日志）：
+//
+//	gophrame-plugin|<version>|tcp|127.0.0.1:port
+//
+// 宿主连接该地址调用 Plugin.*；插件连接宿主回调地址调用 Host.*。
+package protocol
+
+const (
+	VERSION = 1
+
+	HANDSHAKE_PREFIX = "gophrame-plugin"
+
+	ENV_MAGIC_COOKIE = "GOPHRAME_PLUGIN_MAGIC_COOKIE"
+	ENV_TOKEN        = "GOPHRAME_PLUGIN_TOKEN"
+	ENV_HOST_ADDR    = "GOPHRAME_PLUGIN_HOST"
+	ENV_ROUTER_PATH  = "GOPHRAME_PLUGIN_ROUTER_PATH"
+
+	// 防止插件可执行文件被直接运行
+	MAGIC_COOKIE = "5c1b0e3e-6c33-4a8a-9c51-5f4a1f0c3d2e"
+)
+
+// 每次调用携带宿主分配的令牌
+type Request struct {
+	Token string `json:"token"`
+}
+
+type Empty struct{}
+
+// Plugin.Serve：宿主转发到 RouterPath 下的 HTTP 请求
+type HttpRequest struct {
+	Request
+	Method   string              `json:"method"`
+	Path     string              `json:"path"` // 相对于 RouterPath
+	RawQuery string              `json:"rawQuery,omitempty"`
+	Header   map[string][]string `json:"header,omitempty"`
+	Body     []byte              `json:"body,omitempty"`
+	ClientIP string              `json:"clientIp,omitempty"`
+}
+
+type HttpResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   []byte              `json:"body,omitempty"`
+}
+
+// Plugin.Callback：宿主回调点通知
+type CallbackRequest struct {
+	Request
+	NameSpace string `json:"namespace"`
+	Code      string `json:"code"`
+	Args      []any  `json:"args,omitempty"`
+}
+
+// Host.Call：插件调用宿主入口点
+type CallRequest struct {
+	Request
+	NameSpace string `json:"namespace"`
+	Code      string `json:"code"`
+	Args      []any  `json:"args,omitempty"`
+}
+
+type CallResponse struct {
+	Result any `json:"result,omitempty"`
+}
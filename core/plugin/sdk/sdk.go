@@ -0,0 +1,233 @@
+// 进程外插件开发包：
+//
+//	func main() {
+//		if err := sdk.Serve(sdk.HttpHandler(mux)); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// 插件进程由宿主启动，标准输出中的握手行之外的输出写入宿主日志。
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/gophab/gophrame/core/plugin/protocol"
+)
+
+var ErrNotPlugin = errors.New("this binary is a plugin and must be started by the host")
+
+// 插件处理宿主转发的 HTTP 请求
+type Handler interface {
+	Serve(request *protocol.HttpRequest) (*protocol.HttpResponse, error)
+}
+
+// 可选：插件启动、停止
+type Starter interface {
+	Start() error
+}
+
+type Stopper interface {
+	Stop() error
+}
+
+// 可选：接收清单中订阅的回调点
+type CallbackHandler interface {
+	Callback(namespace, code string, args []any) error
+}
+
+type pluginService struct {
+	handler Handler
+	token   string
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func (s *pluginService) check(token string) error {
+	if token != s.token {
+		return errors.New("invalid host token")
+	}
+	return nil
+}
+
+func (s *pluginService) Start(request *protocol.Request, _ *protocol.Empty) error {
+	if err := s.check(request.Token); err != nil {
+		return err
+	}
+	if starter, ok := s.handler.(Starter); ok {
+		return starter.Start()
+	}
+	return nil
+}
+
+func (s *pluginService) Stop(request *protocol.Request, _ *protocol.Empty) error {
+	if err := s.check(request.Token); err != nil {
+		return err
+	}
+	defer s.once.Do(func() { close(s.stopped) })
+	if stopper, ok := s.handler.(Stopper); ok {
+		return stopper.Stop()
+	}
+	return nil
+}
+
+func (s *pluginService) Serve(request *protocol.HttpRequest, response *protocol.HttpResponse) error {
+	if err := s.check(request.Token); err != nil {
+		return err
+	}
+	result, err := s.handler.Serve(request)
+	if err != nil {
+		return err
+	}
+	*response = *result
+	return nil
+}
+
+func (s *pluginService) Callback(request *protocol.CallbackRequest, _ *protocol.Empty) error {
+	if err := s.check(request.Token); err != nil {
+		return err
+	}
+	if handler, ok := s.handler.(CallbackHandler); ok {
+		return handler.Callback(request.NameSpace, request.Code, request.Args)
+	}
+	return nil
+}
+
+// 启动插件服务，直到宿主调用 Stop 或断开连接
+func Serve(handler Handler) error {
+	if os.Getenv(protocol.ENV_MAGIC_COOKIE) != protocol.MAGIC_COOKIE {
+		return ErrNotPlugin
+	}
+
+	service := &pluginService{
+		handler: handler,
+		token:   os.Getenv(protocol.ENV_TOKEN),
+		stopped: make(chan struct{}),
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("Plugin", service); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	// 握手
+	fmt.Printf("%s|%d|tcp|%s\n", protocol.HANDSHAKE_PREFIX, protocol.VERSION, listener.Addr().String())
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+
+	disconnected := make(chan struct{})
+	go func() {
+		server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		close(disconnected)
+	}()
+
+	select {
+	case <-service.stopped:
+	case <-disconnected:
+	}
+	return nil
+}
+
+var (
+	hostMutex  sync.Mutex
+	hostClient *rpc.Client
+)
+
+// 调用宿主入口点，入口点须在清单 entryPoints 中声明
+func Call(namespace, code string, args ...any) (any, error) {
+	hostMutex.Lock()
+	if hostClient == nil {
+		conn, err := net.Dial("tcp", os.Getenv(protocol.ENV_HOST_ADDR))
+		if err != nil {
+			hostMutex.Unlock()
+			return nil, err
+		}
+		hostClient = rpc.NewClientWithCodec(jsonrpc.NewClientCodec(conn))
+	}
+	client := hostClient
+	hostMutex.Unlock()
+
+	var response protocol.CallResponse
+	err := client.Call("Host.Call", &protocol.CallRequest{
+		Request:   protocol.Request{Token: os.Getenv(protocol.ENV_TOKEN)},
+		NameSpace: namespace,
+		Code:      code,
+		Args:      args,
+	}, &response)
+	if errors.Is(err, rpc.ErrShutdown) {
+		hostMutex.Lock()
+		hostClient = nil
+		hostMutex.Unlock()
+	}
+	return response.Result, err
+}
+
+// 使用标准库 http.Handler 处理请求
+func HttpHandler(h http.Handler) Handler {
+	return &httpHandler{handler: h}
+}
+
+type httpHandler struct {
+	handler http.Handler
+}
+
+func (h *httpHandler) Serve(request *protocol.HttpRequest) (*protocol.HttpResponse, error) {
+	req, err := http.NewRequest(request.Method, (&url.URL{Path: request.Path, RawQuery: request.RawQuery}).String(), bytes.NewReader(request.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = request.Header
+	req.RemoteAddr = request.ClientIP
+
+	writer := &responseWriter{header: make(http.Header)}
+	h.handler.ServeHTTP(writer, req)
+	if writer.status == 0 {
+		writer.status = http.StatusOK
+	}
+	return &protocol.HttpResponse{
+		Status: writer.status,
+		Header: writer.header,
+		Body:   writer.body.Bytes(),
+	}, nil
+}
+
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
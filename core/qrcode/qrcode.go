@@ -0,0 +1,108 @@
+// Package qrcode 二维码编码与渲染，编码由 github.com/skip2/go-qrcode 完成。
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	qr "github.com/skip2/go-qrcode"
+)
+
+// 纠错等级
+type Level int
+
+const (
+	Low      Level = iota // 约 7%
+	Medium                // 约 15%
+	Quartile              // 约 25%
+	High                  // 约 30%
+)
+
+var ErrTooLong = errors.New("qrcode: content too long")
+
+var recoveryLevels = [4]qr.RecoveryLevel{qr.Low, qr.Medium, qr.High, qr.Highest}
+
+// 二维码：自动选择最小版本和最优掩码，模块矩阵不含空白边
+type QRCode struct {
+	Version int
+	Size    int
+	Level   Level
+
+	modules [][]bool
+}
+
+func Encode(content string, level Level) (*QRCode, error) {
+	if level < Low || level > High {
+		return nil, fmt.Errorf("qrcode: invalid level %d", level)
+	}
+
+	code, err := qr.New(content, recoveryLevels[level])
+	if err != nil {
+		// 库只在内容超出最大版本容量时返回错误
+		return nil, ErrTooLong
+	}
+	code.DisableBorder = true
+
+	modules := code.Bitmap()
+	return &QRCode{Version: code.VersionNumber, Size: len(modules), Level: level, modules: modules}, nil
+}
+
+// 模块是否为深色，越界时为浅色
+func (q *QRCode) Dark(x, y int) bool {
+	return x >= 0 && x < q.Size && y >= 0 && y < q.Size && q.modules[y][x]
+}
+
+// 每个模块 scale 像素，四周留 border 个模块的空白
+func (q *QRCode) Image(scale, border int) image.Image {
+	scale, border = max(scale, 1), max(border, 0)
+	width := (q.Size + border*2) * scale
+
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for y := 0; y < width; y++ {
+		for x := 0; x < width; x++ {
+			if q.Dark(x/scale-border, y/scale-border) {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+func (q *QRCode) PNG(scale, border int) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, q.Image(scale, border)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (q *QRCode) SVG(border int) string {
+	border = max(border, 0)
+	width := q.Size + border*2
+
+	var path strings.Builder
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				if path.Len() > 0 {
+					path.WriteByte(' ')
+				}
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+border, y+border)
+			}
+		}
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %d %d" stroke="none">
+<rect width="100%%" height="100%%" fill="#FFFFFF"/>
+<path d="%s" fill="#000000"/>
+</svg>
+`, width, width, path.String())
+}
@@ -0,0 +1,103 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// 三个角上的定位图形：7x7，外圈深、次圈浅、中心 3x3 深
+func checkFinderPatterns(t *testing.T, q *QRCode) {
+	t.Helper()
+	for _, corner := range [][2]int{{0, 0}, {q.Size - 7, 0}, {0, q.Size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if q.Dark(corner[0]+dx, corner[1]+dy) != (ring != 2) {
+					t.Fatalf("finder pattern broken at %v+(%d,%d)", corner, dx, dy)
+				}
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestEncode(t *testing.T) {
+	cases := []struct {
+		content string
+		level   Level
+	}{
+		{"https://s.example.com/aB3x9", Medium},
+		{"短链二维码 https://s.example.com/tenant-a/promo?utm=qr", Quartile},
+		{strings.Repeat("gophrame-", 120), High},
+	}
+
+	for _, c := range cases {
+		q, err := Encode(c.content, c.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Size != q.Version*4+17 || q.Level != c.level {
+			t.Fatalf("unexpected code: version=%d size=%d level=%d", q.Version, q.Size, q.Level)
+		}
+		checkFinderPatterns(t, q)
+		if q.Dark(-1, 0) || q.Dark(0, q.Size) {
+			t.Fatal("out of range module is dark")
+		}
+	}
+
+	// 纠错等级越高，同样内容需要的版本不会更小
+	low, _ := Encode("https://s.example.com/aB3x9", Low)
+	high, _ := Encode("https://s.example.com/aB3x9", High)
+	if high.Version < low.Version {
+		t.Fatalf("high level version %d < low level version %d", high.Version, low.Version)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode(strings.Repeat("x", 3000), Low); err != ErrTooLong {
+		t.Fatalf("expected ErrTooLong, got %v", err)
+	}
+	if _, err := Encode("x", Level(9)); err == nil {
+		t.Fatal("expected invalid level error")
+	}
+}
+
+func TestRender(t *testing.T) {
+	q, err := Encode("https://s.example.com/aB3x9", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := q.PNG(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width := (q.Size + 8) * 2; img.Bounds().Dx() != width || img.Bounds().Dy() != width {
+		t.Fatalf("unexpected png size %v", img.Bounds())
+	}
+	// 左上角为空白边，随后是定位图形
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Fatal("border is not blank")
+	}
+	if r, _, _, _ := img.At(8, 8).RGBA(); r != 0 {
+		t.Fatal("finder pattern is not dark")
+	}
+
+	svg := q.SVG(4)
+	if viewBox := fmt.Sprintf(`viewBox="0 0 %d %d"`, q.Size+8, q.Size+8); !strings.Contains(svg, viewBox) || !strings.Contains(svg, "M4,4h1v1h-1z") {
+		t.Fatalf("unexpected svg: %s", svg)
+	}
+}
@@ -104,6 +104,11 @@ func (r *RedisClient) Execute(cmd string, args ...any) (any, error) {
 	return r.client.Do(cmd, args...)
 }
 
+// 底层连接，用于订阅等需要独占连接的操作
+func (r *RedisClient) Conn() redis.Conn {
+	return r.client
+}
+
 // 释放连接到连接池
 func (r *RedisClient) ReleaseOneRedisClient() {
 	_ = r.client.Close()
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS oauth_client_details (
+    client_id VARCHAR(64) NOT NULL,
+    client_secret VARCHAR(255),
+    resource_ids VARCHAR(255),
+    scope VARCHAR(255),
+    created_by VARCHAR(255),
+    created_time DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    modified_by VARCHAR(255),
+    modified_time DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    del_flag TINYINT(1) DEFAULT false,
+    PRIMARY KEY (client_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
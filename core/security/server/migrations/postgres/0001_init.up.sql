@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS oauth_client_details (
+    client_id VARCHAR(64) NOT NULL,
+    client_secret VARCHAR(255),
+    resource_ids VARCHAR(255),
+    scope VARCHAR(255),
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP,
+    modified_by VARCHAR(255),
+    modified_time TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP,
+    del_flag BOOLEAN DEFAULT false,
+    PRIMARY KEY (client_id)
+);
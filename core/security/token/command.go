@@ -0,0 +1,39 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gophab/gophrame/core/command"
+	DatabaseStarter "github.com/gophab/gophrame/core/database/starter"
+	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/security/token/config"
+)
+
+func init() {
+	command.RegisterCommand(&command.Command{
+		Name:        "token purge",
+		Description: "Delete expired access tokens, refresh tokens and codes",
+		Bootstrap:   command.BOOTSTRAP_CONFIG,
+		Requires:    []func(){DatabaseStarter.InitDB},
+		Run:         purgeCommand,
+	})
+}
+
+func purgeCommand(args []string) error {
+	if config.Setting.Store.Mode != "database" {
+		// memory/file/redis 存储自行过期
+		fmt.Printf("Token store mode %q needs no purge\n", config.Setting.Store.Mode)
+		return nil
+	}
+	if global.DB == nil {
+		return errors.New("database not enabled")
+	}
+
+	if err := (&DatabaseTokenStore{}).clearExpiredTokens(); err != nil {
+		return err
+	}
+
+	fmt.Println("Expired tokens purged")
+	return nil
+}
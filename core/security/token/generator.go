@@ -97,7 +97,7 @@ func NewUUIDAccessGenerate() oauth2.AccessGenerate {
 }
 
 type UUIDTokenGenerator struct {
-	TokenStore ITokenStore `inject:"tokenStore"`
+	TokenStore ITokenStore `inject:"tokenStore,optional"` // memory/file 存储不支持 GetToken
 }
 
 func (g *UUIDTokenGenerator) Token(ctx context.Context, data *oauth2.GenerateBasic, isGenRefresh bool) (access string, refresh string, err error) {
@@ -105,7 +105,7 @@ func (g *UUIDTokenGenerator) Token(ctx context.Context, data *oauth2.GenerateBas
 	refresh = uuid.NewString()
 	if !isGenRefresh {
 		token := data.TokenInfo
-		if token == nil {
+		if token == nil && g.TokenStore != nil {
 			var authentication Authentication = Authentication{
 				UserId:        data.UserID,
 				Authenticated: true,
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS oauth_access_token (
+    access_token VARCHAR(64) NOT NULL,
+    token TEXT,
+    authentication_id VARCHAR(64) NOT NULL UNIQUE,
+    authentication TEXT,
+    client_id VARCHAR(64),
+    user_name VARCHAR(255),
+    refresh_token VARCHAR(64),
+    expiration DATETIME(3),
+    PRIMARY KEY (access_token),
+    KEY idx_oauth_access_token_refresh_token (refresh_token),
+    KEY idx_oauth_access_token_expiration (expiration)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS oauth_refresh_token (
+    refresh_token VARCHAR(64) NOT NULL,
+    token TEXT,
+    authentication TEXT,
+    expiration DATETIME(3),
+    PRIMARY KEY (refresh_token),
+    KEY idx_oauth_refresh_token_expiration (expiration)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS oauth_code (
+    code VARCHAR(64) NOT NULL,
+    authentication TEXT,
+    expiration DATETIME(3),
+    PRIMARY KEY (code),
+    KEY idx_oauth_code_expiration (expiration)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS oauth_access_token (
+    access_token VARCHAR(64) NOT NULL,
+    token TEXT,
+    authentication_id VARCHAR(64) NOT NULL UNIQUE,
+    authentication TEXT,
+    client_id VARCHAR(64),
+    user_name VARCHAR(255),
+    refresh_token VARCHAR(64),
+    expiration TIMESTAMP(3),
+    PRIMARY KEY (access_token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_access_token_refresh_token ON oauth_access_token (refresh_token);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_access_token_expiration ON oauth_access_token (expiration);
+
+CREATE TABLE IF NOT EXISTS oauth_refresh_token (
+    refresh_token VARCHAR(64) NOT NULL,
+    token TEXT,
+    authentication TEXT,
+    expiration TIMESTAMP(3),
+    PRIMARY KEY (refresh_token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_token_expiration ON oauth_refresh_token (expiration);
+
+CREATE TABLE IF NOT EXISTS oauth_code (
+    code VARCHAR(64) NOT NULL,
+    authentication TEXT,
+    expiration TIMESTAMP(3),
+    PRIMARY KEY (code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_code_expiration ON oauth_code (expiration);
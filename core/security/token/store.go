@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gophab/gophrame/core/cron"
 	"github.com/gophab/gophrame/core/database"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/json"
@@ -76,14 +77,16 @@ type DatabaseTokenStore struct {
 func NewDatabaseTokenStore() (oauth2.TokenStore, error) {
 	logger.Debug("Using database token store")
 	result := &DatabaseTokenStore{}
-	go func() {
-		for {
-			result.clearExpiredTokens()
-
-			// 延时30秒
-			time.Sleep(time.Second * 30)
-		}
-	}()
+	if err := cron.Register(&cron.Job{
+		Name:        "token.clear-expired",
+		Description: "Clear expired oauth tokens",
+		Spec:        "@every 30s",
+		Func: func(ctx context.Context) error {
+			return result.clearExpiredTokens()
+		},
+	}); err != nil {
+		logger.Warn("Register token clear job error: ", err.Error())
+	}
 	return result, nil
 }
 
@@ -96,11 +99,13 @@ func reformatSQL(sql string) string {
 	return sql
 }
 
-func (s *DatabaseTokenStore) clearExpiredTokens() {
+func (s *DatabaseTokenStore) clearExpiredTokens() error {
 	now := time.Now()
-	database.DB().Exec(`DELETE FROM oauth_access_token WHERE expiration < ?`, now)
-	database.DB().Exec(`DELETE FROM oauth_refresh_token WHERE expiration < ?`, now)
-	database.DB().Exec(`DELETE FROM oauth_code WHERE expiration < ?`, now)
+	return errors.Join(
+		database.DB().Exec(`DELETE FROM oauth_access_token WHERE expiration < ?`, now).Error,
+		database.DB().Exec(`DELETE FROM oauth_refresh_token WHERE expiration < ?`, now).Error,
+		database.DB().Exec(`DELETE FROM oauth_code WHERE expiration < ?`, now).Error,
+	)
 }
 
 func (s *DatabaseTokenStore) insertAccessToken(info oauth2.TokenInfo) (int64, error) {
@@ -255,7 +260,7 @@ func (s *DatabaseTokenStore) Create(ctx context.Context, info oauth2.TokenInfo)
 		} else if rows <= 0 {
 			if exist, _ := s.GetByRefresh(ctx, authentication.GetId()); exist != nil {
 				if err := database.DB().Exec(
-					`UPDATE oauth_refresh_token SET token=?, authentication=?, expirtaion=? WHERE refresh_token=?`,
+					`UPDATE oauth_refresh_token SET token=?, authentication=?, expiration=? WHERE refresh_token=?`,
 					json.String(info),
 					json.String(authentication),
 					info.GetRefreshCreateAt().Add(info.GetRefreshExpiresIn()),
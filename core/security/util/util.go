@@ -102,7 +102,7 @@ func GetCurrentUser(c *gin.Context) *SecurityModel.UserDetails {
 
 	currentUserId := GetCurrentUserId(c)
 	if currentUserId != "" {
-		if strings.HasPrefix(currentUserId, "sns:") {
+		if strings.HasPrefix(currentUserId, "sns:") && service.GetSocialUserService() != nil {
 			// 社交账户
 			if socialUser, _ := service.GetSocialUserService().GetById(strings.SplitN(currentUserId, ":", 2)[1]); socialUser != nil {
 				if socialUser.UserId == nil {
@@ -275,6 +275,40 @@ func (c *Client) GetUserDetail(userId string, accessToken string) (*GetUserDetai
 
 }
 
+type SendCorpMessageResponse struct {
+	*OpenAPIResponse
+	TaskId int64 `json:"task_id,omitempty" xml:"taskId,omitempty"`
+}
+
+// 工作通知：以 Markdown 消息发送给企业内用户
+func (c *Client) SendCorpMessage(agentId int, userIds []string, title string, content string, accessToken string) (*SendCorpMessageResponse, error) {
+	query := url.Values{}
+	query.Set("access_token", accessToken)
+
+	if body, err := json.Marshal(map[string]any{
+		"agent_id":    agentId,
+		"userid_list": strings.Join(userIds, ","),
+		"msg": map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": title,
+				"text":  content,
+			},
+		},
+	}); err == nil {
+		req, err := http.NewRequest("POST", fmt.Sprintf("%s%s?%s", c.Base, "/topapi/message/corpconversation/asyncsend_v2", query.Encode()), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		response := &SendCorpMessageResponse{}
+		err = c.doRequest(req, response)
+		return response, err
+	} else {
+		return nil, err
+	}
+}
+
 type DingtalkService struct {
 	apps      sync.Map
 	tokens    *cache.Cache
@@ -513,3 +547,27 @@ func (s *DingtalkService) GetSocialUserByCode(ctx context.Context, socialChannel
 
 	return nil
 }
+
+// 发送工作通知，使用默认应用，OpenId 为钉钉 userid
+func (s *DingtalkService) SendWorkMessage(ctx context.Context, user *social.SocialUser, title string, content string) error {
+	userId := util.NotNullString(user.OpenId)
+	if userId == "" {
+		userId = util.NotNullString(user.SocialId)
+	}
+	if userId == "" {
+		return fmt.Errorf("dingtalk user id is empty")
+	}
+
+	appSetting := s.getAppSettingByAppId(config.Setting.AppId)
+	if appSetting == nil {
+		return fmt.Errorf("dingtalk app not configured")
+	}
+
+	token, b := s.GetAccessToken(appSetting.AppId)
+	if !b {
+		return fmt.Errorf("dingtalk access token unavailable: %s", appSetting.AppId)
+	}
+
+	_, err := s.GetAppByAppId(appSetting.AppId).Clone().SendCorpMessage(appSetting.AgentId, []string{userId}, title, content, token)
+	return err
+}
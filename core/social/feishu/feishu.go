@@ -22,6 +22,7 @@ import (
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkauthen "github.com/larksuite/oapi-sdk-go/v3/service/authen/v1"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 	larkspeech_to_text "github.com/larksuite/oapi-sdk-go/v3/service/speech_to_text/v1"
 	"github.com/patrickmn/go-cache"
 )
@@ -208,3 +209,40 @@ func (s *FeishuService) GetSocialUserByCode(ctx context.Context, socialChannelId
 
 	return nil
 }
+
+// 以默认应用发送文本消息，优先按 union_id 投递
+func (s *FeishuService) SendWorkMessage(ctx context.Context, user *social.SocialUser, title string, content string) error {
+	receiveIdType, receiveId := "union_id", util.NotNullString(user.SocialId)
+	if receiveId == "" {
+		receiveIdType, receiveId = "open_id", util.NotNullString(user.OpenId)
+	}
+	if receiveId == "" {
+		return errors.New("feishu user id is empty")
+	}
+
+	app := s.GetApp("")
+	if app == nil {
+		return errors.New("No such appId")
+	}
+
+	text := content
+	if title != "" {
+		text = title + "\n" + content
+	}
+	request := larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(receiveIdType).
+		Body(larkim.NewCreateMessageReqBodyBuilder().
+			ReceiveId(receiveId).
+			MsgType("text").
+			Content(json.String(map[string]string{"text": text})).
+			Build()).
+		Build()
+	resp, err := app.Im.Message.Create(ctx, request)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return resp.CodeError
+	}
+	return nil
+}
@@ -5,3 +5,8 @@ import "context"
 type SocialService interface {
 	GetSocialUserByCode(ctx context.Context, socialChannelId string, code string) *SocialUser
 }
+
+// 以企业应用身份向绑定的社交账号发送工作消息
+type WorkMessenger interface {
+	SendWorkMessage(ctx context.Context, user *SocialUser, title string, content string) error
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/ArtisanCloud/PowerLibs/v3/object"
 	"github.com/ArtisanCloud/PowerSocialite/v3/src/providers"
 	"github.com/ArtisanCloud/PowerWeChat/v3/src/work"
+	"github.com/ArtisanCloud/PowerWeChat/v3/src/work/message/request"
 )
 
 type Work struct {
@@ -232,3 +233,37 @@ func (s *WxcpService) GetSocialUserByCode(ctx context.Context, socialChannelId s
 
 	return nil
 }
+
+// 发送应用文本消息，SocialId 形如 userid@corpId
+func (s *WxcpService) SendWorkMessage(ctx context.Context, user *social.SocialUser, title string, content string) error {
+	if user.SocialId == nil || *user.SocialId == "" {
+		return fmt.Errorf("wecom user id is empty")
+	}
+
+	userId, corpId, _ := strings.Cut(*user.SocialId, "@")
+	app := s.GetApp(corpId, 0)
+	if app == nil {
+		return fmt.Errorf("wecom app not found: %s", corpId)
+	}
+
+	text := content
+	if title != "" {
+		text = title + "\n" + content
+	}
+
+	resp, err := app.Message.SendText(ctx, &request.RequestMessageSendText{
+		RequestMessageSend: request.RequestMessageSend{
+			ToUser:  userId,
+			MsgType: "text",
+			AgentID: config.Setting.AgentId,
+		},
+		Text: &request.RequestText{Content: text},
+	})
+	if err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("errcode: %d, errmsg: %s", resp.ErrCode, resp.ErrMSG)
+	}
+	return nil
+}
@@ -1,29 +1,49 @@
 package starter
 
 import (
+	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/gophab/gophrame/core/logger"
 )
 
 type Func struct {
-	f func()
+	f func() error
 	p int
 }
 
 var initializors = make([]*Func, 0)
 var starters = make([]*Func, 0)
 var terminaters = make([]*Func, 0)
+var onceTerminate sync.Once
+
+func wrap(f func()) func() error {
+	return func() error {
+		f()
+		return nil
+	}
+}
+
+func register(funcs []*Func, f func() error, p int) []*Func {
+	funcs = append(funcs, &Func{f: f, p: p})
+	sort.SliceStable(funcs, func(i, j int) bool {
+		return funcs[i].p < funcs[j].p
+	})
+	return funcs
+}
 
 func RegisterStarter(f func()) {
 	RegisterStarterEx(f, int(0))
 }
 
 func RegisterStarterEx(f func(), p int) {
-	starters = append(starters, &Func{f: f, p: p})
-	sort.Slice(starters, func(i, j int) bool {
-		return starters[i].p < starters[j].p
-	})
+	starters = register(starters, wrap(f), p)
+}
+
+// 返回错误的启动器，出错时中止启动
+func RegisterStarterFunc(f func() error, p int) {
+	starters = register(starters, f, p)
 }
 
 func RegisterInitializor(f func()) {
@@ -31,10 +51,12 @@ func RegisterInitializor(f func()) {
 }
 
 func RegisterInitializorEx(f func(), p int) {
-	initializors = append(initializors, &Func{f: f, p: p})
-	sort.Slice(initializors, func(i, j int) bool {
-		return initializors[i].p < initializors[j].p
-	})
+	initializors = register(initializors, wrap(f), p)
+}
+
+// 返回错误的初始化器，出错时中止启动
+func RegisterInitializorFunc(f func() error, p int) {
+	initializors = register(initializors, f, p)
 }
 
 func RegisterTerminater(f func()) {
@@ -42,29 +64,51 @@ func RegisterTerminater(f func()) {
 }
 
 func RegisterTerminaterEx(f func(), p int) {
-	terminaters = append(terminaters, &Func{f: f, p: p})
-	sort.Slice(terminaters, func(i, j int) bool {
-		return terminaters[i].p < terminaters[j].p
-	})
+	terminaters = register(terminaters, wrap(f), p)
+}
+
+func RegisterTerminaterFunc(f func() error, p int) {
+	terminaters = register(terminaters, f, p)
 }
 
-func Init() {
+// 执行终止器，panic 转为错误
+func call(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f()
+}
+
+func Init() error {
 	logger.Info("Starting initializors...")
 	for _, s := range initializors {
-		s.f()
+		if err := s.f(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func Start() {
+func Start() error {
 	logger.Info("Starting starters...")
 	for _, s := range starters {
-		s.f()
+		if err := s.f(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
+// 依次执行全部终止器，单个失败不影响其他；仅执行一次
 func Terminate() {
-	logger.Info("Starting terminaters...")
-	for _, s := range terminaters {
-		s.f()
-	}
+	onceTerminate.Do(func() {
+		logger.Info("Starting terminaters...")
+		for _, s := range terminaters {
+			if err := call(s.f); err != nil {
+				logger.Error("Terminating error: ", err.Error())
+			}
+		}
+	})
 }
@@ -25,3 +25,7 @@ type InternationalTelephone struct {
 func NewInternationalTelephoneValidator(field string) validation.Validator {
 	return InternationalTelephone{validation.Match{Regexp: internationalPhonePattern}, field}
 }
+
+func IsInternationalTelephone(value string) bool {
+	return internationalPhonePattern.MatchString(value)
+}
@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"regexp"
+
+	"github.com/gophab/gophrame/core/util"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 字母、数字、下划线和短横线，与 valid.AlphaDash 一致
+var alphaDashPattern = regexp.MustCompile(`^[\w-]*$`)
+
+// 自定义 binding 标签：
+//
+//	alphadash  账号等标识，同 valid.AlphaDash
+//	telephone  国际电话号码，同 util.NewInternationalTelephoneValidator
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("alphadash", func(fl validator.FieldLevel) bool {
+			return alphaDashPattern.MatchString(fl.Field().String())
+		})
+		_ = v.RegisterValidation("telephone", func(fl validator.FieldLevel) bool {
+			return util.IsInternationalTelephone(fl.Field().String())
+		})
+	}
+}
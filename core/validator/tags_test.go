@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestCustomTags(t *testing.T) {
+	type row struct {
+		Login  string `binding:"omitempty,alphadash"`
+		Mobile string `binding:"omitempty,telephone"`
+	}
+
+	for _, c := range []struct {
+		row   row
+		valid bool
+	}{
+		{row{}, true},
+		{row{Login: "user_01-a", Mobile: "+86-13800138000"}, true},
+		{row{Login: "13800138000", Mobile: "13800138000"}, true},
+		{row{Login: "user 01"}, false},
+		{row{Login: "user@example.com"}, false},
+		{row{Mobile: "138-0013-8000"}, false},
+		{row{Mobile: "abc12345"}, false},
+	} {
+		if err := binding.Validator.ValidateStruct(&c.row); (err == nil) != c.valid {
+			t.Errorf("%+v: valid=%v, err=%v", c.row, c.valid, err)
+		}
+	}
+}
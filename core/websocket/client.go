@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gophab/gophrame/core/global"
@@ -11,6 +12,7 @@ import (
 	"github.com/gophab/gophrame/core/websocket/config"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -23,14 +25,20 @@ type ClientMoreParams struct {
 }
 
 type Client struct {
+	Id                 string          // 连接标识
+	UserId             string          // 认证用户，匿名连接为空
+	TenantId           string          // 用户所属租户
 	Hub                *Hub            // 负责处理客户端注册、注销、在线管理
-	Conn               *websocket.Conn // 一个ws连接
-	Send               chan []byte     // 一个ws连接存储自己的消息管道
+	Conn               *websocket.Conn // 一个ws连接，SSE 订阅为空
+	Send               chan []byte     // 一个ws连接存储自己的消息管道，由 WritePump 写出
 	PingPeriod         time.Duration
 	ReadDeadline       time.Duration
 	WriteDeadline      time.Duration
 	HeartbeatFailTimes int
-	State              uint8 // ws状态，1=ok；0=出错、掉线等
+	state              atomic.Uint32   // ws状态，1=ok；0=出错、掉线等，读写协程并发访问
+	rooms              map[string]bool // 由 Hub 维护
+	done               chan struct{}
+	closeOnce          sync.Once
 	sync.RWMutex
 	ClientMoreParams // 这里追加一个结构体，方便开发者在成功上线后，可以自定义追加更多字段信息
 }
@@ -67,8 +75,11 @@ func (c *Client) OnOpen(context *gin.Context) (*Client, bool) {
 		if wsHub, ok := global.WebsocketHub.(*Hub); ok {
 			c.Hub = wsHub
 		}
+		c.Id = uuid.NewString()
 		c.Conn = wsConn
-		c.Send = make(chan []byte, config.Setting.BufferSize)
+		c.Send = make(chan []byte, max(config.Setting.SendQueueSize, 1))
+		c.rooms = make(map[string]bool)
+		c.done = make(chan struct{})
 		c.PingPeriod = time.Second * time.Duration(config.Setting.PingPeriod)
 		c.ReadDeadline = time.Second * time.Duration(config.Setting.ReadDeadline)
 		c.WriteDeadline = time.Second * time.Duration(config.Setting.WriteDeadline)
@@ -77,8 +88,14 @@ func (c *Client) OnOpen(context *gin.Context) (*Client, bool) {
 			logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
 		}
 		c.Conn.SetReadLimit(config.Setting.MaxMessageSize) // 设置最大读取长度
-		c.Hub.Register <- c
-		c.State = 1
+		c.SetState(1)
+		go c.WritePump()
+		select {
+		case c.Hub.Register <- c:
+		case <-c.Hub.closed:
+			c.Close()
+			return nil, false
+		}
 		return c, true
 	}
 
@@ -99,7 +116,7 @@ func (c *Client) ReadPump(callbackOnMessage func(messageType int, receivedData [
 
 	// OnMessage事件
 	for {
-		if c.State == 1 {
+		if c.State() == 1 {
 			mt, bReceivedData, err := c.Conn.ReadMessage()
 			if err == nil {
 				callbackOnMessage(mt, bReceivedData)
@@ -137,6 +154,74 @@ func (c *Client) SendMessage(messageType int, message string) error {
 	}
 }
 
+// 放入发送队列，不阻塞调用方；队列已满说明客户端消费过慢，断开连接
+func (c *Client) Enqueue(message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		logger.Warn(ErrorsWebsocketSlowConsumer, c.Id, c.UserId)
+		c.Close()
+		return false
+	}
+}
+
+// 依次写出发送队列中的消息，连接关闭时退出
+func (c *Client) WritePump() {
+	for {
+		select {
+		case message := <-c.Send:
+			if err := c.SendMessage(websocket.TextMessage, string(message)); err != nil {
+				logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
+				c.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// 关闭连接，ReadPump 随之退出并回调 onclose 注销
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		c.SetState(0)
+		if c.done != nil {
+			close(c.done)
+		}
+		if c.Conn != nil {
+			_ = c.Conn.Close()
+		}
+	})
+}
+
+// ws状态，1=ok；0=出错、掉线等
+func (c *Client) State() uint8 {
+	return uint8(c.state.Load())
+}
+
+func (c *Client) SetState(state uint8) {
+	c.state.Store(uint32(state))
+}
+
+// 当前加入的房间，房间关系由 Hub 的锁保护
+func (c *Client) Rooms() []string {
+	c.Hub.mutex.RLock()
+	defer c.Hub.mutex.RUnlock()
+
+	result := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		result = append(result, room)
+	}
+	return result
+}
+
 // 按照websocket标准协议实现隐式心跳,Server端向Client远端发送ping格式数据包,浏览器收到ping标准格式，自动将消息原路返回给服务器
 func (c *Client) Heartbeat() {
 	//  1. 设置一个时钟，周期性的向client远端发送心跳数据包
@@ -169,12 +254,12 @@ func (c *Client) Heartbeat() {
 	for {
 		select {
 		case <-ticker.C:
-			if c.State == 1 {
+			if c.State() == 1 {
 				if err := c.SendMessage(websocket.PingMessage, WebsocketServerPingMsg); err != nil {
 					c.HeartbeatFailTimes++
 					if c.HeartbeatFailTimes > config.Setting.HeartbeatFailMaxTimes {
-						c.State = 0
 						logger.Error(ErrorsWebsocketBeatHeartsMoreThanMaxTimes, err.Error())
+						c.Close()
 						return
 					}
 				} else {
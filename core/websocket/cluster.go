@@ -0,0 +1,255 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis"
+	RedisConfig "github.com/gophab/gophrame/core/redis/config"
+	"github.com/gophab/gophrame/core/websocket/config"
+
+	Redigo "github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+// 消息目标
+const (
+	TARGET_USER   = "user"
+	TARGET_TENANT = "tenant"
+	TARGET_ROOM   = "room"
+	TARGET_ALL    = "all"
+)
+
+type fanout struct {
+	Node   string `json:"node"`
+	Target string `json:"target"`
+	Key    string `json:"key,omitempty"`
+	Data   []byte `json:"data"`
+}
+
+// 集群：经 Redis pub/sub 向其他节点分发消息，在线状态保存在 Redis 有序集合中（score 为过期时间）
+// 未启用 Redis 时只在本节点投递
+type Cluster struct {
+	Node string
+	hub  *Hub
+	stop chan struct{}
+}
+
+func NewCluster(hub *Hub) *Cluster {
+	return &Cluster{
+		Node: uuid.NewString(),
+		hub:  hub,
+		stop: make(chan struct{}),
+	}
+}
+
+func (c *Cluster) Enabled() bool {
+	return RedisConfig.Setting.Enabled
+}
+
+func (c *Cluster) Start() {
+	if !c.Enabled() {
+		return
+	}
+	go c.subscribe()
+	go c.refresh()
+}
+
+func (c *Cluster) Stop() {
+	select {
+	case <-c.stop:
+	default:
+		close(c.stop)
+	}
+}
+
+func (c *Cluster) Publish(target string, key string, data []byte) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	message, err := json.Marshal(&fanout{Node: c.Node, Target: target, Key: key, Data: data})
+	if err != nil {
+		return err
+	}
+
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return fmt.Errorf("%s: redis unavailable", ErrorsWebsocketFanoutFail)
+	}
+	defer client.ReleaseOneRedisClient()
+
+	_, err = client.Execute("PUBLISH", config.Setting.Channel, message)
+	return err
+}
+
+// 订阅集群消息，连接断开后重连
+func (c *Cluster) subscribe() {
+	for {
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+
+		if err := c.receive(); err != nil {
+			logger.Warn(ErrorsWebsocketFanoutFail, err.Error())
+		}
+
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(max(RedisConfig.Setting.ReConnectInterval, time.Second)):
+		}
+	}
+}
+
+func (c *Cluster) receive() error {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return fmt.Errorf("redis unavailable")
+	}
+
+	conn := Redigo.PubSubConn{Conn: client.Conn()}
+	defer conn.Close()
+
+	if err := conn.Subscribe(config.Setting.Channel); err != nil {
+		return err
+	}
+
+	// 停止时退订，使 Receive 返回
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-c.stop:
+			_ = conn.Unsubscribe()
+		case <-done:
+		}
+	}()
+
+	for {
+		switch v := conn.Receive().(type) {
+		case Redigo.Message:
+			var message fanout
+			if err := json.Unmarshal(v.Data, &message); err != nil {
+				logger.Warn(ErrorsWebsocketFanoutFail, err.Error())
+				continue
+			}
+			if message.Node != c.Node {
+				c.hub.deliver(message.Target, message.Key, message.Data)
+			}
+		case Redigo.Subscription:
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+func presenceKey(userId string) string {
+	return "websocket:presence:" + userId
+}
+
+func (c *Cluster) member(client *Client) string {
+	return c.Node + ":" + client.Id
+}
+
+func (c *Cluster) ttl() time.Duration {
+	return time.Second * time.Duration(max(config.Setting.PresenceTTL, 10))
+}
+
+func (c *Cluster) Online(client *Client) {
+	if !c.Enabled() {
+		return
+	}
+	c.touch(map[string][]string{client.UserId: {c.member(client)}})
+}
+
+func (c *Cluster) Offline(client *Client) {
+	if !c.Enabled() {
+		return
+	}
+
+	redisClient := redis.GetOneRedisClient()
+	if redisClient == nil {
+		return
+	}
+	defer redisClient.ReleaseOneRedisClient()
+
+	_, _ = redisClient.Execute("ZREM", presenceKey(client.UserId), c.member(client))
+}
+
+// 续期本节点全部连接的在线状态
+func (c *Cluster) touch(members map[string][]string) {
+	redisClient := redis.GetOneRedisClient()
+	if redisClient == nil {
+		return
+	}
+	defer redisClient.ReleaseOneRedisClient()
+
+	ttl := c.ttl()
+	expireAt := time.Now().Add(ttl).UnixMilli()
+	for userId, items := range members {
+		args := []any{presenceKey(userId)}
+		for _, member := range items {
+			args = append(args, expireAt, member)
+		}
+		if _, err := redisClient.Execute("ZADD", args...); err != nil {
+			logger.Warn("Refresh websocket presence error: ", err.Error())
+			return
+		}
+		_, _ = redisClient.Execute("PEXPIRE", presenceKey(userId), ttl.Milliseconds())
+	}
+}
+
+func (c *Cluster) refresh() {
+	ticker := time.NewTicker(c.ttl() / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			members := make(map[string][]string)
+			for _, client := range c.hub.OnlineClients() {
+				if client.UserId != "" {
+					members[client.UserId] = append(members[client.UserId], c.member(client))
+				}
+			}
+			c.touch(members)
+		case <-c.stop:
+			// 移除本节点的在线状态
+			for _, client := range c.hub.OnlineClients() {
+				if client.UserId != "" {
+					c.Offline(client)
+				}
+			}
+			return
+		}
+	}
+}
+
+func (c *Cluster) IsOnline(userId string) bool {
+	return c.Connections(userId) > 0
+}
+
+// 清理过期成员（节点宕机未能移除）后计数
+func (c *Cluster) Connections(userId string) int {
+	if !c.Enabled() {
+		return 0
+	}
+
+	redisClient := redis.GetOneRedisClient()
+	if redisClient == nil {
+		return 0
+	}
+	defer redisClient.ReleaseOneRedisClient()
+
+	_, _ = redisClient.Execute("ZREMRANGEBYSCORE", presenceKey(userId), "-inf", time.Now().UnixMilli())
+	count, _ := redisClient.Int(redisClient.Execute("ZCARD", presenceKey(userId)))
+	return count
+}
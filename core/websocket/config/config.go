@@ -6,13 +6,17 @@ import (
 )
 
 type WebsocketSetting struct {
-	Enabled               bool  `json:"enabled"`
-	BufferSize            int   `json:"bufferSize" yaml:"bufferSize"`
-	MaxMessageSize        int64 `json:"maxMessageSize" yaml:"maxMessageSize"`
-	PingPeriod            int   `json:"pingPeriod" yaml:"pingPeriod"`
-	HeartbeatFailMaxTimes int   `json:"heartbeatFailMaxTimes" yaml:"heartbeatFialMaxTimes"`
-	ReadDeadline          int   `json:"readDeadline" yaml:"readDeadline"`
-	WriteDeadline         int   `json:"writeDeadline" yaml:"writeDeadline"`
+	Enabled               bool   `json:"enabled"`
+	BufferSize            int    `json:"bufferSize" yaml:"bufferSize"`
+	MaxMessageSize        int64  `json:"maxMessageSize" yaml:"maxMessageSize"`
+	PingPeriod            int    `json:"pingPeriod" yaml:"pingPeriod"`
+	HeartbeatFailMaxTimes int    `json:"heartbeatFailMaxTimes" yaml:"heartbeatFialMaxTimes"`
+	ReadDeadline          int    `json:"readDeadline" yaml:"readDeadline"`
+	WriteDeadline         int    `json:"writeDeadline" yaml:"writeDeadline"`
+	Path                  string `json:"path" yaml:"path"`                   // 连接端点，使用与 HTTP 相同的令牌认证
+	SendQueueSize         int    `json:"sendQueueSize" yaml:"sendQueueSize"` // 每个连接的发送队列长度，队列满时断开慢消费者
+	Channel               string `json:"channel" yaml:"channel"`             // 集群消息分发的 Redis 频道
+	PresenceTTL           int    `json:"presenceTTL" yaml:"presenceTTL"`     // 在线状态有效期（秒），节点定时续期
 }
 
 var Setting *WebsocketSetting = &WebsocketSetting{
@@ -23,6 +27,10 @@ var Setting *WebsocketSetting = &WebsocketSetting{
 	HeartbeatFailMaxTimes: 4,
 	ReadDeadline:          100,
 	WriteDeadline:         35,
+	Path:                  "/ws",
+	SendQueueSize:         256,
+	Channel:               "websocket:fanout",
+	PresenceTTL:           60,
 }
 
 func init() {
@@ -0,0 +1,57 @@
+package websocket
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 连接端点：GET <path>，SSE 订阅：GET <path>/events
+// 浏览器无法设置请求头时可通过 access_token 参数携带令牌
+type WebsocketController struct {
+	controller.ResourceController
+}
+
+var websocketController = &WebsocketController{}
+
+func init() {
+	inject.InjectValue("websocketController", websocketController)
+}
+
+func (c *WebsocketController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "", Handler: c.Connect},
+		{HttpMethod: "GET", ResourcePath: "/events", Handler: ServeEvents},
+	})
+}
+
+func (c *WebsocketController) Connect(ctx *gin.Context) {
+	if w, ok := (&Websocket{}).OnOpen(ctx); ok {
+		w.OnMessage(ctx)
+	}
+}
+
+// 查询参数中的令牌转为 Authorization 头，与 HTTP 请求使用相同的认证
+func QueryToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			if token := ctx.Query("access_token"); token != "" {
+				ctx.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		ctx.Next()
+	}
+}
+
+func newResources(path string) *controller.Controllers {
+	return &controller.Controllers{
+		Base: path,
+		Handlers: []gin.HandlerFunc{
+			QueryToken(),
+			security.HandleTokenVerify(), // oauth2 验证
+		},
+		Controllers: []controller.Controller{websocketController},
+	}
+}
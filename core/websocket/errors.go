@@ -9,4 +9,6 @@ const (
 	ErrorsWebsocketSetWriteDeadlineFail       string = "websocket  设置消息写入截止时间出错"
 	ErrorsWebsocketWriteMgsFail               string = "websocket  Write Msg(send msg) 失败"
 	ErrorsWebsocketStateInvalid               string = "websocket  state 状态已经不可用(掉线、卡死等愿意，造成双方无法进行数据交互)"
+	ErrorsWebsocketSlowConsumer               string = "websocket  发送队列已满，断开慢消费者"
+	ErrorsWebsocketFanoutFail                 string = "websocket  集群消息分发失败"
 )
@@ -1,32 +1,254 @@
 package websocket
 
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gophab/gophrame/core/eventbus"
+)
+
+// 连接上线、下线事件，参数为 *Client
+const (
+	EVENT_CLIENT_CONNECTED    = "websocket.connected"
+	EVENT_CLIENT_DISCONNECTED = "websocket.disconnected"
+)
+
 type Hub struct {
 	//上线注册
 	Register chan *Client
 	//下线注销
 	UnRegister chan *Client
-	//所有在线客户端的内存地址
+	//所有在线客户端的内存地址，读取时需持有锁，建议使用 OnlineClients
 	Clients map[*Client]bool
+
+	mutex   sync.RWMutex
+	users   map[string]map[*Client]bool
+	tenants map[string]map[*Client]bool
+	rooms   map[string]map[*Client]bool
+	cluster *Cluster
+	closed  chan struct{}
 }
 
 func CreateHubFactory() *Hub {
-	return &Hub{
+	hub := &Hub{
 		Register:   make(chan *Client),
 		UnRegister: make(chan *Client),
 		Clients:    make(map[*Client]bool),
+		users:      make(map[string]map[*Client]bool),
+		tenants:    make(map[string]map[*Client]bool),
+		rooms:      make(map[string]map[*Client]bool),
+		closed:     make(chan struct{}),
 	}
+	hub.cluster = NewCluster(hub)
+	return hub
 }
 
 func (h *Hub) Run() {
+	h.cluster.Start()
+
 	for {
 		select {
 		case client := <-h.Register:
-			h.Clients[client] = true
+			h.add(client)
 		case client := <-h.UnRegister:
-			if _, ok := h.Clients[client]; ok {
-				_ = client.Conn.Close()
-				delete(h.Clients, client)
-			}
+			h.remove(client)
+		case <-h.closed:
+			return
+		}
+	}
+}
+
+// 停止分发并断开全部连接
+func (h *Hub) Close() {
+	select {
+	case <-h.closed:
+		return
+	default:
+		close(h.closed)
+	}
+
+	if h.cluster != nil {
+		h.cluster.Stop()
+	}
+	for _, client := range h.OnlineClients() {
+		client.Close()
+	}
+}
+
+func (h *Hub) add(client *Client) {
+	h.mutex.Lock()
+	h.Clients[client] = true
+	index(h.users, client.UserId, client)
+	index(h.tenants, client.TenantId, client)
+	h.mutex.Unlock()
+
+	if h.cluster != nil && client.UserId != "" {
+		h.cluster.Online(client)
+	}
+	eventbus.DispatchEvent(EVENT_CLIENT_CONNECTED, client)
+}
+
+func (h *Hub) remove(client *Client) {
+	h.mutex.Lock()
+	if _, ok := h.Clients[client]; !ok {
+		h.mutex.Unlock()
+		return
+	}
+	delete(h.Clients, client)
+	unindex(h.users, client.UserId, client)
+	unindex(h.tenants, client.TenantId, client)
+	for room := range client.rooms {
+		unindex(h.rooms, room, client)
+	}
+	h.mutex.Unlock()
+
+	client.Close()
+	if h.cluster != nil && client.UserId != "" {
+		h.cluster.Offline(client)
+	}
+	eventbus.DispatchEvent(EVENT_CLIENT_DISCONNECTED, client)
+}
+
+func index(m map[string]map[*Client]bool, key string, client *Client) {
+	if key == "" {
+		return
+	}
+	if m[key] == nil {
+		m[key] = make(map[*Client]bool)
+	}
+	m[key][client] = true
+}
+
+func unindex(m map[string]map[*Client]bool, key string, client *Client) {
+	if clients, ok := m[key]; ok {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(m, key)
 		}
 	}
 }
+
+// 加入房间，房间成员关系只保存在连接所在节点
+func (h *Hub) Join(client *Client, room string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.Clients[client]; !ok {
+		return
+	}
+	client.rooms[room] = true
+	index(h.rooms, room, client)
+}
+
+func (h *Hub) Leave(client *Client, room string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(client.rooms, room)
+	unindex(h.rooms, room, client)
+}
+
+// 本节点的在线连接
+func (h *Hub) OnlineClients() []*Client {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	result := make([]*Client, 0, len(h.Clients))
+	for client := range h.Clients {
+		result = append(result, client)
+	}
+	return result
+}
+
+// 用户在任一节点有连接即为在线
+func (h *Hub) IsOnline(userId string) bool {
+	h.mutex.RLock()
+	local := len(h.users[userId]) > 0
+	h.mutex.RUnlock()
+
+	if local || h.cluster == nil {
+		return local
+	}
+	return h.cluster.IsOnline(userId)
+}
+
+// 集群内用户的连接数
+func (h *Hub) Connections(userId string) int {
+	if h.cluster != nil && h.cluster.Enabled() {
+		return h.cluster.Connections(userId)
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.users[userId])
+}
+
+// 消息为 []byte、string 时原样发送，其他类型序列化为 JSON
+func (h *Hub) SendToUser(userId string, message any) error {
+	return h.send(TARGET_USER, userId, message)
+}
+
+func (h *Hub) SendToTenant(tenantId string, message any) error {
+	return h.send(TARGET_TENANT, tenantId, message)
+}
+
+func (h *Hub) SendToRoom(room string, message any) error {
+	return h.send(TARGET_ROOM, room, message)
+}
+
+func (h *Hub) Broadcast(message any) error {
+	return h.send(TARGET_ALL, "", message)
+}
+
+func (h *Hub) send(target string, key string, message any) error {
+	data, err := encode(message)
+	if err != nil {
+		return err
+	}
+
+	h.deliver(target, key, data)
+	if h.cluster != nil {
+		return h.cluster.Publish(target, key, data)
+	}
+	return nil
+}
+
+// 投递到本节点的连接
+func (h *Hub) deliver(target string, key string, data []byte) int {
+	h.mutex.RLock()
+	var clients map[*Client]bool
+	switch target {
+	case TARGET_USER:
+		clients = h.users[key]
+	case TARGET_TENANT:
+		clients = h.tenants[key]
+	case TARGET_ROOM:
+		clients = h.rooms[key]
+	case TARGET_ALL:
+		clients = h.Clients
+	}
+	receivers := make([]*Client, 0, len(clients))
+	for client := range clients {
+		receivers = append(receivers, client)
+	}
+	h.mutex.RUnlock()
+
+	count := 0
+	for _, client := range receivers {
+		if client.Enqueue(data) {
+			count++
+		}
+	}
+	return count
+}
+
+func encode(message any) ([]byte, error) {
+	switch v := message.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return json.Marshal(message)
+	}
+}
@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/websocket/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// 服务端推送（SSE）：作为 Hub 的连接注册，与 websocket 共用按用户、租户、房间的路由和集群分发
+// 每条消息作为一个 data 事件写出，空闲时按 PingPeriod 发送注释行保活
+func ServeEvents(ctx *gin.Context) {
+	hub := GetHub()
+	if hub == nil {
+		ctx.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	client := &Client{
+		Id:       uuid.NewString(),
+		UserId:   ctx.GetString("_CURRENT_USER_ID_"),
+		TenantId: ctx.GetString("_CURRENT_TENANT_ID_"),
+		Hub:      hub,
+		Send:     make(chan []byte, max(config.Setting.SendQueueSize, 1)),
+		rooms:    make(map[string]bool),
+		done:     make(chan struct{}),
+	}
+	client.SetState(1)
+
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	select {
+	case hub.Register <- client:
+	case <-hub.closed:
+		return
+	}
+	logger.Info("SSE client online: ", client.Id, client.UserId)
+
+	defer func() {
+		select {
+		case hub.UnRegister <- client:
+		case <-hub.closed:
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second * time.Duration(max(config.Setting.PingPeriod, 1)))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-client.Send:
+			if err := writeEvent(ctx.Writer, message); err != nil {
+				logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
+				return
+			}
+		case <-ticker.C:
+			if _, err := io.WriteString(ctx.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case <-client.done:
+			return
+		case <-ctx.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, message []byte) error {
+	var buffer bytes.Buffer
+	for _, line := range bytes.Split(message, []byte("\n")) {
+		buffer.WriteString("data: ")
+		buffer.Write(line)
+		buffer.WriteByte('\n')
+	}
+	buffer.WriteByte('\n')
+
+	if _, err := w.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"github.com/gophab/gophrame/core/controller"
 	"github.com/gophab/gophrame/core/starter"
 	"github.com/gophab/gophrame/core/websocket/config"
 
@@ -9,7 +10,15 @@ import (
 )
 
 func init() {
+	starter.RegisterInitializor(Init)
 	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
+}
+
+func Init() {
+	if config.Setting.Enabled && config.Setting.Path != "" {
+		controller.AddController(newResources(config.Setting.Path))
+	}
 }
 
 func Start() {
@@ -24,3 +33,9 @@ func Start() {
 		}
 	}
 }
+
+func Terminate() {
+	if hub := GetHub(); hub != nil {
+		hub.Close()
+	}
+}
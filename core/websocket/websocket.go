@@ -1,10 +1,10 @@
 package websocket
 
 import (
+	"github.com/gophab/gophrame/core/global"
 	"github.com/gophab/gophrame/core/logger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 )
 
 /**
@@ -17,17 +17,14 @@ type Websocket struct {
 }
 
 // onOpen 事件函数
+// 经过令牌认证的请求，连接以当前用户、租户标识，可按用户、租户定向发送
 func (w *Websocket) OnOpen(context *gin.Context) (*Websocket, bool) {
-	if client, ok := (&Client{}).OnOpen(context); ok {
-
-		token := context.GetString("token")
-		logger.Info("获取到的客户端上线时携带的唯一标记值：", "token", token)
-
-		// 成功上线以后，开发者可以基于客户端上线时携带的唯一参数(这里用token键表示)
-		// 在数据库查询更多的其他字段信息，直接追加在 Client 结构体上，方便后续使用
-		//client.ClientMoreParams.UserParams1 = "123"
-		//client.ClientMoreParams.UserParams2 = "456"
-		//fmt.Printf("最终每一个客户端(client) 已有的参数：%+v\n", client)
+	client := &Client{
+		UserId:   context.GetString("_CURRENT_USER_ID_"),
+		TenantId: context.GetString("_CURRENT_TENANT_ID_"),
+	}
+	if client, ok := client.OnOpen(context); ok {
+		logger.Info("Websocket client online: ", client.Id, client.UserId)
 
 		w.Client = client
 
@@ -39,6 +36,15 @@ func (w *Websocket) OnOpen(context *gin.Context) (*Websocket, bool) {
 	return nil, false
 }
 
+// 客户端消息处理器，未注册时忽略客户端消息
+type MessageHandler func(client *Client, messageType int, receivedData []byte)
+
+var messageHandler MessageHandler
+
+func RegisterMessageHandler(handler MessageHandler) {
+	messageHandler = handler
+}
+
 // OnMessage 处理业务消息
 func (w *Websocket) OnMessage(context *gin.Context) {
 	go w.Client.ReadPump(func(messageType int, receivedData []byte) {
@@ -46,12 +52,9 @@ func (w *Websocket) OnMessage(context *gin.Context) {
 		//messageType 消息类型，1=文本
 		//receivedData 服务器接收到客户端（例如js客户端）发来的的数据，[]byte 格式
 		// 实际项目中，消息的传递请统一按照json格式传递
-		tempMsg := "服务器已经收到了你的消息：" + string(receivedData)
-		// 回复客户端已经收到消息;
-		if err := w.Client.SendMessage(messageType, tempMsg); err != nil {
-			logger.Error("消息发送出现错误", err.Error())
+		if messageHandler != nil {
+			messageHandler(w.Client, messageType, receivedData)
 		}
-
 	}, w.OnError, w.OnClose)
 }
 
@@ -63,23 +66,65 @@ func (w *Websocket) OnError(err error) {
 
 // OnClose 客户端关闭回调，发生onError回调以后会继续回调该函数
 func (w *Websocket) OnClose() {
-	w.Client.State = 0
-	w.Client.Hub.UnRegister <- w.Client // 向hub管道投递一条注销消息，由hub中心负责关闭连接、删除在线数据
+	w.Client.SetState(0)
+	// 向hub管道投递一条注销消息，由hub中心负责关闭连接、删除在线数据；hub 已关闭时直接返回
+	select {
+	case w.Client.Hub.UnRegister <- w.Client:
+	case <-w.Client.Hub.closed:
+	}
 }
 
-// 获取在线的全部客户端
+// 获取本节点在线的全部客户端
 func (w *Websocket) GetOnlineClients() int {
-	//fmt.Printf("在线客户端数量：%d\n", len(w.WsClient.Hub.Clients))
-	return len(w.Client.Hub.Clients)
+	return len(w.Client.Hub.OnlineClients())
 }
 
-// 向全部在线客户端广播消息
-// 广播函数可能被不同的逻辑同时调用，由于操作的都是 Conn ，因此为了保证并发安全，加互斥锁
+// 向全部在线客户端广播消息，包括集群中的其他节点
 func (w *Websocket) BroadcastMsg(sendMsg string) {
-	for onlineClient := range w.Client.Hub.Clients {
-		//获取每一个在线的客户端，向远端发送消息
-		if err := onlineClient.SendMessage(websocket.TextMessage, sendMsg); err != nil {
-			logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
-		}
+	if err := w.Client.Hub.Broadcast(sendMsg); err != nil {
+		logger.Error(ErrorsWebsocketFanoutFail, err.Error())
+	}
+}
+
+// 全局 Hub，未启用 websocket 时为空
+func GetHub() *Hub {
+	if hub, ok := global.WebsocketHub.(*Hub); ok {
+		return hub
+	}
+	return nil
+}
+
+func SendToUser(userId string, message any) error {
+	if hub := GetHub(); hub != nil {
+		return hub.SendToUser(userId, message)
+	}
+	return nil
+}
+
+func SendToTenant(tenantId string, message any) error {
+	if hub := GetHub(); hub != nil {
+		return hub.SendToTenant(tenantId, message)
+	}
+	return nil
+}
+
+func SendToRoom(room string, message any) error {
+	if hub := GetHub(); hub != nil {
+		return hub.SendToRoom(room, message)
+	}
+	return nil
+}
+
+func Broadcast(message any) error {
+	if hub := GetHub(); hub != nil {
+		return hub.Broadcast(message)
+	}
+	return nil
+}
+
+func IsOnline(userId string) bool {
+	if hub := GetHub(); hub != nil {
+		return hub.IsOnline(userId)
 	}
+	return false
 }
@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnCloseAfterHubClosed(t *testing.T) {
+	hub := &Hub{
+		Register:   make(chan *Client),
+		UnRegister: make(chan *Client),
+		Clients:    make(map[*Client]bool),
+		closed:     make(chan struct{}),
+	}
+	hub.Close()
+
+	w := &Websocket{Client: &Client{Hub: hub, done: make(chan struct{})}}
+	w.Client.SetState(1)
+
+	done := make(chan struct{})
+	go func() {
+		w.OnClose()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OnClose blocked after hub closed")
+	}
+	if w.Client.State() != 0 {
+		t.Fatalf("unexpected state: %d", w.Client.State())
+	}
+}
+
+func TestClientStateConcurrent(t *testing.T) {
+	c := &Client{done: make(chan struct{})}
+	c.SetState(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = c.State()
+		}()
+		go func() {
+			defer wg.Done()
+			c.Close()
+		}()
+	}
+	wg.Wait()
+
+	if c.State() != 0 {
+		t.Fatalf("unexpected state: %d", c.State())
+	}
+}
@@ -1,6 +1,8 @@
 package slink
 
 import (
+	"embed"
+
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/module"
 
@@ -11,9 +13,13 @@ import (
 	_ "github.com/gophab/gophrame/module/authority/v1"
 )
 
+//go:embed migrations
+var migrations embed.FS
+
 var _module = &module.Module{
 	Name:        "Authority",
 	Description: "",
+	Migrations:  migrations,
 }
 
 func init() {
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS auth_role_authority (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    auth_type VARCHAR(64) NOT NULL,
+    auth_id VARCHAR(64) NOT NULL,
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    role_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (auth_type, auth_id, role_id),
+    KEY idx_auth_role_authority_role_id (role_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS auth_user_authority (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    auth_type VARCHAR(64) NOT NULL,
+    auth_id VARCHAR(64) NOT NULL,
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    user_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (auth_type, auth_id, user_id),
+    KEY idx_auth_user_authority_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS auth_organization_authority (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    auth_type VARCHAR(64) NOT NULL,
+    auth_id VARCHAR(64) NOT NULL,
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    organization_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (auth_type, auth_id, organization_id),
+    KEY idx_auth_organization_authority_organization_id (organization_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
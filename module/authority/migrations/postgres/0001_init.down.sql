@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS auth_organization_authority;
+DROP TABLE IF EXISTS auth_user_authority;
+DROP TABLE IF EXISTS auth_role_authority;
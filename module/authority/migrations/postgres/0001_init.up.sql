@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS auth_role_authority (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    auth_type VARCHAR(64) NOT NULL,
+    auth_id VARCHAR(64) NOT NULL,
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    role_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (auth_type, auth_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_role_authority_role_id ON auth_role_authority (role_id);
+
+CREATE TABLE IF NOT EXISTS auth_user_authority (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    auth_type VARCHAR(64) NOT NULL,
+    auth_id VARCHAR(64) NOT NULL,
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    user_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (auth_type, auth_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_user_authority_user_id ON auth_user_authority (user_id);
+
+CREATE TABLE IF NOT EXISTS auth_organization_authority (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    auth_type VARCHAR(64) NOT NULL,
+    auth_id VARCHAR(64) NOT NULL,
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    organization_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (auth_type, auth_id, organization_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_organization_authority_organization_id ON auth_organization_authority (organization_id);
@@ -11,7 +11,7 @@ import (
 
 // CasbinService负责更新Casbin Enforce数据至
 type CasbinService struct {
-	Enforcer    *casbin.SyncedEnforcer `inject:"enforcer"`
+	Enforcer    *casbin.SyncedEnforcer `inject:"enforcer,optional"`
 	UserService *service.UserService   `inject:"userService"`
 	RoleService *service.RoleService   `inject:"roleService"`
 }
@@ -9,7 +9,8 @@ import (
 )
 
 var _module = &module.Module{
-	Name:        "Authority",
+	Name:        "AuthorityV1",
+	DependsOn:   []string{"Authority"},
 	Description: "",
 }
 
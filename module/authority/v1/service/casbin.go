@@ -12,7 +12,7 @@ import (
 
 // CasbinService负责更新Casbin Enforce数据至
 type CasbinService struct {
-	Enforcer    *casbin.SyncedEnforcer `inject:"enforcer_v1"`
+	Enforcer    *casbin.SyncedEnforcer `inject:"enforcer_v1,optional"`
 	UserService *service.UserService   `inject:"userService_v1"`
 	RoleService *service.RoleService   `inject:"roleService_v1"`
 }
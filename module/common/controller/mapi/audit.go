@@ -0,0 +1,121 @@
+package mapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gophab/gophrame/core/audit"
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/query"
+	"github.com/gophab/gophrame/core/webservice/request"
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+)
+
+type AuditLogMController struct {
+	controller.ResourceController
+}
+
+var auditLogMController = &AuditLogMController{}
+
+func init() {
+	inject.InjectValue("auditLogMController", auditLogMController)
+}
+
+func (m *AuditLogMController) AfterInitialize() {
+	m.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/audit-logs", Handler: m.GetAuditLogs},
+		{HttpMethod: "GET", ResourcePath: "/audit-logs/verify", Handler: m.VerifyAuditLogs},
+		{HttpMethod: "GET", ResourcePath: "/audit-logs/export", Handler: m.ExportAuditLogs},
+	})
+}
+
+// 查询条件：target, targetId, operatorId, tenantId, action, from/to（yyyy-MM-dd）
+func auditCriteria(ctx *gin.Context) (*audit.Criteria, bool) {
+	var result = &audit.Criteria{
+		Target:     request.Param(ctx, "target").DefaultString(""),
+		TargetId:   request.Param(ctx, "targetId").DefaultString(""),
+		OperatorId: request.Param(ctx, "operatorId").DefaultString(""),
+		TenantId:   request.Param(ctx, "tenantId").DefaultString(""),
+		Action:     request.Param(ctx, "action").DefaultString(""),
+	}
+
+	if value := request.Param(ctx, "from").DefaultString(""); value != "" {
+		if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+			result.From = t
+		} else {
+			response.FailCode(ctx, errors.INVALID_PARAMS)
+			return nil, false
+		}
+	}
+	if value := request.Param(ctx, "to").DefaultString(""); value != "" {
+		if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+			result.To = t.AddDate(0, 0, 1)
+		} else {
+			response.FailCode(ctx, errors.INVALID_PARAMS)
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+// GET /audit-logs
+func (c *AuditLogMController) GetAuditLogs(ctx *gin.Context) {
+	conds, b := auditCriteria(ctx)
+	if !b {
+		return
+	}
+
+	pageable := query.GetPageable(ctx)
+	if count, list, err := audit.Find(conds, pageable); err == nil {
+		ctx.Header("X-Total-Count", strconv.FormatInt(count, 10))
+		response.Success(ctx, list)
+	} else {
+		response.SystemFail(ctx, err)
+	}
+}
+
+// 校验哈希链：?from=1&to=0，to 为 0 时校验到链尾
+func (c *AuditLogMController) VerifyAuditLogs(ctx *gin.Context) {
+	from := request.Param(ctx, "from").DefaultInt64(1)
+	to := request.Param(ctx, "to").DefaultInt64(0)
+
+	result, err := audit.Verify(from, to)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+	response.Success(ctx, result)
+}
+
+// 导出：?format=csv|json，条件同查询
+func (c *AuditLogMController) ExportAuditLogs(ctx *gin.Context) {
+	conds, b := auditCriteria(ctx)
+	if !b {
+		return
+	}
+
+	format := request.Param(ctx, "format").DefaultString("csv")
+	switch format {
+	case "csv":
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	case "json":
+		ctx.Header("Content-Type", "application/x-ndjson")
+	default:
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	filename := fmt.Sprintf("audit_%v.%s", time.Now().Format("20060102_150405"), format)
+	ctx.Header("Content-Disposition", "attachment; filename="+url.QueryEscape(filename))
+
+	// 已开始输出，出错时只能中断
+	if err := audit.Export(ctx.Writer, format, conds); err != nil {
+		logger.Error("Export audit logs error: ", err.Error())
+	}
+}
@@ -0,0 +1,130 @@
+package mapi
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/query"
+	"github.com/gophab/gophrame/core/webservice/request"
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+
+	"github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/file/config"
+	"github.com/gophab/gophrame/module/common/service"
+)
+
+type FileMController struct {
+	controller.ResourceController
+	FileService *service.FileService `inject:"fileService"`
+}
+
+var fileMController = &FileMController{}
+
+func init() {
+	inject.InjectValue("fileMController", fileMController)
+}
+
+func (m *FileMController) AfterInitialize() {
+	m.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/files", Handler: m.GetFiles},
+		{HttpMethod: "GET", ResourcePath: "/file/:id", Handler: m.GetFile},
+		{HttpMethod: "DELETE", ResourcePath: "/file/:id", Handler: m.DeleteFile},
+		{HttpMethod: "POST", ResourcePath: "/files/gc", Handler: m.CollectGarbage},
+	})
+}
+
+// GET /files?name=&mimeType=&tenantId=&createdBy=&policy=
+func (c *FileMController) GetFiles(ctx *gin.Context) {
+	pageable := query.GetPageable(ctx)
+
+	var conds = make(map[string]any)
+	for _, name := range []string{"name", "mimeType", "tenantId", "createdBy", "policy"} {
+		if value := request.Param(ctx, name).DefaultString(""); value != "" {
+			conds[name] = value
+		}
+	}
+
+	if count, lists, err := c.FileService.Find(conds, pageable); err == nil {
+		ctx.Header("X-Total-Count", strconv.FormatInt(count, 10))
+		response.Success(ctx, lists)
+	} else {
+		ctx.Header("X-Total-Count", "0")
+		response.Success(ctx, []any{})
+	}
+}
+
+// GET /file/:id，附带引用方列表
+func (c *FileMController) GetFile(ctx *gin.Context) {
+	id, err := request.Param(ctx, "id").MustString()
+	if err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	result, err := c.FileService.GetById(id)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	if result == nil {
+		response.NotFound(ctx, "Not Found")
+		return
+	}
+
+	references, err := c.FileService.GetReferences(id)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+	result.References = int64(len(references))
+
+	response.Success(ctx, struct {
+		*domain.File
+		ReferenceList []*domain.FileReference `json:"referenceList"`
+	}{result, references})
+}
+
+// DELETE /file/:id，同时解除全部引用
+func (c *FileMController) DeleteFile(ctx *gin.Context) {
+	id, err := request.Param(ctx, "id").MustString()
+	if err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	result, err := c.FileService.GetById(id)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	if result == nil {
+		response.NotFound(ctx, "Not Found")
+		return
+	}
+
+	if err := c.FileService.Delete(ctx, result); err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// POST /files/gc，立即回收未被引用的文件，返回回收数量；与定时任务一样需开启 gcEnabled
+func (c *FileMController) CollectGarbage(ctx *gin.Context) {
+	if !config.Setting.Enabled || !config.Setting.GcEnabled {
+		response.FailMessage(ctx, errors.INVALID_PARAMS, "未开启文件回收")
+		return
+	}
+
+	if count, err := c.FileService.CollectGarbage(ctx); err == nil {
+		response.Success(ctx, count)
+	} else {
+		response.SystemFail(ctx, err)
+	}
+}
@@ -0,0 +1,28 @@
+package mapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/module/common/file/config"
+)
+
+func TestCollectGarbageDisabled(t *testing.T) {
+	defer func(enabled, gc bool) {
+		config.Setting.Enabled, config.Setting.GcEnabled = enabled, gc
+	}(config.Setting.Enabled, config.Setting.GcEnabled)
+	config.Setting.Enabled, config.Setting.GcEnabled = true, false
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/files/gc", (&FileMController{}).CollectGarbage)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/files/gc", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("gc disabled = %d", recorder.Code)
+	}
+}
@@ -12,5 +12,8 @@ var Resources = &controller.Controllers{
 		systemOptionMController,
 		tenantOptionMController,
 		taskMController,
+		auditLogMController,
+		operationLogMController,
+		fileMController,
 	},
 }
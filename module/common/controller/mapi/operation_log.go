@@ -0,0 +1,56 @@
+package mapi
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/excel"
+	"github.com/gophab/gophrame/core/i18n"
+	"github.com/gophab/gophrame/core/inject"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
+	"github.com/gophab/gophrame/core/webservice/request"
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+
+	"github.com/gophab/gophrame/module/common/service"
+)
+
+type OperationLogMController struct {
+	controller.ResourceController
+	OperationLogService *service.OperationLogService `inject:"operationLogService"`
+}
+
+var operationLogMController = &OperationLogMController{}
+
+func init() {
+	inject.InjectValue("operationLogMController", operationLogMController)
+}
+
+func (m *OperationLogMController) AfterInitialize() {
+	m.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "POST", ResourcePath: "/operation-logs/export", Handler: m.ExportOperationLogs},
+	})
+}
+
+// 后台导出：?format=xlsx|csv|pdf&operatorId=&target=&targetId=&operation=&tenantId=，返回任务 Id，
+// 完成后任务结果为下载链接
+func (c *OperationLogMController) ExportOperationLogs(ctx *gin.Context) {
+	format := request.Param(ctx, "format").DefaultString(excel.FORMAT_XLSX)
+	if format != excel.FORMAT_XLSX && format != excel.FORMAT_CSV && format != excel.FORMAT_PDF {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	var conds = make(map[string]any)
+	for _, name := range []string{"operatorId", "target", "targetId", "operation", "tenantId"} {
+		if value := request.Param(ctx, name).DefaultString(""); value != "" {
+			conds[name] = value
+		}
+	}
+
+	options := excel.ExportOptions{Format: format, Title: "操作日志", Locale: i18n.GetEnableLanguage()}
+	if taskId, err := c.OperationLogService.SubmitExport(SecurityUtil.GetCurrentUserId(ctx), conds, options); err == nil {
+		response.Success(ctx, taskId)
+	} else {
+		response.SystemFail(ctx, err)
+	}
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/query"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
+	"github.com/gophab/gophrame/core/webservice/request"
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+
+	"github.com/gophab/gophrame/module/common/file"
+	"github.com/gophab/gophrame/module/common/file/config"
+	"github.com/gophab/gophrame/module/common/service"
+)
+
+type FileOpenController struct {
+	controller.ResourceController
+	FileService *service.FileService `inject:"fileService"`
+}
+
+var fileOpenController = &FileOpenController{}
+
+// 文件内容按 Id 公开访问，Id 为随机 UUID
+type PublicFileOpenController struct {
+	controller.ResourceController
+	FileService *service.FileService `inject:"fileService"`
+}
+
+var publicFileOpenController = &PublicFileOpenController{}
+
+func init() {
+	inject.InjectValue("fileOpenController", fileOpenController)
+	inject.InjectValue("publicFileOpenController", publicFileOpenController)
+}
+
+func (m *FileOpenController) AfterInitialize() {
+	m.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "POST", ResourcePath: "/file", Handler: m.UploadFile},
+		{HttpMethod: "GET", ResourcePath: "/files", Handler: m.GetFiles},
+		{HttpMethod: "GET", ResourcePath: "/file/:id", Handler: m.GetFile},
+	})
+}
+
+// POST /file?policy=file|image，表单字段 file
+func (c *FileOpenController) UploadFile(ctx *gin.Context) {
+	if !config.Setting.Enabled {
+		response.NotFound(ctx, "Not Found")
+		return
+	}
+
+	policy := request.Param(ctx, "policy").DefaultString(config.POLICY_FILE)
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	result, err := c.FileService.Upload(header, policy, SecurityUtil.GetCurrentUserId(ctx), SecurityUtil.GetCurrentTenantId(ctx))
+	switch err {
+	case nil:
+		response.Success(ctx, result)
+	case file.ErrUnknownPolicy, file.ErrTooLarge, file.ErrTypeNotAllowed:
+		response.FailMessage(ctx, errors.INVALID_PARAMS, err.Error())
+	default:
+		response.SystemFail(ctx, err)
+	}
+}
+
+// GET /files?name=&mimeType=
+func (c *FileOpenController) GetFiles(ctx *gin.Context) {
+	pageable := query.GetPageable(ctx)
+
+	var conds = map[string]any{
+		"createdBy": SecurityUtil.GetCurrentUserId(ctx),
+	}
+	for _, name := range []string{"name", "mimeType"} {
+		if value := request.Param(ctx, name).DefaultString(""); value != "" {
+			conds[name] = value
+		}
+	}
+
+	if count, lists, err := c.FileService.Find(conds, pageable); err == nil {
+		ctx.Header("X-Total-Count", strconv.FormatInt(count, 10))
+		response.Success(ctx, lists)
+	} else {
+		ctx.Header("X-Total-Count", "0")
+		response.Success(ctx, []any{})
+	}
+}
+
+// GET /file/:id
+func (c *FileOpenController) GetFile(ctx *gin.Context) {
+	id, err := request.Param(ctx, "id").MustString()
+	if err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	result, err := c.FileService.GetById(id)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	if result == nil {
+		response.NotFound(ctx, "Not Found")
+		return
+	}
+
+	if result.CreatedBy != SecurityUtil.GetCurrentUserId(ctx) {
+		response.NotAllowed(ctx, "Not Allowed")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+func (m *PublicFileOpenController) AfterInitialize() {
+	m.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/file/:id/content", Handler: m.GetFileContent},
+	})
+}
+
+// GET /file/:id/content?w=&h=，w、h 为图片缩放后的最大宽高
+func (c *PublicFileOpenController) GetFileContent(ctx *gin.Context) {
+	id, err := request.Param(ctx, "id").MustString()
+	if err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	result, err := c.FileService.GetById(id)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	if result == nil {
+		response.NotFound(ctx, "Not Found")
+		return
+	}
+
+	width := request.Param(ctx, "w").DefaultInt(0)
+	height := request.Param(ctx, "h").DefaultInt(0)
+
+	// 内容按 Id 不变，客户端已缓存时不读取存储、不缩放
+	etag := "\"" + result.Sha256 + "-" + strconv.Itoa(width) + "x" + strconv.Itoa(height) + "\""
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Header("ETag", etag)
+	if ctx.GetHeader("If-None-Match") == etag {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	reader, info, err := c.FileService.Open(ctx, result, width, height)
+	switch err {
+	case nil:
+	case file.ErrNotImage, file.ErrImageTooLarge:
+		response.FailMessage(ctx, errors.INVALID_PARAMS, err.Error())
+		return
+	case oss.ErrNotFound:
+		response.NotFound(ctx, "Not Found")
+		return
+	default:
+		response.SystemFail(ctx, err)
+		return
+	}
+	defer reader.Close()
+
+	// 可缩放图片以外的类型（如 SVG、HTML）只作为附件下载，防止在站点域名下执行脚本
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	if !file.IsImage(result.MimeType) {
+		ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": result.Name}))
+	}
+	ctx.Header("Content-Type", info.ContentType)
+
+	if f, b := reader.(io.ReadSeeker); b {
+		http.ServeContent(ctx.Writer, ctx.Request, "", info.LastModified, f)
+	} else {
+		ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, reader, nil)
+	}
+}
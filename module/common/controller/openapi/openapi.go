@@ -15,6 +15,7 @@ var Resources *controller.Controllers = &controller.Controllers{
 var PublicResources *controller.Controllers = &controller.Controllers{
 	Controllers: []controller.Controller{
 		publicSystemOptionOpenController,
+		publicFileOpenController,
 	},
 }
 
@@ -25,6 +26,8 @@ var UserResources *controller.Controllers = &controller.Controllers{
 		tenantOptionOpenController,
 		userOptionOpenController,
 		taskOpenController,
+		unreadOpenController,
+		fileOpenController,
 	},
 }
 
@@ -37,10 +37,46 @@ func (m *TaskOpenController) AfterInitialize() {
 	m.SetResourceHandlers([]controller.ResourceHandler{
 		{HttpMethod: "GET", ResourcePath: "/tasks", Handler: m.GetTasks},
 		{HttpMethod: "GET", ResourcePath: "/task/:id", Handler: m.GetTask},
+		{HttpMethod: "POST", ResourcePath: "/task/:id/cancel", Handler: m.CancelTask},
 		{HttpMethod: "DELETE", ResourcePath: "/task/:id", Handler: m.DeleteTask},
 	})
 }
 
+// POST /task/:id/cancel
+func (c *TaskOpenController) CancelTask(ctx *gin.Context) {
+	id, err := request.Param(ctx, "id").MustString()
+	if err != nil {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
+		return
+	}
+
+	result, err := c.TaskService.GetById(id)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	if result == nil {
+		response.NotFound(ctx, "Not Found")
+		return
+	}
+
+	currentUserId := SecurityUtil.GetCurrentUserId(ctx)
+
+	if result.CreatedBy != currentUserId {
+		response.NotAllowed(ctx, "Not Allowed")
+		return
+	}
+
+	result, err = c.TaskService.CancelTask(result)
+	if err != nil {
+		response.SystemFail(ctx, err)
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
 // GET /task/:id
 func (c *TaskOpenController) GetTask(ctx *gin.Context) {
 	id, err := request.Param(ctx, "id").MustString()
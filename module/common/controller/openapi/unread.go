@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gophab/gophrame/module/common/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UnreadOpenController struct {
+	controller.ResourceController
+	UnreadService *service.UnreadService `inject:"unreadService"`
+}
+
+var unreadOpenController *UnreadOpenController = &UnreadOpenController{}
+
+func init() {
+	inject.InjectValue("unreadOpenController", unreadOpenController)
+}
+
+// 未读计数，实时变化经 websocket 或 SSE 推送
+func (m *UnreadOpenController) AfterInitialize() {
+	m.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/unread", Handler: m.GetUnread},
+	})
+}
+
+func (a *UnreadOpenController) GetUnread(context *gin.Context) {
+	userId := SecurityUtil.GetCurrentUserId(context)
+	tenantId := SecurityUtil.GetCurrentTenantId(context)
+
+	if result, err := a.UnreadService.GetUnread(userId, tenantId); err == nil {
+		response.Success(context, result)
+	} else {
+		response.SystemFail(context, err)
+	}
+}
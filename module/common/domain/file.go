@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/domain"
+)
+
+// 上传的文件，相同内容（Sha256）共用一个存储对象
+type File struct {
+	domain.Entity
+	Name         string    `gorm:"column:name" json:"name"`                                         // 原始文件名
+	Key          string    `gorm:"column:storage_key" json:"-"`                                     // 对象存储键
+	Url          string    `gorm:"column:url" json:"url"`                                           // 访问地址
+	MimeType     string    `gorm:"column:mime_type" json:"mimeType"`                                // 按内容识别的类型
+	Size         int64     `gorm:"column:size" json:"size"`                                         // 字节数
+	Sha256       string    `gorm:"column:sha256" json:"sha256"`                                     // 内容摘要
+	Width        int       `gorm:"column:width;default:0" json:"width,omitempty"`                   // 图片宽度
+	Height       int       `gorm:"column:height;default:0" json:"height,omitempty"`                 // 图片高度
+	ThumbnailUrl *string   `gorm:"column:thumbnail_url;default:null" json:"thumbnailUrl,omitempty"` // 缩略图地址
+	Policy       string    `gorm:"column:policy" json:"policy"`                                     // 上传策略
+	CreatedBy    string    `gorm:"column:created_by;<-:create" json:"createdBy"`
+	CreatedTime  time.Time `gorm:"column:created_time;autoCreateTime;<-:create" json:"createdTime"`
+	TenantId     string    `gorm:"column:tenant_id" json:"tenantId"`
+	References   int64     `gorm:"-" json:"references"` // 引用数
+}
+
+func (*File) TableName() string {
+	return "sys_file"
+}
+
+// 实体对文件的引用，没有引用的文件会被回收
+type FileReference struct {
+	domain.Entity
+	FileId      string    `gorm:"column:file_id;uniqueIndex:uk_sys_file_reference,priority:1" json:"fileId"`
+	EntityType  string    `gorm:"column:entity;uniqueIndex:uk_sys_file_reference,priority:2" json:"entity"`      // 实体类型
+	EntityId    string    `gorm:"column:entity_id;uniqueIndex:uk_sys_file_reference,priority:3" json:"entityId"` // 实体 Id
+	CreatedTime time.Time `gorm:"column:created_time;autoCreateTime;<-:create" json:"createdTime"`
+}
+
+func (*FileReference) TableName() string {
+	return "sys_file_reference"
+}
@@ -17,6 +17,7 @@ type MessageInfo struct {
 	DueTime   *time.Time `gorm:"column:due_time" json:"dueTime"`
 	Status    int        `gorm:"column:status;default:1" json:"status"`
 	Read      bool       `gorm:"column:read;->" json:"read"`
+	Sender    any        `gorm:"-" json:"sender,omitempty"` /* 发送人 */
 }
 
 type Message struct {
@@ -35,6 +36,7 @@ type SimpleMessage struct {
 	DueTime   *time.Time `gorm:"column:due_time" json:"dueTime"`
 	Status    int        `gorm:"column:status;default:1" json:"status"`
 	Read      bool       `gorm:"column:read;->" json:"read"`
+	Sender    any        `gorm:"-" json:"sender,omitempty"` /* 发送人 */
 }
 
 func (*Message) TableName() string {
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/domain"
+)
+
+// 投递状态
+const (
+	DELIVERY_PENDING = "PENDING" // 待发送或等待重试
+	DELIVERY_SENDING = "SENDING" // 已被认领发送，next_retry_time 为认领到期时间
+	DELIVERY_SENT    = "SENT"
+	DELIVERY_FAILED  = "FAILED"  // 重试次数用尽
+	DELIVERY_SKIPPED = "SKIPPED" // 缺少地址、渠道不可用或用户不在线
+)
+
+// 通知在单个渠道上对单个接收人的投递记录，保存渲染结果以便重试
+type NotificationDelivery struct {
+	domain.Model
+	Scene         string             `gorm:"column:scene" json:"scene"`
+	Channel       string             `gorm:"column:channel" json:"channel"`
+	From          string             `gorm:"column:from" json:"from,omitempty"`
+	UserId        string             `gorm:"column:user_id" json:"userId"`
+	Locale        string             `gorm:"column:locale" json:"locale,omitempty"`
+	Title         string             `gorm:"column:title" json:"title"`
+	Content       string             `gorm:"column:content" json:"content"`
+	Template      string             `gorm:"column:template" json:"template,omitempty"`
+	Params        *domain.Properties `gorm:"column:params;type:json" json:"params,omitempty"`
+	Status        string             `gorm:"column:status;default:PENDING" json:"status"`
+	Attempts      int                `gorm:"column:attempts;default:0" json:"attempts"`
+	LastError     string             `gorm:"column:last_error" json:"lastError,omitempty"`
+	NextRetryTime *time.Time         `gorm:"column:next_retry_time" json:"nextRetryTime,omitempty"`
+	SentTime      *time.Time         `gorm:"column:sent_time" json:"sentTime,omitempty"`
+	TenantId      string             `gorm:"column:tenant_id" json:"tenantId"`
+	CreatedTime   time.Time          `gorm:"column:created_time;autoCreateTime;<-:create" json:"createdTime"`
+	UpdatedTime   *time.Time         `gorm:"column:updated_time;autoUpdateTime" json:"updatedTime,omitempty"`
+}
+
+func (*NotificationDelivery) TableName() string {
+	return "sys_notification_delivery"
+}
@@ -18,7 +18,7 @@ type OperationLog struct {
 	Location     string             `gorm:"column:location" json:"location"`
 	LocationId   string             `gorm:"column:location_id" json:"locationId"`
 	Content      string             `gorm:"column:content" json:"content"`
-	OperatedTime time.Time          `gorm:"column:operatedTime;autoCreateTime;->" json:"operatedTime"`
+	OperatedTime time.Time          `gorm:"column:operated_time;autoCreateTime" json:"operatedTime"`
 	Properties   *domain.Properties `gorm:"column:properties;type:json" json:"properties,omitempty"`
 	TenantId     string             `gorm:"column:tenant_id" json:"tenantId"`
 	Text         string             `gorm:"-" json:"text"` /* 组合文本 */
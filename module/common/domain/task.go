@@ -6,6 +6,15 @@ import (
 	"github.com/gophab/gophrame/domain"
 )
 
+// 任务状态
+const (
+	TASK_PENDING   = 0 // 初始化或等待重试
+	TASK_RUNNING   = 1
+	TASK_FINISHED  = 2
+	TASK_CANCELLED = 3 // 中断
+	TASK_FAILED    = 4 // 重试次数用尽
+)
+
 type Task struct {
 	domain.Entity
 	domain.PropertiesEnabled
@@ -22,6 +31,18 @@ type Task struct {
 	UpdatedTime  *time.Time `gorm:"column:updated_time;autoUpdateTime;<-:update" json:"updatedTime,omitempty"`
 	FinishedTime *time.Time `gorm:"column:finished_time" json:"finishedTime,omitempty"`
 	DelFlag      bool       `gorm:"column:del_flag;default:0" json:"delFlag"`
+
+	// 异步执行：按 Handler 查找处理器，由 Worker 在租约期内执行
+	Handler         string     `gorm:"column:handler;default:null" json:"handler,omitempty"`
+	Payload         *string    `gorm:"column:payload;default:null" json:"-"`
+	Attempts        int        `gorm:"column:attempts;default:0" json:"attempts"`
+	MaxAttempts     int        `gorm:"column:max_attempts;default:0" json:"maxAttempts"`
+	Worker          string     `gorm:"column:worker;default:null" json:"-"`
+	LeaseTime       *time.Time `gorm:"column:lease_time" json:"-"`
+	HeartbeatTime   *time.Time `gorm:"column:heartbeat_time" json:"heartbeatTime,omitempty"`
+	NextRunTime     *time.Time `gorm:"column:next_run_time" json:"nextRunTime,omitempty"`
+	CancelRequested bool       `gorm:"column:cancel_requested;default:0" json:"cancelRequested"`
+	Checkpoint      *string    `gorm:"column:checkpoint;default:null" json:"-"` // 处理器保存的执行进度，重试时读取
 }
 
 func (*Task) TableName() string {
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 上传策略名称
+const (
+	POLICY_FILE  = "file"
+	POLICY_IMAGE = "image"
+)
+
+type FilePolicy struct {
+	MaxSize int64    `json:"maxSize" yaml:"maxSize"` // 最大字节数，0 不限
+	Types   []string `json:"types" yaml:"types"`     // 允许的 MIME 类型，支持 image/* 形式，为空不限
+}
+
+type FileSetting struct {
+	Enabled      bool                   `json:"enabled" yaml:"enabled"`
+	Prefix       string                 `json:"prefix" yaml:"prefix"`             // 对象键前缀
+	Policies     map[string]*FilePolicy `json:"policies" yaml:"policies"`         // 各上传入口的策略
+	Thumbnail    int                    `json:"thumbnail" yaml:"thumbnail"`       // 上传图片时生成的缩略图边长，0 不生成
+	MaxImageSize int                    `json:"maxImageSize" yaml:"maxImageSize"` // 按需缩放允许的最大边长
+	MaxPixels    int64                  `json:"maxPixels" yaml:"maxPixels"`       // 处理图片的最大像素数，防止解码超大图片
+	MaxResizes   int                    `json:"maxResizes" yaml:"maxResizes"`     // 同时进行的按需缩放数
+	GcEnabled    bool                   `json:"gcEnabled" yaml:"gcEnabled"`       // 用户头像和租户 Logo 自动登记引用，其他只返回地址的旧接口上传的文件没有引用，确认都已登记后再开启
+	GcGrace      time.Duration          `json:"gcGrace" yaml:"gcGrace"`           // 上传后超过该时长仍未被引用的文件被回收
+}
+
+var Setting *FileSetting = &FileSetting{
+	Enabled: true,
+	Prefix:  "files",
+	Policies: map[string]*FilePolicy{
+		POLICY_FILE: {
+			MaxSize: 50 << 20,
+		},
+		POLICY_IMAGE: {
+			MaxSize: 10 << 20,
+			Types:   []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		},
+	},
+	Thumbnail:    200,
+	MaxImageSize: 2048,
+	MaxPixels:    50_000_000,
+	MaxResizes:   4,
+	GcEnabled:    false,
+	GcGrace:      7 * 24 * time.Hour,
+}
+
+func init() {
+	logger.Debug("Register File Config")
+	config.RegisterConfig("file", Setting, "File Settings")
+}
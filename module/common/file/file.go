@@ -0,0 +1,15 @@
+package file
+
+import (
+	"errors"
+
+	"github.com/gophab/gophrame/service"
+)
+
+var (
+	ErrUnknownPolicy  = service.ErrUnknownFilePolicy
+	ErrTooLarge       = service.ErrFileTooLarge
+	ErrTypeNotAllowed = service.ErrFileTypeNotAllowed
+	ErrNotImage       = errors.New("not an image")
+	ErrImageTooLarge  = errors.New("image too large")
+)
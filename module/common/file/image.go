@@ -0,0 +1,73 @@
+package file
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/gophab/gophrame/module/common/file/config"
+)
+
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// 可缩放的图片类型
+func IsImage(mimeType string) bool {
+	return imageTypes[mimeType]
+}
+
+// 图片尺寸，像素数超过 MaxPixels 时返回 ErrImageTooLarge
+func ImageSize(data []byte) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, ErrNotImage
+	}
+	if config.Setting.MaxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > config.Setting.MaxPixels {
+		return cfg.Width, cfg.Height, ErrImageTooLarge
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// 等比缩放到 width x height 以内，不放大；width 或 height 为 0 时只按另一边约束。
+// JPEG 输出为 JPEG，其他格式输出 PNG（保留透明），GIF 只取第一帧
+func Resize(data []byte, width, height int) ([]byte, string, error) {
+	if _, _, err := ImageSize(data); err != nil {
+		return nil, "", err
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrNotImage
+	}
+
+	w, h := fit(src.Bounds().Dx(), src.Bounds().Dy(), width, height)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buffer bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buffer, dst, &jpeg.Options{Quality: 85})
+		return buffer.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&buffer, dst)
+	return buffer.Bytes(), "image/png", err
+}
+
+func fit(w, h, width, height int) (int, int) {
+	scale := 1.0
+	if width > 0 && w > width {
+		scale = float64(width) / float64(w)
+	}
+	if height > 0 && float64(h)*scale > float64(height) {
+		scale = float64(height) / float64(h)
+	}
+	return max(int(float64(w)*scale+0.5), 1), max(int(float64(h)*scale+0.5), 1)
+}
@@ -0,0 +1,66 @@
+package file
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gophab/gophrame/module/common/file/config"
+)
+
+// 扩展名只在与内容一致时采用：zip 容器的 Office 文档、纯文本格式
+var (
+	zipTypes = map[string]string{
+		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		".apk":  "application/vnd.android.package-archive",
+		".jar":  "application/java-archive",
+	}
+	textTypes = map[string]string{
+		".csv":  "text/csv",
+		".json": "application/json",
+		".md":   "text/markdown",
+		".xml":  "application/xml",
+		".svg":  "image/svg+xml",
+	}
+)
+
+// 按内容识别 MIME 类型，head 为文件开头（至少 512 字节，文件更短时为全部内容）
+func SniffMimeType(head []byte, name string) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	ext := strings.ToLower(filepath.Ext(name))
+
+	switch {
+	case sniffed == "application/zip":
+		if t, b := zipTypes[ext]; b {
+			return t
+		}
+	case strings.HasPrefix(sniffed, "text/"):
+		if t, b := textTypes[ext]; b {
+			return t
+		}
+	}
+	return sniffed
+}
+
+// 按策略校验大小和类型
+func CheckPolicy(name string, size int64, mimeType string) error {
+	policy, b := config.Setting.Policies[name]
+	if !b || policy == nil {
+		return ErrUnknownPolicy
+	}
+	if policy.MaxSize > 0 && size > policy.MaxSize {
+		return ErrTooLarge
+	}
+	if len(policy.Types) == 0 {
+		return nil
+	}
+	for _, t := range policy.Types {
+		if t == mimeType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(t, "*"))) {
+			return nil
+		}
+	}
+	return ErrTypeNotAllowed
+}
@@ -1,20 +1,27 @@
 package module
 
 import (
+	"embed"
+
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/module"
 
 	_ "github.com/gophab/gophrame/module/common/controller"
 	_ "github.com/gophab/gophrame/module/common/service"
+	_ "github.com/gophab/gophrame/module/common/task"
 )
 
 const (
 	MODULE_ID = 102304
 )
 
+//go:embed migrations
+var migrations embed.FS
+
 var _module = &module.Module{
 	Name:        "Common",
 	Description: "",
+	Migrations:  migrations,
 }
 
 func init() {
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS sys_user_option;
+DROP TABLE IF EXISTS sys_task;
+DROP TABLE IF EXISTS sys_option;
+DROP TABLE IF EXISTS sys_operation_log;
+DROP TABLE IF EXISTS sys_message_access_log;
+DROP TABLE IF EXISTS sys_message_history;
+DROP TABLE IF EXISTS sys_message;
+DROP TABLE IF EXISTS sys_locale_field;
+DROP TABLE IF EXISTS sys_event_access_log;
+DROP TABLE IF EXISTS sys_event_history;
+DROP TABLE IF EXISTS sys_event;
+DROP TABLE IF EXISTS sys_country_area;
+DROP TABLE IF EXISTS sys_content_template;
@@ -0,0 +1,200 @@
+CREATE TABLE IF NOT EXISTS sys_content_template (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    parameters JSON,
+    properties JSON,
+    name VARCHAR(255),
+    title VARCHAR(255),
+    type VARCHAR(255),
+    scene VARCHAR(255),
+    content TEXT,
+    status INT,
+    PRIMARY KEY (id),
+    KEY idx_sys_content_template_tenant_id_name (tenant_id, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_country_area (
+    code VARCHAR(255) NOT NULL,
+    area_code BIGINT,
+    name VARCHAR(255),
+    PRIMARY KEY (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_event (
+    id BIGINT NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    source VARCHAR(255),
+    source_id VARCHAR(64),
+    type VARCHAR(255),
+    target VARCHAR(255),
+    scope VARCHAR(255),
+    content TEXT,
+    status INT DEFAULT 1,
+    properties JSON,
+    PRIMARY KEY (id),
+    KEY idx_sys_event_tenant_id (tenant_id),
+    KEY idx_sys_event_status_created_time (status, created_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_event_history (
+    id BIGINT NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    source VARCHAR(255),
+    source_id VARCHAR(64),
+    type VARCHAR(255),
+    target VARCHAR(255),
+    scope VARCHAR(255),
+    content TEXT,
+    status INT DEFAULT 1,
+    properties JSON,
+    PRIMARY KEY (id),
+    KEY idx_sys_event_history_tenant_id (tenant_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_event_access_log (
+    user_id VARCHAR(64) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    access_time DATETIME(3),
+    PRIMARY KEY (user_id, action)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_locale_field (
+    entity_name VARCHAR(64) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    locale VARCHAR(64) NOT NULL DEFAULT 'en',
+    value TEXT,
+    PRIMARY KEY (entity_name, entity_id, name, locale)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_message (
+    id BIGINT NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    `from` VARCHAR(255),
+    `to` VARCHAR(255),
+    scope VARCHAR(255) DEFAULT 'TENANT',
+    type VARCHAR(255) DEFAULT 'NOTICE',
+    title VARCHAR(255),
+    content TEXT,
+    valid_time DATETIME(3),
+    due_time DATETIME(3),
+    status INT DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_sys_message_tenant_id (tenant_id),
+    KEY idx_sys_message_status_valid_time (status, valid_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_message_history (
+    id BIGINT NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    `from` VARCHAR(255),
+    `to` VARCHAR(255),
+    scope VARCHAR(255) DEFAULT 'TENANT',
+    type VARCHAR(255) DEFAULT 'NOTICE',
+    title VARCHAR(255),
+    content TEXT,
+    valid_time DATETIME(3),
+    due_time DATETIME(3),
+    status INT DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_sys_message_history_tenant_id (tenant_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_message_access_log (
+    message_id BIGINT NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    created_time DATETIME(3),
+    PRIMARY KEY (message_id, user_id, action)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_operation_log (
+    id BIGINT NOT NULL,
+    operator_id VARCHAR(64),
+    operation VARCHAR(255),
+    target VARCHAR(255),
+    target_id VARCHAR(64),
+    location VARCHAR(255),
+    location_id VARCHAR(64),
+    content TEXT,
+    properties JSON,
+    tenant_id VARCHAR(64),
+    PRIMARY KEY (id),
+    KEY idx_sys_operation_log_tenant_id (tenant_id),
+    KEY idx_sys_operation_log_target_id (target_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_option (
+    name VARCHAR(64) NOT NULL,
+    value TEXT,
+    value_type VARCHAR(255),
+    description TEXT,
+    public TINYINT(1),
+    created_time DATETIME(3),
+    created_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    tenant_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (name, tenant_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_task (
+    id VARCHAR(64) NOT NULL,
+    properties JSON,
+    type VARCHAR(255),
+    name VARCHAR(255),
+    description TEXT,
+    progress DOUBLE DEFAULT 0,
+    status INT DEFAULT 0,
+    mode VARCHAR(255) DEFAULT 'void',
+    result TEXT,
+    remark TEXT,
+    created_time DATETIME(3),
+    created_by VARCHAR(255),
+    updated_time DATETIME(3),
+    finished_time DATETIME(3),
+    del_flag TINYINT(1) DEFAULT false,
+    PRIMARY KEY (id),
+    KEY idx_sys_task_created_by (created_by)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_user_option (
+    name VARCHAR(64) NOT NULL,
+    value TEXT,
+    value_type VARCHAR(255),
+    description TEXT,
+    user_id VARCHAR(64) NOT NULL,
+    PRIMARY KEY (name, user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS sys_notification_delivery (
+    id BIGINT NOT NULL,
+    scene VARCHAR(255),
+    channel VARCHAR(64),
+    `from` VARCHAR(255),
+    user_id VARCHAR(64),
+    locale VARCHAR(64),
+    title VARCHAR(255),
+    content TEXT,
+    template VARCHAR(255),
+    params JSON,
+    status VARCHAR(32) DEFAULT 'PENDING',
+    attempts INT DEFAULT 0,
+    last_error TEXT,
+    next_retry_time DATETIME(3),
+    sent_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    created_time DATETIME(3),
+    updated_time DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_sys_notification_delivery_user_id (user_id),
+    KEY idx_sys_notification_delivery_status_next_retry_time (status, next_retry_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+ALTER TABLE sys_task
+    DROP KEY idx_sys_task_status_next_run_time,
+    DROP COLUMN handler,
+    DROP COLUMN payload,
+    DROP COLUMN attempts,
+    DROP COLUMN max_attempts,
+    DROP COLUMN worker,
+    DROP COLUMN lease_time,
+    DROP COLUMN heartbeat_time,
+    DROP COLUMN next_run_time,
+    DROP COLUMN cancel_requested;
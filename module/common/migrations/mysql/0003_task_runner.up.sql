@@ -0,0 +1,11 @@
+ALTER TABLE sys_task
+    ADD COLUMN handler VARCHAR(255),
+    ADD COLUMN payload TEXT,
+    ADD COLUMN attempts INT DEFAULT 0,
+    ADD COLUMN max_attempts INT DEFAULT 0,
+    ADD COLUMN worker VARCHAR(64),
+    ADD COLUMN lease_time DATETIME(3),
+    ADD COLUMN heartbeat_time DATETIME(3),
+    ADD COLUMN next_run_time DATETIME(3),
+    ADD COLUMN cancel_requested TINYINT(1) DEFAULT false,
+    ADD KEY idx_sys_task_status_next_run_time (status, next_run_time);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sys_file_reference;
+DROP TABLE IF EXISTS sys_file;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS sys_file (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    storage_key VARCHAR(512),
+    url VARCHAR(1024),
+    mime_type VARCHAR(255),
+    size BIGINT DEFAULT 0,
+    sha256 CHAR(64),
+    width INT DEFAULT 0,
+    height INT DEFAULT 0,
+    thumbnail_url VARCHAR(1024),
+    policy VARCHAR(64),
+    created_by VARCHAR(64),
+    created_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    PRIMARY KEY (id),
+    KEY idx_sys_file_sha256 (sha256),
+    KEY idx_sys_file_url (url(255)),
+    KEY idx_sys_file_tenant_id_created_by (tenant_id, created_by),
+    KEY idx_sys_file_created_time (created_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_file_reference (
+    id VARCHAR(64) NOT NULL,
+    file_id VARCHAR(64),
+    entity VARCHAR(255),
+    entity_id VARCHAR(64),
+    created_time DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_sys_file_reference (file_id, entity, entity_id),
+    KEY idx_sys_file_reference_entity (entity, entity_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE sys_operation_log
+    DROP KEY idx_sys_operation_log_operated_time,
+    DROP COLUMN operated_time;
@@ -0,0 +1,6 @@
+ALTER TABLE sys_operation_log
+    ADD COLUMN operated_time DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    ADD KEY idx_sys_operation_log_operated_time (operated_time, id);
+
+-- 已有记录按雪花 Id 中的时间戳回填
+UPDATE sys_operation_log SET operated_time = FROM_UNIXTIME(((id >> 8) + 1483228800000) / 1000);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS sys_notification_delivery (
+    id BIGINT NOT NULL,
+    scene VARCHAR(255),
+    channel VARCHAR(64),
+    "from" VARCHAR(255),
+    user_id VARCHAR(64),
+    locale VARCHAR(64),
+    title VARCHAR(255),
+    content TEXT,
+    template VARCHAR(255),
+    params JSONB,
+    status VARCHAR(32) DEFAULT 'PENDING',
+    attempts INT DEFAULT 0,
+    last_error TEXT,
+    next_retry_time TIMESTAMP(3),
+    sent_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    created_time TIMESTAMP(3),
+    updated_time TIMESTAMP(3),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_notification_delivery_user_id ON sys_notification_delivery (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_sys_notification_delivery_status_next_retry_time ON sys_notification_delivery (status, next_retry_time);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_sys_task_status_next_run_time;
+
+ALTER TABLE sys_task
+    DROP COLUMN IF EXISTS handler,
+    DROP COLUMN IF EXISTS payload,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS worker,
+    DROP COLUMN IF EXISTS lease_time,
+    DROP COLUMN IF EXISTS heartbeat_time,
+    DROP COLUMN IF EXISTS next_run_time,
+    DROP COLUMN IF EXISTS cancel_requested;
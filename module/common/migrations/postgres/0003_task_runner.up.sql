@@ -0,0 +1,12 @@
+ALTER TABLE sys_task
+    ADD COLUMN IF NOT EXISTS handler VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS payload TEXT,
+    ADD COLUMN IF NOT EXISTS attempts INT DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_attempts INT DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS worker VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS lease_time TIMESTAMP(3),
+    ADD COLUMN IF NOT EXISTS heartbeat_time TIMESTAMP(3),
+    ADD COLUMN IF NOT EXISTS next_run_time TIMESTAMP(3),
+    ADD COLUMN IF NOT EXISTS cancel_requested BOOLEAN DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_sys_task_status_next_run_time ON sys_task (status, next_run_time);
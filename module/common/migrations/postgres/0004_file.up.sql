@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS sys_file (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    storage_key VARCHAR(512),
+    url VARCHAR(1024),
+    mime_type VARCHAR(255),
+    size BIGINT DEFAULT 0,
+    sha256 CHAR(64),
+    width INT DEFAULT 0,
+    height INT DEFAULT 0,
+    thumbnail_url VARCHAR(1024),
+    policy VARCHAR(64),
+    created_by VARCHAR(64),
+    created_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_file_sha256 ON sys_file (sha256);
+
+CREATE INDEX IF NOT EXISTS idx_sys_file_url ON sys_file (url);
+
+CREATE INDEX IF NOT EXISTS idx_sys_file_tenant_id_created_by ON sys_file (tenant_id, created_by);
+
+CREATE INDEX IF NOT EXISTS idx_sys_file_created_time ON sys_file (created_time);
+
+CREATE TABLE IF NOT EXISTS sys_file_reference (
+    id VARCHAR(64) NOT NULL,
+    file_id VARCHAR(64),
+    entity VARCHAR(255),
+    entity_id VARCHAR(64),
+    created_time TIMESTAMP(3),
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_sys_file_reference ON sys_file_reference (file_id, entity, entity_id);
+
+CREATE INDEX IF NOT EXISTS idx_sys_file_reference_entity ON sys_file_reference (entity, entity_id);
@@ -0,0 +1,2 @@
+ALTER TABLE sys_task
+    DROP COLUMN IF EXISTS checkpoint;
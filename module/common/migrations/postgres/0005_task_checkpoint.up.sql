@@ -0,0 +1,2 @@
+ALTER TABLE sys_task
+    ADD COLUMN IF NOT EXISTS checkpoint TEXT;
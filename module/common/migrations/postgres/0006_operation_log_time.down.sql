@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sys_operation_log_operated_time;
+
+ALTER TABLE sys_operation_log
+    DROP COLUMN IF EXISTS operated_time;
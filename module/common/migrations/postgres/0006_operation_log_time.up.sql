@@ -0,0 +1,7 @@
+ALTER TABLE sys_operation_log
+    ADD COLUMN IF NOT EXISTS operated_time TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- 已有记录按雪花 Id 中的时间戳回填
+UPDATE sys_operation_log SET operated_time = to_timestamp(((id >> 8) + 1483228800000) / 1000.0);
+
+CREATE INDEX IF NOT EXISTS idx_sys_operation_log_operated_time ON sys_operation_log (operated_time, id);
@@ -130,6 +130,27 @@ func (r *EventRepository) Check(conds map[string]any) (*domain.Event, error) {
 	}
 }
 
+// 用户的未读事件数：上次进入事件中心之后产生的私有事件
+func (r *EventRepository) CountUnread(userId string) (int64, error) {
+	tx := r.Model(&domain.Event{}).
+		Where("target = ? and scope = 'PRIVATE'", userId).
+		Where("del_flag = ?", false)
+
+	var accessTime time.Time
+	if res := r.Table("sys_event_access_log b").
+		Select("access_time").
+		Where("user_id=?", userId).
+		Where("action=?", "READ").
+		Limit(1).
+		Find(&accessTime); res.Error == nil && res.RowsAffected > 0 {
+		tx.Where("created_time > ?", accessTime)
+	}
+
+	var count int64
+	res := tx.Count(&count)
+	return count, res.Error
+}
+
 func (r *EventRepository) HistoryEvents() {
 	// valid
 	tx := r.Model(&domain.Event{}).
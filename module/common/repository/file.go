@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/query"
+
+	"github.com/gophab/gophrame/module/common/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FileRepository struct {
+	*gorm.DB `inject:"database"`
+}
+
+type FileReferenceRepository struct {
+	*gorm.DB `inject:"database"`
+}
+
+var fileRepository = &FileRepository{}
+var fileReferenceRepository = &FileReferenceRepository{}
+
+func init() {
+	inject.InjectValue("fileRepository", fileRepository)
+	inject.InjectValue("fileReferenceRepository", fileReferenceRepository)
+}
+
+func (r *FileRepository) first(tx *gorm.DB) (*domain.File, error) {
+	var result domain.File
+	if res := tx.Limit(1).Find(&result); res.Error == nil && res.RowsAffected > 0 {
+		return &result, nil
+	} else {
+		return nil, res.Error
+	}
+}
+
+func (r *FileRepository) GetById(id string) (*domain.File, error) {
+	return r.first(r.Model(&domain.File{}).Where("id = ?", id))
+}
+
+func (r *FileRepository) GetByUrl(url string) (*domain.File, error) {
+	return r.first(r.Model(&domain.File{}).Where("url = ?", url).Order("created_time"))
+}
+
+// 租户内地址为 url 的记录，按上传时间排序；相同内容的记录共用同一地址
+func (r *FileRepository) FindByUrl(url string, tenantId string) ([]*domain.File, error) {
+	var results = make([]*domain.File, 0)
+	if res := r.Where("url = ? AND tenant_id = ?", url, tenantId).Order("created_time").Find(&results); res.Error == nil {
+		return results, nil
+	} else {
+		return nil, res.Error
+	}
+}
+
+// 在事务中锁定同一内容的全部记录（按上传时间排序）后执行 fn，
+// 复用已存储对象的上传与回收该对象的删除因此互斥
+func (r *FileRepository) LockByHash(sha256 string, fn func(tx *FileRepository, files []*domain.File) error) error {
+	return r.Transaction(func(tx *gorm.DB) error {
+		var files = make([]*domain.File, 0)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sha256 = ?", sha256).
+			Order("created_time").
+			Find(&files).Error; err != nil {
+			return err
+		}
+		return fn(&FileRepository{DB: tx}, files)
+	})
+}
+
+func (r *FileRepository) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.File, error) {
+	tx := r.Model(&domain.File{})
+	for k, v := range conds {
+		switch k {
+		case "name":
+			tx = tx.Where("name like ?", "%"+v.(string)+"%")
+		case "mimeType":
+			tx = tx.Where("mime_type like ?", v.(string)+"%")
+		case "tenantId":
+			tx = tx.Where("tenant_id = ?", v)
+		case "createdBy":
+			tx = tx.Where("created_by = ?", v)
+		case "policy":
+			tx = tx.Where("policy = ?", v)
+		}
+	}
+
+	var count int64
+	if !pageable.NoCount() {
+		if res := tx.Count(&count); res.Error != nil {
+			return 0, nil, res.Error
+		}
+	}
+
+	var results = make([]*domain.File, 0)
+	if res := query.Page(tx.Order("created_time desc"), pageable).Find(&results); res.Error == nil {
+		return count, results, nil
+	} else {
+		return 0, results, res.Error
+	}
+}
+
+func (r *FileRepository) CreateFile(file *domain.File) (*domain.File, error) {
+	if res := r.Create(file); res.Error == nil {
+		return file, nil
+	} else {
+		return nil, res.Error
+	}
+}
+
+// 删除文件记录及其引用
+func (r *FileRepository) DeleteById(id string) error {
+	if err := r.Where("file_id = ?", id).Delete(&domain.FileReference{}).Error; err != nil {
+		return err
+	}
+	return r.Where("id = ?", id).Delete(&domain.File{}).Error
+}
+
+// 仅在没有引用时删除文件记录，返回是否删除
+func (r *FileRepository) DeleteUnreferenced(id string) (bool, error) {
+	res := r.Where("id = ?", id).
+		Where("not exists (select 1 from sys_file_reference r where r.file_id = sys_file.id)").
+		Delete(&domain.File{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// 上传时间早于 before 且没有引用的文件
+func (r *FileRepository) FindUnreferenced(before time.Time, limit int) ([]*domain.File, error) {
+	var results = make([]*domain.File, 0)
+	res := r.Model(&domain.File{}).
+		Where("created_time < ?", before).
+		Where("not exists (select 1 from sys_file_reference r where r.file_id = sys_file.id)").
+		Order("created_time").
+		Limit(limit).
+		Find(&results)
+	return results, res.Error
+}
+
+// 已存在时忽略，依赖 (file_id, entity, entity_id) 唯一索引
+func (r *FileReferenceRepository) AddReference(fileId string, entity string, entityId string) error {
+	return r.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.FileReference{FileId: fileId, EntityType: entity, EntityId: entityId}).Error
+}
+
+func (r *FileReferenceRepository) DeleteReference(fileId string, entity string, entityId string) error {
+	return r.Where("file_id = ? and entity = ? and entity_id = ?", fileId, entity, entityId).Delete(&domain.FileReference{}).Error
+}
+
+func (r *FileReferenceRepository) DeleteByEntity(entity string, entityId string) error {
+	return r.Where("entity = ? and entity_id = ?", entity, entityId).Delete(&domain.FileReference{}).Error
+}
+
+// 删除实体对 fileIds 以外文件的引用
+func (r *FileReferenceRepository) DeleteByEntityExcept(entity string, entityId string, fileIds []string) error {
+	tx := r.Where("entity = ? and entity_id = ?", entity, entityId)
+	if len(fileIds) > 0 {
+		tx = tx.Where("file_id not in ?", fileIds)
+	}
+	return tx.Delete(&domain.FileReference{}).Error
+}
+
+func (r *FileReferenceRepository) DeleteByFileId(fileId string) error {
+	return r.Where("file_id = ?", fileId).Delete(&domain.FileReference{}).Error
+}
+
+func (r *FileReferenceRepository) FindByFileId(fileId string) ([]*domain.FileReference, error) {
+	var results = make([]*domain.FileReference, 0)
+	res := r.Model(&domain.FileReference{}).Where("file_id = ?", fileId).Order("created_time").Find(&results)
+	return results, res.Error
+}
+
+// 各文件的引用数
+func (r *FileReferenceRepository) CountByFileIds(fileIds []string) (map[string]int64, error) {
+	var rows []struct {
+		FileId string
+		Count  int64
+	}
+	var result = make(map[string]int64)
+	if len(fileIds) == 0 {
+		return result, nil
+	}
+	if err := r.Model(&domain.FileReference{}).
+		Select("file_id, count(*) as count").
+		Where("file_id in ?", fileIds).
+		Group("file_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.FileId] = row.Count
+	}
+	return result, nil
+}
@@ -252,7 +252,33 @@ func (r *MessageRepository) FindSimplesAvailable(conds map[string]any, pageable
 	}
 }
 
-func (r *MessageRepository) ValidateMessages() {
+// 用户可见的未读消息数：私信、企业内部和公共消息中没有阅读记录的
+func (r *MessageRepository) CountUnread(userId, tenantId string) (int64, error) {
+	tx := r.Model(&domain.Message{})
+	if tenantId != "" {
+		tx.Where(
+			r.Where("(`to` = ? and scope = 'PRIVATE')", userId).
+				Or("tenant_id = 'SYSTEM' and scope = 'PUBLIC'").
+				Or("tenant_id = ? and scope = 'TENANT'", tenantId))
+	} else {
+		tx.Where(
+			r.Where("(`to` = ? and scope = 'PRIVATE')", userId).
+				Or("tenant_id = 'SYSTEM' and scope = 'PUBLIC'"))
+	}
+
+	var count int64
+	res := tx.Where("valid_time is null or valid_time <= ?", time.Now()).
+		Where("due_time is null or due_time >= ?", time.Now()).
+		Where("`status` = ?", 1).
+		Where("del_flag = ?", false).
+		Where("NOT EXISTS (?)",
+			gorm.Expr("select 1 from sys_message_access_log b where user_id=? and action=? and b.message_id=sys_message.id", userId, "READ")).
+		Count(&count)
+	return count, res.Error
+}
+
+// 发布到达生效时间的消息，并将过期消息置为失效，返回两者以便刷新未读计数
+func (r *MessageRepository) ValidateMessages() (published []*domain.Message, expired []*domain.Message) {
 	// valid
 	tx := r.Model(&domain.Message{}).
 		Where("status = ?", 0).
@@ -273,7 +299,9 @@ func (r *MessageRepository) ValidateMessages() {
 		if res := query.Page(tx, pageable).Find(&list); res.Error == nil && res.RowsAffected > 0 {
 			for _, message := range list {
 				message.Status = 1
-				r.Save(message)
+				if res := r.Save(message); res.Error == nil {
+					published = append(published, message)
+				}
 			}
 
 			pageable.Page++
@@ -303,12 +331,14 @@ func (r *MessageRepository) ValidateMessages() {
 				message.Status = -1
 			}
 			r.Updates(list)
+			expired = append(expired, list...)
 
 			pageable.Page++
 		} else {
 			break
 		}
 	}
+	return
 }
 
 func (r *MessageRepository) HistoryMessages() {
@@ -348,7 +378,13 @@ func (r *MessageRepository) HistoryMessages() {
 		Delete(&domain.Message{})
 }
 
-func (r *MessageAccessLogRepository) AccessMessage(userId, action string, message *domain.Message) {
+// 记录访问，返回是否为首次
+func (r *MessageAccessLogRepository) AccessMessage(userId, action string, message *domain.Message) bool {
+	var count int64
+	r.Model(&domain.MessageAccessLog{}).
+		Where("message_id = ? and user_id = ? and action = ?", message.Id, userId, action).
+		Count(&count)
+
 	var log = domain.MessageAccessLog{
 		MessageId:   message.Id,
 		UserId:      userId,
@@ -356,4 +392,5 @@ func (r *MessageAccessLogRepository) AccessMessage(userId, action string, messag
 		CreatedTime: time.Now(),
 	}
 	r.Save(&log)
+	return count == 0
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/query"
+
+	"github.com/gophab/gophrame/module/common/domain"
+
+	"gorm.io/gorm"
+)
+
+type NotificationDeliveryRepository struct {
+	*gorm.DB `inject:"database"`
+}
+
+var notificationDeliveryRepository = &NotificationDeliveryRepository{}
+
+func init() {
+	inject.InjectValue("notificationDeliveryRepository", notificationDeliveryRepository)
+}
+
+func (r *NotificationDeliveryRepository) CreateDeliveries(deliveries []*domain.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.Model(&domain.NotificationDelivery{}).CreateInBatches(deliveries, 100).Error
+}
+
+func (r *NotificationDeliveryRepository) UpdateDelivery(delivery *domain.NotificationDelivery) error {
+	return r.Save(delivery).Error
+}
+
+func (r *NotificationDeliveryRepository) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.NotificationDelivery, error) {
+	tx := r.Model(&domain.NotificationDelivery{})
+	for k, v := range conds {
+		tx.Where(fmt.Sprintf("%s = ?", k), v)
+	}
+
+	var count int64
+	if !pageable.NoCount() {
+		tx.Count(&count)
+	}
+
+	var list = make([]*domain.NotificationDelivery, 0)
+	if res := query.Page(tx, pageable).Order("created_time desc").Find(&list); res.Error == nil {
+		return count, list, nil
+	} else {
+		return 0, []*domain.NotificationDelivery{}, res.Error
+	}
+}
+
+// 到达重试时间的待发送记录，以及认领已到期（发送节点中断）的记录
+func (r *NotificationDeliveryRepository) FindRetryable(limit int) ([]*domain.NotificationDelivery, error) {
+	var list = make([]*domain.NotificationDelivery, 0)
+	if res := r.Model(&domain.NotificationDelivery{}).
+		Where("status in ?", []string{domain.DELIVERY_PENDING, domain.DELIVERY_SENDING}).
+		Where("next_retry_time <= ?", time.Now()).
+		Order("next_retry_time asc").
+		Limit(limit).
+		Find(&list); res.Error == nil {
+		return list, nil
+	} else {
+		return nil, res.Error
+	}
+}
+
+// 认领投递：只有状态未变且已到重试时间的记录可以认领，认领期间其他节点不会重复发送
+func (r *NotificationDeliveryRepository) Claim(delivery *domain.NotificationDelivery, lease time.Duration) (bool, error) {
+	now := time.Now()
+	until := now.Add(lease)
+	res := r.Model(&domain.NotificationDelivery{}).
+		Where("id = ? AND status = ? AND next_retry_time <= ?", delivery.Id, delivery.Status, now).
+		Updates(map[string]any{"status": domain.DELIVERY_SENDING, "next_retry_time": until})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	delivery.Status, delivery.NextRetryTime = domain.DELIVERY_SENDING, &until
+	return true, nil
+}
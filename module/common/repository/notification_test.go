@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/domain"
+	CommonDomain "github.com/gophab/gophrame/module/common/domain"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 只生成 SQL，不连接数据库；返回最后一条语句
+func dryRun(t *testing.T) (*gorm.DB, *string) {
+	t.Helper()
+	conn, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statement string
+	capture := func(tx *gorm.DB) {
+		statement = tx.Statement.SQL.String()
+	}
+	db.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	db.Callback().Query().After("gorm:query").Register("test:capture", capture)
+	return db, &statement
+}
+
+func TestClaimDeliveryConditions(t *testing.T) {
+	db, statement := dryRun(t)
+	r := &NotificationDeliveryRepository{DB: db}
+
+	delivery := &CommonDomain.NotificationDelivery{Model: domain.Model{Id: 1}, Status: CommonDomain.DELIVERY_PENDING}
+	if ok, err := r.Claim(delivery, time.Minute); err != nil || ok {
+		t.Fatalf("dry run claim = %v, %v", ok, err)
+	}
+	// 按读取时的状态和到期时间条件更新，保证只有一个节点认领成功
+	if !strings.Contains(*statement, "UPDATE `sys_notification_delivery` SET `next_retry_time`=?,`status`=?") ||
+		!strings.Contains(*statement, "WHERE id = ? AND status = ? AND next_retry_time <= ?") {
+		t.Fatalf("unexpected claim statement: %s", *statement)
+	}
+	if delivery.Status != CommonDomain.DELIVERY_PENDING {
+		t.Fatal("unclaimed delivery changed")
+	}
+
+	if _, err := r.FindRetryable(10); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(*statement, "status in (?,?)") {
+		t.Fatalf("expired claims not retried: %s", *statement)
+	}
+}
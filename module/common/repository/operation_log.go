@@ -21,14 +21,24 @@ func init() {
 	inject.InjectValue("operationLogRepository", operationLogRepository)
 }
 
-func (r *OperationLogRepository) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.OperationLog, error) {
+func (r *OperationLogRepository) QueryBy(conds map[string]any) *gorm.DB {
 	tx := r.Model(&domain.OperationLog{})
 
 	for k, v := range conds {
 		tx.Where(fmt.Sprintf("%s = ?", k), v)
 	}
+	return tx
+}
+
+func (r *OperationLogRepository) GetCount(conds map[string]any) (count int64, err error) {
+	err = r.QueryBy(conds).Count(&count).Error
+	return
+}
+
+func (r *OperationLogRepository) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.OperationLog, error) {
+	tx := r.QueryBy(conds)
 
-	tx.Order("operation_time desc")
+	tx.Order("operated_time desc").Order("id desc")
 
 	var results = []*domain.OperationLog{}
 	if res := query.Page(tx, pageable).Find(&results); res.Error == nil {
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/query"
 	"github.com/gophab/gophrame/module/common/domain"
@@ -67,3 +69,109 @@ func (r *TaskRepository) DeleteTask(task *domain.Task) (*domain.Task, error) {
 		return nil, res.Error
 	}
 }
+
+// 可领取的异步任务：到达执行时间的待执行任务，以及租约已过期（执行节点失联）且仍有执行次数的任务；
+// maxAttempts 为任务未指定时的最大执行次数
+func (r *TaskRepository) FindRunnable(limit int, maxAttempts int) ([]string, error) {
+	var ids = make([]string, 0)
+	res := r.Model(&domain.Task{}).
+		Where("handler is not null and handler <> ''").
+		Where("del_flag = ?", false).
+		Where(r.runnable(time.Now(), maxAttempts)).
+		Order("created_time asc").
+		Limit(limit).
+		Pluck("id", &ids)
+	return ids, res.Error
+}
+
+func (r *TaskRepository) runnable(now time.Time, maxAttempts int) *gorm.DB {
+	return r.Where("status = ? and (next_run_time is null or next_run_time <= ?)", domain.TASK_PENDING, now).
+		Or("status = ? and lease_time < ? and attempts < COALESCE(NULLIF(max_attempts, 0), ?)", domain.TASK_RUNNING, now, maxAttempts)
+}
+
+// 以条件更新领取任务，返回是否领取成功
+func (r *TaskRepository) Claim(id string, worker string, lease time.Duration, maxAttempts int) (bool, error) {
+	now := time.Now()
+	res := r.Model(&domain.Task{}).
+		Where("id = ?", id).
+		Where("del_flag = ?", false).
+		Where(r.runnable(now, maxAttempts)).
+		UpdateColumns(map[string]any{
+			"status":         domain.TASK_RUNNING,
+			"worker":         worker,
+			"lease_time":     now.Add(lease),
+			"heartbeat_time": now,
+			"attempts":       gorm.Expr("attempts + ?", 1),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// 租约已过期且执行次数用尽的任务（执行中节点失联）标记为失败，返回这些任务的 Id
+func (r *TaskRepository) FailExpired(maxAttempts int) ([]string, error) {
+	var ids = make([]string, 0)
+	now := time.Now()
+	expired := func() *gorm.DB {
+		return r.Model(&domain.Task{}).
+			Where("del_flag = ?", false).
+			Where("status = ? and lease_time < ? and attempts >= COALESCE(NULLIF(max_attempts, 0), ?)", domain.TASK_RUNNING, now, maxAttempts)
+	}
+	if res := expired().Pluck("id", &ids); res.Error != nil || len(ids) == 0 {
+		return ids, res.Error
+	}
+
+	res := expired().
+		Where("id in ?", ids).
+		UpdateColumns(map[string]any{
+			"status":        domain.TASK_FAILED,
+			"remark":        "task lease expired",
+			"lease_time":    nil,
+			"finished_time": now,
+		})
+	return ids, res.Error
+}
+
+// 续约，返回是否仍持有租约以及是否已请求取消
+func (r *TaskRepository) Heartbeat(id string, worker string, lease time.Duration) (bool, bool, error) {
+	now := time.Now()
+	res := r.Model(&domain.Task{}).
+		Where("id = ? and worker = ? and status = ?", id, worker, domain.TASK_RUNNING).
+		UpdateColumns(map[string]any{
+			"lease_time":     now.Add(lease),
+			"heartbeat_time": now,
+		})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, false, res.Error
+	}
+
+	var cancelRequested bool
+	if res := r.Model(&domain.Task{}).Where("id = ?", id).Select("cancel_requested").Scan(&cancelRequested); res.Error != nil {
+		return true, false, res.Error
+	}
+	return true, cancelRequested, nil
+}
+
+// 只更新当前执行节点持有的任务
+func (r *TaskRepository) UpdateRunning(id string, worker string, data map[string]any) (bool, error) {
+	res := r.Model(&domain.Task{}).
+		Where("id = ? and worker = ? and status = ?", id, worker, domain.TASK_RUNNING).
+		UpdateColumns(data)
+	return res.RowsAffected > 0, res.Error
+}
+
+// 请求取消：未开始的任务直接中断，执行中的任务由执行节点在心跳时感知
+func (r *TaskRepository) RequestCancel(id string) error {
+	return r.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Model(&domain.Task{}).
+			Where("id = ? and status = ?", id, domain.TASK_PENDING).
+			UpdateColumns(map[string]any{
+				"status":           domain.TASK_CANCELLED,
+				"cancel_requested": true,
+				"finished_time":    time.Now(),
+			}); res.Error != nil {
+			return res.Error
+		}
+		return tx.Model(&domain.Task{}).
+			Where("id = ? and status = ?", id, domain.TASK_RUNNING).
+			UpdateColumn("cancel_requested", true).Error
+	})
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClaimTaskConditions(t *testing.T) {
+	db, statement := dryRun(t)
+	r := &TaskRepository{DB: db}
+
+	if ok, err := r.Claim("task-1", "worker-1", time.Minute, 3); err != nil || ok {
+		t.Fatalf("dry run claim = %v, %v", ok, err)
+	}
+	// 租约过期的任务只在仍有执行次数时重新领取
+	if !strings.Contains(*statement, "UPDATE `sys_task` SET `attempts`=attempts + ?") ||
+		!strings.Contains(*statement, "OR (status = ? and lease_time < ? and attempts < COALESCE(NULLIF(max_attempts, 0), ?))") {
+		t.Fatalf("unexpected claim statement: %s", *statement)
+	}
+
+	if _, err := r.FindRunnable(10, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(*statement, "attempts < COALESCE(NULLIF(max_attempts, 0), ?)") {
+		t.Fatalf("expired tasks claimed without attempts check: %s", *statement)
+	}
+}
+
+func TestFailExpiredTasks(t *testing.T) {
+	db, statement := dryRun(t)
+	r := &TaskRepository{DB: db}
+
+	ids, err := r.FailExpired(3)
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("dry run fail expired = %v, %v", ids, err)
+	}
+	if !strings.Contains(*statement, "status = ? and lease_time < ? and attempts >= COALESCE(NULLIF(max_attempts, 0), ?)") {
+		t.Fatalf("unexpected expired statement: %s", *statement)
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/gophab/gophrame/module/common/domain"
 	"github.com/gophab/gophrame/module/common/repository"
 
@@ -8,14 +10,20 @@ import (
 	"github.com/gophab/gophrame/core/query"
 	SecurityUtil "github.com/gophab/gophrame/core/security/util"
 	"github.com/gophab/gophrame/service"
+
+	"github.com/patrickmn/go-cache"
 )
 
 type ContentTemplateService struct {
 	service.BaseService
 	ContentTemplateRepository *repository.ContentTemplateRepository `inject:"contentTemplateRepository"`
+	LocalCache                *cache.Cache
 }
 
-var contentTemplateService = &ContentTemplateService{}
+// 模板按 type:scene:tenant 缓存（包括不存在的情况），本节点修改时清空，其他节点在过期后生效
+var contentTemplateService = &ContentTemplateService{
+	LocalCache: cache.New(5*time.Minute, 1*time.Minute),
+}
 
 func init() {
 	inject.InjectValue("contentTemplateService", contentTemplateService)
@@ -26,7 +34,16 @@ func (s *ContentTemplateService) GetById(id string) (*domain.ContentTemplate, er
 }
 
 func (s *ContentTemplateService) GetByTypeAndSceneAndTenantId(typeName, scene, tenantId string) (*domain.ContentTemplate, error) {
-	return s.ContentTemplateRepository.GetByTypeAndSceneAndTenantId(typeName, scene, tenantId)
+	var key = typeName + ":" + scene + ":" + tenantId
+	if v, b := s.LocalCache.Get(key); b {
+		return v.(*domain.ContentTemplate), nil
+	}
+
+	result, err := s.ContentTemplateRepository.GetByTypeAndSceneAndTenantId(typeName, scene, tenantId)
+	if err == nil {
+		s.LocalCache.Set(key, result, cache.DefaultExpiration)
+	}
+	return result, err
 }
 
 func (s *ContentTemplateService) FindAll(conds map[string]any, pageable query.Pageable) (int64, []*domain.ContentTemplate, error) {
@@ -34,23 +51,27 @@ func (s *ContentTemplateService) FindAll(conds map[string]any, pageable query.Pa
 }
 
 func (s *ContentTemplateService) CreateContentTemplate(template *domain.ContentTemplate) (*domain.ContentTemplate, error) {
+	defer s.LocalCache.Flush()
 	return s.ContentTemplateRepository.CreateContentTemplate(template)
 }
 
 func (s *ContentTemplateService) UpdateContentTemplate(template *domain.ContentTemplate) (*domain.ContentTemplate, error) {
+	defer s.LocalCache.Flush()
 	return s.ContentTemplateRepository.CreateContentTemplate(template)
 }
 
 func (s *ContentTemplateService) PatchContentTemplate(id string, data map[string]any) (result *domain.ContentTemplate, err error) {
+	defer s.LocalCache.Flush()
 	return s.ContentTemplateRepository.PatchContentTemplate(id, data)
 }
 
 func (s *ContentTemplateService) DeleteContentTemplate(id string) error {
+	defer s.LocalCache.Flush()
 	return s.ContentTemplateRepository.DeleteById(id)
 }
 
 func (s *ContentTemplateService) GetContentTemplate(typeName, scene string) (title, content string) {
-	contentTemplate, err := s.ContentTemplateRepository.GetByTypeAndSceneAndTenantId(typeName, scene, SecurityUtil.GetCurrentTenantId(nil))
+	contentTemplate, err := s.GetByTypeAndSceneAndTenantId(typeName, scene, SecurityUtil.GetCurrentTenantId(nil))
 	if err == nil && contentTemplate != nil {
 		return contentTemplate.Title, contentTemplate.Content
 	}
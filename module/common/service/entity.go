@@ -1,47 +1,37 @@
 package service
 
 import (
-	"strings"
-	"sync"
+	"fmt"
+
+	"github.com/gophab/gophrame/service"
 )
 
 type EntityGetter func(id any) any
 
-type EntityHelper struct {
-	mutex   sync.RWMutex
-	Getters map[string]EntityGetter
-}
-
-var entityHelper = &EntityHelper{
-	mutex:   sync.RWMutex{},
-	Getters: make(map[string]EntityGetter),
+// 单个读取的 getter 适配为 EntityProvider，每个 id 仍是一次查询，建议改为实现 GetEntities
+type getterProvider struct {
+	getter EntityGetter
 }
 
-func (h *EntityHelper) RegisterEntity(entity string, getter EntityGetter) {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
-
-	h.Getters[strings.ToLower(entity)] = getter
-}
-
-func (h *EntityHelper) GetEntity(entity string, id any) any {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-
-	if getter, b := h.Getters[strings.ToLower(entity)]; b {
-		return getter(id)
+func (p *getterProvider) GetEntities(entity string, ids []string) (map[string]any, error) {
+	var result = make(map[string]any, len(ids))
+	for _, id := range ids {
+		if v := p.getter(id); v != nil {
+			result[id] = v
+		}
 	}
-	return nil
+	return result, nil
 }
 
 func RegisterEntity(entity string, getter EntityGetter) {
-	entityHelper.RegisterEntity(entity, getter)
+	service.RegisterEntityProvider(&getterProvider{getter: getter}, entity)
 }
 
 func GetEntity(entity string, id any) any {
-	return entityHelper.GetEntity(entity, id)
+	return service.GetEntity(entity, fmt.Sprint(id))
 }
 
 func GetEntityAs[T any](entity string, id any) *T {
-	return entityHelper.GetEntity(entity, id).(*T)
+	result, _ := GetEntity(entity, id).(*T)
+	return result
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -27,7 +28,16 @@ var eventService = &EventService{}
 func init() {
 	inject.InjectValue("eventService", eventService)
 
-	cron.AddFunc("@daily", eventService.HistoryEvents)
+	cron.MustRegister(&cron.Job{
+		Name:        "event.history",
+		Description: "Move expired events to history",
+		Spec:        "@daily",
+		Misfire:     cron.MISFIRE_FIRE_ONCE,
+		Func: func(ctx context.Context) error {
+			eventService.HistoryEvents()
+			return nil
+		},
+	})
 
 	eventbus.RegisterEventListener("SYSTEM_EVENT", eventService.TriggerEvent)
 	eventbus.RegisterEventListener("ON_ACCESS_EVENT_CENTER", eventService.OnAccessEventCenter)
@@ -46,7 +56,11 @@ func (s *EventService) Find(conds map[string]any, pageable query.Pageable) (int6
 }
 
 func (s *EventService) CreateEvent(event *domain.Event) (*domain.Event, error) {
-	return s.EventRepository.CreateEvent(event)
+	result, err := s.EventRepository.CreateEvent(event)
+	if err == nil {
+		eventbus.DispatchEvent("SYSTEM_EVENT_CREATED", result)
+	}
+	return result, err
 }
 
 func (s *EventService) PatchEvent(id int64, data map[string]any) (*domain.Event, error) {
@@ -78,7 +92,7 @@ func (s *EventService) FormatEvent(event *domain.Event) *domain.Event {
 }
 
 func (s *EventService) AccessEventCenter(userId string) {
-	s.EventRepository.Table("sys_event_access_log").Where("user_id=?", userId).Update("access_time", time.Now())
+	s.EventRepository.Save(&domain.EventAccessLog{UserId: userId, Action: "READ", AccessTime: time.Now()})
 }
 
 func (s *EventService) HistoryEvents() {
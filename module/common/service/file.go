@@ -0,0 +1,559 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/cron"
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/query"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/service"
+	"github.com/gophab/gophrame/service/dto"
+
+	"github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/file"
+	"github.com/gophab/gophrame/module/common/file/config"
+	"github.com/gophab/gophrame/module/common/repository"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrStorageUnavailable = service.ErrFileStorageUnavailable
+
+type FileService struct {
+	service.BaseService
+	FileRepository          *repository.FileRepository          `inject:"fileRepository"`
+	FileReferenceRepository *repository.FileReferenceRepository `inject:"fileReferenceRepository"`
+	Oss                     oss.OSS                             `inject:"oss,optional"`
+
+	resizing singleflight.Group
+	slots    chan struct{}
+	once     sync.Once
+}
+
+var fileService = &FileService{}
+
+func init() {
+	inject.InjectValue("fileService", fileService)
+	inject.InjectValue("commonFileService", commonFileService)
+
+	// 登记系统实体中的文件地址，被引用的文件不会被回收
+	eventbus.RegisterEventListener("USER_CREATED", fileService.onEntitySaved("user", "avatar", "tenantId"))
+	eventbus.RegisterEventListener("USER_UPDATED", fileService.onEntitySaved("user", "avatar", "tenantId"))
+	eventbus.RegisterEventListener("USER_DELETED", fileService.onEntityDeleted("user"))
+	eventbus.RegisterEventListener("TENANT_CREATED", fileService.onEntitySaved("tenant", "logo", "id"))
+	eventbus.RegisterEventListener("TENANT_UPDATED", fileService.onEntitySaved("tenant", "logo", "id"))
+	eventbus.RegisterEventListener("TENANT_DELETED", fileService.onEntityDeleted("tenant"))
+
+	cron.MustRegister(&cron.Job{
+		Name:        "file.gc",
+		Description: "Remove unreferenced files",
+		Spec:        "@daily",
+		Misfire:     cron.MISFIRE_FIRE_ONCE,
+		Func: func(ctx context.Context) error {
+			if !config.Setting.Enabled || !config.Setting.GcEnabled {
+				return nil
+			}
+			_, err := fileService.CollectGarbage(ctx)
+			return err
+		},
+	})
+}
+
+func (s *FileService) GetById(id string) (*domain.File, error) {
+	return s.FileRepository.GetById(id)
+}
+
+func (s *FileService) GetByUrl(url string) (*domain.File, error) {
+	return s.FileRepository.GetByUrl(url)
+}
+
+// 带引用数的文件列表
+func (s *FileService) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.File, error) {
+	count, list, err := s.FileRepository.Find(conds, pageable)
+	if err != nil {
+		return 0, list, err
+	}
+
+	var ids = make([]string, 0, len(list))
+	for _, f := range list {
+		ids = append(ids, f.Id)
+	}
+	if counts, err := s.FileReferenceRepository.CountByFileIds(ids); err == nil {
+		for _, f := range list {
+			f.References = counts[f.Id]
+		}
+	}
+	return count, list, nil
+}
+
+func (s *FileService) GetReferences(fileId string) ([]*domain.FileReference, error) {
+	return s.FileReferenceRepository.FindByFileId(fileId)
+}
+
+// 对象键按内容摘要生成，相同内容只存一份
+func (s *FileService) key(sha string) string {
+	return path.Join(config.Setting.Prefix, sha[:2], sha)
+}
+
+func (s *FileService) variantPrefix(sha string) string {
+	return path.Join(config.Setting.Prefix, "thumbs", sha) + "/"
+}
+
+// 保存上传的文件：同一用户重复上传相同内容时返回已有记录，
+// 其他用户上传过的相同内容只新增记录、复用存储对象
+func (s *FileService) Upload(header *multipart.FileHeader, policy string, createdBy string, tenantId string) (*domain.File, error) {
+	if s.Oss == nil {
+		return nil, ErrStorageUnavailable
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	hash := sha256.New()
+	hash.Write(head[:n])
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return nil, err
+	}
+	size += int64(n)
+
+	mimeType := file.SniffMimeType(head[:n], header.Filename)
+	if err := file.CheckPolicy(policy, size, mimeType); err != nil {
+		return nil, err
+	}
+
+	var record = &domain.File{
+		Name:      header.Filename,
+		MimeType:  mimeType,
+		Size:      size,
+		Sha256:    hex.EncodeToString(hash.Sum(nil)),
+		Policy:    policy,
+		CreatedBy: createdBy,
+		TenantId:  tenantId,
+	}
+
+	// 对象和缩略图在事务之外写入，锁内只查找和登记；写入后对象被并发删除时重新写入
+	for attempt := 1; ; attempt++ {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		thumbnail, err := s.store(context.Background(), record, f)
+		if err != nil {
+			return nil, err
+		}
+
+		var result *domain.File
+		err = s.FileRepository.LockByHash(record.Sha256, func(tx *repository.FileRepository, files []*domain.File) error {
+			for _, existing := range files {
+				if existing.TenantId == tenantId && existing.CreatedBy == createdBy {
+					result = existing
+					return nil
+				}
+			}
+
+			for _, key := range []string{record.Key, thumbnail} {
+				if key == "" {
+					continue
+				}
+				if _, err := s.Oss.Stat(context.Background(), key); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			result, err = tx.CreateFile(record)
+			return err
+		})
+		if err == oss.ErrNotFound && attempt < 3 {
+			continue
+		}
+		return result, err
+	}
+}
+
+// 写入对象并生成缩略图，返回缩略图的对象键；对象按内容摘要寻址，已存在时不重复写入
+func (s *FileService) store(ctx context.Context, record *domain.File, reader io.Reader) (string, error) {
+	record.Key = s.key(record.Sha256)
+	record.Url = s.Oss.URL(record.Key)
+	options := &oss.PutOptions{ContentType: record.MimeType, Metadata: map[string]string{"sha256": record.Sha256}}
+
+	_, err := s.Oss.Stat(ctx, record.Key)
+	if err != nil && err != oss.ErrNotFound {
+		return "", err
+	}
+	stored := err == nil
+
+	if !file.IsImage(record.MimeType) {
+		if !stored {
+			if _, err := oss.PutLarge(ctx, s.Oss, record.Key, reader, record.Size, options); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	if !stored {
+		if _, err := s.Oss.Put(ctx, record.Key, bytes.NewReader(data), int64(len(data)), options); err != nil {
+			return "", err
+		}
+	}
+
+	// 超大图片只记录尺寸，不生成缩略图
+	w, h, err := file.ImageSize(data)
+	record.Width, record.Height = w, h
+	if err != nil || config.Setting.Thumbnail <= 0 {
+		return "", nil
+	}
+	size := config.Setting.Thumbnail
+	key, _, err := s.variant(ctx, record, data, size, size)
+	if err != nil {
+		logger.Warn("Generate thumbnail error: ", record.Sha256, err.Error())
+		return "", nil
+	}
+	record.ThumbnailUrl = util.StringAddr(s.Oss.URL(key))
+	return key, nil
+}
+
+// 生成缩放后的图片，已生成过时直接返回；data 为空时从存储读取原图
+func (s *FileService) variant(ctx context.Context, record *domain.File, data []byte, width, height int) (string, string, error) {
+	key := fmt.Sprintf("%s%dx%d", s.variantPrefix(record.Sha256), width, height)
+	if info, err := s.Oss.Stat(ctx, key); err == nil {
+		return key, info.ContentType, nil
+	} else if err != oss.ErrNotFound {
+		return "", "", err
+	}
+
+	// 同一尺寸的并发请求只缩放一次
+	mimeType, err, _ := s.resizing.Do(key, func() (any, error) {
+		return s.resize(ctx, record, data, key, width, height)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return key, mimeType.(string), nil
+}
+
+// 同时进行的缩放数，解码图片占用大量内存和 CPU
+func (s *FileService) resizeSlots() chan struct{} {
+	s.once.Do(func() {
+		s.slots = make(chan struct{}, max(config.Setting.MaxResizes, 1))
+	})
+	return s.slots
+}
+
+func (s *FileService) resize(ctx context.Context, record *domain.File, data []byte, key string, width, height int) (string, error) {
+	// 已知尺寸时不下载原图即可拒绝
+	if config.Setting.MaxPixels > 0 && int64(record.Width)*int64(record.Height) > config.Setting.MaxPixels {
+		return "", file.ErrImageTooLarge
+	}
+
+	slots := s.resizeSlots()
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+		return "", context.Cause(ctx)
+	}
+
+	if data == nil {
+		reader, _, err := s.Oss.Get(ctx, record.Key)
+		if err != nil {
+			return "", err
+		}
+		data, err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	resized, mimeType, err := file.Resize(data, width, height)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.Oss.Put(ctx, key, bytes.NewReader(resized), int64(len(resized)), &oss.PutOptions{ContentType: mimeType}); err != nil {
+		return "", err
+	}
+	return mimeType, nil
+}
+
+// 按需缩放的尺寸向上取到 64、128、256… 的档位，避免任意尺寸的缩放结果占满存储
+func snap(size int) int {
+	if size <= 0 {
+		return 0
+	}
+	s := 64
+	for s < size && s < config.Setting.MaxImageSize {
+		s *= 2
+	}
+	return min(s, config.Setting.MaxImageSize)
+}
+
+// 读取文件内容，width、height 不为 0 时返回缩放后的图片，调用方负责关闭
+func (s *FileService) Open(ctx context.Context, record *domain.File, width int, height int) (io.ReadCloser, *oss.ObjectInfo, error) {
+	if s.Oss == nil {
+		return nil, nil, ErrStorageUnavailable
+	}
+	if width <= 0 && height <= 0 {
+		return s.Oss.Get(ctx, record.Key)
+	}
+	if !file.IsImage(record.MimeType) {
+		return nil, nil, file.ErrNotImage
+	}
+
+	// 不放大：请求的尺寸不小于原图时返回原图
+	width, height = snap(width), snap(height)
+	if record.Width > 0 && record.Height > 0 && (width == 0 || width >= record.Width) && (height == 0 || height >= record.Height) {
+		return s.Oss.Get(ctx, record.Key)
+	}
+
+	key, _, err := s.variant(ctx, record, nil, width, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Oss.Get(ctx, key)
+}
+
+// fileId 可以是文件 Id 或文件地址
+func (s *FileService) resolve(fileId string) (*domain.File, error) {
+	if record, err := s.FileRepository.GetById(fileId); err != nil || record != nil {
+		return record, err
+	}
+	return s.FileRepository.GetByUrl(fileId)
+}
+
+func (s *FileService) AddReference(fileId string, entity string, entityId string) error {
+	record, err := s.resolve(fileId)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return oss.ErrNotFound
+	}
+	return s.FileReferenceRepository.AddReference(record.Id, entity, entityId)
+}
+
+func (s *FileService) RemoveReference(fileId string, entity string, entityId string) error {
+	record, err := s.resolve(fileId)
+	if err != nil || record == nil {
+		return err
+	}
+	return s.FileReferenceRepository.DeleteReference(record.Id, entity, entityId)
+}
+
+func (s *FileService) RemoveReferences(entity string, entityId string) error {
+	return s.FileReferenceRepository.DeleteByEntity(entity, entityId)
+}
+
+// 删除文件记录及其引用，没有其他记录使用同一内容时一并删除存储对象
+func (s *FileService) Delete(ctx context.Context, record *domain.File) error {
+	_, err := s.remove(ctx, record, true)
+	return err
+}
+
+// force 为 false 时只删除仍没有引用的记录；与复用同一内容的上传互斥，不会删除刚被复用的对象
+func (s *FileService) remove(ctx context.Context, record *domain.File, force bool) (bool, error) {
+	var removed bool
+	err := s.FileRepository.LockByHash(record.Sha256, func(tx *repository.FileRepository, files []*domain.File) (err error) {
+		if force {
+			removed, err = true, tx.DeleteById(record.Id)
+		} else {
+			removed, err = tx.DeleteUnreferenced(record.Id)
+		}
+		if err != nil || !removed || s.Oss == nil {
+			return err
+		}
+		for _, f := range files {
+			if f.Id != record.Id {
+				return nil
+			}
+		}
+		return s.deleteObjects(ctx, record)
+	})
+	return removed, err
+}
+
+// 先删除缩放图再删除原对象，中途失败时记录随事务回滚，缩放图可重新生成
+func (s *FileService) deleteObjects(ctx context.Context, record *domain.File) error {
+	var options = &oss.ListOptions{Prefix: s.variantPrefix(record.Sha256)}
+	for {
+		result, err := s.Oss.List(ctx, options)
+		if err != nil {
+			return err
+		}
+		for _, object := range result.Objects {
+			if err := s.Oss.Delete(ctx, object.Key); err != nil {
+				return err
+			}
+		}
+		if !result.Truncated {
+			break
+		}
+		options.Marker = result.NextMarker
+	}
+	return s.Oss.Delete(ctx, record.Key)
+}
+
+// 回收上传后超过 GcGrace 仍未被引用的文件，返回回收数量
+func (s *FileService) CollectGarbage(ctx context.Context) (int, error) {
+	const batch = 100
+
+	var count = 0
+	before := time.Now().Add(-config.Setting.GcGrace)
+	for {
+		list, err := s.FileRepository.FindUnreferenced(before, batch)
+		if err != nil {
+			return count, err
+		}
+		for _, record := range list {
+			if err := ctx.Err(); err != nil {
+				return count, err
+			}
+			// 查询之后新增了引用的文件不删除，下一批也不会再查到
+			removed, err := s.remove(ctx, record, false)
+			if err != nil {
+				return count, err
+			}
+			if removed {
+				count++
+			}
+		}
+		if len(list) < batch {
+			break
+		}
+	}
+
+	if count > 0 {
+		logger.Info("Collected unreferenced files: ", count)
+	}
+	return count, nil
+}
+
+// 实体保存后按 field 中的文件地址更新其引用，地址不是登记的文件时只清除旧引用；
+// 地址只在 tenantField 指定的租户内查找，优先取实体修改人、实体本身（用户）、创建人上传的记录
+func (s *FileService) onEntitySaved(entity string, field string, tenantField string) eventbus.EventListener {
+	return func(event string, args ...any) {
+		if !config.Setting.Enabled || len(args) == 0 {
+			return
+		}
+		record := util.Struct2Map(args[0])
+		id, _ := util.GetRecordField(record, "id")
+		if id == nil || fmt.Sprint(id) == "" {
+			return
+		}
+
+		var fileIds = make([]string, 0, 1)
+		if url, b := util.GetRecordField(record, field); b && url != nil && fmt.Sprint(url) != "" {
+			tenantId, _ := util.GetRecordField(record, tenantField)
+			var uploaders = make([]string, 0, 3)
+			for _, name := range []string{"lastModifiedBy", "id", "createdBy"} {
+				if v, _ := util.GetRecordField(record, name); v != nil && fmt.Sprint(v) != "" {
+					uploaders = append(uploaders, fmt.Sprint(v))
+				}
+			}
+			if f, err := s.resolveInTenant(fmt.Sprint(url), fmt.Sprint(tenantId), uploaders...); err == nil && f != nil {
+				fileIds = append(fileIds, f.Id)
+			}
+		}
+		if err := s.SetReferences(entity, fmt.Sprint(id), fileIds...); err != nil {
+			logger.Warn("Update file references error: ", entity, id, err.Error())
+		}
+	}
+}
+
+// 租户内地址为 url 的记录，按 uploaders 的顺序优先取其上传的记录，都没有时取最早的记录
+func (s *FileService) resolveInTenant(url string, tenantId string, uploaders ...string) (*domain.File, error) {
+	files, err := s.FileRepository.FindByUrl(url, tenantId)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	for _, uploader := range uploaders {
+		for _, f := range files {
+			if f.CreatedBy == uploader {
+				return f, nil
+			}
+		}
+	}
+	return files[0], nil
+}
+
+func (s *FileService) onEntityDeleted(entity string) eventbus.EventListener {
+	return func(event string, args ...any) {
+		if len(args) == 0 {
+			return
+		}
+		if id, _ := util.GetRecordField(util.Struct2Map(args[0]), "id"); id != nil {
+			if err := s.RemoveReferences(entity, fmt.Sprint(id)); err != nil {
+				logger.Warn("Remove file references error: ", entity, id, err.Error())
+			}
+		}
+	}
+}
+
+// 实体只引用 fileIds 中的文件：先登记新引用再删除其他引用，期间不会出现无引用的窗口
+func (s *FileService) SetReferences(entity string, entityId string, fileIds ...string) error {
+	for _, fileId := range fileIds {
+		if err := s.FileReferenceRepository.AddReference(fileId, entity, entityId); err != nil {
+			return err
+		}
+	}
+	return s.FileReferenceRepository.DeleteByEntityExcept(entity, entityId, fileIds)
+}
+
+//////////////////////////////////// CommonFileService /////////////////////////////////////
+
+type CommonFileService struct{}
+
+var commonFileService = &CommonFileService{}
+
+func (s *CommonFileService) Save(header *multipart.FileHeader, policy string, createdBy string, tenantId string) (*dto.File, error) {
+	if !config.Setting.Enabled {
+		return nil, ErrStorageUnavailable
+	}
+	record, err := fileService.Upload(header, policy, createdBy, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.File{
+		Id:           record.Id,
+		Name:         record.Name,
+		Url:          record.Url,
+		MimeType:     record.MimeType,
+		Size:         record.Size,
+		Sha256:       record.Sha256,
+		ThumbnailUrl: util.StringValue(record.ThumbnailUrl),
+	}, nil
+}
+
+func (s *CommonFileService) AddReference(fileId string, entity string, entityId string) error {
+	return fileService.AddReference(fileId, entity, entityId)
+}
+
+func (s *CommonFileService) RemoveReferences(entity string, entityId string) error {
+	return fileService.RemoveReferences(entity, entityId)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gophab/gophrame/core/oss/local"
+	"github.com/gophab/gophrame/service"
+
+	"github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/file/config"
+	"github.com/gophab/gophrame/module/common/repository"
+)
+
+func newFileService(t *testing.T) *FileService {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "file.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.File{}, &domain.FileReference{}); err != nil {
+		t.Fatal(err)
+	}
+	storage, err := local.NewLocalOSS(filepath.Join(dir, "storage"), "default", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &FileService{
+		FileRepository:          &repository.FileRepository{DB: db},
+		FileReferenceRepository: &repository.FileReferenceRepository{DB: db},
+		Oss:                     storage,
+	}
+}
+
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", name)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
+
+func exists(s *FileService, key string) bool {
+	_, err := s.Oss.Stat(context.Background(), key)
+	return err == nil
+}
+
+func TestUploadDeduplicatesAndDeleteKeepsSharedObject(t *testing.T) {
+	s := newFileService(t)
+	content := []byte("hello file registry")
+
+	first, err := s.Upload(fileHeader(t, "a.txt", content), config.POLICY_FILE, "u1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.Upload(fileHeader(t, "b.txt", content), config.POLICY_FILE, "u1", "t1")
+	if err != nil || again.Id != first.Id {
+		t.Fatalf("same user upload = %+v, %v", again, err)
+	}
+	other, err := s.Upload(fileHeader(t, "c.txt", content), config.POLICY_FILE, "u2", "t1")
+	if err != nil || other.Id == first.Id || other.Key != first.Key {
+		t.Fatalf("other user upload = %+v, %v", other, err)
+	}
+
+	if err := s.Delete(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(s, first.Key) {
+		t.Fatal("shared object deleted while still in use")
+	}
+	if err := s.Delete(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	if exists(s, first.Key) {
+		t.Fatal("object left after last record deleted")
+	}
+
+	// 删除后重新上传相同内容时重新存储
+	again, err = s.Upload(fileHeader(t, "a.txt", content), config.POLICY_FILE, "u1", "t1")
+	if err != nil || !exists(s, again.Key) {
+		t.Fatalf("upload after delete = %+v, %v", again, err)
+	}
+}
+
+func TestUploadRestoresMissingObject(t *testing.T) {
+	s := newFileService(t)
+	content := []byte("restored object")
+
+	first, err := s.Upload(fileHeader(t, "a.txt", content), config.POLICY_FILE, "u1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 记录仍在但对象已被删除时，其他用户上传相同内容会重新写入对象
+	if err := s.Oss.Delete(context.Background(), first.Key); err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.Upload(fileHeader(t, "b.txt", content), config.POLICY_FILE, "u2", "t1")
+	if err != nil || other.Key != first.Key || !exists(s, other.Key) {
+		t.Fatalf("upload with missing object = %+v, %v", other, err)
+	}
+}
+
+type user struct {
+	Id             string  `json:"id"`
+	TenantId       string  `json:"tenantId"`
+	LastModifiedBy string  `json:"lastModifiedBy"`
+	Avatar         *string `json:"avatar"`
+}
+
+func TestReferencesAndGarbageCollection(t *testing.T) {
+	defer func(grace time.Duration) { config.Setting.GcGrace = grace }(config.Setting.GcGrace)
+	config.Setting.GcGrace = -time.Minute
+
+	s := newFileService(t)
+	avatar, err := s.Upload(fileHeader(t, "avatar.txt", []byte("avatar")), config.POLICY_FILE, "u1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan, err := s.Upload(fileHeader(t, "orphan.txt", []byte("orphan")), config.POLICY_FILE, "u1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := s.onEntitySaved("user", "avatar", "tenantId")
+	saved("USER_UPDATED", &user{Id: "u1", TenantId: "t1", Avatar: &avatar.Url})
+	// 重复登记不报错
+	if err := s.AddReference(avatar.Id, "user", "u1"); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := s.CollectGarbage(context.Background())
+	if err != nil || count != 1 {
+		t.Fatalf("collect = %d, %v", count, err)
+	}
+	if f, _ := s.GetById(avatar.Id); f == nil {
+		t.Fatal("referenced file collected")
+	}
+	if f, _ := s.GetById(orphan.Id); f != nil || exists(s, orphan.Key) {
+		t.Fatal("unreferenced file not collected")
+	}
+
+	// 更换头像后旧文件不再被引用
+	saved("USER_UPDATED", &user{Id: "u1", TenantId: "t1", Avatar: nil})
+	if refs, _ := s.GetReferences(avatar.Id); len(refs) != 0 {
+		t.Fatalf("stale references: %+v", refs)
+	}
+
+	// 回收前新增引用的文件不会被删除
+	if err := s.AddReference(avatar.Id, "tenant", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := s.remove(context.Background(), avatar, false); err != nil || removed {
+		t.Fatalf("referenced file removed: %v, %v", removed, err)
+	}
+	s.onEntityDeleted("tenant")("TENANT_DELETED", map[string]any{"id": "t1"})
+	if removed, err := s.remove(context.Background(), avatar, false); err != nil || !removed {
+		t.Fatalf("unreferenced file kept: %v, %v", removed, err)
+	}
+}
+
+func TestOpenResizesOnceAndServesOriginalWhenLarger(t *testing.T) {
+	s := newFileService(t)
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 300, 100))); err != nil {
+		t.Fatal(err)
+	}
+	record, err := s.Upload(fileHeader(t, "a.png", buffer.Bytes()), config.POLICY_IMAGE, "u1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Width != 300 || record.Height != 100 {
+		t.Fatalf("unexpected size %dx%d", record.Width, record.Height)
+	}
+
+	reader, info, err := s.Open(context.Background(), record, 1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Close()
+	if info.Key != record.Key {
+		t.Fatalf("expected original, got %s", info.Key)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reader, info, err := s.Open(context.Background(), record, 100, 100)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer reader.Close()
+			if cfg, _, err := image.DecodeConfig(reader); err != nil || cfg.Width != 128 || info.Key == record.Key {
+				t.Errorf("unexpected variant %+v %v", cfg, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 已知尺寸超过像素上限时不下载原图
+	defer func(pixels int64) { config.Setting.MaxPixels = pixels }(config.Setting.MaxPixels)
+	config.Setting.MaxPixels = 1000
+	if _, _, err := s.Open(context.Background(), record, 64, 64); err == nil {
+		t.Fatal("expected image too large")
+	}
+}
+
+// 未启用文件登记时返回 ErrFileStorageUnavailable，上传接口据此退回直接上传
+func TestSaveWhenDisabled(t *testing.T) {
+	defer func(enabled bool) { config.Setting.Enabled = enabled }(config.Setting.Enabled)
+	config.Setting.Enabled = false
+
+	if _, err := commonFileService.Save(fileHeader(t, "a.txt", []byte("a")), config.POLICY_FILE, "u1", "t1"); err != service.ErrFileStorageUnavailable {
+		t.Fatalf("expected ErrFileStorageUnavailable, got %v", err)
+	}
+}
+
+func TestEntityReferenceStaysInTenant(t *testing.T) {
+	s := newFileService(t)
+	content := []byte("shared across tenants")
+
+	first, err := s.Upload(fileHeader(t, "a.txt", content), config.POLICY_FILE, "u1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Upload(fileHeader(t, "b.txt", content), config.POLICY_FILE, "u2", "t2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := s.Upload(fileHeader(t, "c.txt", content), config.POLICY_FILE, "admin", "t2")
+	if err != nil || first.Url != second.Url || second.Url != admin.Url {
+		t.Fatalf("uploads = %+v, %+v, %+v, %v", first, second, admin, err)
+	}
+
+	// 相同地址按实体所在租户、优先修改人登记
+	saved := s.onEntitySaved("user", "avatar", "tenantId")
+	saved("USER_UPDATED", &user{Id: "u2", TenantId: "t2", Avatar: &second.Url})
+	if refs, _ := s.GetReferences(second.Id); len(refs) != 1 {
+		t.Fatalf("uploader references: %+v", refs)
+	}
+	saved("USER_UPDATED", &user{Id: "u2", TenantId: "t2", LastModifiedBy: "admin", Avatar: &second.Url})
+	if refs, _ := s.GetReferences(admin.Id); len(refs) != 1 {
+		t.Fatalf("modifier references: %+v", refs)
+	}
+	if refs, _ := s.GetReferences(first.Id); len(refs) != 0 {
+		t.Fatalf("reference landed in another tenant: %+v", refs)
+	}
+	// 租户内没有该地址的记录时不登记
+	saved("USER_UPDATED", &user{Id: "u3", TenantId: "t3", Avatar: &first.Url})
+	if refs, _ := s.GetReferences(first.Id); len(refs) != 0 {
+		t.Fatalf("reference landed in another tenant: %+v", refs)
+	}
+
+	// 删除其他租户的记录不影响引用和对象
+	if err := s.Delete(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(context.Background(), second); err != nil {
+		t.Fatal(err)
+	}
+	if refs, _ := s.GetReferences(admin.Id); len(refs) != 1 || !exists(s, admin.Key) {
+		t.Fatalf("referenced record lost: %+v", refs)
+	}
+}
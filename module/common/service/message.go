@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/gophab/gophrame/core/cron"
 	"github.com/gophab/gophrame/core/eventbus"
 	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/json"
 	"github.com/gophab/gophrame/core/query"
 	"github.com/gophab/gophrame/core/util"
 
@@ -26,8 +30,25 @@ func init() {
 
 	eventbus.RegisterEventListener("SYSTEM_MESSAGE_VIEWED", messageService.OnMessageViewed)
 
-	cron.AddFunc("@every 1m", messageService.ValidateMessages)
-	cron.AddFunc("@daily", messageService.HistoryMessages)
+	cron.MustRegister(&cron.Job{
+		Name:        "message.validate",
+		Description: "Publish messages reaching their valid time",
+		Spec:        "@every 1m",
+		Func: func(ctx context.Context) error {
+			messageService.ValidateMessages()
+			return nil
+		},
+	})
+	cron.MustRegister(&cron.Job{
+		Name:        "message.history",
+		Description: "Move expired messages to history",
+		Spec:        "@daily",
+		Misfire:     cron.MISFIRE_FIRE_ONCE,
+		Func: func(ctx context.Context) error {
+			messageService.HistoryMessages()
+			return nil
+		},
+	})
 }
 
 func (s *MessageService) GetById(id int64) (*domain.Message, error) {
@@ -35,40 +56,125 @@ func (s *MessageService) GetById(id int64) (*domain.Message, error) {
 }
 
 func (s *MessageService) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.Message, error) {
-	return s.MessageRepository.Find(util.DbFields(conds), pageable)
+	return withSenders(messageSender)(s.MessageRepository.Find(util.DbFields(conds), pageable))
 }
 
 func (s *MessageService) FindAvailable(conds map[string]any, pageable query.Pageable) (int64, []*domain.Message, error) {
-	return s.MessageRepository.FindAvailable(util.DbFields(conds), pageable)
+	return withSenders(messageSender)(s.MessageRepository.FindAvailable(util.DbFields(conds), pageable))
 }
 
 func (s *MessageService) FindSimples(conds map[string]any, pageable query.Pageable) (int64, []*domain.SimpleMessage, error) {
-	return s.MessageRepository.FindSimples(util.DbFields(conds), pageable)
+	return withSenders(simpleMessageSender)(s.MessageRepository.FindSimples(util.DbFields(conds), pageable))
 }
 
 func (s *MessageService) FindSimplesAvailable(conds map[string]any, pageable query.Pageable) (int64, []*domain.SimpleMessage, error) {
-	return s.MessageRepository.FindSimplesAvailable(util.DbFields(conds), pageable)
+	return withSenders(simpleMessageSender)(s.MessageRepository.FindSimplesAvailable(util.DbFields(conds), pageable))
+}
+
+func messageSender(m *domain.Message) (string, *any) {
+	return m.From, &m.Sender
+}
+
+func simpleMessageSender(m *domain.SimpleMessage) (string, *any) {
+	return m.From, &m.Sender
+}
+
+// 填充发送人（用户），整页只查询一次
+func withSenders[T any](sender func(*T) (string, *any)) func(int64, []*T, error) (int64, []*T, error) {
+	return func(count int64, list []*T, err error) (int64, []*T, error) {
+		if err != nil || len(list) == 0 {
+			return count, list, err
+		}
+
+		loader := service.GetEntityLoader()
+		for _, item := range list {
+			if from, _ := sender(item); from != "" && from != "SYSTEM" {
+				loader.Add("user", from)
+			}
+		}
+		loader.Load()
+
+		for _, item := range list {
+			if from, target := sender(item); from != "" && from != "SYSTEM" {
+				if user := loader.Get("user", from); user != nil {
+					*target = senderOf(user)
+				}
+			}
+		}
+		return count, list, err
+	}
+}
+
+// 只公开发送人的 id、名称和头像
+func senderOf(user any) map[string]any {
+	var fields map[string]any
+	if err := json.Json(json.String(user), &fields); err != nil {
+		return nil
+	}
+
+	var result = make(map[string]any)
+	for _, key := range []string{"id", "name", "avatar"} {
+		if v, b := fields[key]; b {
+			result[key] = v
+		}
+	}
+	return result
 }
 
 func (s *MessageService) CreateMessage(message *domain.Message) (*domain.Message, error) {
-	return s.MessageRepository.CreateMessage(message)
+	result, err := s.MessageRepository.CreateMessage(message)
+	if err == nil && !result.ValidTime.After(time.Now()) {
+		eventbus.DispatchEvent("SYSTEM_MESSAGE_CREATED", result)
+	}
+	return result, err
 }
 
 func (s *MessageService) UpdateMessage(message *domain.Message) (*domain.Message, error) {
-	return s.MessageRepository.UpdateMessage(message)
+	origin, _ := s.MessageRepository.GetById(message.Id)
+	result, err := s.MessageRepository.UpdateMessage(message)
+	if err == nil {
+		s.changed(origin, result)
+	}
+	return result, err
 }
 
 func (s *MessageService) PatchMessage(id int64, data map[string]any) (*domain.Message, error) {
-	return s.MessageRepository.PatchMessage(id, data)
+	origin, _ := s.MessageRepository.GetById(id)
+	result, err := s.MessageRepository.PatchMessage(id, data)
+	if err == nil {
+		s.changed(origin, result)
+	}
+	return result, err
 }
 
 func (s *MessageService) DeleteMessage(message *domain.Message) error {
-	return s.MessageRepository.DeleteMessage(message)
+	err := s.MessageRepository.DeleteMessage(message)
+	if err == nil {
+		s.changed(message)
+	}
+	return err
+}
+
+// 通知未读计数刷新
+func (s *MessageService) changed(messages ...*domain.Message) {
+	var args = make([]any, 0, len(messages))
+	for _, message := range messages {
+		if message != nil {
+			args = append(args, message)
+		}
+	}
+	if len(args) > 0 {
+		eventbus.DispatchEvent("SYSTEM_MESSAGE_CHANGED", args...)
+	}
 }
 
 func (s *MessageService) ValidateMessages() {
 	if s.MessageRepository != nil {
-		s.MessageRepository.ValidateMessages()
+		published, expired := s.MessageRepository.ValidateMessages()
+		for _, message := range published {
+			eventbus.DispatchEvent("SYSTEM_MESSAGE_CREATED", message)
+		}
+		s.changed(expired...)
 	}
 }
 
@@ -82,5 +188,7 @@ func (s *MessageService) OnMessageViewed(event string, argv ...any) {
 	var message = argv[0].(*domain.Message)
 	var userId = argv[1].(string)
 
-	s.MessageAccessLogRepository.AccessMessage(userId, "READ", message)
+	if s.MessageAccessLogRepository.AccessMessage(userId, "READ", message) {
+		eventbus.DispatchEvent("SYSTEM_MESSAGE_READ", message, userId)
+	}
 }
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/cron"
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/notification"
+	"github.com/gophab/gophrame/core/notification/config"
+	"github.com/gophab/gophrame/core/query"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
+	"github.com/gophab/gophrame/core/util"
+
+	"github.com/gophab/gophrame/domain"
+
+	CommonDomain "github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/repository"
+
+	"github.com/gophab/gophrame/service"
+)
+
+// 用户选项：语言，以及允许的通知渠道（逗号分隔，场景级优先于全局）
+const (
+	OPTION_LOCALE                = "locale"
+	OPTION_NOTIFICATION_CHANNELS = "notification.channels"
+)
+
+// 未配置渠道模板时使用的通用模板类型
+const TEMPLATE_TYPE_NOTIFICATION = "notification"
+
+// 发送认领时长，超时未完成（节点中断）的投递由重试任务重新发送
+const deliveryLease = time.Minute * 5
+
+type NotificationService struct {
+	service.BaseService
+	NotificationDeliveryRepository *repository.NotificationDeliveryRepository `inject:"notificationDeliveryRepository"`
+	ContentTemplateService         *ContentTemplateService                    `inject:"contentTemplateService"`
+	UserOptionService              *UserOptionService                         `inject:"userOptionService"`
+	LocaleFieldService             *LocaleFieldService                        `inject:"localeFieldService"`
+}
+
+var notificationService = &NotificationService{}
+
+func init() {
+	inject.InjectValue("notificationService", notificationService)
+
+	notification.RegisterChannel(&InboxChannel{MessageService: messageService})
+
+	eventbus.RegisterEventListener("SYSTEM_NOTIFICATION", notificationService.OnNotification)
+
+	cron.MustRegister(&cron.Job{
+		Name:        "notification.retry",
+		Description: "Retry failed notification deliveries",
+		Spec:        "@every 1m",
+		Func: func(ctx context.Context) error {
+			notificationService.RetryDeliveries(ctx)
+			return nil
+		},
+	})
+}
+
+// 站内信：为接收人创建私信
+type InboxChannel struct {
+	MessageService *MessageService
+}
+
+func (c *InboxChannel) Name() string {
+	return notification.CHANNEL_INBOX
+}
+
+func (c *InboxChannel) Send(ctx context.Context, recipient *notification.Recipient, message *notification.Message) error {
+	var inbox = &CommonDomain.Message{
+		MessageInfo: CommonDomain.MessageInfo{
+			From:    message.From,
+			To:      recipient.UserId,
+			Scope:   "PRIVATE",
+			Type:    "NOTICE",
+			Title:   message.Title,
+			Content: message.Content,
+		},
+	}
+	inbox.TenantId = recipient.TenantId
+
+	_, err := c.MessageService.CreateMessage(inbox)
+	return err
+}
+
+// 展开接收人，按用户偏好和语言渲染各渠道内容并记录投递，随后异步发送
+func (s *NotificationService) Notify(n *notification.Notification) error {
+	if !config.Setting.Enabled {
+		return notification.ErrUnavailable
+	}
+
+	tenantId := cmp.Or(n.TenantId, SecurityUtil.GetCurrentTenantId(nil), "SYSTEM")
+	recipients, err := notification.ResolveRecipients(tenantId, &n.Recipients)
+	if err != nil {
+		return err
+	}
+
+	channels := n.Channels
+	if len(channels) == 0 {
+		channels = config.Setting.Channels
+	}
+
+	var (
+		deliveries = make([]*CommonDomain.NotificationDelivery, 0, len(recipients)*len(channels))
+		targets    = make(map[*CommonDomain.NotificationDelivery]*notification.Recipient)
+		messages   = make(map[string]*notification.Message)
+		claimed    = time.Now().Add(deliveryLease)
+	)
+	for _, recipient := range recipients {
+		recipient.TenantId = cmp.Or(recipient.TenantId, tenantId)
+
+		var options *CommonDomain.UserOptions
+		if s.UserOptionService != nil {
+			options, _ = s.UserOptionService.GetUserOptions(recipient.UserId)
+		}
+		locale := option(options, OPTION_LOCALE)
+
+		for _, channel := range preferredChannels(options, n.Scene, channels) {
+			key := channel + "|" + recipient.TenantId + "|" + locale
+			message, b := messages[key]
+			if !b {
+				message = s.render(channel, n.Scene, recipient.TenantId, locale, n.Params)
+				messages[key] = message
+			}
+
+			delivery := &CommonDomain.NotificationDelivery{
+				Scene:         n.Scene,
+				Channel:       channel,
+				From:          n.From,
+				UserId:        recipient.UserId,
+				Locale:        locale,
+				Params:        params(n.Params),
+				Status:        CommonDomain.DELIVERY_SENDING,
+				NextRetryTime: &claimed,
+				TenantId:      recipient.TenantId,
+			}
+			if message == nil {
+				delivery.Status = CommonDomain.DELIVERY_SKIPPED
+				delivery.LastError = "content template not found"
+				delivery.NextRetryTime = nil
+			} else {
+				delivery.Title, delivery.Content, delivery.Template = message.Title, message.Content, message.Template
+				targets[delivery] = recipient
+			}
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	if err := s.NotificationDeliveryRepository.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+
+	go func() {
+		defer func() {
+			// 未完成的投递在认领到期后由重试任务发送
+			if r := recover(); r != nil {
+				logger.Error("Send notifications panic: ", n.Scene, fmt.Sprint(r))
+			}
+		}()
+		for _, delivery := range deliveries {
+			if recipient, b := targets[delivery]; b {
+				s.send(context.Background(), delivery, recipient)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *NotificationService) OnNotification(event string, args ...any) {
+	n, b := args[0].(*notification.Notification)
+	if !b || n == nil {
+		return
+	}
+
+	if err := s.Notify(n); err != nil {
+		logger.Error("Notify error: ", n.Scene, err.Error())
+	}
+}
+
+func (s *NotificationService) Find(conds map[string]any, pageable query.Pageable) (int64, []*CommonDomain.NotificationDelivery, error) {
+	return s.NotificationDeliveryRepository.Find(util.DbFields(conds), pageable)
+}
+
+// 重新发送到达重试时间的投递，接收人地址重新获取
+func (s *NotificationService) RetryDeliveries(ctx context.Context) {
+	if s.NotificationDeliveryRepository == nil || !config.Setting.Enabled {
+		return
+	}
+
+	deliveries, err := s.NotificationDeliveryRepository.FindRetryable(100)
+	if err != nil {
+		logger.Error("Find retryable notifications error: ", err.Error())
+		return
+	}
+
+	for _, delivery := range deliveries {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if ok, err := s.NotificationDeliveryRepository.Claim(delivery, deliveryLease); err != nil {
+			logger.Error("Claim notification delivery error: ", delivery.Id, err.Error())
+			continue
+		} else if !ok {
+			// 已被其他节点认领
+			continue
+		}
+
+		recipients, err := notification.ResolveRecipients(delivery.TenantId, &notification.Recipients{Users: []string{delivery.UserId}})
+		if err != nil {
+			// 认领到期后重新发送
+			logger.Warn("Resolve notification recipient error: ", delivery.UserId, err.Error())
+			continue
+		}
+
+		var recipient *notification.Recipient
+		if len(recipients) > 0 {
+			recipient = recipients[0]
+			recipient.TenantId = cmp.Or(recipient.TenantId, delivery.TenantId)
+		}
+		s.send(ctx, delivery, recipient)
+	}
+}
+
+func (s *NotificationService) send(ctx context.Context, delivery *CommonDomain.NotificationDelivery, recipient *notification.Recipient) {
+	var err error
+	if channel := notification.GetChannel(delivery.Channel); channel == nil {
+		err = notification.ErrUnavailable
+	} else if recipient == nil {
+		err = notification.ErrNoAddress
+	} else {
+		message := &notification.Message{
+			Scene:    delivery.Scene,
+			From:     delivery.From,
+			Title:    delivery.Title,
+			Content:  delivery.Content,
+			Template: delivery.Template,
+			Params:   make(map[string]string),
+		}
+		if delivery.Params != nil {
+			for k, v := range *delivery.Params {
+				message.Params[k] = fmt.Sprint(v)
+			}
+		}
+		err = deliver(ctx, channel, recipient, message)
+	}
+
+	delivery.Attempts++
+	delivery.NextRetryTime = nil
+	switch {
+	case err == nil:
+		delivery.Status = CommonDomain.DELIVERY_SENT
+		delivery.SentTime = util.TimeAddr(time.Now())
+		delivery.LastError = ""
+	case notification.IsPermanent(err):
+		delivery.Status = CommonDomain.DELIVERY_SKIPPED
+		delivery.LastError = err.Error()
+	case delivery.Attempts > config.Setting.MaxRetries:
+		delivery.Status = CommonDomain.DELIVERY_FAILED
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = CommonDomain.DELIVERY_PENDING
+		delivery.LastError = err.Error()
+		delivery.NextRetryTime = util.TimeAddr(time.Now().Add(s.retryInterval(delivery.Attempts)))
+	}
+
+	if err != nil && delivery.Status != CommonDomain.DELIVERY_SKIPPED {
+		logger.Warn("Send notification error: ", delivery.Channel, delivery.UserId, err.Error())
+	}
+
+	if err := s.NotificationDeliveryRepository.UpdateDelivery(delivery); err != nil {
+		logger.Error("Update notification delivery error: ", delivery.Id, err.Error())
+	}
+}
+
+// 渠道实现的异常视为发送失败，按重试规则处理
+func deliver(ctx context.Context, channel notification.Channel, recipient *notification.Recipient, message *notification.Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("channel %s panic: %v", channel.Name(), r)
+		}
+	}()
+	return channel.Send(ctx, recipient, message)
+}
+
+// 第 n 次重试前的等待时间，逐次翻倍
+func (s *NotificationService) retryInterval(attempts int) time.Duration {
+	return time.Duration(max(config.Setting.RetryInterval, 1)) * time.Second << min(max(attempts-1, 0), 10)
+}
+
+// 优先使用渠道类型的模板，其次为通用通知模板；按语言取模板的翻译
+func (s *NotificationService) render(channel, scene, tenantId, locale string, params map[string]string) *notification.Message {
+	if s.ContentTemplateService == nil {
+		return nil
+	}
+
+	template, err := s.ContentTemplateService.GetByTypeAndSceneAndTenantId(channel, scene, tenantId)
+	if err == nil && template == nil {
+		template, err = s.ContentTemplateService.GetByTypeAndSceneAndTenantId(TEMPLATE_TYPE_NOTIFICATION, scene, tenantId)
+	}
+	if err != nil || template == nil {
+		return nil
+	}
+
+	title, content := template.Title, template.Content
+	if locale != "" && s.LocaleFieldService != nil {
+		for _, field := range s.LocaleFieldService.LoadTranslations(locale, "ContentTemplate", template.Id)[template.Id] {
+			switch field.Name {
+			case "Title":
+				title = field.Value
+			case "Content":
+				content = field.Value
+			}
+		}
+	}
+
+	if params == nil {
+		params = make(map[string]string)
+	}
+	return &notification.Message{
+		Scene:    scene,
+		Title:    util.FormatParamterContent(title, params),
+		Content:  util.FormatParamterContent(content, params),
+		Template: template.Property("template").AsString(),
+		Params:   params,
+	}
+}
+
+func option(options *CommonDomain.UserOptions, name string) string {
+	if options != nil {
+		if value, b := options.GetOption(name); b {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// 请求的渠道与用户允许渠道的交集，用户未设置时为请求的渠道
+func preferredChannels(options *CommonDomain.UserOptions, scene string, channels []string) []string {
+	preference, b := "", false
+	if options != nil {
+		if preference, b = options.GetOption("notification." + scene + ".channels"); !b {
+			preference, b = options.GetOption(OPTION_NOTIFICATION_CHANNELS)
+		}
+	}
+	if !b {
+		return channels
+	}
+
+	allowed := strings.Split(preference, ",")
+	for i := range allowed {
+		allowed[i] = strings.TrimSpace(allowed[i])
+	}
+
+	result := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		if slices.Contains(allowed, channel) {
+			result = append(result, channel)
+		}
+	}
+	return result
+}
+
+func params(values map[string]string) *domain.Properties {
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make(domain.Properties, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+	return &result
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gophab/gophrame/core/notification"
+)
+
+type testChannel struct {
+	send func() error
+}
+
+func (c *testChannel) Name() string {
+	return "test"
+}
+
+func (c *testChannel) Send(ctx context.Context, recipient *notification.Recipient, message *notification.Message) error {
+	return c.send()
+}
+
+func TestDeliverRecoversChannelPanic(t *testing.T) {
+	channel := &testChannel{send: func() error { panic("boom") }}
+	err := deliver(context.Background(), channel, &notification.Recipient{}, &notification.Message{})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected panic error, got %v", err)
+	}
+
+	failed := errors.New("failed")
+	channel.send = func() error { return failed }
+	if err := deliver(context.Background(), channel, &notification.Recipient{}, &notification.Message{}); err != failed {
+		t.Fatalf("expected channel error, got %v", err)
+	}
+}
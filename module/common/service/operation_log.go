@@ -1,7 +1,12 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/excel"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/query"
 	SecurityUtil "github.com/gophab/gophrame/core/security/util"
@@ -11,8 +16,27 @@ import (
 	"github.com/gophab/gophrame/module/common/repository"
 
 	"github.com/gophab/gophrame/service"
+
+	"gorm.io/gorm"
 )
 
+const TASK_OPERATION_LOG_EXPORT = "common.operation-log.export"
+
+type ExportPayload struct {
+	excel.ExportOptions
+	Conds map[string]any `json:"conds,omitempty"`
+}
+
+// 导出的操作日志
+type OperationLogExportRow struct {
+	OperatedTime time.Time `excel:"operatedTime,alias=时间,width=20"`
+	Operator     string    `excel:"operator,alias=操作人,width=16"`
+	Operation    string    `excel:"operation,alias=操作,width=12"`
+	Target       string    `excel:"target,alias=对象,width=16"`
+	TargetId     string    `excel:"targetId,alias=对象Id,width=24"`
+	Text         string    `excel:"text,alias=内容,width=60"`
+}
+
 type OperationLogService struct {
 	service.BaseService
 	OperationLogRepository *repository.OperationLogRepository `inject:"operationLogRepository"`
@@ -25,6 +49,7 @@ func init() {
 	inject.InjectValue("operationLogService", operationLogService)
 
 	eventbus.RegisterEventListener("SYSTEM_LOG_OPERATION", operationLogService.logOperation)
+	service.RegisterTaskHandler(TASK_OPERATION_LOG_EXPORT, operationLogService.export)
 }
 
 func (s *OperationLogService) Find(conds map[string]any, pageable query.Pageable) (int64, []*domain.OperationLog, error) {
@@ -33,12 +58,33 @@ func (s *OperationLogService) Find(conds map[string]any, pageable query.Pageable
 		return count, list, err
 	}
 
+	s.format(list, service.GetEntityLoader())
+	return count, list, err
+}
+
+// 组合操作日志文本
+func (s *OperationLogService) format(list []*domain.OperationLog, loader *service.EntityLoader) {
+	// 批量加载关联实体，每类实体一次查询
+	for _, operationLog := range list {
+		loader.Add("user", operationLog.OperatorId)
+		if operationLog.TenantId != "" && operationLog.TenantId != "SYSTEM" {
+			loader.Add("tenant", operationLog.TenantId)
+		}
+		if operationLog.Target != "" {
+			loader.Add(operationLog.Target, operationLog.TargetId)
+		}
+		if operationLog.Location != "" {
+			loader.Add(operationLog.Location, operationLog.LocationId)
+		}
+	}
+	loader.Load()
+
 	// 组装
 	for _, operationLog := range list {
 		// i18n: template.Content
 		params := make(map[string]any)
 		// 1. user
-		entity := GetEntity("user", operationLog.OperatorId)
+		entity := loader.Get("user", operationLog.OperatorId)
 		if entity != nil {
 			params["operator"] = entity
 		} else {
@@ -47,7 +93,7 @@ func (s *OperationLogService) Find(conds map[string]any, pageable query.Pageable
 
 		// 2. tenant
 		if operationLog.TenantId != "" && operationLog.TenantId != "SYSTEM" {
-			entity = GetEntity("tenant", operationLog.TenantId)
+			entity = loader.Get("tenant", operationLog.TenantId)
 			if entity != nil {
 				params["tenant"] = entity
 			} else {
@@ -57,7 +103,7 @@ func (s *OperationLogService) Find(conds map[string]any, pageable query.Pageable
 
 		// 3. target
 		if operationLog.Target != "" {
-			entity = GetEntity(operationLog.Target, operationLog.TargetId)
+			entity = loader.Get(operationLog.Target, operationLog.TargetId)
 			if entity != nil {
 				params["target"] = entity
 			} else {
@@ -67,7 +113,7 @@ func (s *OperationLogService) Find(conds map[string]any, pageable query.Pageable
 
 		// 4. Location
 		if operationLog.Location != "" {
-			entity = GetEntity(operationLog.Location, operationLog.LocationId)
+			entity = loader.Get(operationLog.Location, operationLog.LocationId)
 			if entity != nil {
 				params["location"] = entity
 			} else {
@@ -92,8 +138,6 @@ func (s *OperationLogService) Find(conds map[string]any, pageable query.Pageable
 			operationLog.Text = util.FormatParamterContentEx(operationLog.Content, params)
 		}
 	}
-
-	return count, list, err
 }
 
 func (s *OperationLogService) Append(log *domain.OperationLog) {
@@ -127,3 +171,57 @@ func (s *OperationLogService) logOperation(event string, args ...any) {
 		s.Append(operationLog)
 	}
 }
+
+// 提交导出任务，返回任务 Id
+func (s *OperationLogService) SubmitExport(createdBy string, conds map[string]any, options excel.ExportOptions) (string, error) {
+	taskService := service.GetTaskService()
+	if taskService == nil {
+		return "", errors.New("task service not available")
+	}
+	return taskService.Submit(createdBy, TASK_OPERATION_LOG_EXPORT, "导出操作日志", &ExportPayload{ExportOptions: options, Conds: util.DbFields(conds)})
+}
+
+func (s *OperationLogService) export(ctx service.TaskContext) error {
+	var payload ExportPayload
+	if err := ctx.Bind(&payload); err != nil {
+		return err
+	}
+
+	total, err := s.OperationLogRepository.GetCount(payload.Conds)
+	if err != nil {
+		return err
+	}
+
+	logs := excel.GormSource(func() *gorm.DB {
+		return s.OperationLogRepository.QueryBy(payload.Conds)
+	}, []string{"operated_time", "id"}, true, func(log *domain.OperationLog) []any { return []any{log.OperatedTime, log.Id} })
+
+	source := func(cursor any, limit int) ([]*OperationLogExportRow, any, error) {
+		list, next, err := logs(cursor, limit)
+		if err != nil || len(list) == 0 {
+			return nil, next, err
+		}
+
+		loader := service.NewEntityLoader()
+		s.format(list, loader)
+
+		var rows = make([]*OperationLogExportRow, len(list))
+		for i, log := range list {
+			rows[i] = &OperationLogExportRow{
+				OperatedTime: log.OperatedTime,
+				Operator:     log.OperatorId,
+				Operation:    log.Operation,
+				Target:       log.Target,
+				TargetId:     log.TargetId,
+				Text:         log.Text,
+			}
+			if name, b := util.GetRecordField(util.Struct2Map(loader.Get("user", log.OperatorId)), "name"); b {
+				rows[i].Operator = fmt.Sprint(name)
+			}
+		}
+		return rows, next, nil
+	}
+
+	_, err = excel.ExportTask(ctx, source, nil, payload.ExportOptions, total)
+	return err
+}
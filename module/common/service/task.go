@@ -1,6 +1,7 @@
 package service
 
 import (
+	"github.com/gophab/gophrame/core/eventbus"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/query"
 	"github.com/gophab/gophrame/module/common/domain"
@@ -39,6 +40,15 @@ func (r *TaskService) DeleteTask(task *domain.Task) (*domain.Task, error) {
 	return r.TaskRepository.DeleteTask(task)
 }
 
+// 请求取消任务，执行中的任务在所在节点中断
+func (r *TaskService) CancelTask(task *domain.Task) (*domain.Task, error) {
+	if err := r.TaskRepository.RequestCancel(task.Id); err != nil {
+		return nil, err
+	}
+	eventbus.DispatchEvent("SYSTEM_TASK_CANCEL", task.Id)
+	return r.TaskRepository.GetById(task.Id)
+}
+
 func (r *TaskService) FinishTask(task *domain.Task) (*domain.Task, error) {
 
 	if res := r.TaskRepository.Save(task); res.Error == nil && res.RowsAffected > 0 {
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis"
+	RedisConfig "github.com/gophab/gophrame/core/redis/config"
+	"github.com/gophab/gophrame/core/websocket"
+
+	"github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/repository"
+
+	"github.com/gophab/gophrame/service"
+
+	Redigo "github.com/gomodule/redigo/redis"
+	"github.com/patrickmn/go-cache"
+)
+
+// 推送类型
+const (
+	PUSH_MESSAGE = "message"
+	PUSH_EVENT   = "event"
+	PUSH_UNREAD  = "unread"
+	PUSH_TASK    = "task"
+)
+
+// 计数缓存有效期，过期后从数据库重新统计
+const UNREAD_EXPIRATION = time.Hour
+
+type UnreadCount struct {
+	Message int64 `json:"message"`
+	Event   int64 `json:"event"`
+}
+
+// 经 websocket / SSE 推送给用户的内容
+type InboxPush struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// 未读计数：按用户缓存（启用 Redis 时保存在 Redis，否则在本地），私信和事件增量更新；
+// 企业内部和公共消息变化时递增对应租户的版本号，缓存的版本号不一致时重新统计
+type UnreadService struct {
+	service.BaseService
+	MessageRepository *repository.MessageRepository `inject:"messageRepository"`
+	EventRepository   *repository.EventRepository   `inject:"eventRepository"`
+	local             *localUnreadStore
+}
+
+var unreadService = &UnreadService{local: newLocalUnreadStore()}
+
+func init() {
+	inject.InjectValue("unreadService", unreadService)
+
+	eventbus.RegisterEventListener("SYSTEM_MESSAGE_CREATED", unreadService.OnMessageCreated)
+	eventbus.RegisterEventListener("SYSTEM_MESSAGE_READ", unreadService.OnMessageRead)
+	eventbus.RegisterEventListener("SYSTEM_MESSAGE_CHANGED", unreadService.OnMessageChanged)
+	eventbus.RegisterEventListener("SYSTEM_EVENT_CREATED", unreadService.OnEventCreated)
+	eventbus.RegisterEventListener("ON_ACCESS_EVENT_CENTER", unreadService.OnAccessEventCenter)
+}
+
+func (s *UnreadService) store() unreadStore {
+	if RedisConfig.Setting.Enabled {
+		return redisUnreadStore{}
+	}
+	return s.local
+}
+
+func (s *UnreadService) GetUnread(userId, tenantId string) (*UnreadCount, error) {
+	store := s.store()
+	generation := store.Generation(tenantId)
+	if count, gen, b := store.Get(userId); b && gen == generation {
+		return count, nil
+	}
+
+	messages, err := s.MessageRepository.CountUnread(userId, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.EventRepository.CountUnread(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	count := &UnreadCount{Message: messages, Event: events}
+	store.Set(userId, count, generation)
+	return count, nil
+}
+
+func (s *UnreadService) OnMessageCreated(event string, args ...any) {
+	message, b := args[0].(*domain.Message)
+	if !b || message == nil {
+		return
+	}
+
+	push := &InboxPush{Type: PUSH_MESSAGE, Data: message}
+	switch message.Scope {
+	case "PRIVATE":
+		s.store().Incr(message.To, "message", 1)
+		s.push(message.To, push)
+		s.pushUnread(message.To, message.TenantId)
+	case "TENANT":
+		s.store().Bump(message.TenantId)
+		s.broadcast(message.TenantId, push)
+	case "PUBLIC":
+		s.store().Bump("SYSTEM")
+		s.broadcast("", push)
+	}
+}
+
+func (s *UnreadService) OnMessageRead(event string, args ...any) {
+	message, b := args[0].(*domain.Message)
+	userId, _ := args[1].(string)
+	if !b || message == nil || userId == "" {
+		return
+	}
+
+	s.store().Incr(userId, "message", -1)
+	s.pushUnread(userId, message.TenantId)
+}
+
+// 消息修改、删除或过期：私信重新统计接收人，其余递增租户版本号
+func (s *UnreadService) OnMessageChanged(event string, args ...any) {
+	for _, arg := range args {
+		message, b := arg.(*domain.Message)
+		if !b || message == nil {
+			continue
+		}
+
+		switch message.Scope {
+		case "PRIVATE":
+			s.store().Delete(message.To)
+			s.pushUnread(message.To, message.TenantId)
+		case "TENANT":
+			s.store().Bump(message.TenantId)
+		case "PUBLIC":
+			s.store().Bump("SYSTEM")
+		}
+	}
+}
+
+func (s *UnreadService) OnEventCreated(event string, args ...any) {
+	data, b := args[0].(*domain.Event)
+	if !b || data == nil {
+		return
+	}
+
+	push := &InboxPush{Type: PUSH_EVENT, Data: data}
+	switch data.Scope {
+	case "PRIVATE":
+		if data.Target != "" {
+			s.store().Incr(data.Target, "event", 1)
+			s.push(data.Target, push)
+			s.pushUnread(data.Target, data.TenantId)
+		}
+	case "TENANT":
+		s.broadcast(data.TenantId, push)
+	}
+}
+
+// 进入事件中心后事件全部视为已读
+func (s *UnreadService) OnAccessEventCenter(event string, args ...any) {
+	userId, _ := args[0].(string)
+	if userId == "" {
+		return
+	}
+
+	s.store().Reset(userId, "event")
+	if count, _, b := s.store().Get(userId); b {
+		s.push(userId, &InboxPush{Type: PUSH_UNREAD, Data: count})
+	}
+}
+
+// 仅在用户在线时统计并推送
+func (s *UnreadService) pushUnread(userId, tenantId string) {
+	if userId == "" || !websocket.IsOnline(userId) {
+		return
+	}
+
+	if count, err := s.GetUnread(userId, tenantId); err == nil {
+		s.push(userId, &InboxPush{Type: PUSH_UNREAD, Data: count})
+	} else {
+		logger.Warn("Count unread error: ", userId, err.Error())
+	}
+}
+
+func (s *UnreadService) push(userId string, push *InboxPush) {
+	if err := websocket.SendToUser(userId, push); err != nil {
+		logger.Warn("Push inbox error: ", userId, err.Error())
+	}
+}
+
+func (s *UnreadService) broadcast(tenantId string, push *InboxPush) {
+	var err error
+	if tenantId == "" {
+		err = websocket.Broadcast(push)
+	} else {
+		err = websocket.SendToTenant(tenantId, push)
+	}
+	if err != nil {
+		logger.Warn("Push inbox error: ", tenantId, err.Error())
+	}
+}
+
+type unreadStore interface {
+	// 缓存的计数及其版本号
+	Get(userId string) (*UnreadCount, string, bool)
+	Set(userId string, count *UnreadCount, generation string)
+	// 只更新已缓存的计数，结果不小于 0
+	Incr(userId string, field string, delta int64)
+	Reset(userId string, field string)
+	Delete(userId string)
+	// 用户所在租户与公共消息的版本号
+	Generation(tenantId string) string
+	Bump(tenantId string)
+}
+
+func unreadKey(userId string) string {
+	return "unread:" + userId
+}
+
+func generationKey(tenantId string) string {
+	return "unread:gen:" + tenantId
+}
+
+type redisUnreadStore struct{}
+
+const redisIncrScript = `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	if redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2]) < 0 then
+		redis.call('HSET', KEYS[1], ARGV[1], 0)
+	end
+end
+return 1`
+
+const redisResetScript = `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HSET', KEYS[1], ARGV[1], 0)
+end
+return 1`
+
+func (redisUnreadStore) execute(cmd string, args ...any) (any, error) {
+	client := redis.GetOneRedisClient()
+	if client == nil {
+		return nil, fmt.Errorf("redis unavailable")
+	}
+	defer client.ReleaseOneRedisClient()
+
+	return client.Execute(cmd, args...)
+}
+
+func (r redisUnreadStore) Get(userId string) (*UnreadCount, string, bool) {
+	values, err := Redigo.Strings(r.execute("HMGET", unreadKey(userId), "message", "event", "gen"))
+	if err != nil || len(values) != 3 || values[0] == "" {
+		return nil, "", false
+	}
+
+	var count UnreadCount
+	count.Message, _ = strconv.ParseInt(values[0], 10, 64)
+	count.Event, _ = strconv.ParseInt(values[1], 10, 64)
+	return &count, values[2], true
+}
+
+func (r redisUnreadStore) Set(userId string, count *UnreadCount, generation string) {
+	key := unreadKey(userId)
+	if _, err := r.execute("HSET", key, "message", count.Message, "event", count.Event, "gen", generation); err == nil {
+		r.execute("EXPIRE", key, int64(UNREAD_EXPIRATION/time.Second))
+	}
+}
+
+func (r redisUnreadStore) Incr(userId string, field string, delta int64) {
+	r.execute("EVAL", redisIncrScript, 1, unreadKey(userId), field, delta)
+}
+
+func (r redisUnreadStore) Reset(userId string, field string) {
+	r.execute("EVAL", redisResetScript, 1, unreadKey(userId), field)
+}
+
+func (r redisUnreadStore) Delete(userId string) {
+	r.execute("DEL", unreadKey(userId))
+}
+
+func (r redisUnreadStore) Generation(tenantId string) string {
+	values, _ := Redigo.Strings(r.execute("MGET", generationKey(tenantId), generationKey("SYSTEM")))
+	if len(values) != 2 {
+		return ""
+	}
+	return values[0] + ":" + values[1]
+}
+
+func (r redisUnreadStore) Bump(tenantId string) {
+	r.execute("INCR", generationKey(tenantId))
+}
+
+type unreadEntry struct {
+	count      UnreadCount
+	generation string
+}
+
+type localUnreadStore struct {
+	mutex       sync.Mutex
+	entries     *cache.Cache
+	generations map[string]int64
+}
+
+func newLocalUnreadStore() *localUnreadStore {
+	return &localUnreadStore{
+		entries:     cache.New(UNREAD_EXPIRATION, 10*time.Minute),
+		generations: make(map[string]int64),
+	}
+}
+
+func (l *localUnreadStore) Get(userId string) (*UnreadCount, string, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if value, b := l.entries.Get(userId); b {
+		entry := value.(*unreadEntry)
+		count := entry.count
+		return &count, entry.generation, true
+	}
+	return nil, "", false
+}
+
+func (l *localUnreadStore) Set(userId string, count *UnreadCount, generation string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.entries.Set(userId, &unreadEntry{count: *count, generation: generation}, cache.DefaultExpiration)
+}
+
+func (l *localUnreadStore) Incr(userId string, field string, delta int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if value, b := l.entries.Get(userId); b {
+		entry := value.(*unreadEntry)
+		switch field {
+		case "message":
+			entry.count.Message = max(entry.count.Message+delta, 0)
+		case "event":
+			entry.count.Event = max(entry.count.Event+delta, 0)
+		}
+	}
+}
+
+func (l *localUnreadStore) Reset(userId string, field string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if value, b := l.entries.Get(userId); b {
+		entry := value.(*unreadEntry)
+		switch field {
+		case "message":
+			entry.count.Message = 0
+		case "event":
+			entry.count.Event = 0
+		}
+	}
+}
+
+func (l *localUnreadStore) Delete(userId string) {
+	l.entries.Delete(userId)
+}
+
+func (l *localUnreadStore) Generation(tenantId string) string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return strconv.FormatInt(l.generations[tenantId], 10) + ":" + strconv.FormatInt(l.generations["SYSTEM"], 10)
+}
+
+func (l *localUnreadStore) Bump(tenantId string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.generations[tenantId]++
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 任务队列
+const (
+	QUEUE_DB       = "db"
+	QUEUE_RABBITMQ = "rabbitmq"
+)
+
+type TaskSetting struct {
+	Enabled       bool   `json:"enabled" yaml:"enabled"`             // 本节点是否执行任务；未开启时提交的任务由开启的节点执行
+	Workers       int    `json:"workers" yaml:"workers"`             // 本节点并发执行的任务数
+	Queue         string `json:"queue" yaml:"queue"`                 // db 或 rabbitmq；rabbitmq 只用于唤醒，任务仍以数据库为准
+	QueueName     string `json:"queueName" yaml:"queueName"`         // rabbitmq 队列名
+	PollInterval  int    `json:"pollInterval" yaml:"pollInterval"`   // 轮询数据库的间隔（秒）
+	Lease         int    `json:"lease" yaml:"lease"`                 // 租约时长（秒），执行期间每 1/3 租约续约一次
+	MaxAttempts   int    `json:"maxAttempts" yaml:"maxAttempts"`     // 任务未指定时的最大执行次数
+	RetryInterval int    `json:"retryInterval" yaml:"retryInterval"` // 首次重试间隔（秒），之后逐次翻倍
+	ResultExpires int    `json:"resultExpires" yaml:"resultExpires"` // 结果文件签名链接的有效期（秒），对象存储支持签名时生效
+}
+
+var Setting *TaskSetting = &TaskSetting{
+	Enabled:       false,
+	Workers:       4,
+	Queue:         QUEUE_DB,
+	QueueName:     "gophrame.task",
+	PollInterval:  5,
+	Lease:         60,
+	MaxAttempts:   3,
+	RetryInterval: 30,
+	ResultExpires: 86400,
+}
+
+func init() {
+	logger.Debug("Register Task Config")
+	config.RegisterConfig("task", Setting, "Task Settings")
+}
@@ -0,0 +1,130 @@
+package task
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/gophab/gophrame/core/json"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/service"
+
+	"github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/task/config"
+)
+
+var _ service.TaskContext = (*Context)(nil)
+
+// 任务执行上下文：任务被取消、租约丢失或节点停止时 Done() 关闭
+type Context struct {
+	context.Context
+	Task *domain.Task
+
+	runner *Runner
+	mode   string
+	result string
+}
+
+// 解析提交时的参数
+func (c *Context) Bind(payload any) error {
+	if c.Task.Payload == nil {
+		return nil
+	}
+	return json.Json(*c.Task.Payload, payload)
+}
+
+// 更新进度（0-100）并推送给任务创建人
+func (c *Context) Progress(progress float32, remark string) error {
+	data := map[string]any{"progress": progress}
+	if remark != "" {
+		data["remark"] = remark
+	}
+
+	held, err := c.runner.TaskRepository.UpdateRunning(c.Task.Id, c.runner.worker, data)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return ErrLeaseLost
+	}
+
+	c.Task.Progress = progress
+	if remark != "" {
+		c.Task.Remark = util.StringAddr(remark)
+	}
+	c.runner.push(c.Task)
+	return nil
+}
+
+// 保存执行进度，只在仍持有租约时写入
+func (c *Context) Checkpoint(state any) error {
+	checkpoint := json.String(state)
+	held, err := c.runner.TaskRepository.UpdateRunning(c.Task.Id, c.runner.worker, map[string]any{"checkpoint": checkpoint})
+	if err != nil {
+		return err
+	}
+	if !held {
+		return ErrLeaseLost
+	}
+
+	c.Task.Checkpoint = util.StringAddr(checkpoint)
+	return nil
+}
+
+// 读取上次执行保存的进度
+func (c *Context) Restore(state any) (bool, error) {
+	if c.Task.Checkpoint == nil || *c.Task.Checkpoint == "" {
+		return false, nil
+	}
+	return true, json.Json(*c.Task.Checkpoint, state)
+}
+
+// 已取消或执行次数已用尽
+func (c *Context) LastAttempt() bool {
+	return context.Cause(c) == ErrCancelled || c.Task.Attempts >= cmpOr(c.Task.MaxAttempts, config.Setting.MaxAttempts)
+}
+
+// 文本结果
+func (c *Context) SetResult(result string) {
+	c.mode, c.result = "text", result
+}
+
+// 上传本地结果文件到对象存储并作为任务结果，上传后删除本地文件；
+// ResultExpires 大于 0 时结果为该有效期的签名链接
+func (c *Context) SetResultFile(fileName string, prefix string) (string, error) {
+	url, key, err := c.upload(fileName, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	if config.Setting.ResultExpires > 0 {
+		if signed, err := c.runner.Oss.PresignGet(key, time.Duration(config.Setting.ResultExpires)*time.Second); err == nil {
+			url = signed
+		} else {
+			logger.Warn("Sign task result error: ", c.Task.Id, err.Error())
+		}
+	}
+
+	c.mode, c.result = "file", url
+	return url, nil
+}
+
+// 上传本地文件到对象存储，上传后删除本地文件
+func (c *Context) UploadFile(fileName string, prefix string) (string, error) {
+	url, _, err := c.upload(fileName, prefix)
+	return url, err
+}
+
+func (c *Context) upload(fileName string, prefix string) (string, string, error) {
+	if c.runner.Oss == nil {
+		return "", "", ErrNoStorage
+	}
+
+	url, key, err := c.runner.Oss.UploadLocal(fileName, prefix)
+	if err != nil {
+		return "", "", err
+	}
+	_ = os.Remove(fileName)
+	return url, key, nil
+}
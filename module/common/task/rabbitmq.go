@@ -0,0 +1,116 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	RabbitConfig "github.com/gophab/gophrame/core/rabbitmq/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQ 队列只传递任务 Id 用于及时唤醒，任务的领取仍以数据库条件更新为准
+type rabbitQueue struct {
+	name    string
+	mutex   sync.Mutex
+	connect *amqp.Connection
+	channel *amqp.Channel
+}
+
+func newRabbitQueue(name string) *rabbitQueue {
+	return &rabbitQueue{name: name}
+}
+
+func (q *rabbitQueue) open() (*amqp.Connection, *amqp.Channel, error) {
+	connect, err := amqp.Dial(RabbitConfig.Setting.Addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	channel, err := connect.Channel()
+	if err == nil {
+		_, err = channel.QueueDeclare(q.name, true, false, false, false, nil)
+	}
+	if err != nil {
+		_ = connect.Close()
+		return nil, nil, err
+	}
+	return connect, channel, nil
+}
+
+func (q *rabbitQueue) Publish(id string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.channel == nil || q.channel.IsClosed() {
+		if q.connect != nil {
+			_ = q.connect.Close()
+		}
+		connect, channel, err := q.open()
+		if err != nil {
+			return err
+		}
+		q.connect, q.channel = connect, channel
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return q.channel.PublishWithContext(ctx, "", q.name, false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "text/plain",
+		Body:         []byte(id),
+	})
+}
+
+// 断线后自动重连，直到 ctx 结束
+func (q *rabbitQueue) Consume(ctx context.Context, callback func(id string)) {
+	for {
+		if err := q.consume(ctx, callback); err != nil {
+			logger.Warn("Consume task queue error: ", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (q *rabbitQueue) consume(ctx context.Context, callback func(id string)) error {
+	connect, channel, err := q.open()
+	if err != nil {
+		return err
+	}
+	defer connect.Close()
+
+	deliveries, err := channel.Consume(q.name, "", true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return amqp.ErrClosed
+			}
+			callback(string(delivery.Body))
+		}
+	}
+}
+
+func (q *rabbitQueue) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.connect != nil {
+		_ = q.connect.Close()
+		q.connect, q.channel = nil, nil
+	}
+}
@@ -0,0 +1,408 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/json"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/starter"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/core/websocket"
+	CommonService "github.com/gophab/gophrame/service"
+
+	"github.com/gophab/gophrame/module/common/domain"
+	"github.com/gophab/gophrame/module/common/repository"
+	"github.com/gophab/gophrame/module/common/service"
+	"github.com/gophab/gophrame/module/common/task/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCancelled = errors.New("task cancelled")
+	ErrLeaseLost = errors.New("task lease lost")
+	ErrShutdown  = errors.New("task runner shutdown")
+	ErrNoHandler = errors.New("task handler not registered")
+	ErrNoStorage = errors.New("oss not available")
+)
+
+// 任务处理器：应通过 ctx.Done() 响应取消，返回错误时按配置重试
+type Handler func(ctx *Context) error
+
+// 推送给任务创建人的进度
+type TaskProgress struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name"`
+	Handler  string  `json:"handler"`
+	Status   int     `json:"status"`
+	Progress float32 `json:"progress"`
+	Remark   string  `json:"remark,omitempty"`
+	Mode     string  `json:"mode,omitempty"`
+	Result   string  `json:"result,omitempty"`
+}
+
+// 任务执行器：任务保存在 sys_task，各节点以租约领取并定期续约，
+// 租约过期（节点失联或重启）的任务会被重新领取
+type Runner struct {
+	TaskRepository *repository.TaskRepository `inject:"taskRepository"`
+	Oss            oss.OSS                    `inject:"oss,optional"`
+
+	worker   string
+	handlers sync.Map
+	running  sync.Map
+	wakeup   chan string
+	slots    chan struct{}
+	queue    *rabbitQueue
+	stop     context.CancelCauseFunc
+	wg       sync.WaitGroup
+}
+
+var runner = &Runner{
+	worker: uuid.NewString(),
+	wakeup: make(chan string, 64),
+}
+
+func init() {
+	inject.InjectValue("taskRunner", runner)
+	inject.InjectValue("commonTaskService", commonTaskService)
+	eventbus.RegisterEventListener("SYSTEM_TASK_CANCEL", runner.OnCancel)
+	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
+}
+
+func RegisterHandler(handler string, h Handler) {
+	runner.handlers.Store(handler, h)
+}
+
+// 提交异步任务，返回任务 Id
+func Submit(createdBy string, handler string, name string, payload any) (string, error) {
+	var task = &domain.Task{
+		CreatedBy:   createdBy,
+		Name:        name,
+		Type:        "ASYNC",
+		Handler:     handler,
+		Status:      domain.TASK_PENDING,
+		MaxAttempts: config.Setting.MaxAttempts,
+		NextRunTime: util.TimeAddr(time.Now()),
+		CreatedTime: time.Now(),
+	}
+	if payload != nil {
+		task.Payload = util.StringAddr(json.String(payload))
+	}
+
+	if _, err := runner.TaskRepository.CreateTask(task); err != nil {
+		return "", err
+	}
+
+	runner.notify(task.Id)
+	return task.Id, nil
+}
+
+type CommonTaskService struct{}
+
+var commonTaskService = &CommonTaskService{}
+
+func (s *CommonTaskService) Submit(createdBy string, handler string, name string, payload any) (string, error) {
+	return Submit(createdBy, handler, name, payload)
+}
+
+func Start() {
+	logger.Debug("Enable task runner: ...", config.Setting.Enabled)
+	if !config.Setting.Enabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	runner.stop = cancel
+	runner.slots = make(chan struct{}, max(config.Setting.Workers, 1))
+
+	if config.Setting.Queue == config.QUEUE_RABBITMQ {
+		runner.queue = newRabbitQueue(config.Setting.QueueName)
+		go runner.queue.Consume(ctx, runner.signal)
+	}
+
+	runner.wg.Add(1)
+	go runner.loop(ctx)
+	logger.Info("Running task runner OK: ", runner.worker)
+}
+
+// 停止领取任务并中断执行中的任务，被中断的任务释放租约后由其他节点继续
+func Terminate() {
+	if runner.stop == nil {
+		return
+	}
+	runner.stop(ErrShutdown)
+	runner.wg.Wait()
+	if runner.queue != nil {
+		runner.queue.Close()
+	}
+}
+
+func (r *Runner) notify(id string) {
+	if r.queue != nil {
+		if err := r.queue.Publish(id); err == nil {
+			return
+		} else {
+			logger.Warn("Publish task error: ", id, err.Error())
+		}
+	}
+	r.signal(id)
+}
+
+// 唤醒执行循环，id 为空时重新轮询
+func (r *Runner) signal(id string) {
+	select {
+	case r.wakeup <- id:
+	default:
+	}
+}
+
+func (r *Runner) loop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(max(config.Setting.PollInterval, 1)) * time.Second)
+	defer ticker.Stop()
+
+	r.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-r.wakeup:
+			if id == "" {
+				r.poll(ctx)
+			} else {
+				r.dispatch(ctx, id)
+			}
+		case <-ticker.C:
+			r.poll(ctx)
+		}
+	}
+}
+
+func (r *Runner) poll(ctx context.Context) {
+	free := cap(r.slots) - len(r.slots)
+	if free <= 0 {
+		return
+	}
+
+	if ids, err := r.TaskRepository.FailExpired(config.Setting.MaxAttempts); err != nil {
+		logger.Error("Fail expired tasks error: ", err.Error())
+	} else {
+		for _, id := range ids {
+			logger.Warn("Task lease expired without attempts left: ", id)
+			if task, e := r.TaskRepository.GetById(id); e == nil && task != nil {
+				r.push(task)
+			}
+		}
+	}
+
+	ids, err := r.TaskRepository.FindRunnable(free, config.Setting.MaxAttempts)
+	if err != nil {
+		logger.Error("Find runnable tasks error: ", err.Error())
+		return
+	}
+	for _, id := range ids {
+		r.dispatch(ctx, id)
+	}
+}
+
+func (r *Runner) dispatch(ctx context.Context, id string) {
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		return
+	}
+
+	claimed, err := r.TaskRepository.Claim(id, r.worker, r.lease(), config.Setting.MaxAttempts)
+	if err != nil || !claimed {
+		<-r.slots
+		if err != nil {
+			logger.Warn("Claim task error: ", id, err.Error())
+		}
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer func() {
+			<-r.slots
+			r.wg.Done()
+			r.signal("")
+		}()
+		r.run(ctx, id)
+	}()
+}
+
+func (r *Runner) run(parent context.Context, id string) {
+	task, err := r.TaskRepository.GetById(id)
+	if err != nil || task == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	r.running.Store(id, cancel)
+	defer r.running.Delete(id)
+
+	if task.CancelRequested {
+		cancel(ErrCancelled)
+	}
+	go r.heartbeat(ctx, cancel, id)
+
+	tc := &Context{Context: ctx, Task: task, runner: r}
+	if h := r.handler(task.Handler); h == nil {
+		err = ErrNoHandler
+	} else {
+		r.push(task)
+		err = invoke(h, tc)
+	}
+
+	r.complete(tc, err, context.Cause(ctx))
+}
+
+// 本模块注册的处理器优先，其次是其他模块通过 service.RegisterTaskHandler 注册的
+func (r *Runner) handler(name string) Handler {
+	if h, b := r.handlers.Load(name); b {
+		return h.(Handler)
+	}
+	if h := CommonService.GetTaskHandler(name); h != nil {
+		return func(ctx *Context) error { return h(ctx) }
+	}
+	return nil
+}
+
+func invoke(h Handler, ctx *Context) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("task panic: %v", e)
+		}
+	}()
+	return h(ctx)
+}
+
+func (r *Runner) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, id string) {
+	ticker := time.NewTicker(max(r.lease()/3, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			held, cancelRequested, err := r.TaskRepository.Heartbeat(id, r.worker, r.lease())
+			if err != nil {
+				logger.Warn("Task heartbeat error: ", id, err.Error())
+				continue
+			}
+			if !held {
+				cancel(ErrLeaseLost)
+			} else if cancelRequested {
+				cancel(ErrCancelled)
+			}
+		}
+	}
+}
+
+func (r *Runner) complete(ctx *Context, err error, cause error) {
+	task := ctx.Task
+	data := make(map[string]any)
+
+	switch {
+	case cause == ErrLeaseLost:
+		logger.Warn("Task lease lost: ", task.Id)
+		return
+	case cause == ErrShutdown:
+		// 释放租约，本次不计入执行次数
+		data["status"] = domain.TASK_PENDING
+		data["worker"] = nil
+		data["lease_time"] = nil
+		data["attempts"] = gorm.Expr("attempts - ?", 1)
+		data["next_run_time"] = time.Now()
+	case cause == ErrCancelled:
+		data["status"] = domain.TASK_CANCELLED
+		data["remark"] = ErrCancelled.Error()
+		data["finished_time"] = time.Now()
+	case err == nil:
+		data["status"] = domain.TASK_FINISHED
+		data["progress"] = 100
+		data["finished_time"] = time.Now()
+		if ctx.mode != "" {
+			data["mode"] = ctx.mode
+			data["result"] = ctx.result
+		}
+	case err != ErrNoHandler && task.Attempts < cmpOr(task.MaxAttempts, config.Setting.MaxAttempts):
+		data["status"] = domain.TASK_PENDING
+		data["remark"] = err.Error()
+		data["lease_time"] = nil
+		data["next_run_time"] = time.Now().Add(retryInterval(task.Attempts))
+	default:
+		data["status"] = domain.TASK_FAILED
+		data["remark"] = err.Error()
+		data["finished_time"] = time.Now()
+	}
+
+	if err != nil && cause == nil {
+		logger.Warn("Task error: ", task.Id, task.Handler, err.Error())
+	}
+
+	if _, e := r.TaskRepository.UpdateRunning(task.Id, r.worker, data); e != nil {
+		logger.Error("Update task error: ", task.Id, e.Error())
+		return
+	}
+
+	if result, e := r.TaskRepository.GetById(task.Id); e == nil && result != nil {
+		r.push(result)
+	}
+}
+
+// 中断本节点上正在执行的任务，其他节点在心跳时感知
+func (r *Runner) OnCancel(event string, args ...any) {
+	id, _ := args[0].(string)
+	if cancel, b := r.running.Load(id); b {
+		cancel.(context.CancelCauseFunc)(ErrCancelled)
+	}
+}
+
+func (r *Runner) push(task *domain.Task) {
+	progress := &TaskProgress{
+		Id:       task.Id,
+		Name:     task.Name,
+		Handler:  task.Handler,
+		Status:   task.Status,
+		Progress: task.Progress,
+		Remark:   util.NotNullString(task.Remark),
+		Mode:     task.Mode,
+		Result:   util.NotNullString(task.Result),
+	}
+	if err := websocket.SendToUser(task.CreatedBy, &service.InboxPush{Type: service.PUSH_TASK, Data: progress}); err != nil {
+		logger.Warn("Push task progress error: ", task.Id, err.Error())
+	}
+}
+
+func (r *Runner) lease() time.Duration {
+	return time.Duration(max(config.Setting.Lease, 3)) * time.Second
+}
+
+// 第 n 次失败后的等待时间，逐次翻倍
+func retryInterval(attempts int) time.Duration {
+	return time.Duration(max(config.Setting.RetryInterval, 1)) * time.Second << min(max(attempts-1, 0), 10)
+}
+
+func cmpOr(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 1
+}
@@ -1,6 +1,8 @@
 package slink
 
 import (
+	"embed"
+
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/module"
 
@@ -14,9 +16,13 @@ const (
 	MODULE_ID = 10110
 )
 
+//go:embed migrations
+var migrations embed.FS
+
 var _module = &module.Module{
 	Name:        "Operation",
 	Description: "",
+	Migrations:  migrations,
 }
 
 func init() {
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS auth_button;
+DROP TABLE IF EXISTS auth_menu;
+DROP TABLE IF EXISTS auth_module;
@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS auth_module (
+    id BIGINT NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    name VARCHAR(255),
+    title VARCHAR(255),
+    description TEXT,
+    type VARCHAR(255) DEFAULT 'MODULE',
+    oid BIGINT,
+    fid BIGINT,
+    fids VARCHAR(255),
+    path VARCHAR(255),
+    status INT DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_auth_module_fid (fid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS auth_menu (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    id VARCHAR(64) NOT NULL,
+    properties JSON,
+    fid VARCHAR(255),
+    name VARCHAR(255),
+    icon VARCHAR(255),
+    title VARCHAR(255),
+    tags VARCHAR(255),
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    remark TEXT,
+    level INT DEFAULT 0,
+    parameters JSON,
+    path VARCHAR(255),
+    component VARCHAR(255),
+    out_page TINYINT(1),
+    hidden TINYINT(1) DEFAULT false,
+    PRIMARY KEY (id),
+    KEY idx_auth_menu_fid (fid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS auth_button (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    id VARCHAR(64) NOT NULL,
+    properties JSON,
+    fid VARCHAR(255),
+    name VARCHAR(255),
+    icon VARCHAR(255),
+    title VARCHAR(255),
+    tags VARCHAR(255),
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    remark TEXT,
+    level INT DEFAULT 0,
+    color VARCHAR(255),
+    allow_method VARCHAR(255),
+    PRIMARY KEY (id),
+    KEY idx_auth_button_fid (fid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,69 @@
+CREATE TABLE IF NOT EXISTS auth_module (
+    id BIGINT NOT NULL,
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    name VARCHAR(255),
+    title VARCHAR(255),
+    description TEXT,
+    type VARCHAR(255) DEFAULT 'MODULE',
+    oid BIGINT,
+    fid BIGINT,
+    fids VARCHAR(255),
+    path VARCHAR(255),
+    status INT DEFAULT 1,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_module_fid ON auth_module (fid);
+
+CREATE TABLE IF NOT EXISTS auth_menu (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    id VARCHAR(64) NOT NULL,
+    properties JSONB,
+    fid VARCHAR(255),
+    name VARCHAR(255),
+    icon VARCHAR(255),
+    title VARCHAR(255),
+    tags VARCHAR(255),
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    remark TEXT,
+    level INT DEFAULT 0,
+    parameters JSONB,
+    path VARCHAR(255),
+    component VARCHAR(255),
+    out_page BOOLEAN,
+    hidden BOOLEAN DEFAULT false,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_menu_fid ON auth_menu (fid);
+
+CREATE TABLE IF NOT EXISTS auth_button (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    id VARCHAR(64) NOT NULL,
+    properties JSONB,
+    fid VARCHAR(255),
+    name VARCHAR(255),
+    icon VARCHAR(255),
+    title VARCHAR(255),
+    tags VARCHAR(255),
+    sort INT DEFAULT 100,
+    status INT DEFAULT 1,
+    remark TEXT,
+    level INT DEFAULT 0,
+    color VARCHAR(255),
+    allow_method VARCHAR(255),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_button_fid ON auth_button (fid);
@@ -14,6 +14,7 @@ const (
 
 var _module = &module.Module{
 	Name:        "OperationV1",
+	DependsOn:   []string{"Operation"},
 	Description: "",
 }
 
@@ -6,18 +6,34 @@ import (
 	"github.com/gophab/gophrame/core/config"
 )
 
+// 短链生成方式
+const (
+	KEY_RANDOM    = "random"    // 随机串，冲突时重试
+	KEY_SNOWFLAKE = "snowflake" // snowflake ID 的 base62 编码
+)
+
 type ShortLinkSetting struct {
-	Enabled bool          `yaml:"enabled" json:"enabled"`
-	Length  int           `yaml:"length" json:"length"`
-	BaseUrl string        `yaml:"baseUrl" json:"baseUrl"`
-	Context string        `yaml:"context" json:"context"`
-	Expired time.Duration `yaml:"expired" json:"expired"`
+	Enabled        bool          `yaml:"enabled" json:"enabled"`
+	Length         int           `yaml:"length" json:"length"`
+	BaseUrl        string        `yaml:"baseUrl" json:"baseUrl"`
+	Context        string        `yaml:"context" json:"context"`
+	Expired        time.Duration `yaml:"expired" json:"expired"`
+	KeyStrategy    string        `yaml:"keyStrategy" json:"keyStrategy"`
+	MaxRetries     int           `yaml:"maxRetries" json:"maxRetries"`
+	RedirectStatus int           `yaml:"redirectStatus" json:"redirectStatus"` // 301 或 302
+	MaxAttempts    int           `yaml:"maxAttempts" json:"maxAttempts"`       // 每个链接在 lockout 内允许的密码错误次数
+	Lockout        time.Duration `yaml:"lockout" json:"lockout"`
 }
 
 var Setting = &ShortLinkSetting{
-	Enabled: true,
-	Length:  5,
-	Expired: time.Duration(24*180) * time.Hour, /* 180 DAY */
+	Enabled:        true,
+	Length:         5,
+	Expired:        time.Duration(24*180) * time.Hour, /* 180 DAY */
+	KeyStrategy:    KEY_RANDOM,
+	MaxRetries:     5,
+	RedirectStatus: 302,
+	MaxAttempts:    5,
+	Lockout:        15 * time.Minute,
 }
 
 func init() {
@@ -1,13 +1,17 @@
 package mapi
 
 import (
-	"strings"
+	"cmp"
+	"net/http"
+	"time"
 
-	"github.com/gophab/gophrame/module/slink/config"
+	"github.com/gophab/gophrame/module/slink/domain"
 	"github.com/gophab/gophrame/module/slink/service"
 
 	"github.com/gophab/gophrame/core/controller"
 	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/qrcode"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
 	"github.com/gophab/gophrame/core/webservice/request"
 	"github.com/gophab/gophrame/core/webservice/response"
 	"github.com/gophab/gophrame/errors"
@@ -32,138 +36,186 @@ func (c *ShortLinkMController) AfterInitialize() {
 		{HttpMethod: "GET", ResourcePath: "/short-links", Handler: c.GetShortLinks},
 		{HttpMethod: "POST", ResourcePath: "/short-link", Handler: c.CreateShortLink},
 		{HttpMethod: "PATCH", ResourcePath: "/short-link/:id", Handler: c.PatchShortLink},
+		{HttpMethod: "GET", ResourcePath: "/short-link/:id/stats", Handler: c.GetShortLinkStats},
+		{HttpMethod: "GET", ResourcePath: "/short-link/:id/qrcode", Handler: c.GetShortLinkQRCode},
 		{HttpMethod: "DELETE", ResourcePath: "/short-link/:id", Handler: c.DeleteShortLink},
 	})
 }
 
 func (c *ShortLinkMController) GetShortLink(ctx *gin.Context) {
-	id, err := request.Param(ctx, "id").MustString()
-	if err != nil {
-		response.FailCode(ctx, errors.INVALID_PARAMS)
-		return
+	if result, b := c.getShortLink(ctx); b {
+		response.Success(ctx, result)
 	}
+}
 
-	result, err := c.ShortLinkService.GetById(id)
-	if err != nil {
-		response.SystemFail(ctx, err)
-		return
+func (c *ShortLinkMController) GetShortLinks(ctx *gin.Context) {
+	if result, b := c.getShortLink(ctx); b {
+		response.Success(ctx, result)
 	}
+}
 
-	if result != nil {
-		response.Success(ctx, result)
+// key 为自定义短链；expired 为有效期（如 720h），为空时使用默认值
+func (c *ShortLinkMController) CreateShortLink(ctx *gin.Context) {
+	var request struct {
+		Name           string `json:"name"`
+		Url            string `json:"url"`
+		Key            string `json:"key"`
+		Password       string `json:"password"`
+		OneTime        bool   `json:"oneTime"`
+		RedirectStatus int    `json:"redirectStatus"`
+		Expired        string `json:"expired"`
+	}
+	if err := ctx.ShouldBind(&request); err != nil || request.Url == "" {
+		response.FailCode(ctx, errors.INVALID_PARAMS)
 		return
 	}
 
-	result, err = c.ShortLinkService.GetByKey(id)
-	if err != nil {
+	var duration time.Duration
+	if request.Expired != "" {
+		if d, err := time.ParseDuration(request.Expired); err == nil && d > 0 {
+			duration = d
+		} else {
+			response.FailCode(ctx, errors.INVALID_PARAMS)
+			return
+		}
+	}
+
+	result, err := c.ShortLinkService.CreateShortLink(&service.ShortLinkOptions{
+		Name:           request.Name,
+		Url:            request.Url,
+		Key:            request.Key,
+		Password:       request.Password,
+		OneTime:        request.OneTime,
+		RedirectStatus: request.RedirectStatus,
+		Duration:       duration,
+		TenantId:       SecurityUtil.GetCurrentTenantId(ctx),
+	})
+	switch err {
+	case nil:
+	case service.ErrInvalidKey:
+		response.FailMessage(ctx, errors.INVALID_PARAMS, err.Error())
+		return
+	case service.ErrKeyExists:
+		response.FailMessage(ctx, errors.ERROR_EXIST, err.Error())
+		return
+	default:
 		response.SystemFail(ctx, err)
 		return
 	}
 
 	if result != nil {
-		response.Success(ctx, result)
+		response.Success(ctx, result.FullPath)
 		return
 	}
 }
 
-func (c *ShortLinkMController) GetShortLinks(ctx *gin.Context) {
-	id, err := request.Param(ctx, "id").MustString()
-	if err != nil {
-		response.FailCode(ctx, errors.INVALID_PARAMS)
+// 访问统计，from/to 为日期（yyyy-MM-dd），默认最近 30 天
+func (c *ShortLinkMController) GetShortLinkStats(ctx *gin.Context) {
+	result, b := c.getShortLink(ctx)
+	if !b {
 		return
 	}
 
-	result, err := c.ShortLinkService.GetById(id)
-	if err != nil {
-		response.SystemFail(ctx, err)
-		return
+	var from, to time.Time
+	if value := request.Param(ctx, "from").DefaultString(""); value != "" {
+		if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+			from = t
+		} else {
+			response.FailCode(ctx, errors.INVALID_PARAMS)
+			return
+		}
 	}
-
-	if result != nil {
-		response.Success(ctx, result)
-		return
+	if value := request.Param(ctx, "to").DefaultString(""); value != "" {
+		if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+			to = t.AddDate(0, 0, 1)
+		} else {
+			response.FailCode(ctx, errors.INVALID_PARAMS)
+			return
+		}
 	}
 
-	result, err = c.ShortLinkService.GetByKey(id)
+	stats, err := c.ShortLinkService.Stats(result, from, to)
 	if err != nil {
 		response.SystemFail(ctx, err)
 		return
 	}
 
-	if result != nil {
-		response.Success(ctx, result)
-		return
-	}
+	response.Success(ctx, stats)
 }
 
-func (c *ShortLinkMController) CreateShortLink(ctx *gin.Context) {
-	var request struct {
-		Name string `json:"name"`
-		Url  string `json:"url"`
-	}
-	if err := ctx.ShouldBind(&request); err != nil {
-		response.FailCode(ctx, errors.INVALID_PARAMS)
+// 二维码：format 为 png（默认）或 svg，scale 为每个模块的像素数（1~32），border 为留白模块数（0~16）
+func (c *ShortLinkMController) GetShortLinkQRCode(ctx *gin.Context) {
+	result, b := c.getShortLink(ctx)
+	if !b {
 		return
 	}
 
-	result, err := c.ShortLinkService.GenerateShortLink(request.Name, request.Url, config.Setting.Expired)
+	format := request.Param(ctx, "format").DefaultString("png")
+	scale := min(max(request.Param(ctx, "scale").DefaultInt(8), 1), 32)
+	border := min(max(request.Param(ctx, "border").DefaultInt(4), 0), 16)
+
+	code, err := qrcode.Encode(result.FullPath, qrcode.Medium)
 	if err != nil {
 		response.SystemFail(ctx, err)
 		return
 	}
 
-	if result != nil {
-		root, _ := strings.CutSuffix(config.Setting.BaseUrl, "/")
-		response.Success(ctx, root+"/"+result.Key)
-		return
+	switch format {
+	case "svg":
+		ctx.Data(http.StatusOK, "image/svg+xml", []byte(code.SVG(border)))
+	case "png":
+		data, err := code.PNG(scale, border)
+		if err != nil {
+			response.SystemFail(ctx, err)
+			return
+		}
+		ctx.Data(http.StatusOK, "image/png", data)
+	default:
+		response.FailCode(ctx, errors.INVALID_PARAMS)
 	}
 }
 
-func (c *ShortLinkMController) PatchShortLink(ctx *gin.Context) {
+// 按 id 或当前租户下的 key 查找，其他租户的短链视为不存在；SYSTEM 租户可访问全部。未找到时已写出响应
+func (c *ShortLinkMController) getShortLink(ctx *gin.Context) (*domain.ShortLink, bool) {
 	id, err := request.Param(ctx, "id").MustString()
 	if err != nil {
 		response.FailCode(ctx, errors.INVALID_PARAMS)
-		return
+		return nil, false
 	}
 
+	tenantId := cmp.Or(SecurityUtil.GetCurrentTenantId(ctx), "SYSTEM")
 	result, err := c.ShortLinkService.GetById(id)
-	if err != nil {
-		response.SystemFail(ctx, err)
-		return
+	if err == nil && result != nil && tenantId != "SYSTEM" && result.TenantId != tenantId {
+		result = nil
 	}
-
-	if result != nil {
-		response.Success(ctx, result)
-		return
+	if err == nil && result == nil {
+		result, err = c.ShortLinkService.GetByKey(tenantId, id)
 	}
-
-	result, err = c.ShortLinkService.GetByKey(id)
 	if err != nil {
 		response.SystemFail(ctx, err)
-		return
+		return nil, false
 	}
 
-	if result != nil {
-		response.Success(ctx, result)
-		return
+	if result == nil {
+		response.NotFound(ctx, "Not Found")
+		return nil, false
 	}
+	return result, true
 }
 
-func (c *ShortLinkMController) DeleteShortLink(ctx *gin.Context) {
-	id, err := request.Param(ctx, "id").MustString()
-	if err != nil {
-		response.FailCode(ctx, errors.INVALID_PARAMS)
-		return
+func (c *ShortLinkMController) PatchShortLink(ctx *gin.Context) {
+	if result, b := c.getShortLink(ctx); b {
+		response.Success(ctx, result)
 	}
+}
 
-	err = c.ShortLinkService.DeleteById(id)
-	if err != nil {
-		response.SystemFail(ctx, err)
+func (c *ShortLinkMController) DeleteShortLink(ctx *gin.Context) {
+	result, b := c.getShortLink(ctx)
+	if !b {
 		return
 	}
 
-	err = c.ShortLinkService.DeleteByKey(id)
-	if err != nil {
+	if err := c.ShortLinkService.DeleteById(result.Id); err != nil {
 		response.SystemFail(ctx, err)
 		return
 	}
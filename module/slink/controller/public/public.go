@@ -3,8 +3,10 @@ package public
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gophab/gophrame/module/slink/config"
+	"github.com/gophab/gophrame/module/slink/domain"
 	"github.com/gophab/gophrame/module/slink/service"
 
 	"github.com/gin-gonic/gin"
@@ -13,18 +15,30 @@ import (
 	"github.com/gophab/gophrame/core/webservice/request"
 	"github.com/gophab/gophrame/core/webservice/response"
 	"github.com/gophab/gophrame/errors"
+
+	"github.com/patrickmn/go-cache"
 )
 
+// CDN 或网关提供的国家代码请求头
+var countryHeaders = []string{"CF-IPCountry", "X-Country-Code", "X-Appengine-Country"}
+
 type ShortLinkPublicController struct {
 	ShortLinkService *service.ShortLinkService `inject:"shortLinkService"`
+
+	// 按链接统计的密码错误次数
+	failures *cache.Cache
 }
 
-var shortLinkPublicController = &ShortLinkPublicController{}
+var shortLinkPublicController = &ShortLinkPublicController{
+	failures: cache.New(15*time.Minute, time.Minute),
+}
 
 func Start() {
 	if config.Setting.Enabled {
 		contextRoot, _ := strings.CutSuffix(config.Setting.Context, "/")
 		router.Root().GET(contextRoot+"/:key", shortLinkPublicController.RedirectShortLink)
+		// 租户短链 {tenantId}/{key}，gin 要求同一位置的参数同名
+		router.Root().GET(contextRoot+"/:key/:tenantKey", shortLinkPublicController.RedirectShortLink)
 	}
 }
 
@@ -32,6 +46,7 @@ func init() {
 	inject.InjectValue("shortLinkPublicController", shortLinkPublicController)
 }
 
+// 带密码的链接通过 password 参数或 X-Link-Password 请求头提供密码
 func (c *ShortLinkPublicController) RedirectShortLink(ctx *gin.Context) {
 	key, err := request.Param(ctx, "key").MustString()
 	if err != nil {
@@ -39,16 +54,63 @@ func (c *ShortLinkPublicController) RedirectShortLink(ctx *gin.Context) {
 		return
 	}
 
-	slink, err := c.ShortLinkService.GetByKey(key)
+	tenantId := "SYSTEM"
+	if tenantKey := ctx.Param("tenantKey"); tenantKey != "" {
+		tenantId, key = key, tenantKey
+	}
+
+	link := tenantId + "/" + key
+	if c.locked(link) {
+		response.ErrorMessage(ctx, http.StatusTooManyRequests, http.StatusTooManyRequests, "Too Many Attempts")
+		return
+	}
+
+	password := request.Param(ctx, "password").DefaultString(ctx.GetHeader("X-Link-Password"))
+	slink, err := c.ShortLinkService.Resolve(tenantId, key, password)
+	if err == service.ErrPasswordRequired {
+		if password != "" {
+			c.fail(link)
+		}
+		response.Unauthorized(ctx, "Password Required")
+		return
+	}
 	if err != nil {
 		response.SystemFailError(ctx, errors.MakeError("ERROR_QUERY_SHORT_LINK_ERROR"), err)
 		return
 	}
 
 	if slink != nil {
-		ctx.Redirect(http.StatusFound, slink.Url)
+		click := &domain.ShortLinkClick{
+			Referrer:  ctx.Request.Referer(),
+			UserAgent: ctx.Request.UserAgent(),
+			Ip:        ctx.ClientIP(),
+		}
+		for _, header := range countryHeaders {
+			if country := ctx.GetHeader(header); country != "" {
+				click.Country = strings.ToUpper(country)
+				break
+			}
+		}
+		go c.ShortLinkService.RecordClick(slink, click)
+
+		ctx.Redirect(c.ShortLinkService.RedirectStatus(slink), slink.Url)
 		return
 	}
 
 	response.NotFound(ctx, "Not Found or Expired")
 }
+
+func (c *ShortLinkPublicController) locked(link string) bool {
+	if config.Setting.MaxAttempts <= 0 {
+		return false
+	}
+	count, b := c.failures.Get(link)
+	return b && count.(int) >= config.Setting.MaxAttempts
+}
+
+// 首次失败开始计时，lockout 内累计，到期后重新计数
+func (c *ShortLinkPublicController) fail(link string) {
+	if c.failures.Add(link, 1, config.Setting.Lockout) != nil {
+		_, _ = c.failures.IncrementInt(link, 1)
+	}
+}
@@ -0,0 +1,36 @@
+package public
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/module/slink/config"
+
+	"github.com/patrickmn/go-cache"
+)
+
+func TestPasswordAttemptsLockPerLink(t *testing.T) {
+	defer func(attempts int, lockout time.Duration) {
+		config.Setting.MaxAttempts, config.Setting.Lockout = attempts, lockout
+	}(config.Setting.MaxAttempts, config.Setting.Lockout)
+	config.Setting.MaxAttempts, config.Setting.Lockout = 3, 50*time.Millisecond
+
+	c := &ShortLinkPublicController{failures: cache.New(time.Minute, time.Minute)}
+	for i := 0; i < 3; i++ {
+		if c.locked("SYSTEM/a") {
+			t.Fatalf("locked after %d failures", i)
+		}
+		c.fail("SYSTEM/a")
+	}
+	if !c.locked("SYSTEM/a") {
+		t.Fatal("link not locked after max attempts")
+	}
+	if c.locked("SYSTEM/b") || c.locked("t1/a") {
+		t.Fatal("lock leaked to other links")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if c.locked("SYSTEM/a") {
+		t.Fatal("lock not released after lockout")
+	}
+}
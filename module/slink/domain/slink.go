@@ -4,18 +4,26 @@ import (
 	"time"
 
 	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/domain"
+
 	"gorm.io/gorm"
 )
 
 type ShortLink struct {
-	Id          string     `gorm:"column:id;primaryKey"`
-	Name        string     `gorm:"column:name" json:"name"`
-	Key         string     `gorm:"column:key;unique" json:"key"`
-	Url         string     `gorm:"column:url" json:"url"`
-	CreatedTime time.Time  `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
-	ExpiredTime *time.Time `gorm:"column:expired_time" json:"expiredTime"`
-	TenantId    string     `gorm:"column:tenant_id;default:SYSTEM" json:"tentantId"`
-	FullPath    string     `gorm:"-" json:"fullPath"`
+	Id             string     `gorm:"column:id;primaryKey"`
+	Name           string     `gorm:"column:name" json:"name"`
+	Key            string     `gorm:"column:key;uniqueIndex:idx_sys_short_link_tenant_key,priority:2" json:"key"`
+	Url            string     `gorm:"column:url" json:"url"`
+	Password       string     `gorm:"column:password" json:"-"`
+	OneTime        bool       `gorm:"column:one_time" json:"oneTime"`
+	UsedTime       *time.Time `gorm:"column:used_time" json:"usedTime,omitempty"`
+	RedirectStatus int        `gorm:"column:redirect_status" json:"redirectStatus,omitempty"`
+	Clicks         int64      `gorm:"column:clicks;default:0" json:"clicks"`
+	CreatedTime    time.Time  `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
+	ExpiredTime    *time.Time `gorm:"column:expired_time" json:"expiredTime"`
+	TenantId       string     `gorm:"column:tenant_id;default:SYSTEM;uniqueIndex:idx_sys_short_link_tenant_key,priority:1" json:"tentantId"`
+	FullPath       string     `gorm:"-" json:"fullPath"`
+	Protected      bool       `gorm:"-" json:"protected"`
 }
 
 func (e *ShortLink) TableName() string {
@@ -29,3 +37,44 @@ func (e *ShortLink) BeforeCreate(tx *gorm.DB) (err error) {
 
 	return
 }
+
+func (e *ShortLink) AfterFind(tx *gorm.DB) (err error) {
+	e.Protected = e.Password != ""
+	return
+}
+
+// 访问记录
+type ShortLinkClick struct {
+	domain.Model
+	LinkId      string    `gorm:"column:link_id" json:"linkId"`
+	Referrer    string    `gorm:"column:referrer" json:"referrer,omitempty"`
+	UserAgent   string    `gorm:"column:user_agent" json:"userAgent,omitempty"`
+	Ip          string    `gorm:"column:ip" json:"ip,omitempty"`
+	Country     string    `gorm:"column:country" json:"country,omitempty"`
+	Region      string    `gorm:"column:region" json:"region,omitempty"`
+	CreatedTime time.Time `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
+}
+
+func (e *ShortLinkClick) TableName() string {
+	return "sys_short_link_click"
+}
+
+// 按天汇总的访问量，Visitors 为不同 IP 数
+type DailyClicks struct {
+	Date     string `json:"date"`
+	Clicks   int64  `json:"clicks"`
+	Visitors int64  `json:"visitors"`
+}
+
+type NamedCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type ShortLinkStats struct {
+	Clicks    int64          `json:"clicks"`
+	Visitors  int64          `json:"visitors"`
+	Daily     []*DailyClicks `json:"daily"`
+	Referrers []*NamedCount  `json:"referrers"`
+	Countries []*NamedCount  `json:"countries"`
+}
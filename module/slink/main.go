@@ -1,6 +1,8 @@
 package slink
 
 import (
+	"embed"
+
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/module"
 
@@ -15,9 +17,13 @@ const (
 	MODULE_ID = 102101
 )
 
+//go:embed migrations
+var migrations embed.FS
+
 var _module = &module.Module{
 	Name:        "ShortLink",
 	Description: "",
+	Migrations:  migrations,
 }
 
 func init() {
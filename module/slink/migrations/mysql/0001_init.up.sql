@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS sys_short_link (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    `key` VARCHAR(255) UNIQUE,
+    url TEXT,
+    created_time DATETIME(3),
+    expired_time DATETIME(3),
+    tenant_id VARCHAR(64) DEFAULT 'SYSTEM',
+    PRIMARY KEY (id),
+    KEY idx_sys_short_link_expired_time (expired_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
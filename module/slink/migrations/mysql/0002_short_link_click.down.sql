@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS sys_short_link_click;
+
+ALTER TABLE sys_short_link
+    DROP COLUMN password,
+    DROP COLUMN one_time,
+    DROP COLUMN used_time,
+    DROP COLUMN redirect_status,
+    DROP COLUMN clicks;
@@ -0,0 +1,19 @@
+ALTER TABLE sys_short_link
+    ADD COLUMN password VARCHAR(64),
+    ADD COLUMN one_time TINYINT(1) DEFAULT 0,
+    ADD COLUMN used_time DATETIME(3),
+    ADD COLUMN redirect_status INT,
+    ADD COLUMN clicks BIGINT DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS sys_short_link_click (
+    id BIGINT NOT NULL,
+    link_id VARCHAR(64) NOT NULL,
+    referrer VARCHAR(1024),
+    user_agent VARCHAR(512),
+    ip VARCHAR(64),
+    country VARCHAR(64),
+    region VARCHAR(128),
+    created_time DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_sys_short_link_click_link_id_created_time (link_id, created_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
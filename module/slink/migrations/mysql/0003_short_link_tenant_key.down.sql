@@ -0,0 +1,3 @@
+ALTER TABLE sys_short_link
+    DROP INDEX idx_sys_short_link_tenant_key,
+    ADD UNIQUE KEY `key` (`key`);
@@ -0,0 +1,3 @@
+ALTER TABLE sys_short_link
+    DROP INDEX `key`,
+    ADD UNIQUE KEY idx_sys_short_link_tenant_key (tenant_id, `key`);
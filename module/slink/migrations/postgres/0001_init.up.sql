@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sys_short_link (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    "key" VARCHAR(255) UNIQUE,
+    url TEXT,
+    created_time TIMESTAMP(3),
+    expired_time TIMESTAMP(3),
+    tenant_id VARCHAR(64) DEFAULT 'SYSTEM',
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_short_link_expired_time ON sys_short_link (expired_time);
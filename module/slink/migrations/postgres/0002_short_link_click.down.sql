@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS sys_short_link_click;
+
+ALTER TABLE sys_short_link
+    DROP COLUMN IF EXISTS password,
+    DROP COLUMN IF EXISTS one_time,
+    DROP COLUMN IF EXISTS used_time,
+    DROP COLUMN IF EXISTS redirect_status,
+    DROP COLUMN IF EXISTS clicks;
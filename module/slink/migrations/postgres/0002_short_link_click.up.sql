@@ -0,0 +1,20 @@
+ALTER TABLE sys_short_link
+    ADD COLUMN IF NOT EXISTS password VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS one_time BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS used_time TIMESTAMP(3),
+    ADD COLUMN IF NOT EXISTS redirect_status INT,
+    ADD COLUMN IF NOT EXISTS clicks BIGINT DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS sys_short_link_click (
+    id BIGINT NOT NULL,
+    link_id VARCHAR(64) NOT NULL,
+    referrer VARCHAR(1024),
+    user_agent VARCHAR(512),
+    ip VARCHAR(64),
+    country VARCHAR(64),
+    region VARCHAR(128),
+    created_time TIMESTAMP(3),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_short_link_click_link_id_created_time ON sys_short_link_click (link_id, created_time);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sys_short_link_tenant_key;
+
+ALTER TABLE sys_short_link ADD CONSTRAINT sys_short_link_key_key UNIQUE ("key");
@@ -0,0 +1,3 @@
+ALTER TABLE sys_short_link DROP CONSTRAINT IF EXISTS sys_short_link_key_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sys_short_link_tenant_key ON sys_short_link (tenant_id, "key");
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/gophab/gophrame/module/slink/domain"
 
 	"github.com/gophab/gophrame/core/inject"
@@ -16,8 +18,15 @@ type ShortLinkRepository struct {
 
 var shortLinkReposistory = &ShortLinkRepository{}
 
+type ShortLinkClickRepository struct {
+	*gorm.DB `inject:"database"`
+}
+
+var shortLinkClickRepository = &ShortLinkClickRepository{}
+
 func init() {
 	inject.InjectValue("shortLinkRepository", shortLinkReposistory)
+	inject.InjectValue("shortLinkClickRepository", shortLinkClickRepository)
 }
 
 func (r *ShortLinkRepository) GetById(id string) (*domain.ShortLink, error) {
@@ -33,17 +42,18 @@ func (r *ShortLinkRepository) DeleteById(id string) error {
 	return transaction.Session().Delete(&domain.ShortLink{}, "id=?", id).Error
 }
 
-func (r *ShortLinkRepository) GetByKey(key string) (*domain.ShortLink, error) {
+// 短链在租户内唯一
+func (r *ShortLinkRepository) GetByKey(tenantId string, key string) (*domain.ShortLink, error) {
 	var result domain.ShortLink
-	if res := transaction.Session().Model(&domain.ShortLink{}).Where("`key`=?", key).Find(&result); res.Error == nil && res.RowsAffected > 0 {
+	if res := transaction.Session().Model(&domain.ShortLink{}).Where("tenant_id=? and `key`=?", tenantId, key).Find(&result); res.Error == nil && res.RowsAffected > 0 {
 		return &result, nil
 	} else {
 		return nil, res.Error
 	}
 }
 
-func (r *ShortLinkRepository) DeleteByKey(key string) error {
-	return transaction.Session().Delete(&domain.ShortLink{}, "`key`=?", key).Error
+func (r *ShortLinkRepository) DeleteByKey(tenantId string, key string) error {
+	return transaction.Session().Delete(&domain.ShortLink{}, "tenant_id=? and `key`=?", tenantId, key).Error
 }
 
 func (r *ShortLinkRepository) CreateShortLink(shortLink *domain.ShortLink) (*domain.ShortLink, error) {
@@ -54,8 +64,103 @@ func (r *ShortLinkRepository) CreateShortLink(shortLink *domain.ShortLink) (*dom
 	}
 }
 
+func (r *ShortLinkRepository) ExistsKey(tenantId string, key string) (bool, error) {
+	var count int64
+	res := transaction.Session().Model(&domain.ShortLink{}).Where("tenant_id=? and `key`=?", tenantId, key).Count(&count)
+	return count > 0, res.Error
+}
+
+// 一次性链接标记为已使用，返回是否由本次标记
+func (r *ShortLinkRepository) UseOnce(id string) (bool, error) {
+	res := r.Model(&domain.ShortLink{}).
+		Where("id=? and used_time is null", id).
+		Update("used_time", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *ShortLinkRepository) IncreaseClicks(id string) error {
+	return r.Model(&domain.ShortLink{}).Where("id=?", id).UpdateColumn("clicks", gorm.Expr("clicks + ?", 1)).Error
+}
+
 func (r *ShortLinkRepository) ExpireExpiredShortLinks() {
 	if res := r.Delete(&domain.ShortLink{}, "expired_time < CURRENT_TIMESTAMP"); res.Error != nil {
 		logger.Warn("Expire shortlink error: ", res.Error.Error())
 	}
 }
+
+func (r *ShortLinkClickRepository) CreateClick(click *domain.ShortLinkClick) error {
+	return r.Create(click).Error
+}
+
+// 时间范围内的访问统计：总数、按天汇总、来源和国家排行
+func (r *ShortLinkClickRepository) Stats(linkId string, from, to time.Time, top int) (*domain.ShortLinkStats, error) {
+	scope := func() *gorm.DB {
+		return r.Model(&domain.ShortLinkClick{}).
+			Where("link_id = ?", linkId).
+			Where("created_time >= ? and created_time < ?", from, to)
+	}
+
+	var result = domain.ShortLinkStats{
+		Daily:     make([]*domain.DailyClicks, 0),
+		Referrers: make([]*domain.NamedCount, 0),
+		Countries: make([]*domain.NamedCount, 0),
+	}
+
+	var total struct {
+		Clicks   int64
+		Visitors int64
+	}
+	if res := scope().Select("count(*) as clicks, count(distinct ip) as visitors").Scan(&total); res.Error != nil {
+		return nil, res.Error
+	}
+	result.Clicks, result.Visitors = total.Clicks, total.Visitors
+
+	var daily []struct {
+		Day      string
+		Clicks   int64
+		Visitors int64
+	}
+	if res := scope().
+		Select("DATE(created_time) as day, count(*) as clicks, count(distinct ip) as visitors").
+		Group("DATE(created_time)").
+		Order("day").
+		Scan(&daily); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, d := range daily {
+		result.Daily = append(result.Daily, &domain.DailyClicks{Date: d.Day[:min(len(d.Day), 10)], Clicks: d.Clicks, Visitors: d.Visitors})
+	}
+
+	if res := scope().
+		Select("referrer as name, count(*) as count").
+		Where("referrer <> ''").
+		Group("referrer").
+		Order("count desc").
+		Limit(top).
+		Scan(&result.Referrers); res.Error != nil {
+		return nil, res.Error
+	}
+
+	if res := scope().
+		Select("country as name, count(*) as count").
+		Where("country <> ''").
+		Group("country").
+		Order("count desc").
+		Limit(top).
+		Scan(&result.Countries); res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &result, nil
+}
+
+func (r *ShortLinkClickRepository) DeleteByLinkId(linkId string) error {
+	return r.Delete(&domain.ShortLinkClick{}, "link_id=?", linkId).Error
+}
+
+// 清理已删除或过期链接的访问记录
+func (r *ShortLinkClickRepository) DeleteOrphanClicks() {
+	if res := r.Delete(&domain.ShortLinkClick{}, "link_id NOT IN (?)", r.Model(&domain.ShortLink{}).Select("id")); res.Error != nil {
+		logger.Warn("Delete shortlink clicks error: ", res.Error.Error())
+	}
+}
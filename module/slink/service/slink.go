@@ -1,6 +1,11 @@
 package service
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"regexp"
 	"strings"
 	"time"
 
@@ -8,39 +13,94 @@ import (
 	"github.com/gophab/gophrame/module/slink/domain"
 	"github.com/gophab/gophrame/module/slink/repository"
 
+	"github.com/gophab/gophrame/core/cron"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
-	"github.com/gophab/gophrame/core/starter"
+	"github.com/gophab/gophrame/core/snowflake"
 	"github.com/gophab/gophrame/core/util"
 	"github.com/gophab/gophrame/service"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidKey       = errors.New("invalid short link key")
+	ErrKeyExists        = errors.New("short link key already exists")
+	ErrKeyExhausted     = errors.New("no available short link key")
+	ErrPasswordRequired = errors.New("short link password required")
 )
 
+// 自定义短链：字母、数字、下划线和中划线
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,64}$`)
+
+const base62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// IP 所在地区，可注入 ipLocator 替换
+type Location struct {
+	Country string
+	Region  string
+}
+
+type IpLocator interface {
+	Locate(ip string) *Location
+}
+
+// 创建参数：Key 为空时按配置生成；Duration 为 0 时使用默认有效期
+type ShortLinkOptions struct {
+	Name           string
+	Url            string
+	Key            string
+	Password       string
+	OneTime        bool
+	RedirectStatus int
+	Duration       time.Duration
+	TenantId       string
+}
+
 type ShortLinkService struct {
 	service.BaseService
-	ShortLinkRepository *repository.ShortLinkRepository `inject:"shortLinkRepository"`
+	ShortLinkRepository      *repository.ShortLinkRepository      `inject:"shortLinkRepository"`
+	ShortLinkClickRepository *repository.ShortLinkClickRepository `inject:"shortLinkClickRepository"`
+	IpLocator                IpLocator                            `inject:"ipLocator,optional"`
 }
 
 var shortLinkService = &ShortLinkService{}
 
 func init() {
 	inject.InjectValue("shortLinkService", shortLinkService)
-	starter.RegisterStarter(shortLinkService.Start)
+	cron.MustRegister(&cron.Job{
+		Name:        "shortlink.expire",
+		Description: "Expire short links past their expired time",
+		Spec:        "@hourly",
+		Func: func(ctx context.Context) error {
+			shortLinkService.ShortLinkRepository.ExpireExpiredShortLinks()
+			shortLinkService.ShortLinkClickRepository.DeleteOrphanClicks()
+			return nil
+		},
+	})
 }
 
 func (s *ShortLinkService) GetById(id string) (*domain.ShortLink, error) {
-	return s.ShortLinkRepository.GetById(id)
+	return s.fullPath(s.ShortLinkRepository.GetById(id))
 }
 
 func (s *ShortLinkService) DeleteById(id string) error {
-	return s.ShortLinkRepository.DeleteById(id)
+	if err := s.ShortLinkRepository.DeleteById(id); err != nil {
+		return err
+	}
+	return s.ShortLinkClickRepository.DeleteByLinkId(id)
 }
 
-func (s *ShortLinkService) GetByKey(key string) (*domain.ShortLink, error) {
-	return s.ShortLinkRepository.GetByKey(key)
+func (s *ShortLinkService) GetByKey(tenantId string, key string) (*domain.ShortLink, error) {
+	return s.fullPath(s.ShortLinkRepository.GetByKey(cmp.Or(tenantId, "SYSTEM"), key))
 }
 
-func (s *ShortLinkService) DeleteByKey(key string) error {
-	return s.ShortLinkRepository.DeleteByKey(key)
+func (s *ShortLinkService) DeleteByKey(tenantId string, key string) error {
+	link, err := s.ShortLinkRepository.GetByKey(cmp.Or(tenantId, "SYSTEM"), key)
+	if err != nil || link == nil {
+		return err
+	}
+	return s.DeleteById(link.Id)
 }
 
 func (s *ShortLinkService) Generate(url string) *domain.ShortLink {
@@ -53,29 +113,192 @@ func (s *ShortLinkService) Generate(url string) *domain.ShortLink {
 }
 
 func (s *ShortLinkService) GenerateShortLink(name string, url string, duration time.Duration) (*domain.ShortLink, error) {
+	return s.CreateShortLink(&ShortLinkOptions{Name: name, Url: url, Duration: duration})
+}
+
+func (s *ShortLinkService) CreateShortLink(options *ShortLinkOptions) (*domain.ShortLink, error) {
+	duration := options.Duration
+	if duration <= 0 {
+		duration = config.Setting.Expired
+	}
+
 	var result = domain.ShortLink{
-		Name:        name,
-		Key:         util.GenerateRandomString(5),
-		Url:         url,
-		ExpiredTime: util.TimeAddr(time.Now().Add(duration)),
+		Name:           options.Name,
+		Url:            options.Url,
+		OneTime:        options.OneTime,
+		RedirectStatus: options.RedirectStatus,
+		ExpiredTime:    util.TimeAddr(time.Now().Add(duration)),
+		TenantId:       cmp.Or(options.TenantId, "SYSTEM"),
+	}
+	if options.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(options.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		result.Password = string(hash)
+	}
+
+	if options.Key != "" {
+		// 自定义短链在租户内唯一，不重试，冲突时直接返回
+		if !keyPattern.MatchString(options.Key) {
+			return nil, ErrInvalidKey
+		}
+		if exists, err := s.ShortLinkRepository.ExistsKey(result.TenantId, options.Key); err != nil {
+			return nil, err
+		} else if exists {
+			return nil, ErrKeyExists
+		}
+
+		result.Key = options.Key
+		if _, err := s.ShortLinkRepository.CreateShortLink(&result); err != nil {
+			if exists, _ := s.ShortLinkRepository.ExistsKey(result.TenantId, result.Key); exists {
+				return nil, ErrKeyExists
+			}
+			return nil, err
+		}
+		return s.fullPath(&result, nil)
+	}
+
+	for i := 0; i <= max(config.Setting.MaxRetries, 0); i++ {
+		result.Id, result.Key = "", s.generateKey()
+		if exists, err := s.ShortLinkRepository.ExistsKey(result.TenantId, result.Key); err != nil {
+			return nil, err
+		} else if exists {
+			continue
+		}
+
+		if _, err := s.ShortLinkRepository.CreateShortLink(&result); err == nil {
+			return s.fullPath(&result, nil)
+		} else if exists, _ := s.ShortLinkRepository.ExistsKey(result.TenantId, result.Key); !exists {
+			return nil, err
+		}
+		logger.Debug("Short link key collision, retry: ", result.Key)
+	}
+	return nil, ErrKeyExhausted
+}
+
+func (s *ShortLinkService) generateKey() string {
+	if config.Setting.KeyStrategy == config.KEY_SNOWFLAKE {
+		return encodeBase62(snowflake.SnowflakeIdGenerator().GetId())
+	}
+	return util.GenerateRandomString(max(config.Setting.Length, 1))
+}
+
+func encodeBase62(value int64) string {
+	if value <= 0 {
+		return "0"
+	}
+
+	var result []byte
+	for n := uint64(value); n > 0; n /= 62 {
+		result = append(result, base62[n%62])
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
+
+// 解析跳转目标：已过期、一次性链接已使用时返回空；设置了密码时需校验
+func (s *ShortLinkService) Resolve(tenantId string, key string, password string) (*domain.ShortLink, error) {
+	link, err := s.ShortLinkRepository.GetByKey(cmp.Or(tenantId, "SYSTEM"), key)
+	if err != nil || link == nil {
+		return nil, err
 	}
 
-	root, _ := strings.CutSuffix(config.Setting.BaseUrl, "/")
-	result.FullPath = root + "/"
-	if config.Setting.Context != "" {
-		result.FullPath = result.FullPath + config.Setting.Context + "/"
+	if link.ExpiredTime != nil && link.ExpiredTime.Before(time.Now()) {
+		return nil, nil
 	}
-	result.FullPath = result.FullPath + result.Key
 
-	return s.ShortLinkRepository.CreateShortLink(&result)
+	if link.Password != "" {
+		if password == "" || bcrypt.CompareHashAndPassword([]byte(link.Password), []byte(password)) != nil {
+			return nil, ErrPasswordRequired
+		}
+	}
+
+	if link.OneTime {
+		if used, err := s.ShortLinkRepository.UseOnce(link.Id); err != nil {
+			return nil, err
+		} else if !used {
+			return nil, nil
+		}
+	}
+
+	return link, nil
 }
 
-func (s *ShortLinkService) Start() {
-	logger.Info("Starting shortlink expire routine...")
-	go func() {
-		for {
-			s.ShortLinkRepository.ExpireExpiredShortLinks()
-			time.Sleep(time.Hour * time.Duration(1))
+// 跳转状态码：一次性和带密码的链接总是 302，避免浏览器缓存
+func (s *ShortLinkService) RedirectStatus(link *domain.ShortLink) int {
+	if link.OneTime || link.Password != "" {
+		return 302
+	}
+
+	switch link.RedirectStatus {
+	case 301, 302:
+		return link.RedirectStatus
+	}
+	if config.Setting.RedirectStatus == 301 {
+		return 301
+	}
+	return 302
+}
+
+// 记录访问，country 为空时按 IP 判断
+func (s *ShortLinkService) RecordClick(link *domain.ShortLink, click *domain.ShortLinkClick) {
+	click.LinkId = link.Id
+	if click.Country == "" {
+		if location := s.locate(click.Ip); location != nil {
+			click.Country, click.Region = location.Country, location.Region
 		}
-	}()
+	}
+
+	if err := s.ShortLinkClickRepository.CreateClick(click); err != nil {
+		logger.Warn("Record shortlink click error: ", link.Key, err.Error())
+		return
+	}
+	if err := s.ShortLinkRepository.IncreaseClicks(link.Id); err != nil {
+		logger.Warn("Increase shortlink clicks error: ", link.Key, err.Error())
+	}
+}
+
+func (s *ShortLinkService) locate(ip string) *Location {
+	if s.IpLocator != nil {
+		if location := s.IpLocator.Locate(ip); location != nil {
+			return location
+		}
+	}
+
+	if addr := net.ParseIP(ip); addr != nil && (addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast()) {
+		return &Location{Country: "LAN"}
+	}
+	return nil
+}
+
+// 统计 [from, to) 内的访问，默认最近 30 天
+func (s *ShortLinkService) Stats(link *domain.ShortLink, from, to time.Time) (*domain.ShortLinkStats, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+
+	return s.ShortLinkClickRepository.Stats(link.Id, from, to, 10)
+}
+
+func (s *ShortLinkService) fullPath(link *domain.ShortLink, err error) (*domain.ShortLink, error) {
+	if link != nil {
+		root, _ := strings.CutSuffix(config.Setting.BaseUrl, "/")
+		link.FullPath = root + "/"
+		if config.Setting.Context != "" {
+			link.FullPath = link.FullPath + config.Setting.Context + "/"
+		}
+		// 租户短链带租户前缀：{context}/{tenantId}/{key}
+		if link.TenantId != "" && link.TenantId != "SYSTEM" {
+			link.FullPath = link.FullPath + link.TenantId + "/"
+		}
+		link.FullPath = link.FullPath + link.Key
+		link.Protected = link.Password != ""
+	}
+	return link, err
 }
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gophab/gophrame/module/slink/config"
+	"github.com/gophab/gophrame/module/slink/domain"
+)
+
+func TestFullPathWithTenant(t *testing.T) {
+	defer func(baseUrl, context string) {
+		config.Setting.BaseUrl, config.Setting.Context = baseUrl, context
+	}(config.Setting.BaseUrl, config.Setting.Context)
+	config.Setting.BaseUrl, config.Setting.Context = "https://s.example.com/", "l"
+
+	cases := map[string]string{
+		"":         "https://s.example.com/l/promo",
+		"SYSTEM":   "https://s.example.com/l/promo",
+		"tenant-a": "https://s.example.com/l/tenant-a/promo",
+	}
+	for tenantId, want := range cases {
+		link, _ := shortLinkService.fullPath(&domain.ShortLink{Key: "promo", TenantId: tenantId}, nil)
+		if link.FullPath != want {
+			t.Fatalf("tenant %q: full path %s, want %s", tenantId, link.FullPath, want)
+		}
+	}
+}
+
+func TestEncodeBase62(t *testing.T) {
+	if got := encodeBase62(0); got != "0" {
+		t.Fatalf("encode 0 = %s", got)
+	}
+	if got := encodeBase62(62*62 + 61); got != "10Z" {
+		t.Fatalf("encode = %s", got)
+	}
+}
@@ -0,0 +1,113 @@
+package system
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gophab/gophrame/core/command"
+	"github.com/gophab/gophrame/module/system/domain"
+	"github.com/gophab/gophrame/module/system/service"
+	SystemDto "github.com/gophab/gophrame/module/system/service/dto"
+	"github.com/gophab/gophrame/service/dto"
+)
+
+func init() {
+	command.RegisterCommand(&command.Command{
+		Name:        "user create-admin",
+		Usage:       "--login <login> [--password ...] [--name ...] [--tenant ...]",
+		Description: "Create an administrator user (password is read from stdin when omitted)",
+		Bootstrap:   command.BOOTSTRAP_INIT,
+		Run:         createAdminCommand,
+	})
+
+	command.RegisterCommand(&command.Command{
+		Name:        "tenant create",
+		Usage:       "--id <id> --name <name>",
+		Description: "Create a tenant",
+		Bootstrap:   command.BOOTSTRAP_INIT,
+		Run:         createTenantCommand,
+	})
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func createAdminCommand(args []string) error {
+	flags := command.NewFlagSet(command.GetCommand("user create-admin"))
+	login := flags.String("login", "", "Login name")
+	password := flags.String("password", "", "Password")
+	name := flags.String("name", "", "Display name")
+	mobile := flags.String("mobile", "", "Mobile")
+	email := flags.String("email", "", "Email")
+	tenant := flags.String("tenant", "SYSTEM", "Tenant id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *login == "" {
+		return errors.New("--login is required")
+	}
+
+	if *password == "" {
+		// 从标准输入读取，避免密码出现在 shell 历史中
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if *password == "" {
+		return errors.New("password is required")
+	}
+
+	admin := true
+	user, err := service.GetUserService().Create(&SystemDto.User{
+		User: dto.User{
+			Login:         login,
+			PlainPassword: password,
+			Name:          optional(*name),
+			Mobile:        optional(*mobile),
+			Email:         optional(*email),
+			TenantId:      tenant,
+			Admin:         &admin,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created admin user %s (%s)\n", *login, user.Id)
+	return nil
+}
+
+func createTenantCommand(args []string) error {
+	flags := command.NewFlagSet(command.GetCommand("tenant create"))
+	id := flags.String("id", "", "Tenant id")
+	name := flags.String("name", "", "Tenant name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *id == "" || *name == "" {
+		return errors.New("--id and --name are required")
+	}
+
+	tenant, err := service.GetTenantService().Create(&domain.Tenant{
+		Id:   *id,
+		Name: *name,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created tenant %s (%s)\n", tenant.Name, tenant.Id)
+	return nil
+}
@@ -4,8 +4,11 @@ import (
 	"strconv"
 
 	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/excel"
+	"github.com/gophab/gophrame/core/i18n"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/query"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
 	"github.com/gophab/gophrame/core/webservice/request"
 	"github.com/gophab/gophrame/core/webservice/response"
 	"github.com/gophab/gophrame/errors"
@@ -32,6 +35,7 @@ type OrganizationOpenController struct {
 type AdminOrganizationOpenController struct {
 	controller.ResourceController
 	OrganizationService *service.OrganizationService `inject:"organizationService"`
+	ImportService       *service.ImportService       `inject:"importService"`
 }
 
 // 组织
@@ -97,6 +101,7 @@ func (m *AdminOrganizationOpenController) AfterInitialize() {
 		{HttpMethod: "POST", ResourcePath: "/organization", Handler: m.CreateOrganization},
 		{HttpMethod: "PUT", ResourcePath: "/organization", Handler: m.UpdateOrganization},
 		{HttpMethod: "DELETE", ResourcePath: "/organization/:id", Handler: m.DeleteOrganization},
+		{HttpMethod: "POST", ResourcePath: "/organizations/import", Handler: m.ImportOrganizations},
 	})
 }
 
@@ -194,3 +199,30 @@ func (a *AdminOrganizationOpenController) DeleteOrganization(c *gin.Context) {
 		response.SystemErrorMessage(c, errors.ERROR_DELETE_FAIL, err.Error())
 	}
 }
+
+// 导入：上传 xlsx 或 csv，?mode=insert|upsert&dryRun=true，返回任务 Id
+func (a *AdminOrganizationOpenController) ImportOrganizations(c *gin.Context) {
+	_, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.FailMessage(c, 400, "接收文件失败")
+		return
+	}
+
+	mode := request.Param(c, "mode").DefaultString(excel.IMPORT_INSERT)
+	if mode != excel.IMPORT_INSERT && mode != excel.IMPORT_UPSERT {
+		response.FailCode(c, errors.INVALID_PARAMS)
+		return
+	}
+
+	options := excel.ImportOptions{
+		Mode:   mode,
+		DryRun: request.Param(c, "dryRun").DefaultBool(false),
+		Locale: i18n.GetEnableLanguage(),
+	}
+
+	if taskId, err := a.ImportService.Submit(SecurityUtil.GetCurrentUserId(c), SecurityUtil.GetCurrentTenantId(c), service.TASK_ORGANIZATION_IMPORT, "导入组织", header, options); err == nil {
+		response.Success(c, taskId)
+	} else {
+		response.SystemFail(c, err)
+	}
+}
@@ -1,12 +1,12 @@
 package openapi
 
 import (
-	"bufio"
 	"strings"
 
 	"github.com/gophab/gophrame/core/controller"
 	EmailCode "github.com/gophab/gophrame/core/email/code"
 	"github.com/gophab/gophrame/core/excel"
+	"github.com/gophab/gophrame/core/i18n"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/query"
@@ -34,8 +34,8 @@ type UserOpenController struct {
 	InviteCodeService  *service.InviteCodeService    `inject:"inviteCodeService"`
 	UserMapper         *mapper.UserMapper            `inject:"userMapper"`
 	SocialUserMapper   *mapper.SocialUserMapper      `inject:"socialUserMapper"`
-	SmsCodeValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator"`
-	EmailCodeValidator *EmailCode.EmailCodeValidator `inject:"emailCodeValidator"`
+	SmsCodeValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator,optional"`
+	EmailCodeValidator *EmailCode.EmailCodeValidator `inject:"emailCodeValidator,optional"`
 }
 
 var userOpenController *UserOpenController = &UserOpenController{}
@@ -48,6 +48,8 @@ type AdminUserOpenController struct {
 	InviteCodeService *service.InviteCodeService `inject:"inviteCodeService"`
 	UserMapper        *mapper.UserMapper         `inject:"userMapper"`
 	SocialUserMapper  *mapper.SocialUserMapper   `inject:"socialUserMapper"`
+	ImportService     *service.ImportService     `inject:"importService"`
+	ExportService     *service.ExportService     `inject:"exportService"`
 }
 
 var adminUserOpenController *AdminUserOpenController = &AdminUserOpenController{}
@@ -302,6 +304,11 @@ func (u *UserOpenController) ChangeUserMobile(c *gin.Context) {
 		return
 	}
 
+	if u.SmsCodeValidator == nil {
+		response.FailMessage(c, errors.INVALID_PARAMS, "不支持手机验证码")
+		return
+	}
+
 	if !u.SmsCodeValidator.CheckCode(u.SmsCodeValidator, request.Target, "bind", request.Code) {
 		response.Forbidden(c, "Forbidden")
 		return
@@ -326,6 +333,11 @@ func (u *UserOpenController) ChangeUserEmail(c *gin.Context) {
 		return
 	}
 
+	if u.EmailCodeValidator == nil {
+		response.FailMessage(c, errors.INVALID_PARAMS, "不支持邮箱验证码")
+		return
+	}
+
 	if !u.EmailCodeValidator.CheckCode(u.EmailCodeValidator, request.Target, "bind", request.Code) {
 		response.Forbidden(c, "Forbidden")
 		return
@@ -347,6 +359,7 @@ func (m *AdminUserOpenController) AfterInitialize() {
 		{HttpMethod: "POST", ResourcePath: "/user", Handler: m.CreateUser},
 		{HttpMethod: "POST", ResourcePath: "/users", Handler: m.CreateUsers},
 		{HttpMethod: "POST", ResourcePath: "/users/import", Handler: m.ImportUsers},
+		{HttpMethod: "POST", ResourcePath: "/users/export", Handler: m.ExportUsers},
 		{HttpMethod: "PUT", ResourcePath: "/user", Handler: m.UpdateUser},
 		{HttpMethod: "PATCH", ResourcePath: "/user/:id", Handler: m.PatchUser},
 		{HttpMethod: "DELETE", ResourcePath: "/user/:id", Handler: m.DeleteUser},
@@ -604,88 +617,77 @@ func (u *AdminUserOpenController) CreateUsers(c *gin.Context) {
 	response.OK(c, result)
 }
 
-// @Summary   增加用户
+// @Summary   导入用户
 // @Tags  users
-// @Accept json
+// @Accept multipart/form-data
 // @Produce  json
-// @Param   body  body   models.User   true "body"
-// @Success 200 {string} json "{ "code": 200, "data": {}, "msg": "ok" }"
+// @Param   file  formData   file   true "xlsx 或 csv"
+// @Param   mode  query   string   false "insert | upsert"
+// @Param   dryRun  query   bool   false "只校验不写入"
+// @Success 200 {string} json "{ "code": 200, "data": "任务Id", "msg": "ok" }"
 // @Failure 400 {string} json
-// @Router /api/v1/users  [POST]
+// @Router /api/v1/users/import  [POST]
 func (u *AdminUserOpenController) ImportUsers(c *gin.Context) {
-	file, _, err := c.Request.FormFile("file")
+	_, header, err := c.Request.FormFile("file")
 	if err != nil {
 		response.FailMessage(c, 400, "接收文件失败")
 		return
 	}
 
-	tenantId := SecurityUtil.GetCurrentTenantId(c)
+	mode := request.Param(c, "mode").DefaultString(excel.IMPORT_INSERT)
+	if mode != excel.IMPORT_INSERT && mode != excel.IMPORT_UPSERT {
+		response.FailCode(c, errors.INVALID_PARAMS)
+		return
+	}
 
-	// 异步执行导入
-	go func() {
-		defer func() {
-			file.Close()
-		}()
-
-		excel.NewImporter().ReadReader(bufio.NewReader(file), func(rows []map[string]string) {
-			for _, row := range rows {
-				var skip = false
-				// 处理每条记录
-				var user dto.User
-				user.Name = util.Nullable(util.StringAddr(row["name"]))
-				user.Login = util.Nullable(util.StringAddr(row["login"]))
-				user.Email = util.Nullable(util.StringAddr(row["email"]))
-				user.Mobile = util.Nullable(util.StringAddr(row["mobile"]))
-				user.Password = util.Nullable(util.StringAddr(row["password"]))
-				user.PlainPassword = util.Nullable(util.StringAddr("!12345678!"))
-
-				if user.Name == nil && user.Mobile == nil && user.Email == nil {
-					skip = true
-				}
-
-				valid := validation.Validation{}
-
-				valid.MaxSize(user.Name, 100, "name").Message("最长为100字符")
-
-				if user.Login != nil && *user.Login != "" {
-					valid.Min(*user.Login, 5, "login").Message("至少5个字符")
-					valid.AlphaDash(*user.Login, "login").Message("账号格式错误")
-				} else {
-					user.Login = nil
-				}
-
-				if user.Mobile != nil && *user.Mobile != "" {
-					valid.Check(*user.Mobile, util.NewInternationalTelephoneValidator("mobile")).Message("无效手机号")
-				} else {
-					user.Mobile = nil
-				}
-
-				if user.Email != nil && *user.Email != "" {
-					valid.Email(user.Email, "email").Message("无效的邮箱地址")
-				} else {
-					user.Email = nil
-				}
-
-				if user.Password != nil && *user.Password != "" {
-					valid.MaxSize(*user.Password, 100, "password").Message("最长为100字符")
-					valid.MinSize(*user.Password, 6, "password").Message("最短为6字符")
-				} else {
-					user.Password = nil
-				}
-
-				if valid.HasErrors() {
-					skip = true
-				}
-
-				if !skip {
-					user.TenantId = util.StringAddr(tenantId)
-					u.UserService.Create(&user)
-				}
-			}
-		}, 50)
-	}()
+	options := excel.ImportOptions{
+		Mode:   mode,
+		DryRun: request.Param(c, "dryRun").DefaultBool(false),
+		Locale: i18n.GetEnableLanguage(),
+	}
 
-	response.OK(c, nil)
+	if taskId, err := u.ImportService.Submit(SecurityUtil.GetCurrentUserId(c), SecurityUtil.GetCurrentTenantId(c), service.TASK_USER_IMPORT, "导入用户", header, options); err == nil {
+		response.Success(c, taskId)
+	} else {
+		response.SystemFail(c, err)
+	}
+}
+
+// @Summary   导出用户
+// @Tags  users
+// @Produce  json
+// @Param   format  query   string   false "xlsx | csv | pdf"
+// @Success 200 {string} json "{ "code": 200, "data": "任务Id", "msg": "ok" }"
+// @Failure 400 {string} json
+// @Router /api/v1/users/export  [POST]
+func (u *AdminUserOpenController) ExportUsers(c *gin.Context) {
+	format := request.Param(c, "format").DefaultString(excel.FORMAT_XLSX)
+	if format != excel.FORMAT_XLSX && format != excel.FORMAT_CSV && format != excel.FORMAT_PDF {
+		response.FailCode(c, errors.INVALID_PARAMS)
+		return
+	}
+
+	conds := make(map[string]any)
+	for _, key := range []string{"search", "name", "login", "mobile", "email"} {
+		if value := request.Param(c, key).DefaultString(""); value != "" {
+			conds[key] = value
+		}
+	}
+	if tenantId := SecurityUtil.GetCurrentTenantId(c); tenantId != "" {
+		conds["tenantId"] = tenantId
+	}
+
+	options := excel.ExportOptions{
+		Format: format,
+		Title:  "用户",
+		Locale: i18n.GetEnableLanguage(),
+	}
+
+	if taskId, err := u.ExportService.Submit(SecurityUtil.GetCurrentUserId(c), service.TASK_USER_EXPORT, "导出用户", conds, options); err == nil {
+		response.Success(c, taskId)
+	} else {
+		response.SystemFail(c, err)
+	}
 }
 
 // @Summary   更新用户
@@ -18,3 +18,7 @@ type Organization struct {
 func (*Organization) TableName() string {
 	return "sys_organization"
 }
+
+func (*Organization) AuditTarget() string {
+	return "organization"
+}
@@ -19,3 +19,7 @@ type Role struct {
 func (*Role) TableName() string {
 	return "sys_role"
 }
+
+func (*Role) AuditTarget() string {
+	return "role"
+}
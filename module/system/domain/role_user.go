@@ -36,6 +36,10 @@ func (a *RoleUser) TableName() string {
 	return "sys_role_user"
 }
 
+func (a *RoleUser) AuditTarget() string {
+	return "role_user"
+}
+
 // 定义不同的查询结果返回的数据结构体
 type RoleMember struct {
 	RoleUser
@@ -52,3 +52,7 @@ func (e *Tenant) BeforeSave(tx *gorm.DB) (err error) {
 func (*Tenant) TableName() string {
 	return "sys_tenant"
 }
+
+func (*Tenant) AuditTarget() string {
+	return "tenant"
+}
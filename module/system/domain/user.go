@@ -55,6 +55,10 @@ func (u *User) TableName() string {
 	return "sys_user"
 }
 
+func (u *User) AuditTarget() string {
+	return "user"
+}
+
 func (u *User) SetPassword(value string) *User {
 	u.Password = util.SHA1(value)
 	return u
@@ -1,6 +1,8 @@
 package system
 
 import (
+	"embed"
+
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/module"
 	"github.com/gophab/gophrame/core/starter"
@@ -16,9 +18,13 @@ const (
 	MODULE_ID = 1
 )
 
+//go:embed migrations
+var migrations embed.FS
+
 var _module = &module.Module{
 	Name:        "System",
 	Description: "",
+	Migrations:  migrations,
 }
 
 func init() {
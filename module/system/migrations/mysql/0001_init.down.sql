@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS sys_invite_code;
+DROP TABLE IF EXISTS sys_social_user;
+DROP TABLE IF EXISTS sys_organization_user;
+DROP TABLE IF EXISTS sys_organization;
+DROP TABLE IF EXISTS sys_role_user;
+DROP TABLE IF EXISTS sys_role;
+DROP TABLE IF EXISTS sys_tenant;
+DROP TABLE IF EXISTS sys_user;
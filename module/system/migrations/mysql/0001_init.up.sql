@@ -0,0 +1,164 @@
+CREATE TABLE IF NOT EXISTS sys_user (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    login VARCHAR(255),
+    mobile VARCHAR(255),
+    email VARCHAR(255),
+    name VARCHAR(255),
+    status INT,
+    avatar TEXT,
+    remark TEXT,
+    inviter_id VARCHAR(64),
+    login_times INT DEFAULT 0,
+    last_login_time DATETIME(3),
+    last_login_ip VARCHAR(255),
+    password VARCHAR(255),
+    admin TINYINT(1) DEFAULT false,
+    organization_id BIGINT,
+    PRIMARY KEY (id),
+    KEY idx_sys_user_tenant_id (tenant_id),
+    KEY idx_sys_user_login (login),
+    KEY idx_sys_user_mobile (mobile),
+    KEY idx_sys_user_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_tenant (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    name_cn VARCHAR(255),
+    name_tw VARCHAR(255),
+    name_en VARCHAR(255),
+    description TEXT,
+    logo VARCHAR(255),
+    license_id VARCHAR(64),
+    address VARCHAR(255),
+    telephone VARCHAR(255),
+    fax VARCHAR(255),
+    status INT DEFAULT 0,
+    remark TEXT,
+    created_time DATETIME(3),
+    last_modified_time DATETIME(3),
+    created_by VARCHAR(255),
+    last_modified_by VARCHAR(255),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_role (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    name VARCHAR(255),
+    title VARCHAR(255),
+    description TEXT,
+    scope VARCHAR(255) DEFAULT 'TENANT',
+    includes VARCHAR(255),
+    PRIMARY KEY (id),
+    KEY idx_sys_role_tenant_id (tenant_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_role_user (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    role_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    status INT DEFAULT 1,
+    remark TEXT,
+    PRIMARY KEY (role_id, user_id),
+    KEY idx_sys_role_user_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_organization (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    fid VARCHAR(255),
+    name VARCHAR(255),
+    status VARCHAR(255),
+    path_info VARCHAR(255),
+    remark TEXT,
+    PRIMARY KEY (id),
+    KEY idx_sys_organization_tenant_id (tenant_id),
+    KEY idx_sys_organization_fid (fid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_organization_user (
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    organization_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    position_id BIGINT,
+    status INT DEFAULT 1,
+    remark TEXT,
+    PRIMARY KEY (organization_id, user_id),
+    KEY idx_sys_organization_user_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_social_user (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time DATETIME(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time DATETIME(3),
+    tenant_id VARCHAR(64),
+    del_flag TINYINT(1) DEFAULT false,
+    deleted_time DATETIME(3),
+    deleted_by VARCHAR(255),
+    type VARCHAR(255),
+    open_id VARCHAR(64),
+    social_id VARCHAR(64),
+    nick_name VARCHAR(255),
+    title VARCHAR(255),
+    mobile VARCHAR(255),
+    email VARCHAR(255),
+    name VARCHAR(255),
+    avatar TEXT,
+    remark TEXT,
+    status INT DEFAULT 1,
+    login_times INT,
+    last_login_time DATETIME(3),
+    last_login_ip VARCHAR(255),
+    user_id VARCHAR(64),
+    PRIMARY KEY (id),
+    KEY idx_sys_social_user_user_id (user_id),
+    KEY idx_sys_social_user_type_open_id (type, open_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sys_invite_code (
+    id VARCHAR(64) NOT NULL,
+    invite_code VARCHAR(255),
+    user_id VARCHAR(64),
+    channel VARCHAR(255),
+    expire_time DATETIME(3),
+    invite_limit BIGINT,
+    invited_limit BIGINT,
+    PRIMARY KEY (id),
+    KEY idx_sys_invite_code_invite_code (invite_code),
+    KEY idx_sys_invite_code_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,177 @@
+CREATE TABLE IF NOT EXISTS sys_user (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    del_flag BOOLEAN DEFAULT false,
+    deleted_time TIMESTAMP(3),
+    deleted_by VARCHAR(255),
+    login VARCHAR(255),
+    mobile VARCHAR(255),
+    email VARCHAR(255),
+    name VARCHAR(255),
+    status INT,
+    avatar TEXT,
+    remark TEXT,
+    inviter_id VARCHAR(64),
+    login_times INT DEFAULT 0,
+    last_login_time TIMESTAMP(3),
+    last_login_ip VARCHAR(255),
+    password VARCHAR(255),
+    admin BOOLEAN DEFAULT false,
+    organization_id BIGINT,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_user_tenant_id ON sys_user (tenant_id);
+
+CREATE INDEX IF NOT EXISTS idx_sys_user_login ON sys_user (login);
+
+CREATE INDEX IF NOT EXISTS idx_sys_user_mobile ON sys_user (mobile);
+
+CREATE INDEX IF NOT EXISTS idx_sys_user_email ON sys_user (email);
+
+CREATE TABLE IF NOT EXISTS sys_tenant (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    name_cn VARCHAR(255),
+    name_tw VARCHAR(255),
+    name_en VARCHAR(255),
+    description TEXT,
+    logo VARCHAR(255),
+    license_id VARCHAR(64),
+    address VARCHAR(255),
+    telephone VARCHAR(255),
+    fax VARCHAR(255),
+    status INT DEFAULT 0,
+    remark TEXT,
+    created_time TIMESTAMP(3),
+    last_modified_time TIMESTAMP(3),
+    created_by VARCHAR(255),
+    last_modified_by VARCHAR(255),
+    del_flag BOOLEAN DEFAULT false,
+    deleted_time TIMESTAMP(3),
+    deleted_by VARCHAR(255),
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS sys_role (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    del_flag BOOLEAN DEFAULT false,
+    deleted_time TIMESTAMP(3),
+    deleted_by VARCHAR(255),
+    name VARCHAR(255),
+    title VARCHAR(255),
+    description TEXT,
+    scope VARCHAR(255) DEFAULT 'TENANT',
+    includes VARCHAR(255),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_role_tenant_id ON sys_role (tenant_id);
+
+CREATE TABLE IF NOT EXISTS sys_role_user (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    role_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    status INT DEFAULT 1,
+    remark TEXT,
+    PRIMARY KEY (role_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_role_user_user_id ON sys_role_user (user_id);
+
+CREATE TABLE IF NOT EXISTS sys_organization (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    del_flag BOOLEAN DEFAULT false,
+    deleted_time TIMESTAMP(3),
+    deleted_by VARCHAR(255),
+    fid VARCHAR(255),
+    name VARCHAR(255),
+    status VARCHAR(255),
+    path_info VARCHAR(255),
+    remark TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_organization_tenant_id ON sys_organization (tenant_id);
+
+CREATE INDEX IF NOT EXISTS idx_sys_organization_fid ON sys_organization (fid);
+
+CREATE TABLE IF NOT EXISTS sys_organization_user (
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    organization_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    position_id BIGINT,
+    status INT DEFAULT 1,
+    remark TEXT,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_organization_user_user_id ON sys_organization_user (user_id);
+
+CREATE TABLE IF NOT EXISTS sys_social_user (
+    id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    created_time TIMESTAMP(3),
+    last_modified_by VARCHAR(255),
+    last_modified_time TIMESTAMP(3),
+    tenant_id VARCHAR(64),
+    del_flag BOOLEAN DEFAULT false,
+    deleted_time TIMESTAMP(3),
+    deleted_by VARCHAR(255),
+    type VARCHAR(255),
+    open_id VARCHAR(64),
+    social_id VARCHAR(64),
+    nick_name VARCHAR(255),
+    title VARCHAR(255),
+    mobile VARCHAR(255),
+    email VARCHAR(255),
+    name VARCHAR(255),
+    avatar TEXT,
+    remark TEXT,
+    status INT DEFAULT 1,
+    login_times INT,
+    last_login_time TIMESTAMP(3),
+    last_login_ip VARCHAR(255),
+    user_id VARCHAR(64),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_social_user_user_id ON sys_social_user (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_sys_social_user_type_open_id ON sys_social_user (type, open_id);
+
+CREATE TABLE IF NOT EXISTS sys_invite_code (
+    id VARCHAR(64) NOT NULL,
+    invite_code VARCHAR(255),
+    user_id VARCHAR(64),
+    channel VARCHAR(255),
+    expire_time TIMESTAMP(3),
+    invite_limit BIGINT,
+    invited_limit BIGINT,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sys_invite_code_invite_code ON sys_invite_code (invite_code);
+
+CREATE INDEX IF NOT EXISTS idx_sys_invite_code_user_id ON sys_invite_code (user_id);
@@ -49,6 +49,14 @@ func (r *OrganizationRepository) GetByIds(ids []string) ([]*domain.Organization,
 	}
 }
 
+func (r *OrganizationRepository) GetByNames(tenantId string, names []string) ([]*domain.Organization, error) {
+	var results = make([]*domain.Organization, 0)
+	if res := r.Model(&domain.Organization{}).Where("tenant_id = ? AND name in ?", tenantId, names).Where("del_flag = ?", false).Find(&results); res.Error != nil {
+		return nil, res.Error
+	}
+	return results, nil
+}
+
 func (r *OrganizationRepository) GetParentById(id string) (*domain.Organization, error) {
 	if org, err := r.GetById(id); err == nil && org != nil {
 		if org.Fid != "" && org.Fid != "0" {
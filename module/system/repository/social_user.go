@@ -129,3 +129,12 @@ func (r *SocialUserRepository) ConditionChanges(conds map[string]any, columns ma
 		return 0, res.Error
 	}
 }
+
+func (r *SocialUserRepository) GetByUserIds(userIds []string) ([]*domain.SocialUser, error) {
+	var results = make([]*domain.SocialUser, 0)
+	if res := r.Where("user_id in ?", userIds).Where("del_flag=?", false).Find(&results); res.Error == nil {
+		return results, nil
+	} else {
+		return nil, res.Error
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/gophab/gophrame/core/inject"
@@ -146,6 +147,10 @@ func (r *UserRepository) buildQuery(conds map[string]any) *gorm.DB {
 	return q
 }
 
+func (r *UserRepository) QueryBy(conds map[string]any) *gorm.DB {
+	return r.buildQuery(conds)
+}
+
 func (r *UserRepository) GetAll(conds map[string]any) ([]*domain.User, error) {
 	var users []*domain.User = make([]*domain.User, 0)
 
@@ -225,6 +230,30 @@ func (h *UserRepository) GetUserByIds(ids []string) ([]*domain.User, error) {
 	return users, nil
 }
 
+// 按登录名、手机号或邮箱批量查找租户内的用户
+func (h *UserRepository) GetUsersByAccounts(tenantId string, logins, mobiles, emails []string) ([]*domain.User, error) {
+	var users = make([]*domain.User, 0)
+	if len(logins)+len(mobiles)+len(emails) == 0 {
+		return users, nil
+	}
+
+	var conds = h.Where("1 = 0")
+	if len(logins) > 0 {
+		conds = conds.Or("login in ?", logins)
+	}
+	if len(mobiles) > 0 {
+		conds = conds.Or("mobile in ?", mobiles)
+	}
+	if len(emails) > 0 {
+		conds = conds.Or("email in ?", emails)
+	}
+
+	if res := h.Model(&domain.User{}).Where(conds).Where("tenant_id = ? AND del_flag = ?", tenantId, false).Find(&users); res.Error != nil {
+		return nil, res.Error
+	}
+	return users, nil
+}
+
 func (h *UserRepository) UpdateUser(entity *domain.User) error {
 	var user domain.User
 	if res := h.Where("id = ? AND del_flag = ? ", entity.Id, false).Find(&user); res.Error != nil {
@@ -391,3 +420,47 @@ func (a *UserRepository) LogUserLogin(userId string, loginIp string) error {
 	sql := `UPDATE sys_user SET login_times = login_times + 1, last_login_time=?, last_login_ip=? WHERE id=?`
 	return a.Exec(sql, time.Now(), loginIp, userId).Error
 }
+
+// 通知接收人：指定的用户，以及角色、组织、租户下的全部有效用户
+// tenantId 不为空时角色、组织只在该租户内展开，非 SYSTEM 租户只能展开自身、只能指定本租户用户
+func (r *UserRepository) GetUsersByMembership(tenantId string, userIds, roleIds, organizationIds, tenantIds []string) ([]*domain.User, error) {
+	conds := r.Where("1 = 0")
+	if len(userIds) > 0 {
+		if tenantId != "" && tenantId != "SYSTEM" {
+			conds = conds.Or("id in ? AND tenant_id = ?", userIds, tenantId)
+		} else {
+			conds = conds.Or("id in ?", userIds)
+		}
+	}
+	if len(roleIds) > 0 {
+		roleUsers := r.Table("sys_role_user").Select("user_id").Where("role_id in ?", roleIds)
+		if tenantId != "" {
+			roleUsers = roleUsers.Where("tenant_id = ?", tenantId)
+		}
+		conds = conds.Or("id in (?)", roleUsers)
+	}
+	if len(organizationIds) > 0 {
+		organizations := r.Table("sys_organization").Select("id").Where("id in ?", organizationIds)
+		if tenantId != "" {
+			organizations = organizations.Where("tenant_id = ?", tenantId)
+		}
+		conds = conds.Or("id in (?)", r.Table("sys_organization_user").Select("user_id").Where("organization_id in (?)", organizations))
+	}
+	if tenantId != "" && tenantId != "SYSTEM" {
+		if slices.Contains(tenantIds, tenantId) {
+			tenantIds = []string{tenantId}
+		} else {
+			tenantIds = nil
+		}
+	}
+	if len(tenantIds) > 0 {
+		conds = conds.Or("tenant_id in ?", tenantIds)
+	}
+
+	var users = make([]*domain.User, 0)
+	if res := r.Model(&domain.User{}).Where(conds).Where("del_flag = ?", false).Find(&users); res.Error == nil {
+		return users, nil
+	} else {
+		return nil, res.Error
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/gophab/gophrame/module/system/domain"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 只生成 SQL，不连接数据库
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func membershipSQL(t *testing.T, tenantId string, tenantIds []string) string {
+	var statement string
+	db := dryRun(t)
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		if tx.Statement.Schema != nil && tx.Statement.Schema.Table == (&domain.User{}).TableName() {
+			statement = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+		}
+	})
+
+	r := &UserRepository{DB: db}
+	if _, err := r.GetUsersByMembership(tenantId, []string{"u1"}, []string{"r1"}, []string{"o1"}, tenantIds); err != nil {
+		t.Fatal(err)
+	}
+	return statement
+}
+
+func TestGetUsersByMembershipScopedToTenant(t *testing.T) {
+	statement := membershipSQL(t, "t1", []string{"t1", "t2"})
+	for _, expected := range []string{
+		"id in ('u1') AND tenant_id = 't1'",
+		"role_id in ('r1') AND tenant_id = 't1'",
+		"SELECT id FROM `sys_organization` WHERE id in ('o1') AND tenant_id = 't1'",
+		"tenant_id in ('t1')",
+	} {
+		if !strings.Contains(statement, expected) {
+			t.Errorf("missing %q in %s", expected, statement)
+		}
+	}
+	if strings.Contains(statement, "'t2'") {
+		t.Errorf("other tenant expanded: %s", statement)
+	}
+
+	// 不包含自身租户时不展开任何租户
+	if statement := membershipSQL(t, "t1", []string{"t2"}); strings.Contains(statement, "tenant_id in") {
+		t.Errorf("other tenant expanded: %s", statement)
+	}
+
+	// SYSTEM 可以展开其他租户
+	if statement := membershipSQL(t, "SYSTEM", []string{"t2"}); !strings.Contains(statement, "tenant_id in ('t2')") {
+		t.Errorf("system tenant expansion missing: %s", statement)
+	} else if strings.Contains(statement, "id in ('u1') AND") {
+		t.Errorf("system tenant users scoped: %s", statement)
+	}
+}
+
+func TestGetUsersByAccountsScopedToTenant(t *testing.T) {
+	var statement string
+	db := dryRun(t)
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		statement = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+
+	r := &UserRepository{DB: db}
+	if _, err := r.GetUsersByAccounts("t1", []string{"alice"}, nil, []string{"a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(statement, "(1 = 0 OR login in ('alice') OR email in ('a@example.com')) AND (tenant_id = 't1' AND del_flag = false)") {
+		t.Fatalf("accounts not scoped to tenant: %s", statement)
+	}
+}
@@ -26,8 +26,8 @@ import (
 
 type LoginHandler struct {
 	*gorm.DB          `inject:"database"`
-	MobileValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator"`
-	EmailValidator    *EmailCode.EmailCodeValidator `inject:"emailCodeValidator"`
+	MobileValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator,optional"`
+	EmailValidator    *EmailCode.EmailCodeValidator `inject:"emailCodeValidator,optional"`
 	SocialUserService *service.SocialUserService    `inject:"socialUserService"`
 	UserService       *service.UserService          `inject:"userService"`
 	security.UserHandler
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/service"
+)
+
+// 系统实体的批量加载，供操作日志等列表展示使用
+type SystemEntityProvider struct {
+	UserService         *UserService         `inject:"userService"`
+	TenantService       *TenantService       `inject:"tenantService"`
+	OrganizationService *OrganizationService `inject:"organizationService"`
+	RoleService         *RoleService         `inject:"roleService"`
+}
+
+var systemEntityProvider = &SystemEntityProvider{}
+
+func init() {
+	inject.InjectValue("systemEntityProvider", systemEntityProvider)
+	service.RegisterEntityProvider(systemEntityProvider, "user", "tenant", "organization", "role")
+}
+
+func (p *SystemEntityProvider) GetEntities(entity string, ids []string) (map[string]any, error) {
+	var result = make(map[string]any, len(ids))
+
+	switch entity {
+	case "user":
+		users, err := p.UserService.GetByIds(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			user.Password = ""
+			result[user.Id] = user
+		}
+	case "tenant":
+		tenants, err := p.TenantService.GetByIds(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, tenant := range tenants {
+			result[tenant.Id] = tenant
+		}
+	case "organization":
+		organizations, err := p.OrganizationService.GetByIds(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, organization := range organizations {
+			result[organization.Id] = organization
+		}
+	case "role":
+		roles := p.RoleService.RoleRepository.GetByIds(ids)
+		for i := range roles {
+			result[roles[i].Id] = &roles[i]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/excel"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/service"
+
+	"github.com/gophab/gophrame/module/system/domain"
+	"github.com/gophab/gophrame/module/system/repository"
+
+	"gorm.io/gorm"
+)
+
+const TASK_USER_EXPORT = "system.user.export"
+
+type ExportPayload struct {
+	excel.ExportOptions
+	Conds map[string]any `json:"conds,omitempty"`
+}
+
+// 导出的用户，列名与导入一致，导出的文件可直接用于导入
+type UserExportRow struct {
+	Name          string     `excel:"name,alias=姓名,width=16"`
+	Login         string     `excel:"login,alias=账号,width=16"`
+	Mobile        string     `excel:"mobile,alias=手机,width=18"`
+	Email         string     `excel:"email,alias=邮箱,width=24"`
+	Status        *int       `excel:"status,alias=状态,width=8"`
+	CreatedTime   time.Time  `excel:"createdTime,alias=创建时间,width=20"`
+	LastLoginTime *time.Time `excel:"lastLoginTime,alias=最后登录时间,width=20"`
+}
+
+type ExportService struct {
+	UserRepository *repository.UserRepository `inject:"userRepository"`
+}
+
+var exportService = &ExportService{}
+
+func GetExportService() *ExportService {
+	return exportService
+}
+
+func init() {
+	inject.InjectValue("exportService", exportService)
+	service.RegisterTaskHandler(TASK_USER_EXPORT, exportService.ExportUsers)
+}
+
+// 提交导出任务，返回任务 Id
+func (s *ExportService) Submit(createdBy, handler, name string, conds map[string]any, options excel.ExportOptions) (string, error) {
+	taskService := service.GetTaskService()
+	if taskService == nil {
+		return "", ErrTaskUnavailable
+	}
+	return taskService.Submit(createdBy, handler, name, &ExportPayload{ExportOptions: options, Conds: conds})
+}
+
+func (s *ExportService) ExportUsers(ctx service.TaskContext) error {
+	var payload ExportPayload
+	if err := ctx.Bind(&payload); err != nil {
+		return err
+	}
+
+	var conds = util.DbFields(payload.Conds)
+
+	var total int64
+	if err := s.UserRepository.QueryBy(conds).Count(&total).Error; err != nil {
+		return err
+	}
+
+	users := excel.GormSource(func() *gorm.DB {
+		return s.UserRepository.QueryBy(conds)
+	}, []string{"id"}, false, func(user *domain.User) []any { return []any{user.Id} })
+
+	source := func(cursor any, limit int) ([]*UserExportRow, any, error) {
+		list, next, err := users(cursor, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var rows = make([]*UserExportRow, len(list))
+		for i, user := range list {
+			rows[i] = &UserExportRow{
+				Name:          util.NotNullString(user.Name),
+				Login:         util.NotNullString(user.Login),
+				Mobile:        util.NotNullString(user.Mobile),
+				Email:         util.NotNullString(user.Email),
+				Status:        user.Status,
+				CreatedTime:   user.CreatedTime,
+				LastLoginTime: user.LastLoginTime,
+			}
+		}
+		return rows, next, nil
+	}
+
+	_, err := excel.ExportTask(ctx, source, nil, payload.ExportOptions, total)
+	return err
+}
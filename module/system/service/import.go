@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"github.com/gophab/gophrame/core/excel"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/json"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/oss"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/service"
+	CommonDTO "github.com/gophab/gophrame/service/dto"
+
+	"github.com/gophab/gophrame/module/system/domain"
+	"github.com/gophab/gophrame/module/system/repository"
+	"github.com/gophab/gophrame/module/system/service/dto"
+)
+
+const (
+	TASK_USER_IMPORT         = "system.user.import"
+	TASK_ORGANIZATION_IMPORT = "system.organization.import"
+)
+
+// 未填写密码时的初始密码
+const defaultImportPassword = "!12345678!"
+
+var (
+	ErrTaskUnavailable    = errors.New("task service not available")
+	ErrStorageUnavailable = errors.New("oss not available")
+	ErrUserNotInTenant    = errors.New("用户不属于当前租户")
+)
+
+// 导入任务参数
+type ImportPayload struct {
+	excel.ImportOptions
+	Key      string `json:"key,omitempty"`  // 对象存储中的对象键
+	File     string `json:"file,omitempty"` // 未配置对象存储时为本地临时文件
+	TenantId string `json:"tenantId"`
+}
+
+// 导入文件中的用户
+type UserImportRow struct {
+	Name           string `excel:"name,alias=姓名|用户名" binding:"required_without_all=Mobile Email,max=100"`
+	Login          string `excel:"login,alias=账号|登录名" binding:"omitempty,min=5,max=100,alphadash"`
+	Mobile         string `excel:"mobile,alias=手机|手机号" binding:"omitempty,min=5,max=20,telephone"`
+	Email          string `excel:"email,alias=邮箱" binding:"omitempty,email,max=100"`
+	Password       string `excel:"password,alias=密码" binding:"omitempty,min=6,max=100"`
+	OrganizationId string `excel:"organization,alias=组织|部门,ref=organization"`
+	Remark         string `excel:"remark,alias=备注" binding:"max=500"`
+}
+
+// 导入文件中的组织，上级组织需已存在或在之前的批次中导入
+type OrganizationImportRow struct {
+	Name   string `excel:"name,alias=名称|组织名称" binding:"required,max=100"`
+	Fid    string `excel:"parent,alias=上级|上级组织,ref=organization"`
+	Status string `excel:"status,alias=状态" binding:"max=10"`
+	Remark string `excel:"remark,alias=备注" binding:"max=500"`
+}
+
+type ImportService struct {
+	UserService                *UserService                           `inject:"userService"`
+	UserRepository             *repository.UserRepository             `inject:"userRepository"`
+	OrganizationService        *OrganizationService                   `inject:"organizationService"`
+	OrganizationRepository     *repository.OrganizationRepository     `inject:"organizationRepository"`
+	OrganizationUserRepository *repository.OrganizationUserRepository `inject:"organizationUserRepository"`
+	Oss                        oss.OSS                                `inject:"oss,optional"`
+}
+
+var importService = &ImportService{}
+
+func GetImportService() *ImportService {
+	return importService
+}
+
+func init() {
+	inject.InjectValue("importService", importService)
+	service.RegisterTaskHandler(TASK_USER_IMPORT, importService.ImportUsers)
+	service.RegisterTaskHandler(TASK_ORGANIZATION_IMPORT, importService.ImportOrganizations)
+}
+
+// 保存上传的文件并提交导入任务，返回任务 Id
+func (s *ImportService) Submit(createdBy, tenantId, handler, name string, file *multipart.FileHeader, options excel.ImportOptions) (string, error) {
+	taskService := service.GetTaskService()
+	if taskService == nil {
+		return "", ErrTaskUnavailable
+	}
+
+	if options.Format == "" {
+		options.Format = excel.FormatOf(file.Filename)
+	}
+
+	var payload = &ImportPayload{ImportOptions: options, TenantId: tenantId}
+	if s.Oss != nil {
+		_, key, err := s.Oss.Upload(file, "import")
+		if err != nil {
+			return "", err
+		}
+		payload.Key = key
+	} else {
+		// 没有对象存储时只能由本节点读取
+		fileName, err := saveTemp(file)
+		if err != nil {
+			return "", err
+		}
+		payload.File = fileName
+	}
+
+	return taskService.Submit(createdBy, handler, name, payload)
+}
+
+func saveTemp(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "import-*"+filepath.Ext(file.Filename))
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// 通过对象存储接口读取，私有存储桶同样可用
+func (s *ImportService) openSource(ctx context.Context, payload *ImportPayload) (io.ReadCloser, error) {
+	if payload.Key != "" {
+		if s.Oss == nil {
+			return nil, ErrStorageUnavailable
+		}
+		source, _, err := s.Oss.Get(ctx, payload.Key)
+		return source, err
+	}
+	return os.Open(payload.File)
+}
+
+// 导入完成或不再重试时删除上传的文件
+func (s *ImportService) removeSource(payload *ImportPayload) {
+	if payload.Key != "" {
+		if s.Oss != nil {
+			if err := s.Oss.Delete(context.Background(), payload.Key); err != nil {
+				logger.Warn("Delete import file error: ", payload.Key, err.Error())
+			}
+		}
+	} else if payload.File != "" {
+		_ = os.Remove(payload.File)
+	}
+}
+
+// 每个批次前后保存进度，任务重试时跳过已完成的批次
+func runImport[T any](s *ImportService, ctx service.TaskContext, pipeline *excel.Pipeline[T], payload *ImportPayload) (err error) {
+	defer func() {
+		if err == nil || ctx.LastAttempt() {
+			s.removeSource(payload)
+		}
+	}()
+
+	source, err := s.openSource(ctx, payload)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	var state excel.ImportState
+	if _, err := ctx.Restore(&state); err != nil {
+		return err
+	}
+
+	pipeline.WithProgress(func(done, total int) {
+		_ = ctx.Progress(float32(done*100/total), fmt.Sprintf("%d/%d", done, total))
+	}).WithCheckpoint(&state, func(state *excel.ImportState) error {
+		return ctx.Checkpoint(state)
+	})
+
+	result, err := pipeline.Run(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	if pipeline.HasErrors() {
+		if url, err := saveErrorReport(ctx, pipeline); err == nil {
+			result.ErrorFile = url
+		} else {
+			logger.Warn("Save import error report error: ", err.Error())
+		}
+	}
+
+	ctx.SetResult(json.String(result))
+	return nil
+}
+
+func saveErrorReport[T any](ctx service.TaskContext, pipeline *excel.Pipeline[T]) (string, error) {
+	file, err := os.CreateTemp("", "import-errors-*.xlsx")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	err = pipeline.WriteErrorReport(file)
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return "", err
+	}
+	return ctx.UploadFile(file.Name(), "import")
+}
+
+// 按名称或 Id 解析组织，名称重复时取第一个
+func (s *ImportService) organizationResolver(tenantId string) excel.Resolver {
+	return func(values []string) (map[string]string, error) {
+		var result = make(map[string]string, len(values))
+
+		organizations, err := s.OrganizationRepository.GetByNames(tenantId, values)
+		if err != nil {
+			return nil, err
+		}
+		for _, organization := range organizations {
+			if _, b := result[organization.Name]; !b {
+				result[organization.Name] = organization.Id
+			}
+		}
+
+		organizations, err = s.OrganizationRepository.GetByIds(values)
+		if err != nil {
+			return nil, err
+		}
+		for _, organization := range organizations {
+			if organization.TenantId == tenantId {
+				result[organization.Id] = organization.Id
+			}
+		}
+		return result, nil
+	}
+}
+
+func (s *ImportService) ImportUsers(ctx service.TaskContext) error {
+	var payload ImportPayload
+	if err := ctx.Bind(&payload); err != nil {
+		return err
+	}
+
+	pipeline := excel.NewPipeline[UserImportRow](&userImportTarget{s, payload.TenantId}, payload.ImportOptions).
+		WithReference("organization", s.organizationResolver(payload.TenantId))
+	return runImport(s, ctx, pipeline, &payload)
+}
+
+func (s *ImportService) ImportOrganizations(ctx service.TaskContext) error {
+	var payload ImportPayload
+	if err := ctx.Bind(&payload); err != nil {
+		return err
+	}
+
+	pipeline := excel.NewPipeline[OrganizationImportRow](&organizationImportTarget{s, payload.TenantId}, payload.ImportOptions).
+		WithReference("organization", s.organizationResolver(payload.TenantId))
+	return runImport(s, ctx, pipeline, &payload)
+}
+
+// 用户按登录名、手机号、邮箱依次匹配本租户的已有记录
+type userImportTarget struct {
+	*ImportService
+	tenantId string
+}
+
+func (t *userImportTarget) Keys(row *UserImportRow) []string {
+	var keys []string
+	if row.Login != "" {
+		keys = append(keys, "login:"+row.Login)
+	}
+	if row.Mobile != "" {
+		keys = append(keys, "mobile:"+util.FullPhoneNumber(row.Mobile))
+	}
+	if row.Email != "" {
+		keys = append(keys, "email:"+row.Email)
+	}
+	return keys
+}
+
+func (t *userImportTarget) Exists(rows []*UserImportRow) (map[int]string, error) {
+	var logins, mobiles, emails []string
+	for _, row := range rows {
+		if row.Login != "" {
+			logins = append(logins, row.Login)
+		}
+		if row.Mobile != "" {
+			mobiles = append(mobiles, util.FullPhoneNumber(row.Mobile))
+		}
+		if row.Email != "" {
+			emails = append(emails, row.Email)
+		}
+	}
+
+	users, err := t.UserRepository.GetUsersByAccounts(t.tenantId, logins, mobiles, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts = make(map[string]string, len(users)*3)
+	for _, user := range users {
+		for _, account := range []*string{user.Login, user.Mobile, user.Email} {
+			if account != nil && *account != "" {
+				accounts[*account] = user.Id
+			}
+		}
+	}
+
+	var result = make(map[int]string)
+	for i, row := range rows {
+		var mobile string
+		if row.Mobile != "" {
+			mobile = util.FullPhoneNumber(row.Mobile)
+		}
+		for _, account := range []string{row.Login, mobile, row.Email} {
+			if id, b := accounts[account]; b && account != "" {
+				result[i] = id
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (t *userImportTarget) user(row *UserImportRow) *dto.User {
+	var user = &dto.User{
+		User: CommonDTO.User{
+			Name:     util.Nullable(util.StringAddr(row.Name)),
+			Login:    util.Nullable(util.StringAddr(row.Login)),
+			Mobile:   util.Nullable(util.StringAddr(row.Mobile)),
+			Email:    util.Nullable(util.StringAddr(row.Email)),
+			Password: util.Nullable(util.StringAddr(row.Password)),
+			TenantId: util.StringAddr(t.tenantId),
+		},
+		Remark: util.Nullable(util.StringAddr(row.Remark)),
+	}
+	return user
+}
+
+func (t *userImportTarget) Create(row *UserImportRow) error {
+	user := t.user(row)
+	user.PlainPassword = util.StringAddr(defaultImportPassword)
+
+	res, err := t.UserService.Create(user)
+	if err != nil {
+		return err
+	}
+	t.join(res.Id, row.OrganizationId)
+	return nil
+}
+
+func (t *userImportTarget) Update(id string, row *UserImportRow) error {
+	if exists, err := t.UserService.GetById(id); err != nil {
+		return err
+	} else if exists == nil || exists.TenantId != t.tenantId {
+		return ErrUserNotInTenant
+	}
+
+	user := t.user(row)
+	user.Id = util.StringAddr(id)
+	user.TenantId = nil
+
+	if _, err := t.UserService.Update(user); err != nil {
+		return err
+	}
+	t.join(id, row.OrganizationId)
+	return nil
+}
+
+func (t *userImportTarget) join(userId, organizationId string) {
+	if organizationId == "" {
+		return
+	}
+	t.OrganizationUserRepository.InsertData(&domain.OrganizationUser{OrganizationId: organizationId, UserId: userId})
+}
+
+// 组织按上级组织和名称匹配已有记录
+type organizationImportTarget struct {
+	*ImportService
+	tenantId string
+}
+
+func (t *organizationImportTarget) Keys(row *OrganizationImportRow) []string {
+	return []string{t.fid(row) + "/" + row.Name}
+}
+
+func (t *organizationImportTarget) Exists(rows []*OrganizationImportRow) (map[int]string, error) {
+	var names = make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+
+	organizations, err := t.OrganizationRepository.GetByNames(t.tenantId, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys = make(map[string]string, len(organizations))
+	for _, organization := range organizations {
+		keys[util.ConditionString(organization.Fid == "", "0", organization.Fid)+"/"+organization.Name] = organization.Id
+	}
+
+	var result = make(map[int]string)
+	for i, row := range rows {
+		if id, b := keys[t.fid(row)+"/"+row.Name]; b {
+			result[i] = id
+		}
+	}
+	return result, nil
+}
+
+// 顶级组织的 fid 为 0
+func (t *organizationImportTarget) fid(row *OrganizationImportRow) string {
+	if row.Fid == "" {
+		return "0"
+	}
+	return row.Fid
+}
+
+func (t *organizationImportTarget) Create(row *OrganizationImportRow) error {
+	var organization = &domain.Organization{
+		Fid:    t.fid(row),
+		Name:   row.Name,
+		Status: row.Status,
+		Remark: row.Remark,
+	}
+	organization.Id = util.UUID()
+	organization.TenantId = t.tenantId
+
+	_, err := t.OrganizationService.CreateOrganization(organization)
+	return err
+}
+
+func (t *organizationImportTarget) Update(id string, row *OrganizationImportRow) error {
+	organization, err := t.OrganizationService.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	if row.Status != "" {
+		organization.Status = row.Status
+	}
+	if row.Remark != "" {
+		organization.Remark = row.Remark
+	}
+	_, err = t.OrganizationService.UpdateOrganization(organization)
+	return err
+}
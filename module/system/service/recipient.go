@@ -0,0 +1,68 @@
+package service
+
+import (
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/notification"
+	"github.com/gophab/gophrame/core/social"
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/service"
+
+	"github.com/gophab/gophrame/module/system/repository"
+)
+
+// 通知接收人展开：角色、组织、租户展开为用户，并带上联系方式和绑定的社交账号
+type RecipientService struct {
+	service.BaseService
+	UserRepository       *repository.UserRepository       `inject:"userRepository"`
+	SocialUserRepository *repository.SocialUserRepository `inject:"socialUserRepository"`
+}
+
+var recipientService = &RecipientService{}
+
+func init() {
+	inject.InjectValue("recipientResolver", recipientService)
+}
+
+func (s *RecipientService) ResolveRecipients(tenantId string, recipients *notification.Recipients) ([]*notification.Recipient, error) {
+	if recipients.IsEmpty() {
+		return []*notification.Recipient{}, nil
+	}
+
+	users, err := s.UserRepository.GetUsersByMembership(tenantId, recipients.Users, recipients.Roles, recipients.Organizations, recipients.Tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result  = make([]*notification.Recipient, 0, len(users))
+		indexes = make(map[string]*notification.Recipient, len(users))
+		userIds = make([]string, 0, len(users))
+	)
+	for _, user := range users {
+		recipient := &notification.Recipient{
+			UserId:   user.Id,
+			TenantId: user.TenantId,
+			Name:     util.NotNullString(user.Name),
+			Mobile:   util.NotNullString(user.Mobile),
+			Email:    util.NotNullString(user.Email),
+			Socials:  make(map[string]*social.SocialUser),
+		}
+		result = append(result, recipient)
+		indexes[user.Id] = recipient
+		userIds = append(userIds, user.Id)
+	}
+
+	if len(userIds) > 0 {
+		socialUsers, err := s.SocialUserRepository.GetByUserIds(userIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, socialUser := range socialUsers {
+			if recipient, b := indexes[util.NotNullString(socialUser.UserId)]; b {
+				recipient.Socials[socialUser.Type] = &socialUser.SocialUser
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -26,7 +26,7 @@ type UserService struct {
 	UserRepository  *repository.UserRepository `inject:"userRepository"`
 	RoleUserService *RoleUserService           `inject:"roleUserService"`
 	RoleService     *RoleService               `inject:"roleService"`
-	Enforcer        *casbin.SyncedEnforcer     `inject:"enforcer"`
+	Enforcer        *casbin.SyncedEnforcer     `inject:"enforcer,optional"`
 }
 
 var userService *UserService = &UserService{}
@@ -235,6 +235,8 @@ func (s *UserService) Delete(user *domain.User) error {
 	if s.Enforcer != nil {
 		s.Enforcer.DeleteUser(user.Id)
 	}
+
+	eventbus.PublishEvent("USER_DELETED", user)
 	return nil
 }
 
@@ -33,8 +33,8 @@ type UserOpenController struct {
 	InviteCodeService  *service.InviteCodeService    `inject:"inviteCodeService_v1"`
 	UserMapper         *mapper.UserMapper            `inject:"userMapper_v1"`
 	SocialUserMapper   *mapper.SocialUserMapper      `inject:"socialUserMapper_v1"`
-	SmsCodeValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator_v1"`
-	EmailCodeValidator *EmailCode.EmailCodeValidator `inject:"emailCodeValidator"`
+	SmsCodeValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator_v1,optional"`
+	EmailCodeValidator *EmailCode.EmailCodeValidator `inject:"emailCodeValidator,optional"`
 }
 
 var userOpenController *UserOpenController = &UserOpenController{}
@@ -288,6 +288,11 @@ func (u *UserOpenController) ChangeUserMobile(c *gin.Context) {
 		return
 	}
 
+	if u.SmsCodeValidator == nil {
+		response.FailMessage(c, errors.INVALID_PARAMS, "不支持手机验证码")
+		return
+	}
+
 	if !u.SmsCodeValidator.CheckCode(u.SmsCodeValidator, request.Target, "bind", request.Code) {
 		response.Forbidden(c, "Forbidden")
 		return
@@ -312,6 +317,11 @@ func (u *UserOpenController) ChangeUserEmail(c *gin.Context) {
 		return
 	}
 
+	if u.EmailCodeValidator == nil {
+		response.FailMessage(c, errors.INVALID_PARAMS, "不支持邮箱验证码")
+		return
+	}
+
 	if !u.EmailCodeValidator.CheckCode(u.EmailCodeValidator, request.Target, "bind", request.Code) {
 		response.Forbidden(c, "Forbidden")
 		return
@@ -16,6 +16,7 @@ const (
 
 var _module = &module.Module{
 	Name:        "SystemV1",
+	DependsOn:   []string{"System"},
 	Description: "",
 }
 
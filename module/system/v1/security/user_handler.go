@@ -24,8 +24,8 @@ import (
 
 type DefaultUserHandler struct {
 	*gorm.DB          `inject:"database"`
-	MobileValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator"`
-	EmailValidator    *EmailCode.EmailCodeValidator `inject:"emailCodeValidator"`
+	MobileValidator   *SmsCode.SmsCodeValidator     `inject:"smsCodeValidator,optional"`
+	EmailValidator    *EmailCode.EmailCodeValidator `inject:"emailCodeValidator,optional"`
 	SocialUserService *service.SocialUserService    `inject:"socialUserService_v1"`
 	UserService       *service.UserService          `inject:"userService_v1"`
 	security.UserHandler
@@ -23,7 +23,7 @@ import (
 type UserService struct {
 	service.BaseService
 	UserRepository *repository.UserRepository `inject:"userRepository_v1"`
-	Enforcer       *casbin.SyncedEnforcer     `inject:"enforcer"`
+	Enforcer       *casbin.SyncedEnforcer     `inject:"enforcer,optional"`
 }
 
 var userService *UserService = &UserService{}
@@ -0,0 +1,11 @@
+package dto
+
+type File struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	Url          string `json:"url"`
+	MimeType     string `json:"mimeType"`
+	Size         int64  `json:"size"`
+	Sha256       string `json:"sha256"`
+	ThumbnailUrl string `json:"thumbnailUrl,omitempty"`
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gophab/gophrame/core/context"
+	"github.com/gophab/gophrame/core/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 实体提供者：按 Id 批量加载同一类实体，返回 id -> 实体，不存在的 id 不出现在结果中
+type EntityProvider interface {
+	GetEntities(entity string, ids []string) (map[string]any, error)
+}
+
+var entityProviders sync.Map
+
+// 注册实体提供者，entities 为其负责的实体类型（不区分大小写）
+func RegisterEntityProvider(provider EntityProvider, entities ...string) {
+	for _, entity := range entities {
+		entityProviders.Store(strings.ToLower(entity), provider)
+	}
+}
+
+func GetEntityProvider(entity string) EntityProvider {
+	if v, b := entityProviders.Load(strings.ToLower(entity)); b {
+		return v.(EntityProvider)
+	}
+	return nil
+}
+
+// 批量加载，空 id 和重复 id 会被忽略
+func GetEntities(entity string, ids []string) (map[string]any, error) {
+	provider := GetEntityProvider(entity)
+	if provider == nil {
+		return map[string]any{}, nil
+	}
+
+	var (
+		keys = make([]string, 0, len(ids))
+		seen = make(map[string]bool, len(ids))
+	)
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			keys = append(keys, id)
+		}
+	}
+	if len(keys) == 0 {
+		return map[string]any{}, nil
+	}
+	return provider.GetEntities(strings.ToLower(entity), keys)
+}
+
+func GetEntity(entity string, id string) any {
+	if result, err := GetEntities(entity, []string{id}); err == nil {
+		return result[id]
+	}
+	return nil
+}
+
+// 实体加载器：先登记需要的 Id，首次读取时每类实体只查询一次，结果在加载器内缓存
+type EntityLoader struct {
+	mutex   sync.Mutex
+	pending map[string]map[string]bool
+	loaded  map[string]map[string]any
+}
+
+func NewEntityLoader() *EntityLoader {
+	return &EntityLoader{
+		pending: make(map[string]map[string]bool),
+		loaded:  make(map[string]map[string]any),
+	}
+}
+
+// 请求内共享的加载器，没有请求上下文时返回新的加载器
+func GetEntityLoader() *EntityLoader {
+	c, _ := context.GetContextValue("_current_context_").(*gin.Context)
+	if c == nil {
+		return NewEntityLoader()
+	}
+
+	if v, b := c.Get("_ENTITY_LOADER_"); b {
+		if loader, ok := v.(*EntityLoader); ok {
+			return loader
+		}
+	}
+
+	loader := NewEntityLoader()
+	c.Set("_ENTITY_LOADER_", loader)
+	return loader
+}
+
+func (l *EntityLoader) Add(entity string, ids ...string) *EntityLoader {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entity = strings.ToLower(entity)
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if loaded, b := l.loaded[entity]; b {
+			if _, b := loaded[id]; b {
+				continue
+			}
+		}
+		if l.pending[entity] == nil {
+			l.pending[entity] = make(map[string]bool)
+		}
+		l.pending[entity][id] = true
+	}
+	return l
+}
+
+// 读取实体，未登记的 id 会和同类型待加载的 id 一起查询
+func (l *EntityLoader) Get(entity string, id string) any {
+	if id == "" {
+		return nil
+	}
+
+	l.Add(entity, id)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entity = strings.ToLower(entity)
+	l.load(entity)
+	return l.loaded[entity][id]
+}
+
+// 加载全部已登记的 id
+func (l *EntityLoader) Load() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for entity := range l.pending {
+		l.load(entity)
+	}
+}
+
+func (l *EntityLoader) load(entity string) {
+	pending := l.pending[entity]
+	if len(pending) == 0 {
+		return
+	}
+	delete(l.pending, entity)
+
+	var ids = make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+
+	if l.loaded[entity] == nil {
+		l.loaded[entity] = make(map[string]any)
+	}
+	loaded := l.loaded[entity]
+
+	result, err := GetEntities(entity, ids)
+	if err != nil {
+		logger.Warn("Load entities error: ", entity, err.Error())
+	}
+	// 未找到的 id 也记录下来，避免重复查询
+	for _, id := range ids {
+		loaded[id] = result[id]
+	}
+}
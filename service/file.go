@@ -0,0 +1,32 @@
+package service
+
+import (
+	"errors"
+	"mime/multipart"
+
+	"github.com/gophab/gophrame/service/dto"
+)
+
+// 上传校验失败，调用方可据此返回参数错误
+var (
+	ErrUnknownFilePolicy  = errors.New("unknown upload policy")
+	ErrFileTooLarge       = errors.New("file too large")
+	ErrFileTypeNotAllowed = errors.New("file type not allowed")
+)
+
+// 文件登记未启用或没有可用的存储，调用方应退回直接上传
+var ErrFileStorageUnavailable = errors.New("file storage not available")
+
+// 文件登记：上传的文件按内容去重，记录上传者和引用方，未被引用的文件会被回收
+type FileService interface {
+	// 按策略（大小、类型）校验并保存上传的文件
+	Save(file *multipart.FileHeader, policy string, createdBy string, tenantId string) (*dto.File, error)
+	// 实体引用文件，fileId 也可以是文件地址
+	AddReference(fileId string, entity string, entityId string) error
+	// 实体删除时解除其全部引用
+	RemoveReferences(entity string, entityId string) error
+}
+
+func GetFileService() FileService {
+	return _services.FileService
+}
@@ -17,10 +17,12 @@ type EntityService[T any] interface {
 }
 
 type __ struct {
-	RoleService       RoleService       `inject:"commonRoleService"`
+	RoleService       RoleService       `inject:"commonRoleService,optional"`
 	UserService       UserService       `inject:"commonUserService"`
-	InviteCodeService InviteCodeService `inject:"commonInviteCodeService"`
-	SocialUserService SocialUserService `inject:"commonSocialUserService"`
+	InviteCodeService InviteCodeService `inject:"commonInviteCodeService,optional"`
+	SocialUserService SocialUserService `inject:"commonSocialUserService,optional"`
+	TaskService       TaskService       `inject:"commonTaskService,optional"`
+	FileService       FileService       `inject:"commonFileService,optional"`
 }
 
 var _services = &__{}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// 异步任务执行上下文，任务被取消或节点停止时 Done() 关闭
+type TaskContext interface {
+	context.Context
+	// 解析提交时的参数
+	Bind(payload any) error
+	// 更新进度（0-100）
+	Progress(progress float32, remark string) error
+	// 文本结果
+	SetResult(result string)
+	// 上传本地文件作为任务结果，返回访问地址
+	SetResultFile(fileName string, prefix string) (string, error)
+	// 上传任务执行中产生的文件（不作为任务结果），返回访问地址
+	UploadFile(fileName string, prefix string) (string, error)
+	// 保存执行进度，任务重试时通过 Restore 读取，从中断处继续
+	Checkpoint(state any) error
+	// 读取上次保存的执行进度，没有时返回 false
+	Restore(state any) (bool, error)
+	// 本次执行失败或被取消后是否不再重试
+	LastAttempt() bool
+}
+
+type TaskHandler func(ctx TaskContext) error
+
+type TaskService interface {
+	// 提交异步任务，返回任务 Id
+	Submit(createdBy string, handler string, name string, payload any) (string, error)
+}
+
+var taskHandlers sync.Map
+
+// 注册异步任务处理器，各模块在 init 中注册，由任务执行器按名称调用
+func RegisterTaskHandler(handler string, h TaskHandler) {
+	taskHandlers.Store(handler, h)
+}
+
+func GetTaskHandler(handler string) TaskHandler {
+	if v, b := taskHandlers.Load(handler); b {
+		return v.(TaskHandler)
+	}
+	return nil
+}
+
+func GetTaskService() TaskService {
+	return _services.TaskService
+}